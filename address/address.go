@@ -29,11 +29,13 @@ type Address struct {
 	SpendKey crypto.Key
 	ViewKey  crypto.Key
 
-	//TODO add support for integrated address
+	PaymentID []byte // integrated address carry 8 byte payment id, otherwise empty
 }
 
 const ChecksumLength = 4
 
+const INTEGRATED_PAYMENT_ID_LENGTH = 8 // integrated addresses always carry encrypted payment id
+
 type Checksum [ChecksumLength]byte
 
 func GetChecksum(data ...[]byte) (result Checksum) {
@@ -49,11 +51,16 @@ func (a *Address) Base58() (result string) {
 	n := binary.PutUvarint(prefix, a.Network)
 	prefix = prefix[:n]
 
-	checksum := GetChecksum(prefix, a.SpendKey[:], a.ViewKey[:])
-	result = EncodeDeroBase58(prefix, a.SpendKey[:], a.ViewKey[:], checksum[:])
+	checksum := GetChecksum(prefix, a.SpendKey[:], a.ViewKey[:], a.PaymentID)
+	result = EncodeDeroBase58(prefix, a.SpendKey[:], a.ViewKey[:], a.PaymentID, checksum[:])
 	return
 }
 
+// is this an integrated address, ie carries a payment id
+func (a *Address) IsIntegratedAddress() bool {
+	return len(a.PaymentID) > 0
+}
+
 // stringifier
 func (a Address) String() string {
 	return a.Base58()
@@ -91,5 +98,11 @@ func NewAddress(address string) (result *Address, err error) {
 	copy(result.SpendKey[:], raw[0:32])
 	copy(result.ViewKey[:], raw[32:64])
 
+	// integrated address has 8 byte payment id after the keys
+	if len(raw) == 64+INTEGRATED_PAYMENT_ID_LENGTH+ChecksumLength {
+		result.PaymentID = make([]byte, INTEGRATED_PAYMENT_ID_LENGTH, INTEGRATED_PAYMENT_ID_LENGTH)
+		copy(result.PaymentID, raw[64:64+INTEGRATED_PAYMENT_ID_LENGTH])
+	}
+
 	return
 }
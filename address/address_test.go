@@ -132,3 +132,56 @@ func TestAddress(t *testing.T) {
 
 	}
 }
+
+func TestIntegratedAddress(t *testing.T) {
+	address, err := NewAddress("dETosYceeTxRZQBk5hQzN51JepzZn5H24JqR96q7mY7ZFo6JhJKPNSKR3vs9ES1ibyQDQgeRheDP6CJbb7AKJY2H9eacz2RtPy")
+	if err != nil {
+		t.Fatalf("Failed while parsing address %s", err)
+	}
+	if address.IsIntegratedAddress() {
+		t.Fatalf("normal address parsed as integrated")
+	}
+
+	address.Network = config.Testnet.Public_Address_Prefix_Integrated
+	address.PaymentID, _ = hex.DecodeString("0123456789abcdef")
+
+	integrated := address.Base58()
+	if integrated[:4] != "dETi" {
+		t.Fatalf("integrated address has wrong prefix %s", integrated)
+	}
+
+	parsed, err := NewAddress(integrated)
+	if err != nil {
+		t.Fatalf("Failed while parsing integrated address %s", err)
+	}
+	if !parsed.IsIntegratedAddress() || bytes.Compare(parsed.PaymentID, address.PaymentID) != 0 {
+		t.Fatalf("payment id mismatch want %x got %x", address.PaymentID, parsed.PaymentID)
+	}
+	if parsed.Network != address.Network || parsed.SpendKey != address.SpendKey || parsed.ViewKey != address.ViewKey {
+		t.Fatalf("integrated address keys mismatch")
+	}
+	if parsed.Base58() != integrated {
+		t.Fatalf("integrated address round trip failed")
+	}
+}
+
+// chunks starting with zero bytes must survive the round trip
+func TestAddressLeadingZeroChunks(t *testing.T) {
+	var address Address
+	address.Network = config.Mainnet.Public_Address_Prefix
+	for i := range address.SpendKey {
+		address.SpendKey[i] = byte(i + 1)
+		address.ViewKey[i] = byte(i + 1)
+	}
+	address.SpendKey[4] = 0 // prefix is 4 bytes, so the second chunk begins with zero bytes
+	address.SpendKey[5] = 0
+	address.ViewKey[31] = 0
+
+	parsed, err := NewAddress(address.Base58())
+	if err != nil {
+		t.Fatalf("Failed while parsing address %s", err)
+	}
+	if parsed.SpendKey != address.SpendKey || parsed.ViewKey != address.ViewKey {
+		t.Fatalf("address keys mismatch after round trip")
+	}
+}
@@ -34,6 +34,9 @@ var base58Lookup = map[string]int{
 }
 var bigBase = big.NewInt(58)
 
+// encoded length of a chunk for every raw length from 0 to 8, see cryptonote base58.cpp
+var encoded_block_sizes = []int{0, 2, 3, 5, 6, 7, 9, 10, 11}
+
 func encodeChunk(raw []byte, padding int) (result string) {
 	remainder := new(big.Int)
 	remainder.SetBytes(raw)
@@ -60,6 +63,13 @@ func decodeChunk(encoded string) (result []byte) {
 		currentMultiplier.Mul(currentMultiplier, bigBase)
 	}
 	result = bigResult.Bytes()
+
+	// leading zero bytes are lost in big int, restore them as per the encoded length
+	for size := range encoded_block_sizes {
+		if encoded_block_sizes[size] == len(encoded) && len(result) < size {
+			result = append(make([]byte, size-len(result)), result...)
+		}
+	}
 	return
 }
 
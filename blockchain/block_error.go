@@ -0,0 +1,84 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "fmt"
+
+import "github.com/arnaucode/derosuite/crypto"
+
+// every reason for which Add_Complete_Block can refuse a block is listed here
+// so as callers ( p2p, miner ) can decide whether the sender is at fault
+type Block_Rule int
+
+const (
+	BLOCK_RULE_PANIC        Block_Rule = iota // verification crashed
+	BLOCK_RULE_EXISTS                         // block is already in chain
+	BLOCK_RULE_ORPHAN                         // parent block is not known yet
+	BLOCK_RULE_FUTURE                         // timestamp too much into future, may be our clock
+	BLOCK_RULE_TIMESTAMP                      // timestamp is less than median timestamp
+	BLOCK_RULE_POW                            // block does not satisfy difficulty
+	BLOCK_RULE_TX_MISMATCH                    // txs in complete block do not match block tx hashes
+	BLOCK_RULE_DOUBLE_SPEND                   // txs within block spend same key image
+	BLOCK_RULE_COINBASE                       // miner tx failed verification
+	BLOCK_RULE_TX                             // some tx failed verification
+)
+
+var block_rule_names = map[Block_Rule]string{
+	BLOCK_RULE_PANIC:        "panic",
+	BLOCK_RULE_EXISTS:       "exists",
+	BLOCK_RULE_ORPHAN:       "orphan",
+	BLOCK_RULE_FUTURE:       "future",
+	BLOCK_RULE_TIMESTAMP:    "timestamp",
+	BLOCK_RULE_POW:          "pow",
+	BLOCK_RULE_TX_MISMATCH:  "tx_mismatch",
+	BLOCK_RULE_DOUBLE_SPEND: "double_spend",
+	BLOCK_RULE_COINBASE:     "coinbase",
+	BLOCK_RULE_TX:           "tx",
+}
+
+func (r Block_Rule) String() string {
+	if name, ok := block_rule_names[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(r))
+}
+
+// this error is returned by Add_Complete_Block, Rule tells which check failed
+type Block_Verify_Error struct {
+	Rule   Block_Rule
+	BLID   crypto.Hash
+	Reason string
+}
+
+func (e *Block_Verify_Error) Error() string {
+	return fmt.Sprintf("Block %s rejected, rule %s failed: %s", e.BLID, e.Rule, e.Reason)
+}
+
+// returns true if the block itself is provably bad, whoever relayed such a block is misbehaving
+// only rules which are checked against the block and its own parents count, already known, orphan or future blocks
+// can be sent by honest peers, txs are verified against our main chain, so a valid alt chain block may fail them
+func (e *Block_Verify_Error) Invalid() bool {
+	switch e.Rule {
+	case BLOCK_RULE_TIMESTAMP, BLOCK_RULE_POW, BLOCK_RULE_TX_MISMATCH, BLOCK_RULE_DOUBLE_SPEND, BLOCK_RULE_COINBASE:
+		return true
+	}
+	return false
+}
+
+func block_error(blid crypto.Hash, rule Block_Rule, format string, args ...interface{}) error {
+	return &Block_Verify_Error{Rule: rule, BLID: blid, Reason: fmt.Sprintf(format, args...)}
+}
@@ -59,11 +59,34 @@ type Blockchain struct {
 	Top_Block_Median_Size uint64 // median block size of current top block
 	Top_Block_Base_Reward uint64 // top block base reward
 
-	checkpints_disabled bool // are checkpoints disabled
+	checkpints_disabled bool   // are checkpoints disabled
+	prune_depth         uint64 // in pruning mode, blocks deeper than this are pruned, 0 if disabled
+
+	P2P_TX_Relayer    p2p_TX_Relayer    // p2p layer registers this, so accepted txs are relayed to peers
+	P2P_Block_Relayer p2p_Block_Relayer // p2p layer registers this, so blocks mined by us are broadcast to peers
+
+	P2P_Orphan_Expired p2p_Orphan_Expired // p2p layer registers this, so peers sending orphans which never connect are scored
+
+	P2P_Orphan_Rejected p2p_Orphan_Rejected // p2p layer registers this, so peers sending invalid orphans are scored
+
+	RPC_Block_Notifier rpc_Block_Notifier // rpc server registers this, so clients can subscribe to new top blocks
+	RPC_Reorg_Notifier rpc_Reorg_Notifier // rpc server registers this, so clients can subscribe to reorganisations
+
+	notifications []func() // queued while adding a block, run only once the block is committed
+
+	orphans orphan_pool // blocks whose parent is not yet known
 
 	sync.RWMutex
 }
 
+// blockchain cannot import p2p, so p2p hands us this function to relay txs
+// it must not block, since it is called while adding txs to pool
+type p2p_TX_Relayer func(tx *transaction.Transaction, stem bool) // stem txs are sent to a single peer
+
+// rpc server hooks, these must not block, since they are called while chain is locked
+type rpc_Block_Notifier func(block_id crypto.Hash, height uint64, difficulty uint64)
+type rpc_Reorg_Notifier func(old_branch []crypto.Hash, new_branch []crypto.Hash) // both exclude the common parent
+
 var logger *log.Entry
 
 //var Exit_Event = make(chan bool) // causes all threads to exit
@@ -114,8 +137,8 @@ func Blockchain_Start(params map[string]interface{}) (*Blockchain, error) {
 		bl := Generate_Genesis_Block()
 		complete_block.Bl = &bl
 
-		if !chain.Add_Complete_Block(&complete_block) {
-			logger.Fatalf("Failed to add genesis block, we can no longer continue")
+		if err := chain.Add_Complete_Block(&complete_block); err != nil {
+			logger.Fatalf("Failed to add genesis block, we can no longer continue, err %s", err)
 		}
 
 	}
@@ -123,6 +146,14 @@ func Blockchain_Start(params map[string]interface{}) (*Blockchain, error) {
 	// load the chain from the disk
 	chain.Initialise_Chain_From_DB()
 
+	chain.init_pruning(params)
+
+	// databases written before payment ids were indexed are reindexed once
+	chain.upgrade_output_index()
+
+	// add txs saved at previous exit back to pool, txs whose key images got spent are dropped
+	chain.Mempool.Mempool_Restore(chain.Verify_Transaction)
+
 	if chain.checkpints_disabled {
 		logger.Infof("Internal Checkpoints are disabled")
 	} else {
@@ -185,6 +216,23 @@ func (chain *Blockchain) Get_Top_ID() crypto.Hash {
 	return chain.Top_ID
 }
 
+// hard fork version to be used by a block at specific height
+// dero does not have a hard fork schedule yet, so a block carries the version of its parent
+func (chain *Blockchain) Get_Current_Version_at_Height(height uint64) uint64 {
+	if height == 0 { // genesis block
+		return 1
+	}
+	block_id, err := chain.Load_BL_ID_at_Height(height - 1)
+	if err != nil {
+		return 1
+	}
+	bl, err := chain.Load_BL_FROM_ID(block_id)
+	if err != nil {
+		return 1
+	}
+	return uint64(bl.Major_Version)
+}
+
 func (chain *Blockchain) Get_Difficulty() uint64 {
 	return chain.Get_Difficulty_At_Block(chain.Top_ID)
 }
@@ -208,55 +256,87 @@ func (chain *Blockchain) Block_Exists(h crypto.Hash) bool {
 // this is the only entrypoint for new txs in the chain
 // add a transaction to MEMPOOL,
 // verifying everything  means everything possible
-// TODO: currently we are not verifying fees, its on TODO list
 // this only change mempool, no DB changes
-func (chain *Blockchain) Add_TX_To_Pool(tx *transaction.Transaction) (result bool) {
+// returns nil if the tx was added, otherwise the reason ( TX_Verify_Error ) why it was rejected
+func (chain *Blockchain) Add_TX_To_Pool(tx *transaction.Transaction) (err error) {
+	return chain.add_tx_to_pool(tx, false)
+}
+
+// same as above, but the tx is added in dandelion stem phase and relayed to a single peer
+// txs created locally and stem txs from peers come here
+func (chain *Blockchain) Add_TX_To_Pool_Stem(tx *transaction.Transaction) (err error) {
+	return chain.add_tx_to_pool(tx, true)
+}
+
+func (chain *Blockchain) add_tx_to_pool(tx *transaction.Transaction, stem bool) (err error) {
+	tx_hash := tx.GetHash()
 
 	// Coin base TX can not come through this path
 	if tx.IsCoinbase() {
-		logger.WithFields(log.Fields{"txid": tx.GetHash()}).Warnf("TX rejected  coinbase tx cannot appear in mempool")
-		return false
+		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("TX rejected  coinbase tx cannot appear in mempool")
+		return tx_error(tx_hash, TX_RULE_COINBASE, "coinbase tx cannot appear in mempool")
 	}
 
-	// check whether enough fees is provided in the transaction
-	// calculate dynamic_fees_per_kb
-	dynamic_fees_per_kb := uint64(0)
-	previous_height := chain.Load_Height_for_BL_ID(chain.Get_Top_ID())
-	if previous_height >= 2 {
-		dynamic_fees_per_kb = chain.Get_Dynamic_Fee_Rate(previous_height)
+	if chain.Mempool.Mempool_TX_Exist(tx_hash) {
+		// someone fluffed our stem tx, so it is public now and we broadcast it like any other
+		if !stem {
+			if fluffed := chain.Mempool.Mempool_Fluff_TX(tx_hash); fluffed != nil {
+				if chain.P2P_TX_Relayer != nil {
+					chain.P2P_TX_Relayer(fluffed, false)
+				}
+				return nil
+			}
+		}
+		return tx_error(tx_hash, TX_RULE_POOL, "tx already in pool")
 	}
 
-	// check whether the fee provided is enoough
-	calculated_fee := chain.Calculate_TX_fee(dynamic_fees_per_kb, uint64(len(tx.Serialize())))
-	provided_fee := tx.RctSignature.Get_TX_Fee() // get fee from tx
-
-	if ((calculated_fee * 98) / 100) > provided_fee { // 2 % margin see blockchain.cpp L 2913
-		logger.WithFields(log.Fields{"txid": tx.GetHash()}).Warnf("TX rejected due to low fees  provided fee %d calculated fee %d", provided_fee, calculated_fee)
-		return false
+	// fee, size, key images, ring members and signature are all checked here
+	if err = chain.Verify_Transaction(tx); err != nil {
+		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("Incoming TX could not be verified, err %s", err)
+		return err
 	}
 
-	if chain.Verify_Transaction_NonCoinbase(tx) {
-		if chain.Mempool.Mempool_Add_TX(tx, 0) {
-			logger.Debugf("successfully added tx to pool")
-			return true
-		} else {
-			logger.Debugf("TX rejected by pool")
-			return false
-		}
+	added := false
+	if stem {
+		added = chain.Mempool.Mempool_Add_Stem_TX(tx, 0)
+	} else {
+		added = chain.Mempool.Mempool_Add_TX(tx, 0)
+	}
+	if !added {
+		logger.Debugf("TX rejected by pool")
+		return tx_error(tx_hash, TX_RULE_POOL, "tx rejected by pool")
 	}
 
-	logger.Warnf("Incoming TX could not be verified")
-	return false
+	logger.Debugf("successfully added tx to pool")
 
+	if chain.P2P_TX_Relayer != nil { // relay the tx to our peers
+		chain.P2P_TX_Relayer(tx, stem)
+	}
+	return nil
 }
 
 // this is the only entrypoint for new / old blocks even for genesis block
 // this will add the entire block atomically to the chain
-// this is the only function which can add blocks to the chain
 // this is exported, so ii can be fed new blocks by p2p layer
 // genesis block is no different
+// blocks whose parent is not known are queued as orphans, they are added as soon as parent is added
+// returns nil if the block was added, otherwise a Block_Verify_Error telling why it was refused
 // TODO: we should stop mining while adding the new block
-func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result bool) {
+func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (err error) {
+	return chain.Add_Complete_Block_From(cbl, "")
+}
+
+// same as Add_Complete_Block, source is the ip of the peer which sent the block
+// if the block is queued as orphan and never connects, the source is reported through P2P_Orphan_Expired
+func (chain *Blockchain) Add_Complete_Block_From(cbl *block.Complete_Block, source string) (err error) {
+	if err = chain.add_complete_block(cbl, source); err == nil {
+		chain.connect_orphans(cbl.Bl.GetHash())
+	}
+	return
+}
+
+// this is the only function which can add blocks to the chain
+func (chain *Blockchain) add_complete_block(cbl *block.Complete_Block, source string) (err error) {
 
 	var block_hash crypto.Hash
 	chain.Lock()
@@ -267,53 +347,67 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		if r := recover(); r != nil {
 			logger.Warnf("Recovered while adding new block, Stack trace below block_hash %s", block_hash)
 			logger.Warnf("Stack trace  \n%s", debug.Stack())
-			result = false
+			err = block_error(block_hash, BLOCK_RULE_PANIC, "recovered while adding block")
 		}
 
-		if result == true { // block was successfully added, commit it atomically
+		if err == nil { // block was successfully added, commit it atomically
 			chain.store.Commit()
 			chain.store.Sync() // sync the DB to disk after every execution of this function
+			for _, notify := range chain.notifications {
+				notify()
+			}
 		} else {
 			chain.store.Rollback() // if block could not be added, rollback all changes to previous block
 		}
+		chain.notifications = nil
 	}()
 
 	bl := cbl.Bl // small pointer to block
 
 	// first of all lets do some quick checks
 	// before doing extensive checks
-	result = false
-
 	block_hash = bl.GetHash()
 	block_logger := logger.WithFields(log.Fields{"blid": block_hash})
 
 	// check if block already exist skip it
 	if chain.Block_Exists(block_hash) {
 		block_logger.Debugf("block already in chain skipping it ")
-		return
+		return block_error(block_hash, BLOCK_RULE_EXISTS, "block already in chain")
 	}
 
 	// make sure prev_hash refers to some point in our our chain
 	// there is an edge case, where we know child but still donot know parent
 	// this might be some some corrupted miner or initial sync
 	if block_hash != globals.Config.Genesis_Block_Hash && !chain.Block_Exists(bl.Prev_Hash) {
-		// TODO we must queue this block for say 60 minutes, if parents donot appear it, discard it
-		block_logger.Warnf("Prev_Hash  no where in the chain, skipping it till we get a parent ")
-		return
+		// difficulty of an orphan is not known till its parent appears, so check PoW against a fraction
+		// of current difficulty, this way orphans cannot be made up for free
+		orphan_difficulty := chain.Get_Difficulty() / ORPHAN_POW_DIVISOR
+		if orphan_difficulty == 0 {
+			orphan_difficulty = 1
+		}
+		if !difficulty.CheckPowHash(bl.GetPoWHash(), orphan_difficulty) {
+			block_logger.Warnf("Orphan block has invalid PoW, rejecting it")
+			return block_error(block_hash, BLOCK_RULE_POW, "invalid PoW")
+		}
+
+		// queue the block till its parent appears, parent is requested by caller
+		chain.add_orphan(cbl, source)
+		block_logger.Debugf("Prev_Hash  no where in the chain, queued as orphan till we get a parent ")
+		return block_error(block_hash, BLOCK_RULE_ORPHAN, "parent %s not found", bl.Prev_Hash)
 	}
 
 	// make sure time is NOT too much into future
 	// if clock diff is more than 2 hrs, reject the block
 	if bl.Timestamp > (uint64(time.Now().Unix()) + config.CRYPTONOTE_BLOCK_FUTURE_TIME_LIMIT) {
 		block_logger.Warnf("Block timestamp is too much into future, make sure that system clock is correct")
-		return
+		return block_error(block_hash, BLOCK_RULE_FUTURE, "timestamp %d too much into future", bl.Timestamp)
 	}
 
 	// verify that the clock is not being run in reverse
 	median_timestamp := chain.Get_Median_Timestamp_At_Block(bl.Prev_Hash)
 	if bl.Timestamp < median_timestamp {
 		block_logger.Warnf("Block timestamp  %d is less than median timestamp (%d) of %d blocks", bl.Timestamp, median_timestamp, config.BLOCKCHAIN_TIMESTAMP_CHECK_WINDOW)
-		return
+		return block_error(block_hash, BLOCK_RULE_TIMESTAMP, "timestamp %d less than median timestamp %d", bl.Timestamp, median_timestamp)
 	}
 
 	// check  a small list 100 hashes whether they have been reached
@@ -335,7 +429,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		// check if the PoW is satisfied
 		if !difficulty.CheckPowHash(PoW, current_difficulty) { // if invalid Pow, reject the bloc
 			block_logger.Warnf("Block has invalid PoW, rejecting it")
-			return false
+			return block_error(block_hash, BLOCK_RULE_POW, "invalid PoW")
 		}
 
 		// TODO we need to verify block size whether it crosses the limits
@@ -345,7 +439,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		{
 			if len(bl.Tx_hashes) != len(cbl.Txs) {
 				block_logger.Warnf("Block says it has %d txs , however complete block contained %d txs", len(bl.Tx_hashes), len(cbl.Txs))
-				return false
+				return block_error(block_hash, BLOCK_RULE_TX_MISMATCH, "block has %d txs, complete block has %d", len(bl.Tx_hashes), len(cbl.Txs))
 			}
 
 			// first check whether the complete block contains any diplicate hashes
@@ -365,7 +459,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 				if _, ok := tx_checklist[tx_hash]; !ok {
 					// tx is NOT found in map, RED alert reject the block
 					block_logger.Warnf("Block says it has tx %s, but complete block does not have it", tx_hash)
-					return false
+					return block_error(block_hash, BLOCK_RULE_TX_MISMATCH, "tx %s not listed in block", tx_hash)
 				}
 			}
 		}
@@ -377,7 +471,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 			for i := 0; i < len(cbl.Txs); i++ {
 				if !block_pool.Mempool_Add_TX(cbl.Txs[i], 0) { // block pool will reject any tx which are duplicates or double spend attacks
 					block_logger.Warnf("Double spend attack  %s, rejecting ", cbl.Txs[i].GetHash())
-					return false
+					return block_error(block_hash, BLOCK_RULE_DOUBLE_SPEND, "tx %s double spends within block", cbl.Txs[i].GetHash())
 				}
 			}
 		}
@@ -387,7 +481,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		if chain.Get_Height() > 5 { // skip checks for first 5 blocks
 			if !chain.Verify_Transaction_Coinbase(cbl, &bl.Miner_tx) {
 				block_logger.Warnf("Miner tx failed verification  rejecting ")
-				return false
+				return block_error(block_hash, BLOCK_RULE_COINBASE, "miner tx failed verification")
 			}
 		}
 
@@ -416,7 +510,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		wg.Wait()           // wait for verifications to finish
 		if fail_count > 0 { // check the result
 			block_logger.Warnf("Block verification failed  rejecting ")
-			return false
+			return block_error(block_hash, BLOCK_RULE_TX, "%d txs failed verification", fail_count)
 		}
 
 	} // checkpoint based validation completed here
@@ -469,6 +563,10 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		chain.Height = chain.Height + 1 // increment height
 		chain.Top_ID = block_hash       // set new top block id
 
+		chain.prune_blocks(1) // in pruning mode, prune the block which just went deeper than prune depth
+
+		chain.notify_top_block()
+
 		block_logger.Debugf("Chain extended new height %d", chain.Height)
 
 		// every 20 block print a line
@@ -481,8 +579,7 @@ func (chain *Blockchain) Add_Complete_Block(cbl *block.Complete_Block) (result b
 		chain.Chain_Add_And_Reorganise(bl)
 	}
 
-	result = true
-	return // run any handlers necesary to atomically
+	return nil // run any handlers necesary to atomically
 }
 
 /* the block we have is NOT at the top, it either belongs to an altchain or is an alternative */
@@ -572,7 +669,7 @@ func (chain *Blockchain) reorganise(block_hash crypto.Hash) {
 
 	// check if  the block mentioned has more than 1 child
 	block_hash, found := chain.find_parent_with_children(block_hash)
-	logger.Debugf("block with children (in reverse) %s  found %d", block_hash, found)
+	logger.Debugf("block with children (in reverse) %s  found %t", block_hash, found)
 	if found {
 		// reorganise chain at this block
 		children := chain.Load_Block_Children(block_hash)
@@ -611,7 +708,11 @@ func (chain *Blockchain) reorganise(block_hash crypto.Hash) {
 			//also walk through all the new main chain till the top, setting output keys, first of all
 			chain.write_output_index(block_hash) // extract and store keys
 			loop_block_hash := block_hash
+			var old_branch, new_branch []crypto.Hash
 			for {
+				if loop_block_hash != block_hash {
+					new_branch = append(new_branch, loop_block_hash)
+				}
 				chain.write_output_index(loop_block_hash) // extract and store keys
 				chain.consume_keyimages(loop_block_hash)  // consume all keyimages
 
@@ -634,6 +735,7 @@ func (chain *Blockchain) reorganise(block_hash crypto.Hash) {
 			// pushing alt_chain txs to mempool after verification
 			loop_block_hash = main_chain // main chain at this point is the old chain
 			for {
+				old_branch = append(old_branch, loop_block_hash)
 				// load the block
 				bl, err := chain.Load_BL_FROM_ID(loop_block_hash)
 				if err != nil {
@@ -670,6 +772,11 @@ func (chain *Blockchain) reorganise(block_hash crypto.Hash) {
 
 			chain.Store_TOP_ID(chain.Top_ID) // make new block top block
 
+			if chain.RPC_Reorg_Notifier != nil {
+				chain.notifications = append(chain.notifications, func() { chain.RPC_Reorg_Notifier(old_branch, new_branch) })
+			}
+			chain.notify_top_block()
+
 			logger.Infof("Reorganise success")
 		}
 
@@ -1000,6 +1107,17 @@ func (chain *Blockchain) Expand_Transaction_v2(tx *transaction.Transaction) (res
 	return true
 }
 
+// queue new top block notification, it is sent once the block is committed
+func (chain *Blockchain) notify_top_block() {
+	if chain.RPC_Block_Notifier == nil {
+		return
+	}
+	top_id, height := chain.Top_ID, chain.Height-1
+	chain.notifications = append(chain.notifications, func() {
+		chain.RPC_Block_Notifier(top_id, height, chain.Get_Difficulty_At_Block(top_id))
+	})
+}
+
 // this function count all the vouts of the block,
 // this function exists here because  only the chain knws the tx
 //
@@ -0,0 +1,215 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "io"
+import "fmt"
+import "bufio"
+import "bytes"
+import "encoding/binary"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/transaction"
+
+/* this file implements export/import of the blockchain as a bootstrap file, so new nodes need not sync from peers
+ * file starts with a header, followed by blocks in height order, each block is followed by its txs
+ * block and tx blobs are prefixed with their length as uint32 little endian
+ * header is magic (8 bytes) + version (uint32) + network id (16 bytes) + number of blocks (uint64)
+ */
+
+const BOOTSTRAP_MAGIC = "DEROBOOT"
+const BOOTSTRAP_VERSION = uint32(1)
+const BOOTSTRAP_HEADER_SIZE = 8 + 4 + 16 + 8
+const BOOTSTRAP_PROGRESS_INTERVAL = 1000 // progress is printed after these many blocks
+
+type bootstrap_header struct {
+	Version uint32
+	Network [16]byte
+	Height  uint64 // number of blocks in file, including genesis
+}
+
+func (h *bootstrap_header) Serialize() []byte {
+	buf := make([]byte, BOOTSTRAP_HEADER_SIZE)
+	copy(buf[0:], BOOTSTRAP_MAGIC)
+	binary.LittleEndian.PutUint32(buf[8:], h.Version)
+	copy(buf[12:], h.Network[:])
+	binary.LittleEndian.PutUint64(buf[28:], h.Height)
+	return buf
+}
+
+func (h *bootstrap_header) Deserialize(buf []byte) error {
+	if len(buf) != BOOTSTRAP_HEADER_SIZE || !bytes.Equal(buf[:8], []byte(BOOTSTRAP_MAGIC)) {
+		return fmt.Errorf("not a bootstrap file")
+	}
+	h.Version = binary.LittleEndian.Uint32(buf[8:])
+	copy(h.Network[:], buf[12:28])
+	h.Height = binary.LittleEndian.Uint64(buf[28:])
+	if h.Version != BOOTSTRAP_VERSION {
+		return fmt.Errorf("unsupported bootstrap file version %d", h.Version)
+	}
+	return nil
+}
+
+// write a length prefixed blob
+func write_bootstrap_object(w io.Writer, data []byte) (err error) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(data)))
+	if _, err = w.Write(length[:]); err == nil {
+		_, err = w.Write(data)
+	}
+	return
+}
+
+// read a length prefixed blob, lengths are sanity checked since file may be corrupted
+func read_bootstrap_object(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(length[:])
+	if uint64(size) > config.CRYPTONOTE_MAX_TX_SIZE {
+		return nil, fmt.Errorf("object size %d too big", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// export all blocks of main chain alongwith their txs in height order
+// pruned databases cannot be exported, since prunable data is required by importers to verify txs
+func (chain *Blockchain) Export_Blockchain(w io.Writer) (count uint64, err error) {
+	if chain.Is_Pruned() {
+		return 0, fmt.Errorf("pruned database cannot be exported")
+	}
+
+	chain.RLock()
+	defer chain.RUnlock()
+
+	header := bootstrap_header{Version: BOOTSTRAP_VERSION, Network: globals.Config.Network_ID, Height: chain.Height}
+	bw := bufio.NewWriter(w)
+	if _, err = bw.Write(header.Serialize()); err != nil {
+		return
+	}
+
+	for ; count < header.Height; count++ {
+		block_id, err := chain.Load_BL_ID_at_Height(count)
+		if err != nil {
+			return count, fmt.Errorf("cannot find block at height %d err %s", count, err)
+		}
+		bl, err := chain.Load_BL_FROM_ID(block_id)
+		if err != nil {
+			return count, fmt.Errorf("cannot load block %s err %s", block_id, err)
+		}
+		if err = write_bootstrap_object(bw, bl.Serialize()); err != nil {
+			return count, err
+		}
+		for i := range bl.Tx_hashes {
+			tx, err := chain.Load_TX_FROM_ID(bl.Tx_hashes[i])
+			if err != nil {
+				return count, fmt.Errorf("cannot load tx %s err %s", bl.Tx_hashes[i], err)
+			}
+			if err = write_bootstrap_object(bw, tx.Serialize()); err != nil {
+				return count, err
+			}
+		}
+
+		if (count+1)%BOOTSTRAP_PROGRESS_INTERVAL == 0 {
+			logger.Infof("Exported %d/%d blocks", count+1, header.Height)
+		}
+	}
+
+	err = bw.Flush()
+	return
+}
+
+// import blocks from a bootstrap file, every block goes through Add_Complete_Block
+// blocks already in chain are skipped, so an interrupted import can be resumed by importing the same file again
+// if trust is set, blocks upto the last checkpoint are not verified, otherwise every block is verified
+func (chain *Blockchain) Import_Blockchain(r io.Reader, trust bool) (imported uint64, err error) {
+	br := bufio.NewReaderSize(r, 1024*1024)
+
+	var header bootstrap_header
+	header_bytes := make([]byte, BOOTSTRAP_HEADER_SIZE)
+	if _, err = io.ReadFull(br, header_bytes); err != nil {
+		return 0, fmt.Errorf("cannot read bootstrap header err %s", err)
+	}
+	if err = header.Deserialize(header_bytes); err != nil {
+		return
+	}
+	if header.Network != globals.Config.Network_ID {
+		return 0, fmt.Errorf("bootstrap file belongs to a different network")
+	}
+
+	// checkpointed blocks are not verified, so unless the file is trusted, checkpoints are not used
+	checkpoints_disabled := chain.checkpints_disabled
+	if !trust {
+		chain.checkpints_disabled = true
+	}
+	defer func() {
+		chain.checkpints_disabled = checkpoints_disabled
+	}()
+
+	logger.Infof("Importing %d blocks, chain height %d", header.Height, chain.Get_Height())
+
+	for height := uint64(0); height < header.Height; height++ {
+		if globals.Exit_In_Progress {
+			return imported, fmt.Errorf("import interrupted at height %d", height)
+		}
+
+		var bl block.Block
+		var cbl block.Complete_Block
+
+		data, err := read_bootstrap_object(br)
+		if err != nil {
+			return imported, fmt.Errorf("cannot read block at height %d err %s", height, err)
+		}
+		if err = bl.Deserialize(data); err != nil {
+			return imported, fmt.Errorf("cannot deserialize block at height %d err %s", height, err)
+		}
+		cbl.Bl = &bl
+		for i := range bl.Tx_hashes {
+			var tx transaction.Transaction
+			if data, err = read_bootstrap_object(br); err != nil {
+				return imported, fmt.Errorf("cannot read tx %s err %s", bl.Tx_hashes[i], err)
+			}
+			if err = tx.DeserializeHeader(data); err != nil {
+				return imported, fmt.Errorf("cannot deserialize tx %s err %s", bl.Tx_hashes[i], err)
+			}
+			cbl.Txs = append(cbl.Txs, &tx)
+		}
+
+		if chain.Block_Exists(bl.GetHash()) { // imported earlier
+			continue
+		}
+
+		if err = chain.Add_Complete_Block(&cbl); err != nil {
+			return imported, fmt.Errorf("block %s at height %d rejected err %s", bl.GetHash(), height, err)
+		}
+		imported++
+
+		if (height+1)%BOOTSTRAP_PROGRESS_INTERVAL == 0 {
+			logger.Infof("Imported %d/%d blocks", height+1, header.Height)
+		}
+	}
+
+	logger.Infof("Import complete, %d blocks imported, chain height %d", imported, chain.Get_Height())
+	return
+}
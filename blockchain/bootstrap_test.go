@@ -0,0 +1,123 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "io"
+import "bytes"
+import "testing"
+import "encoding/binary"
+
+import "github.com/arnaucode/derosuite/globals"
+
+// bootstrap header and objects must round trip, corrupted files must be rejected
+func Test_Bootstrap_Format(t *testing.T) {
+	header := bootstrap_header{Version: BOOTSTRAP_VERSION, Network: globals.Config.Network_ID, Height: 12345}
+
+	var buf bytes.Buffer
+	buf.Write(header.Serialize())
+	write_bootstrap_object(&buf, []byte("block"))
+	write_bootstrap_object(&buf, []byte{})
+	write_bootstrap_object(&buf, []byte("tx"))
+
+	var decoded bootstrap_header
+	if err := decoded.Deserialize(buf.Next(BOOTSTRAP_HEADER_SIZE)); err != nil || decoded != header {
+		t.Fatalf("header did not round trip err %s %+v", err, decoded)
+	}
+	for _, expected := range []string{"block", "", "tx"} {
+		data, err := read_bootstrap_object(&buf)
+		if err != nil || string(data) != expected {
+			t.Fatalf("object did not round trip err %s data %q", err, data)
+		}
+	}
+	if _, err := read_bootstrap_object(&buf); err == nil {
+		t.Fatalf("reading past end must fail")
+	}
+
+	corrupted := header.Serialize()
+	corrupted[0] = 'X'
+	if err := decoded.Deserialize(corrupted); err == nil {
+		t.Fatalf("wrong magic accepted")
+	}
+	corrupted = header.Serialize()
+	binary.LittleEndian.PutUint32(corrupted[8:], BOOTSTRAP_VERSION+1)
+	if err := decoded.Deserialize(corrupted); err == nil {
+		t.Fatalf("unknown version accepted")
+	}
+
+	// truncated and oversized objects
+	buf.Reset()
+	write_bootstrap_object(&buf, []byte("block"))
+	if _, err := read_bootstrap_object(bytes.NewReader(buf.Bytes()[:6])); err == nil {
+		t.Fatalf("truncated object accepted")
+	}
+	if _, err := read_bootstrap_object(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})); err == nil {
+		t.Fatalf("oversized object accepted")
+	}
+}
+
+// reader which sets exit flag once limit bytes have been read, reads are small so import sees the flag midway
+type interrupting_reader struct {
+	r     io.Reader
+	read  int
+	limit int
+}
+
+func (ir *interrupting_reader) Read(p []byte) (n int, err error) {
+	if len(p) > 64 {
+		p = p[:64]
+	}
+	n, err = ir.r.Read(p)
+	if ir.read += n; ir.read >= ir.limit {
+		globals.Exit_In_Progress = true
+	}
+	return
+}
+
+// an import stopped midway must resume from where it stopped when the same file is imported again
+func Test_Bootstrap_Import_Resume(t *testing.T) {
+	const blocks = 10
+
+	chain, cleanup := test_start_chain(t)
+	test_mine_blocks(t, chain, blocks)
+	top := chain.Get_Top_ID()
+	var file bytes.Buffer
+	if count, err := chain.Export_Blockchain(&file); err != nil || count != blocks+1 {
+		t.Fatalf("export failed count %d err %s", count, err)
+	}
+	cleanup()
+
+	chain, cleanup = test_start_chain(t)
+	defer cleanup()
+	defer func() { globals.Exit_In_Progress = false }()
+
+	imported, err := chain.Import_Blockchain(&interrupting_reader{r: bytes.NewReader(file.Bytes()), limit: file.Len() / 2}, false)
+	if err == nil || imported == 0 || imported >= blocks {
+		t.Fatalf("import was not interrupted midway, imported %d err %v", imported, err)
+	}
+	if chain.Get_Height() != imported+1 {
+		t.Fatalf("chain height %d does not match imported blocks %d", chain.Get_Height(), imported)
+	}
+
+	globals.Exit_In_Progress = false
+	resumed, err := chain.Import_Blockchain(bytes.NewReader(file.Bytes()), false)
+	if err != nil || imported+resumed != blocks {
+		t.Fatalf("import did not resume, imported %d resumed %d err %v", imported, resumed, err)
+	}
+	if chain.Get_Top_ID() != top {
+		t.Fatalf("chain top %s does not match exported top %s", chain.Get_Top_ID(), top)
+	}
+}
@@ -16,12 +16,19 @@
 
 package mempool
 
+import "os"
+import "sort"
 import "sync"
 import "time"
+import "strconv"
+import "math/bits"
+import "io/ioutil"
 import "sync/atomic"
 
 import log "github.com/sirupsen/logrus"
+import "github.com/vmihailenco/msgpack"
 
+import "github.com/arnaucode/derosuite/config"
 import "github.com/arnaucode/derosuite/transaction"
 import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/crypto"
@@ -34,23 +41,58 @@ import "github.com/arnaucode/derosuite/crypto"
 // at this point in time, this is an ultrafast written mempool,
 // it will not scale for more than 10000 transactions  but is good enough for now
 // we can always come back and rewrite it
-// NOTE: the pool is not persistant , means closing the daemon will make the mempool empty next restart
-// TODO:  make the pool persistant
+// pool size is capped, when full lowest fee per KB txs are evicted, txs older than ttl are dropped
+// NOTE: the pool is saved to disk at shutdown and reloaded next restart, see Mempool_Restore
 type Mempool struct {
 	txs        map[crypto.Hash]mempool_object
 	key_images map[crypto.Hash]bool // contains key images of all txs
 	modified   bool                 // used to monitor whethel mem pool contents have changed,
 
+	filename string             // pool is saved to this file at shutdown, empty means do not save
+	saved    []mempool_tx_saved // txs loaded from disk, waiting to be verified and restored
+
+	size     uint64 // total size of all txs in bytes
+	max_size uint64 // when pool reaches this size, lowest fee txs are evicted, 0 means no limit
+	ttl      uint64 // txs older than this many seconds are dropped, 0 means never
+	exit     chan bool
+
+	RPC_TX_Notifier rpc_TX_Notifier // rpc server registers this, so clients can subscribe to pool changes
+
 	// global variable , but don't see it utilisation here except fot tx verification
 	//chain *Blockchain
 
 	sync.Mutex
 }
 
+// called whenever a tx becomes public in pool or leaves pool, stem txs are not reported till fluffed
+// it must not block, since it is called while pool is locked
+type rpc_TX_Notifier func(txid crypto.Hash, added bool)
+
 type mempool_object struct {
-	Tx     *transaction.Transaction
-	Added  uint64 // time in epoch format
-	Reason int    //  why is the tx in the mempool
+	Tx      *transaction.Transaction
+	Added   uint64 // time in epoch format
+	Relayed uint64 // time in epoch format, when the tx was last relayed to peers
+	Reason  int    //  why is the tx in the mempool
+	Size    uint64 // serialized size in bytes
+	Fee     uint64 // fee paid by tx
+	Stem    bool   // tx is in dandelion stem phase, it is hidden from public view till fluffed
+}
+
+// tx info as returned by the fee priority iterator
+type Mempool_TX_Info struct {
+	TXID crypto.Hash
+	Tx   *transaction.Transaction
+	Size uint64
+	Fee  uint64
+	stem bool
+}
+
+// this is how pool txs are saved to disk
+type mempool_tx_saved struct {
+	Tx     []byte // serialized tx
+	Added  uint64
+	Reason int
+	Stem   bool // stem txs are restored in stem phase, so they are not broadcast as if we were their origin
 }
 
 var loggerpool *log.Entry
@@ -66,11 +108,32 @@ func Init_Mempool(params map[string]interface{}) (*Mempool, error) {
 	// initialize maps
 	mempool.txs = map[crypto.Hash]mempool_object{}
 	mempool.key_images = map[crypto.Hash]bool{}
-	//TODO load any trasactions saved at previous exit
+	mempool.max_size = parse_uint_argument("--mempool-size", config.MEMPOOL_MAX_SIZE)
+	mempool.ttl = parse_uint_argument("--mempool-ttl", config.MEMPOOL_TX_LIVETIME)
+	mempool.exit = make(chan bool)
+
+	// load any trasactions saved at previous exit, they are added to pool after verification
+	mempool.filename = globals.Network_Filename(config.POOLDATA_FILENAME)
+	mempool.load()
+
+	go mempool.house_keeping()
 
 	return &mempool, nil
 }
 
+// read an optional numeric command line argument, if missing or invalid the default is used
+func parse_uint_argument(name string, default_value uint64) uint64 {
+	if globals.Arguments[name] == nil {
+		return default_value
+	}
+	value, err := strconv.ParseUint(globals.Arguments[name].(string), 10, 64)
+	if err != nil {
+		loggerpool.Warnf("Invalid %s value, using default %d", name, default_value)
+		return default_value
+	}
+	return value
+}
+
 // this is created per incoming block and then discarded
 // This does not require shutting down and will be garbage collected automatically
 func Init_Block_Mempool(params map[string]interface{}) (*Mempool, error) {
@@ -79,13 +142,14 @@ func Init_Block_Mempool(params map[string]interface{}) (*Mempool, error) {
 	// initialize maps
 	mempool.txs = map[crypto.Hash]mempool_object{}
 	mempool.key_images = map[crypto.Hash]bool{}
-	//TODO load any trasactions saved at previous exit
+	mempool.exit = make(chan bool)
 
 	return &mempool, nil
 }
 
 func (pool *Mempool) Shutdown() {
-	//TODO save mempool tx somewhere
+	close(pool.exit)
+	pool.save()
 	loggerpool.Infof("Mempool stopped")
 	atomic.AddUint32(&globals.Subsystem_Active, ^uint32(0)) // this decrement 1 fom subsystem
 
@@ -109,6 +173,15 @@ func (pool *Mempool) HasChanged() (result bool) {
 
 // a tx should only be added to pool after verification is complete
 func (pool *Mempool) Mempool_Add_TX(tx *transaction.Transaction, Reason int) (result bool) {
+	return pool.add_tx(tx, Reason, false)
+}
+
+// add a tx in stem phase, it is not listed, served or mined till it is fluffed
+func (pool *Mempool) Mempool_Add_Stem_TX(tx *transaction.Transaction, Reason int) (result bool) {
+	return pool.add_tx(tx, Reason, true)
+}
+
+func (pool *Mempool) add_tx(tx *transaction.Transaction, Reason int, stem bool) (result bool) {
 	result = false
 	pool.Lock()
 	defer pool.Unlock()
@@ -134,19 +207,42 @@ func (pool *Mempool) Mempool_Add_TX(tx *transaction.Transaction, Reason int) (re
 		}
 	}
 
+	object.Tx = tx
+	object.Reason = Reason
+	object.Stem = stem
+	object.Size = uint64(len(tx.Serialize()))
+	object.Fee = tx_fee(tx)
+
+	// make space for the tx, if pool is full
+	if pool.max_size > 0 && pool.size+object.Size > pool.max_size {
+		evict_list := pool.evict_list(object)
+		if evict_list == nil {
+			loggerpool.WithFields(log.Fields{"txid": tx_hash}).Debugf("Pool is full and tx fee is too low, rejected")
+			return false
+		}
+		for _, txid := range evict_list {
+			loggerpool.WithFields(log.Fields{"txid": txid}).Debugf("Pool is full, evicting low fee tx")
+			pool.delete_tx(txid)
+		}
+	}
+
 	// add all the key images to check double spend attack within the pool
 	for i := 0; i < len(tx.Vin); i++ {
 		pool.key_images[tx.Vin[i].(transaction.Txin_to_key).K_image] = true // add element to map for next check
 	}
 
 	// we are here means we can add it to pool
-	object.Tx = tx
-	object.Reason = Reason
 	object.Added = uint64(time.Now().Unix())
+	object.Relayed = object.Added // tx is relayed as soon as it is accepted
 
 	pool.txs[tx_hash] = object
+	pool.size += object.Size
 	pool.modified = true // pool has been modified
 
+	if !stem && pool.RPC_TX_Notifier != nil {
+		pool.RPC_TX_Notifier(tx_hash, true)
+	}
+
 	return true
 }
 
@@ -161,6 +257,18 @@ func (pool *Mempool) Mempool_TX_Exist(txid crypto.Hash) (result bool) {
 	return false
 }
 
+// check whether a key image is already used by some tx in the pool
+// this is used to reject double spends before the tx reaches the pool
+func (pool *Mempool) Mempool_Keyimage_Used(kimage crypto.Hash) (result bool) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	if _, ok := pool.key_images[kimage]; ok {
+		return true
+	}
+	return false
+}
+
 // delete specific tx from pool and return it
 // if nil is returned Tx was not found in pool
 func (pool *Mempool) Mempool_Delete_TX(txid crypto.Hash) (tx *transaction.Transaction) {
@@ -173,9 +281,15 @@ func (pool *Mempool) Mempool_Delete_TX(txid crypto.Hash) (tx *transaction.Transa
 		return nil
 	}
 
+	return pool.delete_tx(txid)
+}
+
+// remove tx along with its key images, caller must hold the lock and make sure the tx exists
+func (pool *Mempool) delete_tx(txid crypto.Hash) *transaction.Transaction {
 	// we reached here means, we have the tx remove it from our list, do maintainance cleapup and discard it
 	object := pool.txs[txid]
 	delete(pool.txs, txid)
+	pool.size -= object.Size
 
 	// remove all the key images
 	for i := 0; i < len(object.Tx.Vin); i++ {
@@ -183,7 +297,11 @@ func (pool *Mempool) Mempool_Delete_TX(txid crypto.Hash) (tx *transaction.Transa
 	}
 
 	pool.modified = true // pool has been modified
-	return object.Tx     // return the tx
+
+	if !object.Stem && pool.RPC_TX_Notifier != nil {
+		pool.RPC_TX_Notifier(txid, false)
+	}
+	return object.Tx // return the tx
 }
 
 // get specific tx from mem pool without removing it
@@ -202,15 +320,303 @@ func (pool *Mempool) Mempool_Get_TX(txid crypto.Hash) (tx *transaction.Transacti
 	return object.Tx
 }
 
-// return list of all txs in pool
+// return list of all txs in pool, stem txs are not listed as this is the public view
 func (pool *Mempool) Mempool_List_TX() []crypto.Hash {
 	pool.Lock()
 	defer pool.Unlock()
 
 	var list []crypto.Hash
-	for k, _ := range pool.txs {
-		list = append(list, k)
+	for k, v := range pool.txs {
+		if !v.Stem {
+			list = append(list, k)
+		}
+	}
+
+	return list
+}
+
+// return list of txs in stem phase, p2p uses this to embargo stem txs restored from disk
+func (pool *Mempool) Mempool_List_Stem_TX() []crypto.Hash {
+	pool.Lock()
+	defer pool.Unlock()
+
+	var list []crypto.Hash
+	for k, v := range pool.txs {
+		if v.Stem {
+			list = append(list, k)
+		}
+	}
+	return list
+}
+
+// check whether a tx is in pool and still in stem phase
+func (pool *Mempool) Mempool_TX_Is_Stem(txid crypto.Hash) bool {
+	pool.Lock()
+	defer pool.Unlock()
+	return pool.txs[txid].Stem
+}
+
+// move a stem tx to fluff phase, making it public
+// returns the tx if it was in stem phase, nil otherwise
+func (pool *Mempool) Mempool_Fluff_TX(txid crypto.Hash) *transaction.Transaction {
+	pool.Lock()
+	defer pool.Unlock()
+
+	object, ok := pool.txs[txid]
+	if !ok || !object.Stem {
+		return nil
+	}
+	object.Stem = false
+	object.Relayed = uint64(time.Now().Unix()) // tx is broadcast as soon as it is fluffed
+	pool.txs[txid] = object
+	pool.modified = true
+
+	if pool.RPC_TX_Notifier != nil {
+		pool.RPC_TX_Notifier(txid, true)
+	}
+	return object.Tx
+}
+
+// return total size of all txs in pool in bytes
+func (pool *Mempool) Mempool_Size() uint64 {
+	pool.Lock()
+	defer pool.Unlock()
+	return pool.size
+}
+
+// call the callback for all pool txs, highest fee per KB first
+// stem txs are skipped, a block containing a tx nobody else has seen would reveal its origin
+// callback is called without holding pool lock, return false from it to stop iteration
+func (pool *Mempool) Mempool_Iterate_By_Fee(callback func(info Mempool_TX_Info) bool) {
+	pool.Lock()
+	list := pool.sorted_by_fee()
+	pool.Unlock()
+
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].stem {
+			continue
+		}
+		if !callback(list[i]) {
+			return
+		}
+	}
+}
+
+// return all txs sorted by fee per KB, lowest first, caller must hold the lock
+// txs with equal fee rate are ordered by age, so newer txs are evicted first
+func (pool *Mempool) sorted_by_fee() []Mempool_TX_Info {
+	list := make([]Mempool_TX_Info, 0, len(pool.txs))
+	for k, v := range pool.txs {
+		list = append(list, Mempool_TX_Info{TXID: k, Tx: v.Tx, Size: v.Size, Fee: v.Fee, stem: v.Stem})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if fee_rate_compare(list[i].Fee, list[i].Size, list[j].Fee, list[j].Size) != 0 {
+			return fee_rate_compare(list[i].Fee, list[i].Size, list[j].Fee, list[j].Size) < 0
+		}
+		if pool.txs[list[i].TXID].Added != pool.txs[list[j].TXID].Added {
+			return pool.txs[list[i].TXID].Added > pool.txs[list[j].TXID].Added
+		}
+		return string(list[i].TXID[:]) < string(list[j].TXID[:])
+	})
+	return list
+}
+
+// find lowest fee txs which need to be evicted to make space for the new tx
+// returns nil if the tx cannot be accomodated, since it pays less than the txs which would be evicted
+// caller must hold the lock
+func (pool *Mempool) evict_list(object mempool_object) (list []crypto.Hash) {
+	if object.Size > pool.max_size {
+		return nil
+	}
+	freed := uint64(0)
+	for _, info := range pool.sorted_by_fee() {
+		if pool.size-freed+object.Size <= pool.max_size {
+			break
+		}
+		if fee_rate_compare(info.Fee, info.Size, object.Fee, object.Size) >= 0 {
+			return nil
+		}
+		list = append(list, info.TXID)
+		freed += info.Size
+	}
+	return list
+}
+
+// compare fee per KB of 2 txs, returns -1 , 0, +1
+// cross multiplication is used to avoid rounding, products are 128 bit so they cannot overflow
+func fee_rate_compare(fee_a, size_a, fee_b, size_b uint64) int {
+	a_hi, a_lo := bits.Mul64(fee_a, size_b)
+	b_hi, b_lo := bits.Mul64(fee_b, size_a)
+	switch {
+	case a_hi < b_hi, a_hi == b_hi && a_lo < b_lo:
+		return -1
+	case a_hi > b_hi, a_hi == b_hi && a_lo > b_lo:
+		return 1
 	}
+	return 0
+}
 
+// extract fee from tx, malformed txs are considered to pay nothing
+func tx_fee(tx *transaction.Transaction) (fee uint64) {
+	defer func() {
+		if r := recover(); r != nil {
+			fee = 0
+		}
+	}()
+	return tx.RctSignature.Get_TX_Fee()
+}
+
+// drop txs which have been in pool for longer than ttl
+func (pool *Mempool) expire(now uint64) (count int) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	if pool.ttl == 0 {
+		return
+	}
+	for k, v := range pool.txs {
+		if v.Added+pool.ttl < now {
+			loggerpool.WithFields(log.Fields{"txid": k}).Debugf("TX expired, removing from pool")
+			pool.delete_tx(k)
+			count++
+		}
+	}
+	return
+}
+
+// periodically drop expired txs, till the pool is shutdown
+func (pool *Mempool) house_keeping() {
+	ticker := time.NewTicker(60 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pool.exit:
+			return
+		case <-ticker.C:
+			if count := pool.expire(uint64(time.Now().Unix())); count > 0 {
+				loggerpool.Infof("Removed %d expired txs from pool", count)
+			}
+		}
+	}
+}
+
+// return list of txs which have not been relayed for the given duration
+// the returned txs are marked as relayed now, so they are not returned again before the duration expires
+func (pool *Mempool) Mempool_Rebroadcast_List(interval time.Duration) (list []*transaction.Transaction) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	now := uint64(time.Now().Unix())
+	for k, v := range pool.txs {
+		if !v.Stem && v.Relayed+uint64(interval.Seconds()) <= now { // stem txs are fluffed on embargo expiry
+			v.Relayed = now
+			pool.txs[k] = v
+			list = append(list, v.Tx)
+		}
+	}
 	return list
 }
+
+// save all pool txs to disk, so they survive restart
+func (pool *Mempool) save() {
+	if pool.filename == "" {
+		return
+	}
+
+	pool.Lock()
+	var list []mempool_tx_saved
+	for _, v := range pool.txs {
+		list = append(list, mempool_tx_saved{Tx: v.Tx.Serialize(), Added: v.Added, Reason: v.Reason, Stem: v.Stem})
+	}
+	pool.Unlock()
+
+	data, err := msgpack.Marshal(list)
+	if err != nil {
+		loggerpool.Warnf("Error while serializing pool err %s", err)
+		return
+	}
+
+	// write to a temporary file first, so a crash does not leave a half written pool
+	tmp_filename := pool.filename + ".tmp"
+	if err = ioutil.WriteFile(tmp_filename, data, 0600); err != nil {
+		loggerpool.Warnf("Error while saving pool to %s err %s", tmp_filename, err)
+		return
+	}
+	if err = os.Rename(tmp_filename, pool.filename); err != nil {
+		loggerpool.Warnf("Error while saving pool to %s err %s", pool.filename, err)
+		return
+	}
+	loggerpool.Infof("Saved %d txs from pool to %s", len(list), pool.filename)
+}
+
+// load txs saved at previous exit, they are not added to pool till Mempool_Restore is called
+func (pool *Mempool) load() {
+	data, err := ioutil.ReadFile(pool.filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			loggerpool.Warnf("Error while loading pool from %s err %s", pool.filename, err)
+		}
+		return
+	}
+
+	var list []mempool_tx_saved
+	if err = msgpack.Unmarshal(data, &list); err != nil {
+		loggerpool.Warnf("Error while deserializing pool from %s err %s", pool.filename, err)
+		return
+	}
+
+	pool.Lock()
+	pool.saved = list
+	pool.Unlock()
+}
+
+// add txs loaded from disk to the pool, if they still pass verification against the current chain
+// txs whose key images have been spent meanwhile are dropped
+// restored txs are rebroadcast soon, since peers might have lost them
+// stem txs stay in stem phase, p2p restarts their embargo, see Mempool_List_Stem_TX
+func (pool *Mempool) Mempool_Restore(verify func(tx *transaction.Transaction) error) (restored int, dropped int) {
+	pool.Lock()
+	list := pool.saved
+	pool.saved = nil
+	pool.Unlock()
+
+	now := uint64(time.Now().Unix())
+	for i := range list {
+		if pool.ttl != 0 && list[i].Added+pool.ttl < now { // tx expired while we were down
+			dropped++
+			continue
+		}
+
+		var tx transaction.Transaction
+		if err := tx.DeserializeHeader(list[i].Tx); err != nil {
+			loggerpool.Warnf("Saved pool tx could not be deserialized, dropping it err %s", err)
+			dropped++
+			continue
+		}
+
+		tx_hash := tx.GetHash()
+		if err := verify(&tx); err != nil {
+			loggerpool.WithFields(log.Fields{"txid": tx_hash}).Debugf("Saved pool tx dropped err %s", err)
+			dropped++
+			continue
+		}
+
+		if !pool.add_tx(&tx, list[i].Reason, list[i].Stem) {
+			dropped++
+			continue
+		}
+
+		pool.Lock()
+		object := pool.txs[tx_hash]
+		object.Added = list[i].Added
+		object.Relayed = 0
+		pool.txs[tx_hash] = object
+		pool.Unlock()
+		restored++
+	}
+
+	if restored > 0 || dropped > 0 {
+		loggerpool.Infof("Restored %d txs to pool, dropped %d txs", restored, dropped)
+	}
+	return
+}
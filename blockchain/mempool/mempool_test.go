@@ -18,11 +18,16 @@ package mempool
 
 //import "fmt"
 //import "bytes"
+import "os"
+import "fmt"
+import "time"
 import "testing"
 import "encoding/hex"
+import "path/filepath"
 
 import log "github.com/sirupsen/logrus"
 
+import "github.com/arnaucode/derosuite/crypto"
 import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/transaction"
 
@@ -84,6 +89,58 @@ func Test_mempool_Full_block_72_2c4738d3856e8e3e8f9fc4818a9197d4911af3010e067ec5
 		t.Errorf("Pool get_tx failed")
 	}
 
+	// freshly added tx is already relayed, so it should not be rebroadcast
+	if len(pool.Mempool_Rebroadcast_List(time.Hour)) != 0 {
+		t.Errorf("Pool should not rebroadcast fresh tx")
+	}
+
+	if rebroadcast := pool.Mempool_Rebroadcast_List(0); len(rebroadcast) != 1 || rebroadcast[0].GetHash() != tx.GetHash() {
+		t.Errorf("Pool rebroadcast list failed")
+	}
+
+	// save the pool and restore it in a new pool, the tx must keep its added time
+	pool.filename = filepath.Join(os.TempDir(), "derod_test_poolstate.bin")
+	defer os.Remove(pool.filename)
+	pool.save()
+
+	restored_pool, _ := Init_Block_Mempool(nil)
+	restored_pool.filename = pool.filename
+	restored_pool.load()
+	if restored, dropped := restored_pool.Mempool_Restore(func(*transaction.Transaction) error { return nil }); restored != 1 || dropped != 0 {
+		t.Errorf("Pool restore failed restored %d dropped %d", restored, dropped)
+	}
+	if !restored_pool.Mempool_TX_Exist(tx.GetHash()) || restored_pool.txs[tx.GetHash()].Added != pool.txs[tx.GetHash()].Added {
+		t.Errorf("Pool restore did not restore tx correctly")
+	}
+	if len(restored_pool.Mempool_Rebroadcast_List(time.Hour)) != 1 {
+		t.Errorf("Restored tx should be rebroadcast")
+	}
+
+	// txs failing verification ( eg key image spent meanwhile ) must be dropped
+	spent_pool, _ := Init_Block_Mempool(nil)
+	spent_pool.filename = pool.filename
+	spent_pool.load()
+	if restored, dropped := spent_pool.Mempool_Restore(func(*transaction.Transaction) error { return fmt.Errorf("spent") }); restored != 0 || dropped != 1 || len(spent_pool.Mempool_List_TX()) != 0 {
+		t.Errorf("Pool restore should drop txs failing verification")
+	}
+
+	// stem txs must be restored in stem phase, so they are not announced as ours
+	object := pool.txs[tx.GetHash()]
+	object.Stem = true
+	pool.txs[tx.GetHash()] = object
+	pool.save()
+	object.Stem = false
+	pool.txs[tx.GetHash()] = object
+	stem_pool, _ := Init_Block_Mempool(nil)
+	stem_pool.filename = pool.filename
+	stem_pool.load()
+	if restored, _ := stem_pool.Mempool_Restore(func(*transaction.Transaction) error { return nil }); restored != 1 {
+		t.Errorf("Pool restore of stem tx failed")
+	}
+	if !stem_pool.Mempool_TX_Is_Stem(tx.GetHash()) || len(stem_pool.Mempool_List_Stem_TX()) != 1 || len(stem_pool.Mempool_List_TX()) != 0 {
+		t.Errorf("Restored stem tx should stay in stem phase")
+	}
+
 	// re-adding tx should faild
 	if pool.Mempool_Add_TX(&tx, 0) == true || len(pool.Mempool_List_TX()) > 1 {
 		t.Errorf("Pool should not allow duplicate TX")
@@ -130,3 +187,122 @@ func Test_mempool_Full_block_72_2c4738d3856e8e3e8f9fc4818a9197d4911af3010e067ec5
 	}
 
 }
+
+// test fee priority iteration, eviction and expiry using synthetic pool entries
+func Test_mempool_eviction_expiry(t *testing.T) {
+	globals.Logger = log.New()
+	pool, _ := Init_Block_Mempool(nil)
+	loggerpool = globals.Logger.WithFields(log.Fields{"com": "POOL"})
+
+	// add a synthetic tx with 1 key image directly to the pool
+	add := func(id byte, size, fee, added uint64) crypto.Hash {
+		var txid, kimage crypto.Hash
+		txid[0], kimage[0] = id, id
+		tx := &transaction.Transaction{Vin: []transaction.Txin_v{transaction.Txin_to_key{K_image: kimage}}}
+		pool.txs[txid] = mempool_object{Tx: tx, Added: added, Size: size, Fee: fee}
+		pool.key_images[kimage] = true
+		pool.size += size
+		return txid
+	}
+
+	low := add(1, 1000, 1000, 100)  // 1 per byte
+	high := add(2, 1000, 5000, 100) // 5 per byte
+	mid := add(3, 2000, 6000, 50)   // 3 per byte
+
+	var order []crypto.Hash
+	pool.Mempool_Iterate_By_Fee(func(info Mempool_TX_Info) bool {
+		order = append(order, info.TXID)
+		return true
+	})
+	if len(order) != 3 || order[0] != high || order[1] != mid || order[2] != low {
+		t.Errorf("Pool iteration is not sorted by fee per KB")
+	}
+
+	// pool is full, a tx paying 2 per byte can only evict the lowest fee tx
+	pool.max_size = pool.size
+	if list := pool.evict_list(mempool_object{Size: 1000, Fee: 2000}); len(list) != 1 || list[0] != low {
+		t.Errorf("Pool should evict lowest fee tx")
+	}
+	// a tx needing more space than the lower fee txs provide must be rejected
+	if list := pool.evict_list(mempool_object{Size: 2000, Fee: 4000}); list != nil {
+		t.Errorf("Pool should not evict txs paying more fee")
+	}
+	if list := pool.evict_list(mempool_object{Size: pool.max_size + 1, Fee: 1 << 40}); list != nil {
+		t.Errorf("Tx larger than pool should be rejected")
+	}
+
+	pool.delete_tx(low)
+	if pool.size != 3000 || len(pool.key_images) != 2 || pool.Mempool_Keyimage_Used(crypto.Hash{1}) {
+		t.Errorf("Evicted tx must release its size and key image")
+	}
+
+	// mid was added earlier, so it expires first
+	pool.ttl = 100
+	if count := pool.expire(190); count != 1 || pool.Mempool_TX_Exist(mid) || !pool.Mempool_TX_Exist(high) {
+		t.Errorf("Pool expiry failed")
+	}
+	if pool.size != 1000 || len(pool.key_images) != 1 {
+		t.Errorf("Expired tx must release its size and key image")
+	}
+}
+
+// stem txs must stay hidden from public view till they are fluffed
+func Test_mempool_stem(t *testing.T) {
+	globals.Logger = log.New()
+	pool, _ := Init_Block_Mempool(nil)
+	loggerpool = globals.Logger.WithFields(log.Fields{"com": "POOL"})
+
+	var stem_txid, txid crypto.Hash
+	stem_txid[0], txid[0] = 1, 2
+	pool.txs[stem_txid] = mempool_object{Tx: &transaction.Transaction{}, Size: 1000, Fee: 5000, Stem: true}
+	pool.txs[txid] = mempool_object{Tx: &transaction.Transaction{}, Size: 1000, Fee: 1000}
+
+	if list := pool.Mempool_List_TX(); len(list) != 1 || list[0] != txid {
+		t.Errorf("Stem tx must not be listed")
+	}
+	if !pool.Mempool_TX_Exist(stem_txid) || !pool.Mempool_TX_Is_Stem(stem_txid) || pool.Mempool_TX_Is_Stem(txid) {
+		t.Errorf("Stem tx must exist in pool and be marked stem")
+	}
+	var mined []crypto.Hash
+	pool.Mempool_Iterate_By_Fee(func(info Mempool_TX_Info) bool {
+		mined = append(mined, info.TXID)
+		return true
+	})
+	if len(mined) != 1 || mined[0] != txid {
+		t.Errorf("Stem tx must not be mined")
+	}
+	if len(pool.Mempool_Rebroadcast_List(0)) != 1 {
+		t.Errorf("Stem tx must not be rebroadcast")
+	}
+
+	if pool.Mempool_Fluff_TX(txid) != nil {
+		t.Errorf("Fluffing a public tx should do nothing")
+	}
+	if pool.Mempool_Fluff_TX(stem_txid) == nil || pool.Mempool_TX_Is_Stem(stem_txid) || len(pool.Mempool_List_TX()) != 2 {
+		t.Errorf("Fluffed tx must become public")
+	}
+	if pool.Mempool_Fluff_TX(stem_txid) != nil {
+		t.Errorf("Tx can only be fluffed once")
+	}
+}
+
+// huge fees must not overflow while comparing fee rates
+func Test_mempool_fee_rate_compare(t *testing.T) {
+	tests := []struct {
+		fee_a, size_a, fee_b, size_b uint64
+		expected                     int
+	}{
+		{100, 10, 200, 20, 0},
+		{100, 10, 201, 20, -1},
+		{1 << 63, 4, 1, 1, 1}, // product overflows 64 bits
+		{1, 1 << 40, 1 << 62, 1 << 30, -1},
+		{^uint64(0), 1000, ^uint64(0), 1000, 0},
+		{^uint64(0), 1001, ^uint64(0), 1000, -1},
+	}
+
+	for _, test := range tests {
+		if result := fee_rate_compare(test.fee_a, test.size_a, test.fee_b, test.size_b); result != test.expected {
+			t.Errorf("fee_rate_compare(%d,%d,%d,%d) = %d expected %d", test.fee_a, test.size_a, test.fee_b, test.size_b, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+// this file creates block templates for miners and accepts mined blocks back
+
+import "fmt"
+import "bytes"
+import "time"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/emission"
+import "github.com/arnaucode/derosuite/difficulty"
+import "github.com/arnaucode/derosuite/transaction"
+import "github.com/arnaucode/derosuite/blockchain/mempool"
+
+// space kept for miner tx, while filling the block with txs from pool
+const MINER_TX_RESERVED_SIZE = 600
+
+// blockchain cannot import p2p, so p2p hands us this function to broadcast blocks mined by us
+type p2p_Block_Relayer func(cbl *block.Complete_Block)
+
+// create a new block template on top of current top block
+// txs are picked from mempool, till the block reaches the median size, so as block reward is never penalized
+// reserve_size bytes are reserved in the miner tx extra nonce, which miners can use as extra nonce
+func (chain *Blockchain) Create_new_miner_block(miner_address address.Address, reserve_size int) (bl block.Block, err error) {
+	chain.RLock()
+	defer chain.RUnlock()
+
+	top_id := chain.Top_ID
+	height := chain.Height // height of the new block
+
+	top_bl, err := chain.Load_BL_FROM_ID(top_id)
+	if err != nil {
+		return bl, fmt.Errorf("could not load top block %s, err %s", top_id, err)
+	}
+
+	median_block_size := chain.Get_Median_BlockSize_At_Block(top_id)
+	if median_block_size < config.CRYPTONOTE_BLOCK_GRANTED_FULL_REWARD_ZONE {
+		median_block_size = config.CRYPTONOTE_BLOCK_GRANTED_FULL_REWARD_ZONE
+	}
+	already_generated_coins := chain.Load_Already_Generated_Coins_for_BL_ID(top_id)
+	hf_version := chain.Get_Current_Version_at_Height(height)
+
+	// pick highest fee per KB txs first, which are still valid on top of current chain
+	txs_size := uint64(0)
+	total_fees := uint64(0)
+	chain.Mempool.Mempool_Iterate_By_Fee(func(info mempool.Mempool_TX_Info) bool {
+		if txs_size+info.Size+MINER_TX_RESERVED_SIZE > median_block_size { // block would enter penalty zone
+			return true
+		}
+		if chain.is_tx_spent_on_chain(info.Tx) { // some other block already mined the inputs
+			return true
+		}
+		txs_size += info.Size
+		total_fees += info.Fee
+		bl.Tx_hashes = append(bl.Tx_hashes, info.TXID)
+		return true
+	})
+
+	// reward depends on block size, which depends on the miner tx size, which depends on reward
+	// so iterate till the miner tx size stabilises
+	miner_tx_size := uint64(0)
+	for i := 0; i < 4; i++ {
+		base_reward := emission.GetBlockReward(median_block_size, txs_size+miner_tx_size, already_generated_coins, hf_version, 0)
+		bl.Miner_tx, err = Create_Miner_TX(hf_version, height, base_reward+total_fees, miner_address, reserve_size)
+		if err != nil {
+			return bl, err
+		}
+		if uint64(len(bl.Miner_tx.Serialize())) == miner_tx_size {
+			break
+		}
+		miner_tx_size = uint64(len(bl.Miner_tx.Serialize()))
+	}
+
+	bl.Major_Version = top_bl.Major_Version
+	bl.Minor_Version = top_bl.Minor_Version
+	bl.Prev_Hash = top_id
+	bl.Timestamp = uint64(time.Now().Unix())
+
+	// timestamp must not be below median timestamp, or the block will be rejected
+	if median_timestamp := chain.Get_Median_Timestamp_At_Block(top_id); bl.Timestamp < median_timestamp {
+		bl.Timestamp = median_timestamp
+	}
+
+	return bl, nil
+}
+
+// return true if any of the tx inputs have already been spent in the chain
+func (chain *Blockchain) is_tx_spent_on_chain(tx *transaction.Transaction) bool {
+	for i := range tx.Vin {
+		if input, ok := tx.Vin[i].(transaction.Txin_to_key); ok {
+			if chain.Read_KeyImage_Status(input.K_image) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// offset of the reserved extra nonce within the serialized block
+// extra contains tx public key first, followed by extra nonce tag and its length
+func Get_Reserved_Offset(bl *block.Block) (offset int, err error) {
+	tx_public_key, ok := bl.Miner_tx.Extra_map[transaction.TX_PUBLIC_KEY].(crypto.Key)
+	if !ok {
+		return 0, fmt.Errorf("miner tx does not contain public key")
+	}
+	if _, ok := bl.Miner_tx.Extra_map[transaction.TX_EXTRA_NONCE]; !ok {
+		return 0, nil // nothing is reserved
+	}
+
+	blob := bl.Serialize()
+	pos := bytes.Index(blob, tx_public_key[:])
+	if pos < 0 {
+		return 0, fmt.Errorf("tx public key not found in block")
+	}
+	return pos + len(tx_public_key) + 2, nil // skip extra nonce tag and length
+}
+
+// accept a mined block, verify its PoW and add it to chain
+// the txs are picked up from the mempool
+// if the block is accepted, it is broadcast to all peers
+func (chain *Blockchain) Accept_new_block(block_blob []byte) (blid crypto.Hash, err error) {
+	var bl block.Block
+	var cbl block.Complete_Block
+
+	if err = bl.Deserialize(block_blob); err != nil {
+		return blid, fmt.Errorf("block could not be deserialized, err %s", err)
+	}
+	blid = bl.GetHash()
+
+	if !chain.Block_Exists(bl.Prev_Hash) {
+		return blid, fmt.Errorf("block %s refers to unknown parent %s", blid, bl.Prev_Hash)
+	}
+
+	// quick check, before doing anything expensive
+	if !difficulty.CheckPowHash(bl.GetPoWHash(), chain.Get_Difficulty_At_Block(bl.Prev_Hash)) {
+		return blid, fmt.Errorf("block %s has invalid PoW", blid)
+	}
+
+	for i := range bl.Tx_hashes {
+		tx := chain.Mempool.Mempool_Get_TX(bl.Tx_hashes[i])
+		if tx == nil {
+			return blid, fmt.Errorf("block %s contains tx %s which is not in pool", blid, bl.Tx_hashes[i])
+		}
+		cbl.Txs = append(cbl.Txs, tx)
+	}
+	cbl.Bl = &bl
+
+	if err = chain.Add_Complete_Block(&cbl); err != nil {
+		return blid, err
+	}
+
+	logger.WithFields(log.Fields{"blid": blid}).Infof("Mined block accepted at height %d", chain.Load_Height_for_BL_ID(blid))
+
+	if chain.P2P_Block_Relayer != nil { // relay the block to our peers
+		chain.P2P_Block_Relayer(&cbl)
+	}
+	return blid, nil
+}
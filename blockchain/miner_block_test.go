@@ -0,0 +1,95 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "testing"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/walletapi"
+import "github.com/arnaucode/derosuite/transaction"
+
+// reserved offset must point to the extra nonce within the serialized block
+func Test_Get_Reserved_Offset(t *testing.T) {
+	account, _ := walletapi.Generate_Keys_From_Random()
+
+	for _, reserve_size := range []int{0, 8, 60, 255} {
+		var bl block.Block
+		var err error
+
+		bl.Major_Version = 6
+		bl.Minor_Version = 6
+		bl.Miner_tx, err = Create_Miner_TX(6, 12345, 123456789, account.GetAddress(), reserve_size)
+		if err != nil {
+			t.Fatalf("error creating miner tx, err :%s", err)
+		}
+
+		offset, err := Get_Reserved_Offset(&bl)
+		if err != nil {
+			t.Fatalf("reserved offset could not be found err %s", err)
+		}
+
+		if reserve_size == 0 {
+			if offset != 0 {
+				t.Fatalf("nothing reserved, but offset is %d", offset)
+			}
+			continue
+		}
+
+		blob := bl.Serialize()
+		if blob[offset-2] != byte(transaction.TX_EXTRA_NONCE) || int(blob[offset-1]) != reserve_size {
+			t.Fatalf("reserved offset %d does not point to extra nonce", offset)
+		}
+
+		// miner modifies the reserved area, block must still deserialize with modified nonce
+		for i := 0; i < reserve_size; i++ {
+			blob[offset+i] = 0xff
+		}
+		var parsed block.Block
+		if err = parsed.Deserialize(blob); err != nil {
+			t.Fatalf("block with modified reserved area could not be deserialized err %s", err)
+		}
+		if parsed.Miner_tx.Vin[0].(transaction.Txin_gen).Height != 12345 {
+			t.Fatalf("miner tx height mismatch")
+		}
+	}
+}
+
+// block template must carry the hard fork version of the chain at its height
+func Test_Create_New_Miner_Block_Version(t *testing.T) {
+	chain, cleanup := test_start_chain(t)
+	defer cleanup()
+
+	test_mine_blocks(t, chain, 2)
+
+	account, _ := walletapi.Generate_Keys_From_Random()
+	bl, err := chain.Create_new_miner_block(account.GetAddress(), 0)
+	if err != nil {
+		t.Fatalf("cannot create miner block err %s", err)
+	}
+
+	top_bl, err := chain.Load_BL_FROM_ID(chain.Get_Top_ID())
+	if err != nil {
+		t.Fatalf("cannot load top block err %s", err)
+	}
+	version := chain.Get_Current_Version_at_Height(chain.Get_Height())
+	if version != uint64(top_bl.Major_Version) || uint64(bl.Major_Version) != version {
+		t.Fatalf("version mismatch chain %d top block %d template %d", version, top_bl.Major_Version, bl.Major_Version)
+	}
+	if chain.Get_Current_Version_at_Height(0) != 1 {
+		t.Fatalf("genesis version must be 1")
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+/* this file implements the orphan pool
+ * blocks arriving before their parent ( races between peers, out of order NOTIFY_NEW_BLOCK ) are kept here
+ * once the parent is added to chain, queued descendants are added recursively
+ * pool is bounded and orphans whose parent never appears are expired
+ */
+
+import "sync"
+import "time"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto"
+
+const ORPHAN_POOL_LIMIT = 100         // maximum number of orphans kept, oldest is evicted first
+const ORPHAN_SOURCE_LIMIT = 10        // maximum number of orphans kept from a single peer, its oldest is evicted first
+const ORPHAN_BLOCK_LIFETIME = 60 * 60 // in seconds, orphans whose parent do not appear in this time are discarded
+const ORPHAN_POW_DIVISOR = 16         // orphans must satisfy this fraction of current difficulty
+
+// orphans are queued before PoW can be checked, so whoever sent an orphan which never connects is reported
+// blockchain cannot import p2p, so p2p hands us this function, source is the ip of the peer
+type p2p_Orphan_Expired func(source string, block_id crypto.Hash)
+
+// orphans which turn out invalid once their parent arrives are reported with the peer which sent them
+type p2p_Orphan_Rejected func(source string, err error)
+
+type orphan_block struct {
+	cbl    *block.Complete_Block
+	hash   crypto.Hash
+	source string // peer which sent the orphan, empty if not known
+	added  time.Time
+}
+
+type orphan_pool struct {
+	sync.Mutex
+	by_parent map[crypto.Hash][]*orphan_block // key is prev_hash of orphans
+	by_hash   map[crypto.Hash]*orphan_block
+}
+
+// queue an orphan, returns false if it was already queued
+// orphans expired meanwhile are returned, so their sources can be reported
+func (p *orphan_pool) add(cbl *block.Complete_Block, source string, now time.Time) (queued bool, expired []*orphan_block) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.by_hash == nil {
+		p.by_parent = map[crypto.Hash][]*orphan_block{}
+		p.by_hash = map[crypto.Hash]*orphan_block{}
+	}
+
+	expired = p.expire(now)
+
+	hash := cbl.Bl.GetHash()
+	if _, ok := p.by_hash[hash]; ok {
+		return false, expired
+	}
+
+	// a single peer cannot fill the pool and push out orphans sent by others
+	if source != "" {
+		for p.evict_oldest(source, ORPHAN_SOURCE_LIMIT) {
+		}
+	}
+	for p.evict_oldest("", ORPHAN_POOL_LIMIT) { // evict oldest orphan to make room
+	}
+
+	o := &orphan_block{cbl: cbl, hash: hash, source: source, added: now}
+	p.by_hash[hash] = o
+	p.by_parent[cbl.Bl.Prev_Hash] = append(p.by_parent[cbl.Bl.Prev_Hash], o)
+	logger.Debugf("Orphan block %s queued, waiting for parent %s, orphans %d", hash, cbl.Bl.Prev_Hash, len(p.by_hash))
+	return true, expired
+}
+
+// evict oldest orphan of source ( all sources if empty ) if it has reached the limit, caller must hold the lock
+// returns true if an orphan was evicted
+func (p *orphan_pool) evict_oldest(source string, limit int) bool {
+	count := 0
+	var oldest *orphan_block
+	for _, o := range p.by_hash {
+		if source != "" && o.source != source {
+			continue
+		}
+		count++
+		if oldest == nil || o.added.Before(oldest.added) {
+			oldest = o
+		}
+	}
+	if count < limit {
+		return false
+	}
+	p.remove(oldest)
+	return true
+}
+
+// remove an orphan from both maps, caller must hold the lock
+func (p *orphan_pool) remove(o *orphan_block) {
+	delete(p.by_hash, o.hash)
+	parent := o.cbl.Bl.Prev_Hash
+	siblings := p.by_parent[parent][:0]
+	for _, s := range p.by_parent[parent] {
+		if s != o {
+			siblings = append(siblings, s)
+		}
+	}
+	if len(siblings) == 0 {
+		delete(p.by_parent, parent)
+	} else {
+		p.by_parent[parent] = siblings
+	}
+}
+
+// discard orphans older than ORPHAN_BLOCK_LIFETIME, caller must hold the lock
+func (p *orphan_pool) expire(now time.Time) (expired []*orphan_block) {
+	for _, o := range p.by_hash {
+		if now.Sub(o.added) > ORPHAN_BLOCK_LIFETIME*time.Second {
+			logger.Debugf("Orphan block %s expired, parent %s never appeared", o.hash, o.cbl.Bl.Prev_Hash)
+			p.remove(o)
+			expired = append(expired, o)
+		}
+	}
+	return
+}
+
+// removes and returns all orphans waiting for this parent
+func (p *orphan_pool) take_children(parent crypto.Hash) (children []*orphan_block) {
+	p.Lock()
+	defer p.Unlock()
+	for _, o := range p.by_parent[parent] {
+		delete(p.by_hash, o.hash)
+		children = append(children, o)
+	}
+	delete(p.by_parent, parent)
+	return
+}
+
+func (p *orphan_pool) exists(hash crypto.Hash) bool {
+	p.Lock()
+	defer p.Unlock()
+	_, ok := p.by_hash[hash]
+	return ok
+}
+
+func (p *orphan_pool) count() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.by_hash)
+}
+
+// queue an orphan, peers whose orphans expired without ever connecting are reported to p2p
+func (chain *Blockchain) add_orphan(cbl *block.Complete_Block, source string) {
+	_, expired := chain.orphans.add(cbl, source, time.Now())
+	for _, o := range expired {
+		if o.source != "" && chain.P2P_Orphan_Expired != nil {
+			go chain.P2P_Orphan_Expired(o.source, o.hash) // chain is locked, so do not wait for p2p
+		}
+	}
+}
+
+// blocks waiting for this block are added now, their own children are added recursively
+// orphans keep the peer which sent them, so invalid ones are reported through P2P_Orphan_Rejected
+func (chain *Blockchain) connect_orphans(parent crypto.Hash) {
+	parents := []crypto.Hash{parent}
+	for len(parents) > 0 {
+		children := chain.orphans.take_children(parents[0])
+		parents = parents[1:]
+		for _, o := range children {
+			if err := chain.add_complete_block(o.cbl, o.source); err != nil {
+				logger.Debugf("Orphan block %s could not be added err %s", o.hash, err)
+				if o.source != "" && chain.P2P_Orphan_Rejected != nil {
+					go chain.P2P_Orphan_Rejected(o.source, err) // do not wait for p2p
+				}
+				continue
+			}
+			logger.Debugf("Orphan block %s connected to chain", o.hash)
+			parents = append(parents, o.hash)
+		}
+	}
+}
+
+// check whether a block is waiting in orphan pool, p2p uses this to avoid requesting same parent again
+func (chain *Blockchain) Orphan_Exists(hash crypto.Hash) bool {
+	return chain.orphans.exists(hash)
+}
+
+// number of blocks waiting for their parent
+func (chain *Blockchain) Orphan_Count() int {
+	return chain.orphans.count()
+}
@@ -0,0 +1,112 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "time"
+import "testing"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/walletapi"
+
+// orphans must be found by parent, bounded and expired
+func Test_Orphan_Pool(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "BLKCHAIN"})
+	account, _ := walletapi.Generate_Keys_From_Random()
+	miner_tx, err := Create_Miner_TX(6, 12345, 123456789, account.GetAddress(), 0)
+	if err != nil {
+		t.Fatalf("error creating miner tx, err :%s", err)
+	}
+
+	// creates an orphan which is child of parent
+	orphan := func(parent crypto.Hash, nonce uint32) *block.Complete_Block {
+		bl := &block.Block{Miner_tx: miner_tx}
+		bl.Major_Version = 6
+		bl.Minor_Version = 6
+		bl.Prev_Hash = parent
+		bl.Nonce = nonce
+		return &block.Complete_Block{Bl: bl}
+	}
+
+	var pool orphan_pool
+	now := time.Now()
+
+	// queues an orphan from unknown source
+	add := func(cbl *block.Complete_Block, now time.Time) bool {
+		queued, _ := pool.add(cbl, "", now)
+		return queued
+	}
+
+	parent := crypto.Hash{1}
+	child_a := orphan(parent, 1)
+	child_b := orphan(parent, 2)
+	grand_child := orphan(child_a.Bl.GetHash(), 3)
+
+	if !add(child_a, now) || !add(child_b, now) || !add(grand_child, now) {
+		t.Fatalf("orphans could not be queued")
+	}
+	if add(child_a, now) || pool.count() != 3 {
+		t.Fatalf("duplicate orphan queued")
+	}
+	if !pool.exists(grand_child.Bl.GetHash()) || pool.exists(parent) {
+		t.Fatalf("orphan exists check failed")
+	}
+
+	children := pool.take_children(parent)
+	if len(children) != 2 || pool.count() != 1 || pool.exists(child_a.Bl.GetHash()) {
+		t.Fatalf("children not taken from pool, got %d left %d", len(children), pool.count())
+	}
+	if children = pool.take_children(child_a.Bl.GetHash()); len(children) != 1 || children[0].cbl != grand_child {
+		t.Fatalf("grand child not found")
+	}
+
+	// pool is bounded, oldest orphan is evicted first
+	for i := 0; i < ORPHAN_POOL_LIMIT+1; i++ {
+		add(orphan(parent, uint32(100+i)), now.Add(time.Duration(i)*time.Second))
+	}
+	if pool.count() != ORPHAN_POOL_LIMIT || pool.exists(orphan(parent, 100).Bl.GetHash()) || !pool.exists(orphan(parent, 101).Bl.GetHash()) {
+		t.Fatalf("pool limit not enforced, count %d", pool.count())
+	}
+
+	// orphans whose parent never appeared are expired
+	add(orphan(crypto.Hash{2}, 1000), now.Add((ORPHAN_BLOCK_LIFETIME+ORPHAN_POOL_LIMIT+1)*time.Second))
+	if pool.count() != 1 || len(pool.by_parent) != 1 {
+		t.Fatalf("orphans not expired, count %d", pool.count())
+	}
+
+	// a single peer cannot fill the pool, its oldest orphan is evicted
+	later := now.Add((ORPHAN_BLOCK_LIFETIME + ORPHAN_POOL_LIMIT + 2) * time.Second)
+	for i := 0; i < ORPHAN_SOURCE_LIMIT+1; i++ {
+		pool.add(orphan(parent, uint32(2000+i)), "10.0.0.1", later.Add(time.Duration(i)*time.Second))
+	}
+	pool.add(orphan(parent, 3000), "10.0.0.2", later)
+	if pool.count() != ORPHAN_SOURCE_LIMIT+2 || pool.exists(orphan(parent, 2000).Bl.GetHash()) || !pool.exists(orphan(parent, 3000).Bl.GetHash()) {
+		t.Fatalf("source limit not enforced, count %d", pool.count())
+	}
+
+	// expired orphans are returned with their source, so the peer can be scored
+	_, expired := pool.add(orphan(parent, 4000), "", later.Add((ORPHAN_BLOCK_LIFETIME+ORPHAN_SOURCE_LIMIT+1)*time.Second))
+	sources := map[string]int{}
+	for _, o := range expired {
+		sources[o.source]++
+	}
+	if len(expired) != ORPHAN_SOURCE_LIMIT+2 || sources["10.0.0.1"] != ORPHAN_SOURCE_LIMIT || sources["10.0.0.2"] != 1 || sources[""] != 1 {
+		t.Fatalf("expired orphans not reported, got %d %v", len(expired), sources)
+	}
+}
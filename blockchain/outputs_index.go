@@ -29,6 +29,7 @@ import "fmt"
 
 import "github.com/vmihailenco/msgpack"
 
+import "github.com/arnaucode/derosuite/block"
 import "github.com/arnaucode/derosuite/config"
 import "github.com/arnaucode/derosuite/crypto"
 import "github.com/arnaucode/derosuite/globals"
@@ -70,6 +71,14 @@ func (o *Index_Data) Deserialize(buf []byte) (err error) {
 
 var account walletapi.Account
 
+// version of output index entries, databases with an older version are reindexed at startup
+// version 2 added payment ids, version 1 databases do not store any version
+const OUTPUT_INDEX_VERSION = 2
+
+var OUTPUT_INDEX_VERSION_KEY = []byte("OUTPUT_INDEX_VERSION") // only stores single value
+
+const OUTPUT_INDEX_UPGRADE_COMMIT_INTERVAL = 1000 // reindexing commits after these many blocks
+
 /*
 func init() {
 
@@ -106,6 +115,45 @@ func (chain *Blockchain) write_output_index(block_id crypto.Hash) {
 
 	logger.Debugf("Writing Output Index for block %s height %d output index %d", block_id, height, index_start)
 
+	// store the index and relevant keys together in compact form
+	for i, serialized := range chain.build_output_index(bl, height, index_start) {
+		chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, GALAXY_OUTPUT_INDEX, GALAXY_OUTPUT_INDEX, itob(index_start+uint64(i)), serialized)
+	}
+}
+
+// rewrite output index of main chain if it was written by an older version
+// blocks whose txs have been pruned cannot be reindexed and keep their old entries
+func (chain *Blockchain) upgrade_output_index() {
+	version, err := chain.store.LoadUint64(BLOCKCHAIN_UNIVERSE, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY)
+	if err == nil && version >= OUTPUT_INDEX_VERSION {
+		return
+	}
+
+	chain.Lock()
+	defer chain.Unlock()
+
+	logger.Infof("Output index is outdated, reindexing %d blocks, this may take a while", chain.Get_Height()-chain.Load_Pruned_Height())
+	for height := chain.Load_Pruned_Height(); height < chain.Get_Height(); height++ {
+		block_id, err := chain.Load_BL_ID_at_Height(height)
+		if err != nil {
+			logger.Fatalf("Block at height %d not found while reindexing outputs, run verify-db --repair err %s", height, err)
+		}
+		chain.write_output_index(block_id)
+		if (height+1)%OUTPUT_INDEX_UPGRADE_COMMIT_INTERVAL == 0 {
+			chain.store.Commit()
+			logger.Infof("Reindexed outputs upto height %d", height)
+		}
+	}
+
+	chain.store.StoreUint64(BLOCKCHAIN_UNIVERSE, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION)
+	chain.store.Commit()
+	chain.store.Sync()
+	logger.Infof("Output index reindexed")
+}
+
+// build serialized output index entries of all outputs of the block, first entry goes at index_start
+// this is also used by verify-db to check the stored entries
+func (chain *Blockchain) build_output_index(bl *block.Block, height uint64, index_start uint64) (entries [][]byte) {
 	// ads miner tx separately as a special case
 	var o globals.TX_Output_Data
 	var d Index_Data
@@ -151,8 +199,7 @@ func (chain *Blockchain) write_output_index(block_id crypto.Hash) {
 
 	//fmt.Printf("index %d  %x\n",index_start,d.InKey.Destination)
 
-	// store the index and relevant keys together in compact form
-	chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, GALAXY_OUTPUT_INDEX, GALAXY_OUTPUT_INDEX, itob(index_start), serialized)
+	entries = append(entries, serialized)
 
 	index_start++
 
@@ -182,6 +229,16 @@ func (chain *Blockchain) write_output_index(block_id crypto.Hash) {
 
 		extra_parsed := tx.Parse_Extra()
 
+		// payment id is attached to all outputs of the tx, wallet decides whether it's meant for it
+		o.PaymentID = nil
+		if extra_parsed {
+			if payment_id, ok := tx.PaymentID_map[transaction.TX_EXTRA_NONCE_PAYMENT_ID]; ok {
+				o.PaymentID = append([]byte{byte(transaction.TX_EXTRA_NONCE_PAYMENT_ID)}, payment_id.([]byte)...)
+			} else if payment_id, ok := tx.PaymentID_map[transaction.TX_EXTRA_NONCE_ENCRYPTED_PAYMENT_ID]; ok {
+				o.PaymentID = append([]byte{byte(transaction.TX_EXTRA_NONCE_ENCRYPTED_PAYMENT_ID)}, payment_id.([]byte)...)
+			}
+		}
+
 		// tx has been loaded, now lets get the vout
 		for j := uint64(0); j < uint64(len(tx.Vout)); j++ {
 
@@ -230,7 +287,7 @@ func (chain *Blockchain) write_output_index(block_id crypto.Hash) {
 			if err != nil {
 				panic(err)
 			}
-			chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, GALAXY_OUTPUT_INDEX, GALAXY_OUTPUT_INDEX, itob(index_start), serialized)
+			entries = append(entries, serialized)
 
 			// fmt.Printf("index %d  %x\n",index_start,d.InKey.Destination)
 			index_start++
@@ -239,6 +296,7 @@ func (chain *Blockchain) write_output_index(block_id crypto.Hash) {
 
 	}
 
+	return
 }
 
 // this will load the index  data for specific index
@@ -280,6 +338,25 @@ func (chain *Blockchain) Read_output_index(index uint64) (data_bytes []byte, err
 	return data_bytes, err
 }
 
+// load and decode output index data for specific index, used by rpc to serve ring members
+func (chain *Blockchain) Load_Output_Index(index uint64) (idata globals.TX_Output_Data, err error) {
+	if index >= chain.Get_Output_Count() {
+		return idata, fmt.Errorf("output index %d out of range", index)
+	}
+	data_bytes, err := chain.Read_output_index(index)
+	if err != nil {
+		return
+	}
+	err = msgpack.Unmarshal(data_bytes, &idata)
+	return
+}
+
+// number of outputs in the main chain, all valid output indexes are below this
+func (chain *Blockchain) Get_Output_Count() uint64 {
+	top_id := chain.Get_Top_ID()
+	return chain.Get_Block_Output_Index(top_id) + chain.Block_Count_Vout(top_id)
+}
+
 // this function finds output index for the tx
 // first find a block index , and get the start offset
 // then loop the index till you find the key in the result
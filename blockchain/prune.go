@@ -0,0 +1,164 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "github.com/romana/rlog"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+
+// this file implements pruning mode, where prunable ringct data ( range proofs and mlsag signatures)
+// of txs in old blocks is discarded, these are only needed for verification, which has already been done
+// tx prefix, ringct base and hash of prunable part are kept, so tx hashes, fees and output index remain intact
+// a pruned tx is stored as prefix + ringct base, prunable hash is stored separately
+
+var PRUNED_HEIGHT = []byte("PRUNED_HEIGHT") // all blocks below this height have been pruned, only stores single value
+
+var PLANET_TX_PRUNABLE_HASH = []byte("PHASH") // only exists if tx has been pruned
+
+// whether the chain is running in pruning mode
+func (chain *Blockchain) Is_Pruned() bool {
+	return chain.prune_depth != 0
+}
+
+// blocks below this height have been pruned
+func (chain *Blockchain) Load_Pruned_Height() uint64 {
+	height, err := chain.store.LoadUint64(BLOCKCHAIN_UNIVERSE, PRUNED_HEIGHT, PRUNED_HEIGHT, PRUNED_HEIGHT)
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+func (chain *Blockchain) store_pruned_height(height uint64) {
+	chain.store.StoreUint64(BLOCKCHAIN_UNIVERSE, PRUNED_HEIGHT, PRUNED_HEIGHT, PRUNED_HEIGHT, height)
+}
+
+// whether txs of this block have been pruned, such blocks cannot be served to peers
+func (chain *Blockchain) Block_Pruned(hash crypto.Hash) bool {
+	if !chain.Is_Pruned() {
+		return false
+	}
+	return chain.Load_Height_for_BL_ID(hash) < chain.Load_Pruned_Height()
+}
+
+// load prunable hash of a pruned tx, returns false if tx has not been pruned
+func (chain *Blockchain) load_tx_prunable_hash(txhash crypto.Hash) (hash crypto.Hash, pruned bool) {
+	object_data, err := chain.store.LoadObject(BLOCKCHAIN_UNIVERSE, GALAXY_TRANSACTION, txhash[:], PLANET_TX_PRUNABLE_HASH)
+	if err != nil || len(object_data) != 32 {
+		return
+	}
+	copy(hash[:], object_data)
+	return hash, true
+}
+
+// discard prunable part of a tx, tx size is not updated since it is used for block size calculations
+func (chain *Blockchain) prune_tx(txhash crypto.Hash) {
+	if _, pruned := chain.load_tx_prunable_hash(txhash); pruned {
+		return
+	}
+
+	tx, err := chain.Load_TX_FROM_ID(txhash)
+	if err != nil {
+		logger.Warnf("Cannot load tx %s for pruning err %s", txhash, err)
+		return
+	}
+
+	// v1 txs and miner txs have nothing to prune
+	if tx.Version != 2 || tx.IsCoinbase() || tx.RctSignature.Get_Sig_Type() == ringct.RCTTypeNull {
+		return
+	}
+
+	tx.RctSignature.Prune()
+	prunable_hash := tx.RctSignature.PrunableHash()
+
+	chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, GALAXY_TRANSACTION, txhash[:], PLANET_TX_PRUNABLE_HASH, prunable_hash[:])
+	chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, GALAXY_TRANSACTION, txhash[:], PLANET_TX_BLOB, tx.SerializePruned())
+}
+
+// prune all txs of the block at this height
+func (chain *Blockchain) prune_block(height uint64) {
+	block_id, err := chain.Load_BL_ID_at_Height(height)
+	if err != nil {
+		logger.Warnf("Cannot find block at height %d for pruning err %s", height, err)
+		return
+	}
+	bl, err := chain.Load_BL_FROM_ID(block_id)
+	if err != nil {
+		logger.Warnf("Cannot load block %s for pruning err %s", block_id, err)
+		return
+	}
+	for i := range bl.Tx_hashes {
+		chain.prune_tx(bl.Tx_hashes[i])
+	}
+}
+
+// prune upto limit blocks which are deeper than prune depth, returns number of blocks pruned
+// chain lock must be held by caller, writes are committed by caller
+func (chain *Blockchain) prune_blocks(limit uint64) (count uint64) {
+	if !chain.Is_Pruned() || chain.Height <= chain.prune_depth {
+		return
+	}
+
+	target := chain.Height - chain.prune_depth
+	height := chain.Load_Pruned_Height()
+	for ; height < target && count < limit; height++ {
+		chain.prune_block(height)
+		count++
+	}
+
+	if count > 0 {
+		chain.store_pruned_height(height)
+		rlog.Tracef(1, "Pruned %d blocks, pruned height %d", count, height)
+	}
+	return
+}
+
+// setup pruning mode from startup parameters
+// once a database has been pruned, it stays pruned
+func (chain *Blockchain) init_pruning(params map[string]interface{}) {
+	prune, _ := params["--prune"].(bool)
+
+	if !prune && chain.Load_Pruned_Height() > 0 {
+		logger.Warnf("Database has been pruned, pruning mode cannot be disabled")
+		prune = true
+	}
+	if !prune {
+		return
+	}
+
+	chain.prune_depth = config.PRUNE_DEPTH
+	if depth, ok := params["--prune-depth"].(uint64); ok {
+		chain.prune_depth = depth
+	}
+	if chain.prune_depth < config.PRUNE_DEPTH_MIN {
+		logger.Warnf("Prune depth %d is too low, using %d", chain.prune_depth, config.PRUNE_DEPTH_MIN)
+		chain.prune_depth = config.PRUNE_DEPTH_MIN
+	}
+
+	logger.Infof("Pruning mode enabled, blocks deeper than %d will be pruned", chain.prune_depth)
+
+	// catch up with blocks added while running in full mode, commit in batches to keep tx small
+	chain.Lock()
+	defer chain.Unlock()
+	for chain.prune_blocks(1000) > 0 {
+		chain.store.Commit()
+		logger.Infof("Pruned blocks upto height %d", chain.Load_Pruned_Height())
+	}
+	chain.store.Sync()
+}
@@ -0,0 +1,206 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+/* this file streams chain and pool events, so as clients do not have to poll
+ * events are numbered, last EVENT_HISTORY events are kept so clients can resume after a disconnect
+ * if a client asks for events which are no longer available, it gets resync set and must reload its state
+ * events are available using long poll ( /get_events ) or websocket ( /ws_events )
+ * sequence numbers restart with the daemon, so resync is also set if client is ahead of us
+ */
+
+import "sync"
+import "time"
+import "strconv"
+import "net/http"
+import "encoding/json"
+
+import "golang.org/x/net/websocket"
+
+import "github.com/arnaucode/derosuite/crypto"
+
+const EVENT_HISTORY = 1000                  // events kept for resuming clients
+const EVENT_POLL_TIMEOUT = 30 * time.Second // default long poll timeout
+const EVENT_POLL_MAX_TIMEOUT = 120          // seconds
+
+const EVENT_NEW_BLOCK = "new_block"
+const EVENT_REORG = "reorg"
+const EVENT_MEMPOOL_ADD = "mempool_add"
+const EVENT_MEMPOOL_REMOVE = "mempool_remove"
+const EVENT_RESYNC = "resync" // only sent over websocket, client missed some events
+
+type Event struct {
+	Seq  uint64      `json:"seq"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+type Event_New_Block struct {
+	Hash       crypto.Hash `json:"hash"`
+	Height     uint64      `json:"height"`
+	Difficulty uint64      `json:"difficulty"`
+}
+
+type Event_Reorg struct {
+	Old_Branch []crypto.Hash `json:"old_branch"` // blocks which are no longer in main chain
+	New_Branch []crypto.Hash `json:"new_branch"` // blocks which are now in main chain
+}
+
+type Event_Mempool struct {
+	TXID crypto.Hash `json:"txid"`
+}
+
+type GetEvents_Result struct {
+	Events   []Event `json:"events"`
+	Last_Seq uint64  `json:"last_seq"`
+	Resync   bool    `json:"resync"`
+	Status   string  `json:"status"`
+}
+
+type event_hub struct {
+	events  []Event       // last EVENT_HISTORY events, oldest first
+	seq     uint64        // seq of last event
+	changed chan struct{} // closed and replaced whenever an event is published
+	sync.Mutex
+}
+
+var events = &event_hub{changed: make(chan struct{})}
+
+// hook up to chain and pool, hooks only append to the hub, so they never block
+func register_event_notifiers() {
+	chain.RPC_Block_Notifier = func(block_id crypto.Hash, height uint64, difficulty uint64) {
+		events.publish(EVENT_NEW_BLOCK, Event_New_Block{Hash: block_id, Height: height, Difficulty: difficulty})
+	}
+	chain.RPC_Reorg_Notifier = func(old_branch []crypto.Hash, new_branch []crypto.Hash) {
+		events.publish(EVENT_REORG, Event_Reorg{Old_Branch: old_branch, New_Branch: new_branch})
+	}
+	chain.Mempool.RPC_TX_Notifier = func(txid crypto.Hash, added bool) {
+		if added {
+			events.publish(EVENT_MEMPOOL_ADD, Event_Mempool{TXID: txid})
+		} else {
+			events.publish(EVENT_MEMPOOL_REMOVE, Event_Mempool{TXID: txid})
+		}
+	}
+}
+
+func (h *event_hub) publish(event_type string, data interface{}) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.seq++
+	h.events = append(h.events, Event{Seq: h.seq, Type: event_type, Data: data})
+	if len(h.events) > EVENT_HISTORY {
+		h.events = h.events[len(h.events)-EVENT_HISTORY:]
+	}
+
+	close(h.changed) // wake up all waiting clients
+	h.changed = make(chan struct{})
+}
+
+// events after seq, changed is closed once more events are available
+// resync is set if some of the requested events are no longer available
+func (h *event_hub) since(seq uint64) (list []Event, last uint64, resync bool, changed chan struct{}) {
+	h.Lock()
+	defer h.Unlock()
+
+	last, changed = h.seq, h.changed
+	if seq > h.seq { // client is from a previous run of daemon
+		return nil, last, true, changed
+	}
+	if seq == h.seq {
+		return nil, last, false, changed
+	}
+
+	oldest := h.seq - uint64(len(h.events)) + 1
+	if seq+1 < oldest {
+		resync = true
+		seq = oldest - 1
+	}
+	list = append(list, h.events[seq+1-oldest:]...)
+	return
+}
+
+// parse uint64 query parameter, returns default if missing or invalid
+func query_uint64(req *http.Request, name string, default_value uint64) uint64 {
+	if value, err := strconv.ParseUint(req.URL.Query().Get(name), 10, 64); err == nil {
+		return value
+	}
+	return default_value
+}
+
+// long poll, returns as soon as there are events after since, or timeout ( in seconds ) expires
+func get_events(rw http.ResponseWriter, req *http.Request) {
+	since := query_uint64(req, "since", 0)
+	timeout := EVENT_POLL_TIMEOUT
+	if seconds := query_uint64(req, "timeout", 0); seconds > 0 && seconds <= EVENT_POLL_MAX_TIMEOUT {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	list, last, resync, changed := events.since(since)
+	if len(list) == 0 && !resync {
+		select {
+		case <-changed:
+			list, last, resync, _ = events.since(since)
+		case <-time.After(timeout):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	if list == nil {
+		list = []Event{}
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(GetEvents_Result{Events: list, Last_Seq: last, Resync: resync, Status: "OK"})
+}
+
+// websocket stream, events are sent one per message
+// there is no origin check, events are public and non browser clients do not send origin
+var ws_events = websocket.Server{Handler: func(ws *websocket.Conn) {
+	defer ws.Close()
+
+	// we do not expect anything from client, reading only detects disconnection
+	closed := make(chan struct{})
+	go func() {
+		var msg []byte
+		for websocket.Message.Receive(ws, &msg) == nil {
+		}
+		close(closed)
+	}()
+
+	seq := query_uint64(ws.Request(), "since", 0)
+	for {
+		list, last, resync, changed := events.since(seq)
+		if resync {
+			if err := websocket.JSON.Send(ws, Event{Seq: last, Type: EVENT_RESYNC}); err != nil {
+				return
+			}
+		}
+		for i := range list {
+			if err := websocket.JSON.Send(ws, list[i]); err != nil {
+				return
+			}
+		}
+		seq = last
+
+		select {
+		case <-changed:
+		case <-closed:
+			return
+		}
+	}
+}}
@@ -0,0 +1,59 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "testing"
+
+// clients resume using seq, and are asked to resync once history is lost
+func Test_Event_Hub(t *testing.T) {
+	hub := &event_hub{changed: make(chan struct{})}
+
+	list, last, resync, changed := hub.since(0)
+	if len(list) != 0 || last != 0 || resync {
+		t.Fatalf("Empty hub returned events")
+	}
+
+	hub.publish(EVENT_MEMPOOL_ADD, nil)
+	select {
+	case <-changed:
+	default:
+		t.Fatalf("Waiting clients were not woken up")
+	}
+
+	for i := 0; i < EVENT_HISTORY+9; i++ {
+		hub.publish(EVENT_NEW_BLOCK, nil)
+	}
+
+	list, last, resync, _ = hub.since(EVENT_HISTORY + 5)
+	if resync || last != EVENT_HISTORY+10 || len(list) != 5 || list[0].Seq != EVENT_HISTORY+6 {
+		t.Fatalf("Resume failed resync %t last %d events %d", resync, last, len(list))
+	}
+
+	list, _, resync, _ = hub.since(10)
+	if resync || len(list) != EVENT_HISTORY || list[0].Seq != 11 {
+		t.Fatalf("Resume from oldest available event failed")
+	}
+
+	list, _, resync, _ = hub.since(3)
+	if !resync || len(list) != EVENT_HISTORY {
+		t.Fatalf("Client was not asked to resync after history was lost")
+	}
+
+	if _, _, resync, _ = hub.since(EVENT_HISTORY + 100); !resync {
+		t.Fatalf("Client ahead of hub was not asked to resync")
+	}
+}
@@ -16,10 +16,11 @@
 
 package rpcserver
 
-// get block template handler not implemented
+// get block template handler, miners call this to get work
 
-//import "fmt"
+import "fmt"
 import "context"
+import "encoding/hex"
 
 //import	"log"
 //import 	"net/http"
@@ -27,6 +28,11 @@ import "context"
 import "github.com/intel-go/fastjson"
 import "github.com/osamingo/jsonrpc"
 
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/transaction"
+
 type (
 	GetBlockTemplate_Handler struct{}
 	GetBlockTemplate_Params  struct {
@@ -35,6 +41,8 @@ type (
 	}
 	GetBlockTemplate_Result struct {
 		Blocktemplate_blob string `json:"blocktemplate_blob"`
+		Blockhashing_blob  string `json:"blockhashing_blob"`
+		Expected_reward    uint64 `json:"expected_reward"`
 		Difficulty         uint64 `json:"difficulty"`
 		Height             uint64 `json:"height"`
 		Prev_Hash          string `json:"prev_hash"`
@@ -43,16 +51,52 @@ type (
 	}
 )
 
-func (h GetBlockTemplate_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+func (h GetBlockTemplate_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (response interface{}, jerr *jsonrpc.Error) {
 
 	var p GetBlockTemplate_Params
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
 		return nil, err
 	}
 
+	defer func() { // safety so if anything wrong happens, we return error
+		if r := recover(); r != nil {
+			logger.Warnf("Recovered while processing getblocktemplate %v", r)
+			response = nil
+			jerr = &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: fmt.Sprintf("block template could not be created %v", r)}
+		}
+	}()
+
 	// Wallet_Address needs to validated before
+	miner_address, err := address.NewAddress(p.Wallet_Address)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Invalid wallet address err %s", err)}
+	}
+	if miner_address.Network != globals.Config.Public_Address_Prefix {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "Wallet address belongs to different network"}
+	}
+
+	if p.Reserve_size > 255 {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "Too big reserved size, maximum 255"}
+	}
+
+	bl, err := chain.Create_new_miner_block(*miner_address, int(p.Reserve_size))
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: fmt.Sprintf("block template could not be created err %s", err)}
+	}
+
+	reserved_offset, err := blockchain.Get_Reserved_Offset(&bl)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: fmt.Sprintf("reserved offset could not be found err %s", err)}
+	}
 
 	return GetBlockTemplate_Result{
-		Status: "NOT IMPLEMENTED",
+		Blocktemplate_blob: hex.EncodeToString(bl.Serialize()),
+		Blockhashing_blob:  hex.EncodeToString(bl.GetBlockWork()),
+		Expected_reward:    bl.Miner_tx.Vout[0].Amount,
+		Difficulty:         chain.Get_Difficulty_At_Block(bl.Prev_Hash),
+		Height:             bl.Miner_tx.Vin[0].(transaction.Txin_gen).Height,
+		Prev_Hash:          bl.Prev_Hash.String(),
+		Reserved_Offset:    uint64(reserved_offset),
+		Status:             "OK",
 	}, nil
 }
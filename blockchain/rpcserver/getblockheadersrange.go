@@ -0,0 +1,72 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "fmt"
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/blockchain"
+
+const MAX_HEADERS_PER_REQUEST = 1000
+
+type (
+	GetBlockHeadersRange_Handler struct{}
+	GetBlockHeadersRange_Params  struct {
+		Start_height uint64 `json:"start_height"`
+		End_height   uint64 `json:"end_height"` // inclusive
+	}
+	GetBlockHeadersRange_Result struct {
+		Headers []blockchain.BlockHeader_Print `json:"headers"`
+		Status  string                         `json:"status"`
+	}
+)
+
+func (h GetBlockHeadersRange_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p GetBlockHeadersRange_Params
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Start_height > p.End_height || p.End_height >= chain.Get_Height() {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Invalid height range, chain height %d", chain.Get_Height())}
+	}
+	if p.End_height-p.Start_height >= MAX_HEADERS_PER_REQUEST {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Too many headers requested, limit %d", MAX_HEADERS_PER_REQUEST)}
+	}
+
+	var result GetBlockHeadersRange_Result
+	for height := p.Start_height; height <= p.End_height; height++ {
+		hash, err := chain.Load_BL_ID_at_Height(height)
+		if err != nil {
+			logger.Warnf("User requested %d height block, chain height %d but err occured %s", height, chain.Get_Height(), err)
+			return nil, jsonrpc.ErrInternal()
+		}
+
+		block_header, err := chain.GetBlockHeader(hash)
+		if err != nil {
+			logger.Warnf("User requested %d height block, chain height %d but err occured %s", height, chain.Get_Height(), err)
+			return nil, jsonrpc.ErrInternal()
+		}
+		result.Headers = append(result.Headers, block_header)
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "fmt"
+import "time"
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/p2p"
+import "github.com/arnaucode/derosuite/p2pv2"
+
+// lists both levin and p2pv2 peers in monero format
+type (
+	GetConnections_Handler struct{}
+	GetConnections_Params  struct{} // no params
+	GetConnections_Result  struct {
+		Connections []Connection_Print `json:"connections"`
+		Status      string             `json:"status"`
+	}
+	Connection_Print struct {
+		Address       string `json:"address"`
+		Host          string `json:"host"`
+		Ip            string `json:"ip"`
+		Port          string `json:"port"`
+		Peer_id       string `json:"peer_id"`
+		Incoming      bool   `json:"incoming"`
+		Height        uint64 `json:"height"`
+		State         string `json:"state"`
+		Live_time     uint64 `json:"live_time"` // in seconds
+		Support_flags uint32 `json:"support_flags"`
+		Recv_count    uint64 `json:"recv_count"`
+		Send_count    uint64 `json:"send_count"`
+		Connection_id string `json:"connection_id"`
+	}
+)
+
+func (h GetConnections_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	result := GetConnections_Result{Connections: []Connection_Print{}}
+
+	list := append(p2p.Connection_List(), p2pv2.Connection_List()...)
+	for i := range list {
+		conn := Connection_Print{
+			Peer_id:       fmt.Sprintf("%016x", list[i].Peer_ID),
+			Incoming:      list[i].Incoming,
+			Height:        list[i].Height,
+			State:         list[i].State,
+			Live_time:     uint64(time.Since(list[i].Created).Seconds()),
+			Support_flags: list[i].Support_Flags,
+			Recv_count:    list[i].Bytes_Received,
+			Send_count:    list[i].Bytes_Sent,
+			Connection_id: fmt.Sprintf("%d", i),
+		}
+		if list[i].Addr != nil {
+			conn.Address = list[i].Addr.String()
+			conn.Host = list[i].Addr.IP.String()
+			conn.Ip = list[i].Addr.IP.String()
+			conn.Port = fmt.Sprintf("%d", list[i].Addr.Port)
+		}
+		result.Connections = append(result.Connections, conn)
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
@@ -68,6 +68,7 @@ type (
 		Tx_count                   uint64 `json:"tx_count"`
 		Tx_pool_size               uint64 `json:"tx_pool_size"`
 		White_peerlist_size        uint64 `json:"white_peerlist_size"`
+		Dynamic_fee_per_kb         uint64 `json:"dynamic_fee_per_kb"` // used by wallets to calculate fees
 
 		Status string `json:"status"`
 	}
@@ -86,6 +87,10 @@ func (h GetInfo_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMes
 	result.Target_Height = chain.Get_Height()
 	result.Tx_pool_size = uint64(len(chain.Mempool.Mempool_List_TX()))
 
+	if result.Height >= 2 {
+		result.Dynamic_fee_per_kb = chain.Get_Dynamic_Fee_Rate(result.Height)
+	}
+
 	if globals.Config.Name != config.Mainnet.Name { // anything other than mainnet is testnet at this point in time
 		result.Testnet = true
 	}
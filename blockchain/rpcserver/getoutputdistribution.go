@@ -0,0 +1,123 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "fmt"
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+// number of outputs created per block, used by wallets to pick decoys
+// dero keeps all outputs in a single global index, so only amount 0 is supported
+
+// every height costs a DB lookup, so larger ranges must be requested in parts
+const MAX_DISTRIBUTION_HEIGHTS = 10000
+
+type (
+	GetOutputDistribution_Handler struct{}
+	GetOutputDistribution_Params  struct {
+		Amounts     []uint64 `json:"amounts"`
+		From_height uint64   `json:"from_height"`
+		To_height   uint64   `json:"to_height"` // 0 means top
+		Cumulative  bool     `json:"cumulative"`
+	}
+	GetOutputDistribution_Result struct {
+		Distributions []Output_Distribution `json:"distributions"`
+		Status        string                `json:"status"`
+	}
+	Output_Distribution struct {
+		Amount       uint64   `json:"amount"`
+		Start_height uint64   `json:"start_height"`
+		Distribution []uint64 `json:"distribution"`
+		Base         uint64   `json:"base"` // outputs before start_height
+	}
+)
+
+func (h GetOutputDistribution_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p GetOutputDistribution_Params
+	if params != nil { // params are optional
+		if err := jsonrpc.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(p.Amounts) == 0 {
+		p.Amounts = []uint64{0}
+	}
+
+	top_height := chain.Get_Height() - 1
+	if p.To_height == 0 {
+		p.To_height = top_height
+	}
+	if p.From_height > p.To_height || p.To_height > top_height {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Invalid height range, chain height %d", chain.Get_Height())}
+	}
+	if p.To_height-p.From_height >= MAX_DISTRIBUTION_HEIGHTS {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Too many heights requested, limit %d", MAX_DISTRIBUTION_HEIGHTS)}
+	}
+	if len(p.Amounts) > 1 { // only amount 0 exists, so more amounts can only be repeats
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "only amount 0 is supported"}
+	}
+
+	var result GetOutputDistribution_Result
+	for _, amount := range p.Amounts {
+		if amount != 0 {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "only amount 0 is supported"}
+		}
+
+		dist := Output_Distribution{Amount: amount, Start_height: p.From_height}
+
+		start, err := output_index_at_height(p.From_height)
+		if err != nil {
+			logger.Warnf("Output distribution err occured %s", err)
+			return nil, jsonrpc.ErrInternal()
+		}
+		dist.Base = start
+
+		for height := p.From_height; height <= p.To_height; height++ {
+			next, err := output_index_at_height(height + 1)
+			if err != nil {
+				logger.Warnf("Output distribution err occured %s", err)
+				return nil, jsonrpc.ErrInternal()
+			}
+
+			if p.Cumulative {
+				dist.Distribution = append(dist.Distribution, next)
+			} else {
+				dist.Distribution = append(dist.Distribution, next-start)
+			}
+			start = next
+		}
+		result.Distributions = append(result.Distributions, dist)
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
+
+// global index of first output of block at height, height just above top gives output count
+func output_index_at_height(height uint64) (uint64, error) {
+	if height >= chain.Get_Height() {
+		return chain.Get_Output_Count(), nil
+	}
+	block_id, err := chain.Load_BL_ID_at_Height(height)
+	if err != nil {
+		return 0, err
+	}
+	return chain.Get_Block_Output_Index(block_id), nil
+}
@@ -71,6 +71,11 @@ func getoutputs(rw http.ResponseWriter, req *http.Request) {
 		start = stop - 1
 	}
 
+	// wallets continue from where the stream ended, so a capped range only slows them down
+	if restricted && stop-start >= RESTRICTED_MAX_OUTPUTS {
+		stop = start + RESTRICTED_MAX_OUTPUTS - 1
+	}
+
 	/*   lz4writer := lz4.NewWriter(rw)
 	lz4writer.HighCompression = true // enable extreme but slow compression
 	lz4writer.BlockMaxSize = 256*1024 // small block size to decrease memory consumption
@@ -0,0 +1,92 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "fmt"
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/blockchain/inputmaturity"
+
+// serves ring members by global index, so as wallets can build rings without downloading all outputs
+// dero keeps all outputs in a single global index, so amount must always be 0 ( same as monero rct outputs )
+
+const MAX_OUTS_PER_REQUEST = 5000
+
+type (
+	GetOuts_Handler struct{}
+	GetOuts_Params  struct {
+		Outputs  []Get_Outputs_Out `json:"outputs"`
+		Get_txid bool              `json:"get_txid"`
+	}
+	Get_Outputs_Out struct {
+		Amount uint64 `json:"amount"`
+		Index  uint64 `json:"index"`
+	}
+	GetOuts_Result struct {
+		Outs   []Outkey `json:"outs"`
+		Status string   `json:"status"`
+	}
+	Outkey struct {
+		Height   uint64 `json:"height"`
+		Key      string `json:"key"`
+		Mask     string `json:"mask"`
+		Txid     string `json:"txid,omitempty"`
+		Unlocked bool   `json:"unlocked"`
+	}
+)
+
+func (h GetOuts_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p GetOuts_Params
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if len(p.Outputs) > MAX_OUTS_PER_REQUEST {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Too many outs requested, limit %d", MAX_OUTS_PER_REQUEST)}
+	}
+
+	var result GetOuts_Result
+	current_height := chain.Get_Height()
+	for i := range p.Outputs {
+		if p.Outputs[i].Amount != 0 {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "only amount 0 is supported"}
+		}
+
+		idata, err := chain.Load_Output_Index(p.Outputs[i].Index)
+		if err != nil {
+			logger.Warnf("User requested output %d, err occured %s", p.Outputs[i].Index, err)
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: err.Error()}
+		}
+
+		out := Outkey{
+			Height:   idata.Height,
+			Key:      idata.InKey.Destination.String(),
+			Mask:     idata.InKey.Mask.String(),
+			Unlocked: inputmaturity.Is_Input_Mature(current_height, idata.Height, idata.Unlock_Height, idata.SigType),
+		}
+		if p.Get_txid {
+			out.Txid = idata.TXID.String()
+		}
+		result.Outs = append(result.Outs, out)
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
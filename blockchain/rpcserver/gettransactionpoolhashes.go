@@ -0,0 +1,46 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+type (
+	GetTransactionPoolHashes_Handler struct{}
+	GetTransactionPoolHashes_Params  struct{} // no params
+	GetTransactionPoolHashes_Result  struct {
+		Tx_hashes []string `json:"tx_hashes"`
+		Status    string   `json:"status"`
+	}
+)
+
+func (h GetTransactionPoolHashes_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	result := GetTransactionPoolHashes_Result{Tx_hashes: []string{}}
+
+	pool_list := chain.Mempool.Mempool_List_TX()
+	for i := range pool_list {
+		if chain.Mempool.Mempool_TX_Is_Stem(pool_list[i]) { // stem txs are not public yet
+			continue
+		}
+		result.Tx_hashes = append(result.Tx_hashes, pool_list[i].String())
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
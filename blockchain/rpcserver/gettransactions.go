@@ -16,29 +16,32 @@
 
 package rpcserver
 
+import "fmt"
 import "net/http"
 import "encoding/hex"
 import "encoding/json"
 
-import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/transaction"
+import "github.com/arnaucode/derosuite/crypto/ringct"
 
 // we definitely need to clear up the MESS that has been created by the MONERO project
 // half of their APIs are json rpc and half are http
 // for compatibility reasons, we are implementing theirs ( however we are also providin a json rpc implementation)
 // we should DISCARD the http
 
-//  NOTE: we have currently not implemented the decode as json parameter
-//  it is however on the pending list
+// decode_as_json fills txs_as_json with canonical json form of tx
+// prune omits the prunable part ( signatures ), both from hex and json
 
 type (
 	GetTransaction_Handler struct{}
 	GetTransaction_Params  struct {
 		Tx_Hashes []string `json:"txs_hashes"`
-		Decode    uint64   `json:"decode_as_json,omitempty"` // Monero Daemon breaks if this sent
+		Decode    bool     `json:"decode_as_json,omitempty"` // Monero Daemon breaks if this sent
+		Prune     bool     `json:"prune,omitempty"`
 	} // no params
 	GetTransaction_Result struct {
 		Txs_as_hex  []string          `json:"txs_as_hex"`
-		Txs_as_json []string          `json:"txs_as_json"`
+		Txs_as_json []string          `json:"txs_as_json,omitempty"`
 		Txs         []Tx_Related_Info `json:"txs"`
 		Status      string            `json:"status"`
 	}
@@ -49,18 +52,28 @@ type (
 		Block_Height   int64    `json:"block_height"`
 		In_pool        bool     `json:"in_pool"`
 		Output_Indices []uint64 `json:"output_indices"`
+		Prunable_Hash  string   `json:"prunable_hash,omitempty"` // only if pruned
 		Tx_hash        string   `json:"tx_hash"`
 	}
 )
 
 func gettransactions(rw http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	rw.Header().Set("Content-Type", "application/json")
+
 	decoder := json.NewDecoder(req.Body)
 	var p GetTransaction_Params
-	err := decoder.Decode(&p)
-	if err != nil {
-		panic(err)
+	if err := decoder.Decode(&p); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(GetTransaction_Result{Status: "Failed to parse request " + err.Error()})
+		return
+	}
+
+	if restricted && len(p.Tx_Hashes) > RESTRICTED_MAX_TXS {
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(GetTransaction_Result{Status: fmt.Sprintf("Too many txs requested, limit %d", RESTRICTED_MAX_TXS)})
+		return
 	}
-	defer req.Body.Close()
 
 	result := gettransactions_fill(p)
 	//logger.Debugf("Request %+v", p)
@@ -74,34 +87,26 @@ func gettransactions_fill(p GetTransaction_Params) (result GetTransaction_Result
 
 	for i := 0; i < len(p.Tx_Hashes); i++ {
 
-		hash := crypto.HashHexToHash(p.Tx_Hashes[i])
-
-		// check whether we can get the tx from the pool
-		{
-			tx := chain.Mempool.Mempool_Get_TX(hash)
-			if tx != nil { // found the tx in the mempool
-				result.Txs_as_hex = append(result.Txs_as_hex, hex.EncodeToString(tx.Serialize()))
-
-				var related Tx_Related_Info
-
-				related.Block_Height = -1 // not mined
-				related.In_pool = true
+		hash, err := parse_hash(p.Tx_Hashes[i])
+		if err != nil {
+			result.Status = "Failed to parse hash " + p.Tx_Hashes[i]
+			return
+		}
 
-				for i := 0; i < len(tx.Vout); i++ {
-					related.Output_Indices = append(related.Output_Indices, 0) // till the tx is mined we do not get indices
-				}
+		var tx *transaction.Transaction
+		var related Tx_Related_Info
+		related.Tx_hash = hash.String()
 
-				result.Txs = append(result.Txs, related)
+		// check whether we can get the tx from the pool
+		if pool_tx := chain.Mempool.Mempool_Get_TX(hash); pool_tx != nil && !chain.Mempool.Mempool_TX_Is_Stem(hash) { // found the tx in the mempool, stem txs are not public
+			tx = pool_tx
+			related.Block_Height = -1 // not mined
+			related.In_pool = true
 
-				continue // no more processing required
+			for i := 0; i < len(tx.Vout); i++ {
+				related.Output_Indices = append(related.Output_Indices, 0) // till the tx is mined we do not get indices
 			}
-		}
-
-		tx, err := chain.Load_TX_FROM_ID(hash)
-		if err == nil {
-			result.Txs_as_hex = append(result.Txs_as_hex, hex.EncodeToString(tx.Serialize()))
-			var related Tx_Related_Info
-
+		} else if tx, err = chain.Load_TX_FROM_ID(hash); err == nil {
 			related.Block_Height = int64(chain.Load_TX_Height(hash))
 
 			index := chain.Find_TX_Output_Index(hash)
@@ -110,15 +115,34 @@ func gettransactions_fill(p GetTransaction_Params) (result GetTransaction_Result
 			for i := 0; i < len(tx.Vout); i++ {
 				related.Output_Indices = append(related.Output_Indices, index+uint64(i))
 			}
-
-			result.Txs = append(result.Txs, related)
-
 		} else { // we could not fetch the tx, return an empty string
 			result.Txs_as_hex = append(result.Txs_as_hex, "")
 			result.Status = "TX NOT FOUND"
 			return
 		}
 
+		if p.Prune || tx.IsPruned() {
+			related.As_Hex = hex.EncodeToString(tx.SerializePruned())
+			if tx.Version == 2 && tx.RctSignature.Get_Sig_Type() != ringct.RCTTypeNull { // miner txs have nothing to prune
+				related.Prunable_Hash = tx.RctSignature.PrunableHash().String()
+			}
+		} else {
+			related.As_Hex = hex.EncodeToString(tx.Serialize())
+		}
+
+		if p.Decode {
+			tx_json, err := tx.Serialize_JSON(p.Prune)
+			if err != nil {
+				logger.Warnf("Cannot encode tx %s as json err %s", hash, err)
+				result.Status = "Failed to encode tx as json"
+				return
+			}
+			related.As_Json = string(tx_json)
+			result.Txs_as_json = append(result.Txs_as_json, related.As_Json)
+		}
+
+		result.Txs_as_hex = append(result.Txs_as_hex, related.As_Hex)
+		result.Txs = append(result.Txs, related)
 	}
 	result.Status = "OK"
 	return
@@ -0,0 +1,126 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/block"
+
+// dero does not have a hard fork schedule yet, so this is derived from the block versions in the chain
+// votes are counted from minor version of the blocks within the voting window
+
+const HARD_FORK_VOTING_WINDOW = 720 // blocks
+
+// states as reported by monero daemon
+const HARD_FORK_STATE_UPDATE_NEEDED = 1
+const HARD_FORK_STATE_READY = 2
+
+type (
+	HardForkInfo_Handler struct{}
+	HardForkInfo_Params  struct {
+		Version uint64 `json:"version"` // 0 means current version
+	}
+	HardForkInfo_Result struct {
+		Earliest_height uint64 `json:"earliest_height"`
+		Enabled         bool   `json:"enabled"`
+		State           uint64 `json:"state"`
+		Threshold       uint64 `json:"threshold"`
+		Version         uint64 `json:"version"`
+		Votes           uint64 `json:"votes"`
+		Voting          uint64 `json:"voting"`
+		Window          uint64 `json:"window"`
+		Status          string `json:"status"`
+	}
+)
+
+func (h HardForkInfo_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p HardForkInfo_Params
+	if params != nil { // params are optional
+		if err := jsonrpc.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+
+	top_height := chain.Get_Height() - 1
+	top_bl, err := chain.Load_BL_FROM_ID(chain.Get_Top_ID())
+	if err != nil {
+		logger.Warnf("Cannot load top block err %s", err)
+		return nil, jsonrpc.ErrInternal()
+	}
+
+	result := HardForkInfo_Result{
+		Version: p.Version,
+		Voting:  uint64(top_bl.Minor_Version),
+		Window:  HARD_FORK_VOTING_WINDOW,
+		State:   HARD_FORK_STATE_READY,
+		Status:  "OK",
+	}
+	if result.Version == 0 {
+		result.Version = uint64(top_bl.Major_Version)
+	}
+
+	if result.Version > uint64(top_bl.Major_Version) { // we do not know anything about future versions
+		result.State = HARD_FORK_STATE_UPDATE_NEEDED
+	} else {
+		result.Enabled = true
+
+		// versions never go down, so binary search for first block with the version
+		lo, hi := uint64(0), top_height
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			bl, err := load_block_at_height(mid)
+			if err != nil {
+				logger.Warnf("Cannot load block at height %d err %s", mid, err)
+				return nil, jsonrpc.ErrInternal()
+			}
+			if uint64(bl.Major_Version) >= result.Version {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		result.Earliest_height = lo
+	}
+
+	// count votes within the window
+	start := uint64(0)
+	if top_height >= HARD_FORK_VOTING_WINDOW {
+		start = top_height - HARD_FORK_VOTING_WINDOW + 1
+	}
+	for height := start; height <= top_height; height++ {
+		bl, err := load_block_at_height(height)
+		if err != nil {
+			continue
+		}
+		if uint64(bl.Minor_Version) >= result.Version {
+			result.Votes++
+		}
+	}
+
+	return result, nil
+}
+
+func load_block_at_height(height uint64) (*block.Block, error) {
+	block_id, err := chain.Load_BL_ID_at_Height(height)
+	if err != nil {
+		return nil, err
+	}
+	return chain.Load_BL_FROM_ID(block_id)
+}
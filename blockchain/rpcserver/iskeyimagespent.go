@@ -0,0 +1,70 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "fmt"
+import "context"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+// spent status as reported by monero daemon
+const KEY_IMAGE_UNSPENT = 0
+const KEY_IMAGE_SPENT_IN_BLOCKCHAIN = 1
+const KEY_IMAGE_SPENT_IN_POOL = 2
+
+type (
+	IsKeyImageSpent_Handler struct{}
+	IsKeyImageSpent_Params  struct {
+		Key_images []string `json:"key_images"`
+	}
+	IsKeyImageSpent_Result struct {
+		Spent_Status []uint64 `json:"spent_status"`
+		Status       string   `json:"status"`
+	}
+)
+
+func (h IsKeyImageSpent_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p IsKeyImageSpent_Params
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if restricted && len(p.Key_images) > RESTRICTED_MAX_KEY_IMAGES {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Too many key images requested, limit %d", RESTRICTED_MAX_KEY_IMAGES)}
+	}
+
+	var result IsKeyImageSpent_Result
+	for i := range p.Key_images {
+		kimage, err := parse_hash(p.Key_images[i])
+		if err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "invalid key image " + p.Key_images[i]}
+		}
+
+		switch {
+		case chain.Read_KeyImage_Status(kimage):
+			result.Spent_Status = append(result.Spent_Status, KEY_IMAGE_SPENT_IN_BLOCKCHAIN)
+		case chain.Mempool.Mempool_Keyimage_Used(kimage):
+			result.Spent_Status = append(result.Spent_Status, KEY_IMAGE_SPENT_IN_POOL)
+		default:
+			result.Spent_Status = append(result.Spent_Status, KEY_IMAGE_UNSPENT)
+		}
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
@@ -23,13 +23,18 @@ import "sync"
 import "sync/atomic"
 
 //import "context"
+import "net"
 import "net/http"
+import "crypto/tls"
+import "encoding/hex"
+import "encoding/json"
 
-//import "github.com/intel-go/fastjson"
+import "github.com/intel-go/fastjson"
 import "github.com/osamingo/jsonrpc"
 
 import log "github.com/sirupsen/logrus"
 
+import "github.com/arnaucode/derosuite/crypto"
 import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/blockchain"
 
@@ -39,6 +44,13 @@ import "github.com/arnaucode/derosuite/blockchain"
 // this structure must be update while mutex
 type RPCServer struct {
 	Exit_Event chan bool // blockchain is shutting down and we must quit ASAP
+	Address    string    // rpc server listens on this address
+	Restricted bool      // hide admin/mining methods and cap request sizes
+	Login_User string    // if set, clients must authenticate
+	Login_Pass string
+	Rate_Limit uint64      // requests per second per ip, 0 means unlimited
+	TLS_Config *tls.Config // if set, rpc is served over TLS
+	server     *http.Server
 	sync.RWMutex
 }
 
@@ -63,6 +75,45 @@ func RPCServer_Start(params map[string]interface{}) (*RPCServer, error) {
 		return nil, fmt.Errorf("Chain DOES NOT have genesis block")
 	}
 
+	// rpc server listens on loopback on network specific default port, unless user provided an address
+	r.Address = fmt.Sprintf("127.0.0.1:%d", globals.Config.RPC_Default_Port)
+	if _, ok := params["--rpc-bind"]; ok {
+		if r.Address, err = globals.ParseBindAddress(params["--rpc-bind"].(string)); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := params["--rpc-login"]; ok {
+		if r.Login_User, r.Login_Pass, err = globals.Parse_RPC_Login(params["--rpc-login"].(string)); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := params["--restricted-rpc"]; ok && params["--restricted-rpc"].(bool) {
+		r.Restricted = true
+		r.Rate_Limit = RESTRICTED_RATE_LIMIT
+	}
+	restricted = r.Restricted
+	if _, ok := params["--rpc-rate-limit"]; ok {
+		r.Rate_Limit = params["--rpc-rate-limit"].(uint64)
+	}
+
+	if _, ok := params["--rpc-ssl"]; ok && params["--rpc-ssl"].(bool) {
+		cert_file, _ := params["--rpc-ssl-certificate"].(string)
+		key_file, _ := params["--rpc-ssl-private-key"].(string)
+		if r.TLS_Config, err = globals.RPC_TLS_Config(cert_file, key_file); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Login_User != "" && r.TLS_Config == nil {
+		if host, _, _ := net.SplitHostPort(r.Address); !net.ParseIP(host).IsLoopback() {
+			logger.Warnf("RPC login is sent in plain text, use --rpc-ssl when rpc is exposed")
+		}
+	}
+
+	register_event_notifiers()
+
 	go r.Run()
 	logger.Infof("RPC server started")
 	atomic.AddUint32(&globals.Subsystem_Active, 1) // increment subsystem
@@ -74,6 +125,11 @@ func RPCServer_Start(params map[string]interface{}) (*RPCServer, error) {
 func (r *RPCServer) RPCServer_Stop() {
 	Exit_In_Progress = true
 	close(r.Exit_Event) // send signal to all connections to exit
+	r.RLock()
+	if r.server != nil {
+		r.server.Close()
+	}
+	r.RUnlock()
 	// TODO we  must wait for connections to kill themselves
 	time.Sleep(1 * time.Second)
 	logger.Infof("RPC Shutdown")
@@ -98,14 +154,21 @@ func (r *RPCServer) Run() {
 		log.Fatalln(err)
 	}
 
-	// install getblocktemplate handler
-	if err := mr.RegisterMethod("getblocktemplate", GetBlockTemplate_Handler{}, GetBlockTemplate_Params{}, GetBlockTemplate_Result{}); err != nil {
-		log.Fatalln(err)
-	}
-
-	// submitblock handler
-	if err := mr.RegisterMethod("submitblock", SubmitBlock_Handler{}, SubmitBlock_Params{}, SubmitBlock_Result{}); err != nil {
-		log.Fatalln(err)
+	// mining and admin methods are not available in restricted mode
+	if !r.Restricted {
+		// install getblocktemplate handler
+		if err := mr.RegisterMethod("getblocktemplate", GetBlockTemplate_Handler{}, GetBlockTemplate_Params{}, GetBlockTemplate_Result{}); err != nil {
+			log.Fatalln(err)
+		}
+
+		// submitblock handler
+		if err := mr.RegisterMethod("submitblock", SubmitBlock_Handler{}, SubmitBlock_Params{}, SubmitBlock_Result{}); err != nil {
+			log.Fatalln(err)
+		}
+
+		if err := mr.RegisterMethod("get_connections", GetConnections_Handler{}, GetConnections_Params{}, GetConnections_Result{}); err != nil {
+			log.Fatalln(err)
+		}
 	}
 
 	if err := mr.RegisterMethod("getlastblockheader", GetLastBlockHeader_Handler{}, GetLastBlockHeader_Params{}, GetLastBlockHeader_Result{}); err != nil {
@@ -131,15 +194,99 @@ func (r *RPCServer) Run() {
 		log.Fatalln(err)
 	}
 
-	http.HandleFunc("/", hello)
-	http.Handle("/json_rpc", mr)
+	// accept signed transactions from wallets
+	if err := mr.RegisterMethod("sendrawtransaction", SendRawTransaction_Handler{}, SendRawTransaction_Params{}, SendRawTransaction_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	// monero compatible methods, used by third party wallets and libraries
+	if err := mr.RegisterMethod("is_key_image_spent", IsKeyImageSpent_Handler{}, IsKeyImageSpent_Params{}, IsKeyImageSpent_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_outs", GetOuts_Handler{}, GetOuts_Params{}, GetOuts_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_fee_estimate", GetFeeEstimate_Handler{}, GetFeeEstimate_Params{}, GetFeeEstimate_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_transaction_pool_hashes", GetTransactionPoolHashes_Handler{}, GetTransactionPoolHashes_Params{}, GetTransactionPoolHashes_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_block_headers_range", GetBlockHeadersRange_Handler{}, GetBlockHeadersRange_Params{}, GetBlockHeadersRange_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_output_distribution", GetOutputDistribution_Handler{}, GetOutputDistribution_Params{}, GetOutputDistribution_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("hard_fork_info", HardForkInfo_Handler{}, HardForkInfo_Params{}, HardForkInfo_Result{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", hello)
+	if r.Restricted {
+		mux.Handle("/json_rpc", restricted_json_rpc_handler(mr))
+	} else {
+		mux.Handle("/json_rpc", mr)
+	}
 
 	// handle nasty http requests
-	http.HandleFunc("/getoutputs.bin", getoutputs) // stream any outputs to server, can make wallet work offline
-	http.HandleFunc("/gettransactions", gettransactions)
-	//http.HandleFunc("/json_rpc/debug", mr.ServeDebug)
+	mux.HandleFunc("/getoutputs.bin", getoutputs) // stream any outputs to server, can make wallet work offline
+	plain_handlers := map[string]http.HandlerFunc{
+		"/gettransactions":             gettransactions,
+		"/is_key_image_spent":          http_handler(IsKeyImageSpent_Handler{}),
+		"/get_outs":                    http_handler(GetOuts_Handler{}),
+		"/get_transaction_pool_hashes": http_handler(GetTransactionPoolHashes_Handler{}),
+	}
+	for path, h := range plain_handlers {
+		if r.Restricted {
+			h = restricted_body_handler(h)
+		}
+		mux.HandleFunc(path, h)
+	}
+	mux.HandleFunc("/get_events", get_events) // long poll, so clients need not poll get_info
+	mux.Handle("/ws_events", ws_events)
+	//mux.HandleFunc("/json_rpc/debug", mr.ServeDebug)
+
+	// rate limit is checked first, so as login cannot be brute forced
+	var handler http.Handler = mux
+	if r.Login_User != "" {
+		handler = globals.RPC_Auth_Handler("derod", r.Login_User, r.Login_Pass, handler)
+	}
+	if r.Rate_Limit > 0 {
+		limiter := new_rate_limiter(r.Rate_Limit)
+		handler = limiter.handler(handler)
+		go func() {
+			for {
+				select {
+				case <-r.Exit_Event:
+					return
+				case <-time.After(time.Minute):
+					limiter.cleanup(time.Now())
+				}
+			}
+		}()
+	}
+
+	r.Lock()
+	r.server = &http.Server{Addr: r.Address, Handler: handler, TLSConfig: r.TLS_Config}
+	r.Unlock()
 
-	if err := http.ListenAndServe("127.0.0.1:9999", http.DefaultServeMux); err != nil {
+	logger.Infof("RPC server listening on %s restricted %t TLS %t", r.Address, r.Restricted, r.TLS_Config != nil)
+
+	var err error
+	if r.TLS_Config != nil {
+		err = r.server.ListenAndServeTLS("", "") // certificate is already in config
+	} else {
+		err = r.server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		log.Fatalln(err)
 	}
 
@@ -148,3 +295,47 @@ func (r *RPCServer) Run() {
 func hello(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, "Hello world!")
 }
+
+// serve a json rpc handler over plain http, monero serves some of its methods both ways
+// params are the request body, errors are reported with http 400
+func http_handler(h jsonrpc.Handler) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		var raw fastjson.RawMessage
+		if err := json.NewDecoder(req.Body).Decode(&raw); err != nil && err != io.EOF {
+			http.Error(rw, "invalid json "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var params *fastjson.RawMessage
+		if len(raw) > 0 {
+			params = &raw
+		}
+
+		result, jerr := h.ServeJSONRPC(req.Context(), params)
+		if jerr != nil {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(jerr)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(result)
+	}
+}
+
+// strict version of crypto.HashHexToHash, user supplied hashes must be rejected if malformed
+func parse_hash(hash_hex string) (hash crypto.Hash, err error) {
+	hash_raw, err := hex.DecodeString(hash_hex)
+	if err != nil {
+		return
+	}
+	if len(hash_raw) != len(hash) {
+		err = fmt.Errorf("hash must be %d bytes, got %d", len(hash), len(hash_raw))
+		return
+	}
+	copy(hash[:], hash_raw)
+	return
+}
@@ -0,0 +1,147 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+/* this file protects rpc server when it is exposed publicly
+ * login is checked using http basic auth ( see globals/tls.go ), it should only be used with TLS on public interfaces
+ * restricted mode hides admin/mining methods and caps request sizes
+ * rate limiting is per ip, using a token bucket
+ */
+
+import "fmt"
+import "net"
+import "sync"
+import "time"
+import "bytes"
+import "net/http"
+import "io/ioutil"
+import "encoding/json"
+
+const RESTRICTED_MAX_BATCH = 16                  // max json rpc calls in a single batch request
+const RESTRICTED_MAX_BODY_SIZE = 4 * 1024 * 1024 // large enough for any transaction
+const RESTRICTED_MAX_OUTPUTS = 10000             // max outputs streamed by getoutputs.bin in a request
+const RESTRICTED_MAX_TXS = 100                   // max txs served by gettransactions in a request
+const RESTRICTED_MAX_KEY_IMAGES = 1000           // max key images checked by is_key_image_spent in a request
+const RESTRICTED_RATE_LIMIT = 50                 // requests per second per ip, used in restricted mode if user did not provide one
+
+var restricted bool // set if rpc server is running in restricted mode
+
+// caps body size of plain http endpoints, json rpc endpoint is capped by restricted_json_rpc_handler
+func restricted_body_handler(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		req.Body = http.MaxBytesReader(rw, req.Body, RESTRICTED_MAX_BODY_SIZE)
+		next(rw, req)
+	}
+}
+
+// caps body size and number of calls within a batch
+func restricted_json_rpc_handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, RESTRICTED_MAX_BODY_SIZE))
+		req.Body.Close()
+		if err != nil {
+			http.Error(rw, "Request too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if count := batch_count(body); count > RESTRICTED_MAX_BATCH {
+			http.Error(rw, fmt.Sprintf("Batch too large, limit %d", RESTRICTED_MAX_BATCH), http.StatusBadRequest)
+			return
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// number of calls in a json rpc request, 1 if it is not a batch
+func batch_count(body []byte) int {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		return 1
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return 1 // invalid requests are rejected by json rpc handler
+	}
+	return len(batch)
+}
+
+type rate_bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// token bucket per ip, each ip can burst upto 1 second worth of requests
+type rate_limiter struct {
+	rate    float64 // tokens added per second
+	buckets map[string]*rate_bucket
+	sync.Mutex
+}
+
+func new_rate_limiter(rate uint64) *rate_limiter {
+	return &rate_limiter{rate: float64(rate), buckets: map[string]*rate_bucket{}}
+}
+
+// consume a token for the ip, returns false if ip is over the limit
+func (l *rate_limiter) allow(ip string, now time.Time) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &rate_bucket{tokens: l.rate, last: now}
+		l.buckets[ip] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.rate {
+		b.tokens = l.rate
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// forget ips which have been idle long enough to have a full bucket
+func (l *rate_limiter) cleanup(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	for ip, b := range l.buckets {
+		if now.Sub(b.last) > time.Second {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+func (l *rate_limiter) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ip, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			ip = req.RemoteAddr
+		}
+		if !l.allow(ip, time.Now()) {
+			http.Error(rw, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
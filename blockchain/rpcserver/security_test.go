@@ -0,0 +1,97 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "time"
+import "bytes"
+import "strings"
+import "testing"
+import "net/http"
+import "net/http/httptest"
+
+// each ip gets its own bucket, which refills with time
+func Test_Rate_Limiter(t *testing.T) {
+	l := new_rate_limiter(2)
+	now := time.Now()
+
+	if !l.allow("1.1.1.1", now) || !l.allow("1.1.1.1", now) {
+		t.Fatalf("Rate limiter rejected requests within burst")
+	}
+	if l.allow("1.1.1.1", now) {
+		t.Fatalf("Rate limiter allowed requests above burst")
+	}
+	if !l.allow("2.2.2.2", now) {
+		t.Fatalf("Rate limiter did not track ips separately")
+	}
+	if !l.allow("1.1.1.1", now.Add(500*time.Millisecond)) {
+		t.Fatalf("Rate limiter did not refill")
+	}
+
+	l.cleanup(now.Add(time.Minute))
+	if len(l.buckets) != 0 {
+		t.Fatalf("Rate limiter did not forget idle ips")
+	}
+}
+
+func Test_Batch_Count(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected int
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"get_info"}`, 1},
+		{` [{"method":"get_info"},{"method":"getblockcount"}]`, 2},
+		{`[]`, 0},
+		{`[garbage`, 1},
+		{``, 1},
+	}
+	for _, test := range tests {
+		if actual := batch_count([]byte(test.body)); actual != test.expected {
+			t.Fatalf("Batch count failed for %s actual %d expected %d", test.body, actual, test.expected)
+		}
+	}
+}
+
+func Test_Restricted_Body_Handler(t *testing.T) {
+	restricted = true
+	defer func() { restricted = false }()
+
+	handler := restricted_body_handler(http_handler(IsKeyImageSpent_Handler{}))
+
+	// oversized body is rejected before it is parsed
+	body := `{"key_images":["` + strings.Repeat("0", RESTRICTED_MAX_BODY_SIZE) + `"]}`
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest("POST", "/is_key_image_spent", strings.NewReader(body)))
+	if rw.Code != http.StatusBadRequest || !strings.Contains(rw.Body.String(), "too large") {
+		t.Fatalf("Oversized body accepted, code %d body %s", rw.Code, rw.Body.String())
+	}
+
+	// too many key images are rejected before any lookup
+	var buf bytes.Buffer
+	buf.WriteString(`{"key_images":[`)
+	for i := 0; i <= RESTRICTED_MAX_KEY_IMAGES; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(`"` + strings.Repeat("0", 64) + `"`)
+	}
+	buf.WriteString(`]}`)
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest("POST", "/is_key_image_spent", &buf))
+	if rw.Code != http.StatusBadRequest || !strings.Contains(rw.Body.String(), "Too many key images") {
+		t.Fatalf("Too many key images accepted, code %d body %s", rw.Code, rw.Body.String())
+	}
+}
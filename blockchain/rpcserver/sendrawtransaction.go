@@ -0,0 +1,122 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package rpcserver
+
+import "fmt"
+import "context"
+import "encoding/hex"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/transaction"
+
+// this accepts a signed transaction from the wallet and adds it to the pool
+// the rejection fields are kept compatible with monero wherever possible
+type (
+	SendRawTransaction_Handler struct{}
+	SendRawTransaction_Params  struct {
+		Tx_as_hex string `json:"tx_as_hex"`
+	}
+	SendRawTransaction_Result struct {
+		Status string `json:"status"`
+		TXID   string `json:"txid"`
+		Reason string `json:"reason"`
+		Rule   string `json:"rule"` // which verification rule failed
+
+		Double_Spend   bool `json:"double_spend"`
+		Fee_Too_Low    bool `json:"fee_too_low"`
+		Invalid_Input  bool `json:"invalid_input"`
+		Invalid_Output bool `json:"invalid_output"`
+		Low_Mixin      bool `json:"low_mixin"`
+		Not_Rct        bool `json:"not_rct"`
+		Too_Big        bool `json:"too_big"`
+	}
+)
+
+func (h SendRawTransaction_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (response interface{}, jerr *jsonrpc.Error) {
+	var p SendRawTransaction_Params
+	var result SendRawTransaction_Result
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	defer func() { // safety so if anything wrong happens, we return error
+		if r := recover(); r != nil {
+			logger.Warnf("Recovered while processing sendrawtransaction %v", r)
+			response = SendRawTransaction_Result{Status: "Failed", Reason: fmt.Sprintf("tx could not be processed %v", r)}
+			jerr = nil
+		}
+	}()
+
+	tx_bytes, err := hex.DecodeString(p.Tx_as_hex)
+	if err != nil {
+		result.Status = "Failed"
+		result.Reason = fmt.Sprintf("tx_as_hex is not valid hex err %s", err)
+		return result, nil
+	}
+
+	var tx transaction.Transaction
+	if err = tx.DeserializeHeader(tx_bytes); err != nil {
+		result.Status = "Failed"
+		result.Reason = fmt.Sprintf("tx could not be deserialized err %s", err)
+		return result, nil
+	}
+
+	return sendrawtransaction_fill(&tx), nil
+}
+
+// add the tx to pool and convert any error to result
+func sendrawtransaction_fill(tx *transaction.Transaction) (result SendRawTransaction_Result) {
+	result.TXID = tx.GetHash().String()
+
+	err := chain.Add_TX_To_Pool_Stem(tx) // locally submitted txs always start in stem phase, hiding their origin
+	if err == nil {
+		result.Status = "OK"
+		return
+	}
+
+	result.Status = "Failed"
+	result.Reason = err.Error()
+
+	verr, ok := err.(*blockchain.TX_Verify_Error)
+	if !ok {
+		return
+	}
+	result.Rule = verr.Rule.String()
+
+	switch verr.Rule {
+	case blockchain.TX_RULE_KEYIMAGE_SPENT, blockchain.TX_RULE_KEYIMAGE_POOL, blockchain.TX_RULE_KEYIMAGE_DUPLICATE:
+		result.Double_Spend = true
+	case blockchain.TX_RULE_FEE:
+		result.Fee_Too_Low = true
+	case blockchain.TX_RULE_VIN_VOUT, blockchain.TX_RULE_VIN_TYPE, blockchain.TX_RULE_RING_DUPLICATE,
+		blockchain.TX_RULE_KEYIMAGE_LOW_ORDER, blockchain.TX_RULE_RING_MEMBER, blockchain.TX_RULE_IMMATURE:
+		result.Invalid_Input = true
+	case blockchain.TX_RULE_VOUT_TYPE, blockchain.TX_RULE_VOUT_AMOUNT:
+		result.Invalid_Output = true
+	case blockchain.TX_RULE_MIXIN:
+		result.Low_Mixin = true
+	case blockchain.TX_RULE_SIGNATURE_TYPE:
+		result.Not_Rct = true
+	case blockchain.TX_RULE_SIZE:
+		result.Too_Big = true
+	}
+
+	return
+}
@@ -16,10 +16,11 @@
 
 package rpcserver
 
-// get block template handler not implemented
+// submit block handler, miners submit mined blocks here
 
-//import "fmt"
+import "fmt"
 import "context"
+import "encoding/hex"
 
 //import	"log"
 //import 	"net/http"
@@ -27,32 +28,48 @@ import "context"
 import "github.com/intel-go/fastjson"
 import "github.com/osamingo/jsonrpc"
 
+// params are an array of hex encoded block blobs, as in monero
 type (
 	SubmitBlock_Handler struct{}
-	SubmitBlock_Params  struct {
-		Wallet_Address string `json:"wallet_address"`
-		Reserve_size   uint64 `json:"reserve_size"`
-	}
-	SubmitBlock_Result struct {
-		Blocktemplate_blob string `json:"blocktemplate_blob"`
-		Difficulty         uint64 `json:"difficulty"`
-		Height             uint64 `json:"height"`
-		Prev_Hash          string `json:"prev_hash"`
-		Reserved_Offset    uint64 `json:"reserved_offset"`
-		Status             string `json:"status"`
+	SubmitBlock_Params  []string
+	SubmitBlock_Result  struct {
+		BLID   string `json:"blid"`
+		Status string `json:"status"`
 	}
 )
 
-func (h SubmitBlock_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+func (h SubmitBlock_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (response interface{}, jerr *jsonrpc.Error) {
 
-	var p GetBlockTemplate_Params
+	var p SubmitBlock_Params
 	if err := jsonrpc.Unmarshal(params, &p); err != nil {
 		return nil, err
 	}
 
-	// Wallet_Address needs to validated before
+	defer func() { // safety so if anything wrong happens, we return error
+		if r := recover(); r != nil {
+			logger.Warnf("Recovered while processing submitblock %v", r)
+			response = nil
+			jerr = &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: fmt.Sprintf("block could not be processed %v", r)}
+		}
+	}()
+
+	if len(p) != 1 {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "Wrong param, expected exactly one block blob"}
+	}
+
+	block_blob, err := hex.DecodeString(p[0])
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Wrong block blob, not hex err %s", err)}
+	}
+
+	blid, err := chain.Accept_new_block(block_blob)
+	if err != nil {
+		logger.Warnf("Submitted block rejected err %s", err)
+		return nil, &jsonrpc.Error{Code: -7, Message: fmt.Sprintf("Block not accepted err %s", err)}
+	}
 
 	return SubmitBlock_Result{
-		Status: "NOT IMPLEMENTED",
+		BLID:   blid.String(),
+		Status: "OK",
 	}, nil
 }
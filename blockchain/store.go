@@ -324,8 +324,12 @@ func (chain *Blockchain) Load_TX_FROM_ID(hash [32]byte) (*transaction.Transactio
 		return nil, err
 	}
 
-	// we should deserialize the block here
-	err = tx.DeserializeHeader(tx_data)
+	// we should deserialize the block here, pruned txs only contain prefix and ringct base
+	if prunable_hash, pruned := chain.load_tx_prunable_hash(hash); pruned {
+		err = tx.DeserializePruned(tx_data, prunable_hash)
+	} else {
+		err = tx.DeserializeHeader(tx_data)
+	}
 
 	if err != nil {
 		logger.Printf("fError deserialiing tx, block id %s len(data) %d data %x\n", hash[:], len(tx_data), tx_data)
@@ -16,7 +16,8 @@
 
 package blockchain
 
-//import "fmt"
+import "fmt"
+
 //import "time"
 /*import "bytes"
 import "encoding/binary"
@@ -36,14 +37,183 @@ import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/crypto/ringct"
 import "github.com/arnaucode/derosuite/transaction"
 import "github.com/arnaucode/derosuite/emission"
+import "github.com/arnaucode/derosuite/blockchain/inputmaturity"
+
+// every rule which a transaction can fail is listed here
+// so as callers ( rpc, p2p, wallet) can report exactly why a tx was rejected
+type TX_Rule int
+
+const (
+	TX_RULE_PANIC              TX_Rule = iota // verification crashed, tx is considered invalid
+	TX_RULE_COINBASE                          // coinbase tx cannot come through this path
+	TX_RULE_VERSION                           // only version 2 tx are allowed
+	TX_RULE_SIZE                              // tx is bigger than allowed
+	TX_RULE_VIN_VOUT                          // atleast 1 vin and 1 vout are required
+	TX_RULE_VIN_TYPE                          // vin is not Txin_to_key
+	TX_RULE_VOUT_TYPE                         // vout is not Txout_to_key
+	TX_RULE_VOUT_AMOUNT                       // vout amount must be zero in ringct world
+	TX_RULE_MIXIN                             // mixin too low or not same across inputs
+	TX_RULE_RING_DUPLICATE                    // duplicate ring member within an input
+	TX_RULE_KEYIMAGE_DUPLICATE                // duplicate key image within the tx
+	TX_RULE_KEYIMAGE_LOW_ORDER                // key image is not in the prime order subgroup
+	TX_RULE_KEYIMAGE_SPENT                    // key image already spent in blockchain
+	TX_RULE_KEYIMAGE_POOL                     // key image already used by some tx in the pool
+	TX_RULE_RING_MEMBER                       // ring member does not exist in output index
+	TX_RULE_IMMATURE                          // ring member is not mature yet
+	TX_RULE_SIGNATURE_TYPE                    // ringct signature type is unknown
+	TX_RULE_SIGNATURE                         // ringct signature failed
+	TX_RULE_FEE                               // fee is lower than dynamic fee
+	TX_RULE_POOL                              // tx already in pool or rejected by pool
+)
+
+var tx_rule_names = map[TX_Rule]string{
+	TX_RULE_PANIC:              "panic",
+	TX_RULE_COINBASE:           "coinbase",
+	TX_RULE_VERSION:            "version",
+	TX_RULE_SIZE:               "size",
+	TX_RULE_VIN_VOUT:           "vin_vout",
+	TX_RULE_VIN_TYPE:           "vin_type",
+	TX_RULE_VOUT_TYPE:          "vout_type",
+	TX_RULE_VOUT_AMOUNT:        "vout_amount",
+	TX_RULE_MIXIN:              "mixin",
+	TX_RULE_RING_DUPLICATE:     "ring_duplicate",
+	TX_RULE_KEYIMAGE_DUPLICATE: "keyimage_duplicate",
+	TX_RULE_KEYIMAGE_LOW_ORDER: "keyimage_low_order",
+	TX_RULE_KEYIMAGE_SPENT:     "keyimage_spent",
+	TX_RULE_KEYIMAGE_POOL:      "keyimage_pool",
+	TX_RULE_RING_MEMBER:        "ring_member",
+	TX_RULE_IMMATURE:           "immature",
+	TX_RULE_SIGNATURE_TYPE:     "signature_type",
+	TX_RULE_SIGNATURE:          "signature",
+	TX_RULE_FEE:                "fee",
+	TX_RULE_POOL:               "pool",
+}
+
+func (r TX_Rule) String() string {
+	if name, ok := tx_rule_names[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(r))
+}
+
+// this error is returned by Verify_Transaction, Rule tells which check failed
+type TX_Verify_Error struct {
+	Rule   TX_Rule
+	TXID   crypto.Hash
+	Reason string
+}
+
+func (e *TX_Verify_Error) Error() string {
+	return fmt.Sprintf("TX %s rejected, rule %s failed: %s", e.TXID, e.Rule, e.Reason)
+}
+
+// returns true if the tx itself is provably bad, whoever relayed such a tx is misbehaving
+// txs already in pool, spending key images seen in pool or chain, paying low fee or using outputs
+// which we do not have yet can be relayed by honest peers which see a different chain or pool
+// a panic is not counted either, it points to a bug or a damaged DB on our side, not to the peer
+// and banning every peer relaying a tx we cannot handle would cut us off from the network
+func (e *TX_Verify_Error) Invalid() bool {
+	switch e.Rule {
+	case TX_RULE_PANIC, TX_RULE_KEYIMAGE_SPENT, TX_RULE_KEYIMAGE_POOL, TX_RULE_RING_MEMBER, TX_RULE_IMMATURE, TX_RULE_FEE, TX_RULE_POOL:
+		return false
+	}
+	return true
+}
+
+func tx_error(tx_hash crypto.Hash, rule TX_Rule, format string, args ...interface{}) error {
+	return &TX_Verify_Error{Rule: rule, TXID: tx_hash, Reason: fmt.Sprintf(format, args...)}
+}
 
 /* This function verifies tx fully, means all checks,
  * if the transaction has passed the check it can be added to mempool, relayed or added to blockchain
  * the transaction has already been deserialized thats it
+ * NOTE: key images are checked against the mempool also, so this must not be used for txs already in pool
  * */
-func (chain *Blockchain) Verify_Transaction(tx *transaction.Transaction) (result bool) {
+func (chain *Blockchain) Verify_Transaction(tx *transaction.Transaction) (err error) {
+	var tx_hash crypto.Hash
+	defer func() { // safety so if anything wrong happens, verification fails
+		if r := recover(); r != nil {
+			logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("Recovered while Verifying transaction, failed verification, Stack trace below")
+			logger.Warnf("Stack trace  \n%s", debug.Stack())
+			err = tx_error(tx_hash, TX_RULE_PANIC, "%v", r)
+		}
+	}()
+
+	tx_hash = tx.GetHash()
+
+	if tx.IsCoinbase() {
+		return tx_error(tx_hash, TX_RULE_COINBASE, "coinbase tx cannot be verified as normal tx")
+	}
 
-	return false
+	tx_size := uint64(len(tx.Serialize()))
+	if tx_size > Get_Transaction_Maximum_Size() {
+		return tx_error(tx_hash, TX_RULE_SIZE, "tx size %d exceeds maximum %d", tx_size, Get_Transaction_Maximum_Size())
+	}
+
+	if err = verify_transaction_structure(tx_hash, tx); err != nil {
+		return err
+	}
+
+	// check whether enough fees is provided in the transaction, this is cheap so it is done before any lookups
+	top_id := chain.Get_Top_ID()
+	dynamic_fees_per_kb := uint64(0)
+	previous_height := chain.Load_Height_for_BL_ID(top_id)
+	if previous_height >= 2 {
+		dynamic_fees_per_kb = chain.Get_Dynamic_Fee_Rate(previous_height)
+	}
+	calculated_fee := chain.Calculate_TX_fee(dynamic_fees_per_kb, tx_size)
+	provided_fee := tx.RctSignature.Get_TX_Fee()
+	if ((calculated_fee * 98) / 100) > provided_fee { // 2 % margin see blockchain.cpp L 2913
+		return tx_error(tx_hash, TX_RULE_FEE, "provided fee %d calculated fee %d", provided_fee, calculated_fee)
+	}
+
+	// check whether the key image is already used or spent earlier ( in blockchain or in pool )
+	for i := 0; i < len(tx.Vin); i++ {
+		k_image := tx.Vin[i].(transaction.Txin_to_key).K_image
+		if chain.Read_KeyImage_Status(k_image) {
+			return tx_error(tx_hash, TX_RULE_KEYIMAGE_SPENT, "key image %s already spent", k_image)
+		}
+		if chain.Mempool != nil && chain.Mempool.Mempool_Keyimage_Used(k_image) {
+			return tx_error(tx_hash, TX_RULE_KEYIMAGE_POOL, "key image %s already used by tx in pool", k_image)
+		}
+	}
+
+	// every ring member must exist in the output index and must be mature
+	// pool is stricter than blocks, miner outputs must be fully unlocked before they are used as ring members
+	// blocks are verified by Expand_Transaction_v2 only, so consensus is not changed by this check
+	max_index := chain.Get_Block_Output_Index(top_id) + chain.Block_Count_Vout(top_id)
+	current_height := chain.Get_Height()
+	for i := 0; i < len(tx.Vin); i++ {
+		offset := uint64(0)
+		for j := 0; j < len(tx.Vin[i].(transaction.Txin_to_key).Key_offsets); j++ {
+			offset += tx.Vin[i].(transaction.Txin_to_key).Key_offsets[j]
+			if offset >= max_index {
+				return tx_error(tx_hash, TX_RULE_RING_MEMBER, "input %d ring member %d does not exist, max index %d", i, offset, max_index)
+			}
+		}
+	}
+	for i := 0; i < len(tx.Vin); i++ {
+		offset := uint64(0)
+		for j := 0; j < len(tx.Vin[i].(transaction.Txin_to_key).Key_offsets); j++ {
+			offset += tx.Vin[i].(transaction.Txin_to_key).Key_offsets[j]
+			offset_data := chain.load_output_index(offset)
+			if !inputmaturity.Is_Input_Mature(current_height, offset_data.Height, offset_data.Unlock_Height, offset_data.SigType) {
+				return tx_error(tx_hash, TX_RULE_IMMATURE, "input %d ring member %d from block %d is not mature at height %d", i, offset, offset_data.Height, current_height)
+			}
+		}
+	}
+
+	// expand the signature and verify it
+	if !chain.Expand_Transaction_v2(tx) {
+		return tx_error(tx_hash, TX_RULE_RING_MEMBER, "inputs could not be expanded")
+	}
+
+	if !tx.RctSignature.Verify() {
+		return tx_error(tx_hash, TX_RULE_SIGNATURE, "ringct signature failed")
+	}
+
+	logger.WithFields(log.Fields{"txid": tx_hash}).Debugf("TX successfully verified")
+	return nil
 }
 
 /* Coinbase transactions need to verify the amount of coins
@@ -127,8 +297,7 @@ func (chain *Blockchain) Verify_Transaction_Coinbase(cbl *block.Complete_Block,
 }
 
 // all non miner tx must be non-coinbase tx
-// each check is placed in a separate  block of code, to avoid ambigous code or faulty checks
-// all check are placed and not within individual functions ( so as we cannot skip a check )
+// this is used while verifying txs within a block, the structure checks are shared with Verify_Transaction
 func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transaction) (result bool) {
 	result = false
 
@@ -143,30 +312,71 @@ func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transact
 
 	tx_hash = tx.GetHash()
 
-	if tx.Version != 2 {
+	if err := verify_transaction_structure(tx_hash, tx); err != nil {
+		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("%s", err)
+		return false
+	}
+
+	// a similiar block level check is done for double spending attacks within the block itself
+	// check whether the key image is already used or spent earlier ( in blockchain )
+	/*
+		for i := 0; i < len(tx.Vin); i++ {
+			k_image := ringct.Key(tx.Vin[i].(transaction.Txin_to_key).K_image)
+			if chain.Read_KeyImage_Status(crypto.Hash(k_image)) {
+				logger.WithFields(log.Fields{
+					"txid":   tx_hash,
+					"kimage": k_image,
+				}).Warnf("Key image is already spent, attempt to double spend ")
+				return false
+			}
+		}
+	*/
+	// expand the signature first
+	// whether the inputs are mature and can be used at time is verified while expanding the inputs
+	if !chain.Expand_Transaction_v2(tx) {
+		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("TX inputs could not be expanded or inputs are NOT mature")
 		return false
 	}
 
+	// check the ring signature
+	if !tx.RctSignature.Verify() {
+		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("TX RCT Signature failed")
+		return false
+
+	}
+
+	logger.WithFields(log.Fields{"txid": tx_hash}).Debugf("TX successfully verified")
+
+	return true
+}
+
+// all structural checks which do not need the blockchain state
+// tx_hash is passed by the caller, since hashing a malformed tx may panic
+// each check is placed in a separate  block of code, to avoid ambigous code or faulty checks
+// all check are placed and not within individual functions ( so as we cannot skip a check )
+func verify_transaction_structure(tx_hash crypto.Hash, tx *transaction.Transaction) error {
+	if tx.Version != 2 {
+		return tx_error(tx_hash, TX_RULE_VERSION, "version %d not supported", tx.Version)
+	}
+
 	// make sure atleast 1 vin and 1 vout are there
 	if len(tx.Vin) < 1 || len(tx.Vout) < 1 {
-		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("Incoming TX does NOT have atleast 1 vin and 1 vout")
-		return false
+		return tx_error(tx_hash, TX_RULE_VIN_VOUT, "tx does NOT have atleast 1 vin and 1 vout")
 	}
 
 	// this means some other checks have failed somewhere else
 	if tx.IsCoinbase() { // transaction coinbase must never come here
-		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("Coinbase tx in non coinbase path, Please investigate")
-		return false
+		return tx_error(tx_hash, TX_RULE_COINBASE, "coinbase tx in non coinbase path")
 	}
 
 	// Vin can be only specific type rest all make the fail case
 	for i := 0; i < len(tx.Vin); i++ {
 		switch tx.Vin[i].(type) {
 		case transaction.Txin_gen:
-			return false // this is for coinbase so fail it
+			return tx_error(tx_hash, TX_RULE_VIN_TYPE, "input %d is coinbase input", i) // this is for coinbase so fail it
 		case transaction.Txin_to_key: // pass
 		default:
-			return false
+			return tx_error(tx_hash, TX_RULE_VIN_TYPE, "input %d has unknown type", i)
 		}
 	}
 
@@ -175,15 +385,14 @@ func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transact
 		switch tx.Vout[i].Target.(type) {
 		case transaction.Txout_to_key: // pass
 		default:
-			return false
+			return tx_error(tx_hash, TX_RULE_VOUT_TYPE, "output %d has unknown type", i)
 		}
 	}
 
 	// Vout should have amount 0
 	for i := 0; i < len(tx.Vout); i++ {
 		if tx.Vout[i].Amount != 0 {
-			logger.WithFields(log.Fields{"txid": tx_hash, "Amount": tx.Vout[i].Amount}).Warnf("Amount must be zero in ringCT world")
-			return false
+			return tx_error(tx_hash, TX_RULE_VOUT_AMOUNT, "output %d amount %d must be zero in ringCT world", i, tx.Vout[i].Amount)
 		}
 	}
 
@@ -200,13 +409,11 @@ func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transact
 	// someone did send a mixin of 3 in 12006 block height
 	mixin := len(tx.Vin[0].(transaction.Txin_to_key).Key_offsets)
 	if mixin < 3 {
-		logger.WithFields(log.Fields{"txid": tx_hash, "Mixin": mixin}).Warnf("Mixin must be atleast 3 in ringCT world")
-		return false
+		return tx_error(tx_hash, TX_RULE_MIXIN, "mixin %d must be atleast 3 in ringCT world", mixin)
 	}
 	for i := 0; i < len(tx.Vin); i++ {
 		if mixin != len(tx.Vin[i].(transaction.Txin_to_key).Key_offsets) {
-			logger.WithFields(log.Fields{"txid": tx_hash, "Mixin": mixin}).Warnf("Mixin must be same for entire TX in ringCT world")
-			return false
+			return tx_error(tx_hash, TX_RULE_MIXIN, "mixin must be same for entire TX, input %d has %d expected %d", i, len(tx.Vin[i].(transaction.Txin_to_key).Key_offsets), mixin)
 		}
 	}
 
@@ -218,8 +425,7 @@ func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transact
 		for j := 0; j < len(tx.Vin[i].(transaction.Txin_to_key).Key_offsets); j++ {
 			ring_member += tx.Vin[i].(transaction.Txin_to_key).Key_offsets[j]
 			if _, ok := ring_members[ring_member]; ok {
-				logger.WithFields(log.Fields{"txid": tx_hash, "input_index": i}).Warnf("Duplicate ring member within the TX")
-				return false
+				return tx_error(tx_hash, TX_RULE_RING_DUPLICATE, "input %d has duplicate ring member %d", i, ring_member)
 			}
 			ring_members[ring_member] = true // add member to ring member
 		}
@@ -231,11 +437,7 @@ func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transact
 		kimages := map[crypto.Hash]bool{}
 		for i := 0; i < len(tx.Vin); i++ {
 			if _, ok := kimages[tx.Vin[i].(transaction.Txin_to_key).K_image]; ok {
-				logger.WithFields(log.Fields{
-					"txid":   tx_hash,
-					"kimage": tx.Vin[i].(transaction.Txin_to_key).K_image,
-				}).Warnf("TX using duplicate inputs within the TX")
-				return false
+				return tx_error(tx_hash, TX_RULE_KEYIMAGE_DUPLICATE, "key image %s used twice within the TX", tx.Vin[i].(transaction.Txin_to_key).K_image)
 			}
 			kimages[tx.Vin[i].(transaction.Txin_to_key).K_image] = true // add element to map for next check
 		}
@@ -247,54 +449,19 @@ func (chain *Blockchain) Verify_Transaction_NonCoinbase(tx *transaction.Transact
 		curve_order := ringct.CurveOrder()
 		mult_result := ringct.ScalarMultKey(&k_image, &curve_order)
 		if *mult_result != ringct.Identity {
-			logger.WithFields(log.Fields{
-				"txid":        tx_hash,
-				"kimage":      tx.Vin[i].(transaction.Txin_to_key).K_image,
-				"curve_order": curve_order,
-				"mult_result": *mult_result,
-				"identity":    ringct.Identity,
-			}).Warnf("TX contains a low order key image attack, but we are already safeguarded")
-			return false
+			return tx_error(tx_hash, TX_RULE_KEYIMAGE_LOW_ORDER, "key image %s is a low order key image", k_image)
 		}
 	}
 
-	// a similiar block level check is done for double spending attacks within the block itself
-	// check whether the key image is already used or spent earlier ( in blockchain )
-	/*
-		for i := 0; i < len(tx.Vin); i++ {
-			k_image := ringct.Key(tx.Vin[i].(transaction.Txin_to_key).K_image)
-			if chain.Read_KeyImage_Status(crypto.Hash(k_image)) {
-				logger.WithFields(log.Fields{
-					"txid":   tx_hash,
-					"kimage": k_image,
-				}).Warnf("Key image is already spent, attempt to double spend ")
-				return false
-			}
-		}
-	*/
 	// check whether the TX contains a signature or NOT
+	if tx.RctSignature == nil {
+		return tx_error(tx_hash, TX_RULE_SIGNATURE_TYPE, "TX does NOT contain a ringct signature")
+	}
 	switch tx.RctSignature.Get_Sig_Type() {
 	case ringct.RCTTypeSimple, ringct.RCTTypeFull: // default case, pass through
 	default:
-		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("TX does NOT contain a ringct signature. It is NOT possible")
-		return false
+		return tx_error(tx_hash, TX_RULE_SIGNATURE_TYPE, "unknown ringct signature type %d", tx.RctSignature.Get_Sig_Type())
 	}
 
-	// expand the signature first
-	// whether the inputs are mature and can be used at time is verified while expanding the inputs
-	if !chain.Expand_Transaction_v2(tx) {
-		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("TX inputs could not be expanded or inputs are NOT mature")
-		return false
-	}
-
-	// check the ring signature
-	if !tx.RctSignature.Verify() {
-		logger.WithFields(log.Fields{"txid": tx_hash}).Warnf("TX RCT Signature failed")
-		return false
-
-	}
-
-	logger.WithFields(log.Fields{"txid": tx_hash}).Debugf("TX successfully verified")
-
-	return true
+	return nil
 }
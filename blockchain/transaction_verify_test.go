@@ -0,0 +1,279 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "os"
+import "time"
+import "testing"
+import "net/http"
+import "io/ioutil"
+import "compress/gzip"
+import "net/http/httptest"
+import "strconv"
+import "encoding/binary"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/walletapi"
+import "github.com/arnaucode/derosuite/difficulty"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/transaction"
+
+// check that structural failures report the correct rule
+func Test_Verify_Transaction_Structure(t *testing.T) {
+
+	expect_rule := func(name string, tx *transaction.Transaction, rule TX_Rule) {
+		err := verify_transaction_structure(crypto.Hash{}, tx)
+		if err == nil {
+			t.Fatalf("%s: tx should have failed verification", name)
+		}
+		verr, ok := err.(*TX_Verify_Error)
+		if !ok {
+			t.Fatalf("%s: error is not TX_Verify_Error  %T", name, err)
+		}
+		if verr.Rule != rule {
+			t.Fatalf("%s: expected rule %s actual %s", name, rule, verr.Rule)
+		}
+	}
+
+	input := transaction.Txin_to_key{Key_offsets: []uint64{1, 2, 3, 4}}
+	output := transaction.Tx_out{Target: transaction.Txout_to_key{}}
+
+	var tx transaction.Transaction
+	tx.Version = 1
+	expect_rule("version", &tx, TX_RULE_VERSION)
+
+	tx.Version = 2
+	expect_rule("no inputs", &tx, TX_RULE_VIN_VOUT)
+
+	tx.Vin = append(tx.Vin, transaction.Txin_gen{Height: 1})
+	tx.Vout = append(tx.Vout, output)
+	expect_rule("coinbase", &tx, TX_RULE_COINBASE)
+
+	tx.Vin[0] = input
+	tx.Vout[0].Amount = 1
+	expect_rule("amount", &tx, TX_RULE_VOUT_AMOUNT)
+
+	tx.Vout[0].Amount = 0
+	tx.Vin[0] = transaction.Txin_to_key{Key_offsets: []uint64{1, 2}}
+	expect_rule("mixin", &tx, TX_RULE_MIXIN)
+
+	tx.Vin[0] = transaction.Txin_to_key{Key_offsets: []uint64{1, 2, 0, 4}}
+	expect_rule("ring duplicate", &tx, TX_RULE_RING_DUPLICATE)
+
+	var kimage crypto.Hash
+	kimage[0] = 1
+	tx.Vin[0] = transaction.Txin_to_key{Key_offsets: []uint64{1, 2, 3, 4}, K_image: kimage}
+	tx.Vin = append(tx.Vin, tx.Vin[0])
+	expect_rule("keyimage duplicate", &tx, TX_RULE_KEYIMAGE_DUPLICATE)
+}
+
+func Test_TX_Verify_Error(t *testing.T) {
+	err := tx_error(crypto.Hash{}, TX_RULE_FEE, "provided fee %d", 5)
+	if err.Error() != "TX 0000000000000000000000000000000000000000000000000000000000000000 rejected, rule fee failed: provided fee 5" {
+		t.Fatalf("unexpected error string %s", err)
+	}
+
+	if TX_Rule(999).String() != "unknown(999)" {
+		t.Fatalf("unknown rule not reported")
+	}
+}
+
+// start a fresh simulator chain in a temp dir, cleanup must be called by the caller
+func test_start_chain(t *testing.T) (chain *Blockchain, cleanup func()) {
+	dir, err := ioutil.TempDir("", "chaintest")
+	if err != nil {
+		t.Fatalf("cannot create temp dir err %s", err)
+	}
+	old_tmpdir := os.Getenv("TMPDIR")
+	os.Setenv("TMPDIR", dir) // store and pool files go here
+
+	globals.Config = config.Mainnet
+	globals.Logger = log.New()
+	globals.Logger.SetLevel(log.ErrorLevel)
+
+	chain, err = Blockchain_Start(map[string]interface{}{"--disable-checkpoints": false, "--simulator": true})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("cannot start chain err %s", err)
+	}
+
+	return chain, func() {
+		chain.Shutdown()
+		os.Setenv("TMPDIR", old_tmpdir)
+		os.RemoveAll(dir)
+	}
+}
+
+// mine count blocks on top of the chain, timestamps are spaced by block time in the past
+func test_mine_blocks(t *testing.T, chain *Blockchain, count int) {
+	account, _ := walletapi.Generate_Keys_From_Random()
+	test_mine_blocks_to(t, chain, count, account.GetAddress())
+}
+
+// same as test_mine_blocks, rewards go to addr
+func test_mine_blocks_to(t *testing.T, chain *Blockchain, count int, addr address.Address) {
+	base := uint64(time.Now().Unix()) - 100000
+	for i := 0; i < count; i++ {
+		bl, err := chain.Create_new_miner_block(addr, 0)
+		if err != nil {
+			t.Fatalf("cannot create miner block err %s", err)
+		}
+		bl.Timestamp = base + chain.Get_Height()*config.BLOCK_TIME
+		for !difficulty.CheckPowHash(bl.GetPoWHash(), chain.Get_Difficulty_At_Block(bl.Prev_Hash)) {
+			bl.Nonce++
+		}
+		if err := chain.Add_Complete_Block(&block.Complete_Block{Bl: &bl}); err != nil {
+			t.Fatalf("mined block rejected err %s", err)
+		}
+	}
+}
+
+// create a structurally valid tx with a random ring, ring members are placed at given relative offsets
+func test_create_tx(t *testing.T, fee uint64, offsets []uint64) *transaction.Transaction {
+	amount := fee + 1000
+	var input ringct.Input_Info
+	input.Sk.Destination = *ringct.RandomScalar()
+	input.Sk.Mask = *ringct.RandomScalar()
+	input.Amount = amount
+	for i := range offsets {
+		member := ringct.CtKey{Destination: *ringct.RandomPubKey(), Mask: *ringct.RandomPubKey()}
+		if i == input.Index { // commitment is mask*G + amount*H
+			var amount_key ringct.Key
+			binary.LittleEndian.PutUint64(amount_key[:], amount)
+			member.Destination = ringct.ScalarmultBase(input.Sk.Destination)
+			mask := ringct.ScalarmultBase(input.Sk.Mask)
+			ringct.AddKeys(&member.Mask, &mask, ringct.ScalarMultH(&amount_key))
+		}
+		input.Ring = append(input.Ring, member)
+	}
+
+	output := ringct.Output_Info{Destination: *ringct.RandomPubKey(), Amount: 1000, Amount_Key: *ringct.RandomScalar()}
+
+	var tx transaction.Transaction
+	tx.Version = 2
+	tx.Vin = append(tx.Vin, transaction.Txin_to_key{Key_offsets: offsets, K_image: crypto.Hash(ringct.ScalarmultBase(*ringct.RandomScalar()))})
+	tx.Vout = append(tx.Vout, transaction.Tx_out{Target: transaction.Txout_to_key{Key: crypto.Key(output.Destination)}})
+
+	var err error
+	tx.RctSignature, err = ringct.Generate_RctSig(ringct.RCTTypeFull, ringct.Key(tx.GetPrefixHash()), []ringct.Input_Info{input}, []ringct.Output_Info{output}, fee)
+	if err != nil {
+		t.Fatalf("cannot generate ringct signature err %s", err)
+	}
+	return &tx
+}
+
+// checks which need the chain state, spent key images, missing ring members and fees
+func Test_Verify_Transaction_Chain_State(t *testing.T) {
+	chain, cleanup := test_start_chain(t)
+	defer cleanup()
+
+	test_mine_blocks(t, chain, 3) // dynamic fee is only enforced from height 2
+
+	expect_rule := func(name string, tx *transaction.Transaction, rule TX_Rule) {
+		err := chain.Verify_Transaction(tx)
+		if err == nil {
+			t.Fatalf("%s: tx should have failed verification", name)
+		}
+		verr, ok := err.(*TX_Verify_Error)
+		if !ok {
+			t.Fatalf("%s: error is not TX_Verify_Error  %T", name, err)
+		}
+		if verr.Rule != rule {
+			t.Fatalf("%s: expected rule %s actual %s err %s", name, rule, verr.Rule, err)
+		}
+	}
+
+	high_fee := uint64(10000000000000)
+	offsets := []uint64{0, 1, 1, 1}
+
+	expect_rule("low fee", test_create_tx(t, 1, offsets), TX_RULE_FEE)
+
+	tx := test_create_tx(t, high_fee, []uint64{0, 1, 1, 1000000})
+	expect_rule("ring member out of range", tx, TX_RULE_RING_MEMBER)
+
+	tx = test_create_tx(t, high_fee, offsets)
+	chain.Store_KeyImage(tx.Vin[0].(transaction.Txin_to_key).K_image, true)
+	chain.store.Commit()
+	expect_rule("spent key image", tx, TX_RULE_KEYIMAGE_SPENT)
+
+	// ring members are miner outputs, which are not mature for 60 blocks
+	expect_rule("immature ring member", test_create_tx(t, high_fee, offsets), TX_RULE_IMMATURE)
+}
+
+// a tx signed by a wallet must be accepted, and rejected once its key image is used by a tx in the pool
+func Test_Verify_Transaction_Accepted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("miner outputs need a long chain to mature, skipped in short mode")
+	}
+
+	chain, cleanup := test_start_chain(t)
+	defer cleanup()
+
+	// rewards of first blocks are ours, rest of the blocks mature them
+	account, _ := walletapi.Generate_Keys_From_Random()
+	test_mine_blocks_to(t, chain, 10, account.GetAddress())
+	test_mine_blocks(t, chain, int(config.MINER_TX_AMOUNT_UNLOCK)+1)
+
+	// wallet fetches decoys from the chain, same as getoutputs.bin
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start, _ := strconv.ParseUint(req.URL.Query().Get("start"), 10, 64)
+		stop, _ := strconv.ParseUint(req.URL.Query().Get("stop"), 10, 64)
+		gzipwriter := gzip.NewWriter(rw)
+		defer gzipwriter.Close()
+		for i := start; i <= stop && i < chain.Get_Output_Count(); i++ {
+			data, _ := chain.Read_output_index(i)
+			gzipwriter.Write(data)
+		}
+	}))
+	defer server.Close()
+	walletapi.Daemon_Endpoint = server.Listener.Addr().String()
+
+	for i := uint64(0); i < chain.Get_Output_Count(); i++ {
+		output, err := chain.Load_Output_Index(i)
+		if err != nil {
+			t.Fatalf("cannot load output %d err %s", i, err)
+		}
+		account.Process_Output(&output)
+	}
+	account.Height = chain.Get_Height()
+	account.Index_Global = chain.Get_Output_Count()
+
+	receiver, _ := walletapi.Generate_Keys_From_Random()
+	fee_per_kb := chain.Get_Dynamic_Fee_Rate(chain.Get_Height() - 1)
+	tx, _, _, err := account.Transfer([]address.Address{receiver.GetAddress()}, []uint64{1000000000000}, 0, "", fee_per_kb, 5)
+	if err != nil {
+		t.Fatalf("wallet could not create tx err %s", err)
+	}
+
+	if err = chain.Verify_Transaction(tx); err != nil {
+		t.Fatalf("valid tx rejected err %s", err)
+	}
+	if err = chain.Add_TX_To_Pool(tx); err != nil {
+		t.Fatalf("valid tx not added to pool err %s", err)
+	}
+
+	err = chain.Verify_Transaction(tx)
+	if verr, ok := err.(*TX_Verify_Error); !ok || verr.Rule != TX_RULE_KEYIMAGE_POOL {
+		t.Fatalf("tx with key image used in pool not rejected, err %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "fmt"
+import "bytes"
+import "runtime/debug"
+import "encoding/binary"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/transaction"
+
+/* this file implements verify-db, which walks the main chain from genesis and checks all data derived while adding blocks
+ * main chain is reconstructed from TOP_ID and the parent links inside block blobs, since blobs are the primary data
+ * everything else ( height mapping, block planets, children, output index, key images) is recomputed and compared
+ * in repair mode, wrong or missing derived data is rewritten with recomputed values
+ */
+
+const VERIFY_DB_COMMIT_INTERVAL = 1000 // in repair mode, writes are committed after these many blocks
+
+type db_verifier struct {
+	chain      *Blockchain
+	repair     bool
+	issues     uint64
+	repaired   uint64                 // writes pending commit
+	key_images map[crypto.Hash]uint64 // key images spent in main chain and their height, to detect double spends
+}
+
+func (v *db_verifier) report(height uint64, block_id crypto.Hash, format string, args ...interface{}) {
+	v.issues++
+	logger.Warnf("Height %d block %s : %s", height, block_id, fmt.Sprintf(format, args...))
+}
+
+// compare stored object with expected value, returns false if they differ
+func (v *db_verifier) compare(galaxy, solar, key []byte, expected []byte) (stored []byte, ok bool) {
+	stored, err := v.chain.store.LoadObject(BLOCKCHAIN_UNIVERSE, galaxy, solar, key)
+	if err != nil {
+		stored = nil
+	}
+	if len(stored) > 0 && bytes.Equal(stored, expected) {
+		return stored, true
+	}
+	if v.repair {
+		v.chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, galaxy, solar, key, expected)
+		v.repaired++
+	}
+	return stored, false
+}
+
+func (v *db_verifier) check_object(height uint64, block_id crypto.Hash, name string, galaxy, solar, key []byte, expected []byte) {
+	if stored, ok := v.compare(galaxy, solar, key, expected); !ok {
+		if len(stored) == 0 {
+			v.report(height, block_id, "%s missing, expected %x", name, expected)
+		} else {
+			v.report(height, block_id, "%s is %x, expected %x", name, stored, expected)
+		}
+	}
+}
+
+// uint64 values are stored big endian, see itob
+func (v *db_verifier) check_uint64(height uint64, block_id crypto.Hash, name string, galaxy, solar, key []byte, expected uint64) {
+	if stored, ok := v.compare(galaxy, solar, key, itob(expected)); !ok {
+		if len(stored) != 8 {
+			v.report(height, block_id, "%s missing or invalid, expected %d", name, expected)
+		} else {
+			v.report(height, block_id, "%s is %d, expected %d", name, binary.BigEndian.Uint64(stored), expected)
+		}
+	}
+}
+
+// main chain block ids in height order, reconstructed from top block using parent links
+func (chain *Blockchain) main_chain_ids() (ids []crypto.Hash, err error) {
+	block_id := chain.Load_TOP_ID()
+	for {
+		bl, err := chain.Load_BL_FROM_ID(block_id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load block %s at depth %d err %s", block_id, len(ids), err)
+		}
+		ids = append(ids, block_id)
+		if block_id == globals.Config.Genesis_Block_Hash {
+			break
+		}
+		if uint64(len(ids)) > config.MAX_CHAIN_HEIGHT {
+			return nil, fmt.Errorf("chain does not lead to genesis block")
+		}
+		block_id = bl.Prev_Hash
+	}
+
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 { // genesis first
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	return
+}
+
+// values of previous block, which are used to compute values of next block
+type verify_state struct {
+	block_id              crypto.Hash
+	cumulative_difficulty uint64
+	output_index          uint64
+	vout_count            uint64
+	generated_coins       uint64
+}
+
+// verify derived data of a single main chain block
+func (v *db_verifier) verify_block(height uint64, block_id crypto.Hash, prev verify_state) (state verify_state) {
+	chain := v.chain
+	state.block_id = block_id
+
+	bl, err := chain.Load_BL_FROM_ID(block_id)
+	if err != nil {
+		v.report(height, block_id, "cannot load block err %s", err)
+		return
+	}
+
+	v.check_object(height, block_id, "height mapping", GALAXY_HEIGHT, PLANET_HEIGHT, itob(height), block_id[:])
+	v.check_uint64(height, block_id, "height", GALAXY_BLOCK, block_id[:], PLANET_HEIGHT, height)
+	v.check_uint64(height, block_id, "timestamp", GALAXY_BLOCK, block_id[:], PLANET_TIMESTAMP, bl.Timestamp)
+	v.check_object(height, block_id, "parent", GALAXY_BLOCK, block_id[:], PLANET_PARENT, bl.Prev_Hash[:])
+
+	// main child of parent must be this block, alternative children must not contain it
+	if height > 0 {
+		v.check_object(height, block_id, "child of parent", GALAXY_BLOCK, bl.Prev_Hash[:], PLANET_CHILD, block_id[:])
+
+		children_bytes, _ := chain.store.LoadObject(BLOCKCHAIN_UNIVERSE, GALAXY_BLOCK, bl.Prev_Hash[:], PLANET_CHILDREN)
+		if len(children_bytes)%32 != 0 {
+			v.report(height, block_id, "children of parent are not multiple of 32 bytes")
+			if v.repair {
+				chain.Store_Block_Children(bl.Prev_Hash, nil, block_id)
+				v.repaired++
+			}
+		} else {
+			var children []crypto.Hash
+			main_child := false
+			for i := 0; i < len(children_bytes); i += 32 {
+				var child crypto.Hash
+				copy(child[:], children_bytes[i:i+32])
+				children = append(children, child)
+				main_child = main_child || child == block_id
+			}
+			if main_child {
+				v.report(height, block_id, "block is main child, but also listed in alternative children of parent")
+				if v.repair {
+					chain.Store_Block_Children(bl.Prev_Hash, children, block_id)
+					v.repaired++
+				}
+			}
+		}
+	}
+
+	// miner tx is stored separately
+	miner_tx_hash := bl.Miner_tx.GetHash()
+	v.check_uint64(height, block_id, "miner tx height", GALAXY_TRANSACTION, miner_tx_hash[:], PLANET_TX_MINED_IN_BLOCK, height)
+
+	// check all txs, their sizes and their key images
+	size := uint64(len(bl.Miner_tx.Serialize()))
+	fees := uint64(0)
+	state.vout_count = uint64(len(bl.Miner_tx.Vout))
+	txs_ok := true
+	for _, txhash := range bl.Tx_hashes {
+		tx, err := chain.Load_TX_FROM_ID(txhash)
+		if err != nil || tx == nil {
+			v.report(height, block_id, "cannot load tx %s err %s", txhash, err)
+			txs_ok = false
+			continue
+		}
+
+		v.check_uint64(height, block_id, "tx "+txhash.String()+" height", GALAXY_TRANSACTION, txhash[:], PLANET_TX_MINED_IN_BLOCK, height)
+
+		// pruned txs no longer have their full size, so stored size is trusted
+		tx_size := chain.Load_TX_Size(txhash)
+		if !tx.IsPruned() {
+			tx_size = uint64(len(tx.Serialize()))
+			v.check_uint64(height, block_id, "tx "+txhash.String()+" size", GALAXY_TRANSACTION, txhash[:], PLANET_TX_SIZE, tx_size)
+		}
+		size += tx_size
+
+		if tx.Version == 2 && tx.RctSignature != nil {
+			fees += tx.RctSignature.Get_TX_Fee()
+		}
+		state.vout_count += uint64(len(tx.Vout))
+
+		for _, vin := range tx.Vin {
+			key, ok := vin.(transaction.Txin_to_key)
+			if !ok {
+				continue
+			}
+			k_image := crypto.Hash(key.K_image)
+			if spent_height, ok := v.key_images[k_image]; ok {
+				v.report(height, block_id, "key image %s of tx %s already spent at height %d", k_image, txhash, spent_height)
+				continue
+			}
+			v.key_images[k_image] = height
+			if !chain.Read_KeyImage_Status(k_image) {
+				v.report(height, block_id, "key image %s of tx %s not marked as spent", k_image, txhash)
+				if v.repair {
+					chain.Store_KeyImage(k_image, true)
+					v.repaired++
+				}
+			}
+		}
+	}
+
+	// cumulative difficulty, genesis block cumulative difficulty is 1
+	state.cumulative_difficulty = 1
+	if height > 0 {
+		state.cumulative_difficulty = prev.cumulative_difficulty + chain.Get_Difficulty_At_Block(bl.Prev_Hash)
+	}
+	v.check_uint64(height, block_id, "cumulative difficulty", GALAXY_BLOCK, block_id[:], PLANET_CUMULATIVE_DIFFICULTY, state.cumulative_difficulty)
+
+	if txs_ok {
+		v.check_uint64(height, block_id, "size", GALAXY_BLOCK, block_id[:], PLANET_SIZE, size)
+	}
+
+	if height > 0 {
+		state.output_index = prev.output_index + prev.vout_count
+	}
+	v.check_uint64(height, block_id, "output index", GALAXY_BLOCK, block_id[:], PLANET_OUTPUT_INDEX, state.output_index)
+
+	// same rules as Store_BL, genesis coins are special, see comments in emission
+	base_reward := bl.Miner_tx.Vout[0].Amount - fees
+	if txs_ok {
+		v.check_uint64(height, block_id, "base reward", GALAXY_BLOCK, block_id[:], PLANET_BASEREWARD, base_reward)
+	}
+	if height == 0 {
+		base_reward = 1000000000000
+	}
+	state.generated_coins = prev.generated_coins + base_reward
+	v.check_uint64(height, block_id, "generated coins", GALAXY_BLOCK, block_id[:], PLANET_ALREADY_GENERATED_COINS, state.generated_coins)
+
+	// output index entries must match what write_output_index would write
+	if txs_ok {
+		for i, expected := range chain.build_output_index(bl, height, state.output_index) {
+			index := state.output_index + uint64(i)
+			if _, ok := v.compare(GALAXY_OUTPUT_INDEX, GALAXY_OUTPUT_INDEX, itob(index), expected); !ok {
+				v.report(height, block_id, "output index entry %d is wrong", index)
+			}
+		}
+	}
+
+	return
+}
+
+// walk the main chain from genesis and check all derived data, returns number of issues found
+// if repair is set, wrong derived data is rewritten
+func (chain *Blockchain) Verify_DB(repair bool) (issues uint64, err error) {
+	chain.Lock()
+	defer chain.Unlock()
+
+	v := db_verifier{chain: chain, repair: repair, key_images: map[crypto.Hash]uint64{}}
+
+	defer func() { // database may be corrupted in ways we cannot imagine
+		if r := recover(); r != nil {
+			logger.Warnf("Recovered while verifying database, Stack trace below")
+			logger.Warnf("Stack trace  \n%s", debug.Stack())
+			err = fmt.Errorf("database verification aborted err %v", r)
+		}
+		if v.repaired > 0 {
+			chain.store.Commit()
+			chain.store.Sync()
+		}
+		issues = v.issues
+	}()
+
+	ids, err := chain.main_chain_ids()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(ids)) != chain.Height {
+		v.report(chain.Height, chain.Top_ID, "chain height is %d, but main chain has %d blocks", chain.Height, len(ids))
+	}
+
+	logger.Infof("Verifying %d blocks", len(ids))
+
+	var state verify_state
+	for i, block_id := range ids {
+		height := uint64(i)
+		state = v.verify_block(height, block_id, state)
+
+		if (height+1)%VERIFY_DB_COMMIT_INTERVAL == 0 {
+			if v.repaired > 0 {
+				chain.store.Commit()
+				v.repaired = 0
+			}
+			logger.Infof("Verified %d/%d blocks, %d issues", height+1, len(ids), v.issues)
+		}
+	}
+
+	// height mappings above top block are left over after reorganisation
+	if block_id, err := chain.Load_BL_ID_at_Height(uint64(len(ids))); err == nil {
+		v.report(uint64(len(ids)), block_id, "height mapping exists above top block")
+	}
+
+	logger.Infof("Verified %d blocks, %d issues found", len(ids), v.issues)
+	return
+}
@@ -0,0 +1,83 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package blockchain
+
+import "os"
+import "testing"
+import "io/ioutil"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/globals"
+
+// derived data which is damaged must be reported and repaired
+func Test_Verify_DB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verifydb")
+	if err != nil {
+		t.Fatalf("cannot create temp dir err %s", err)
+	}
+	defer os.RemoveAll(dir)
+	old_tmpdir := os.Getenv("TMPDIR")
+	os.Setenv("TMPDIR", dir) // store and pool files go here
+	defer os.Setenv("TMPDIR", old_tmpdir)
+
+	globals.Config = config.Mainnet
+	globals.Logger = log.New()
+	globals.Logger.SetLevel(log.ErrorLevel)
+
+	chain, err := Blockchain_Start(map[string]interface{}{"--disable-checkpoints": false, "--simulator": true})
+	if err != nil {
+		t.Fatalf("cannot start chain err %s", err)
+	}
+	defer chain.Shutdown()
+
+	if issues, err := chain.Verify_DB(false); err != nil || issues != 0 {
+		t.Fatalf("fresh chain has %d issues err %v", issues, err)
+	}
+
+	// damage some derived data of genesis block
+	genesis := globals.Config.Genesis_Block_Hash
+	chain.store.StoreUint64(BLOCKCHAIN_UNIVERSE, GALAXY_BLOCK, genesis[:], PLANET_ALREADY_GENERATED_COINS, 7)
+	chain.store.StoreUint64(BLOCKCHAIN_UNIVERSE, GALAXY_BLOCK, genesis[:], PLANET_CUMULATIVE_DIFFICULTY, 9)
+	chain.store.Commit()
+
+	if issues, err := chain.Verify_DB(false); err != nil || issues != 2 {
+		t.Fatalf("expected 2 issues, found %d err %v", issues, err)
+	}
+	if issues, err := chain.Verify_DB(true); err != nil || issues != 2 {
+		t.Fatalf("expected 2 issues to be repaired, found %d err %v", issues, err)
+	}
+	if issues, err := chain.Verify_DB(false); err != nil || issues != 0 {
+		t.Fatalf("repaired chain has %d issues err %v", issues, err)
+	}
+
+	// output index written by an older version is reindexed once
+	chain.store.StoreObject(BLOCKCHAIN_UNIVERSE, GALAXY_OUTPUT_INDEX, GALAXY_OUTPUT_INDEX, itob(0), []byte{0x80})
+	chain.store.StoreUint64(BLOCKCHAIN_UNIVERSE, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY, 1)
+	chain.store.Commit()
+	if issues, err := chain.Verify_DB(false); err != nil || issues != 1 {
+		t.Fatalf("expected outdated output index entry, found %d issues err %v", issues, err)
+	}
+	chain.upgrade_output_index()
+	if issues, err := chain.Verify_DB(false); err != nil || issues != 0 {
+		t.Fatalf("reindexed chain has %d issues err %v", issues, err)
+	}
+	if version, _ := chain.store.LoadUint64(BLOCKCHAIN_UNIVERSE, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY, OUTPUT_INDEX_VERSION_KEY); version != OUTPUT_INDEX_VERSION {
+		t.Fatalf("output index version %d not updated", version)
+	}
+}
@@ -33,10 +33,12 @@ import "compress/gzip"
 import "github.com/ybbus/jsonrpc"
 
 import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/walletapi"
 import "github.com/arnaucode/derosuite/blockchain/rpcserver"
 
-var Wallet_Height uint64 // height of wallet
-var Daemon_Height uint64 // height of daemon
+var Wallet_Height uint64      // height of wallet
+var Daemon_Height uint64      // height of daemon
+var Dynamic_fee_per_kb uint64 // fee per kb as reported by daemon
 var Connected bool = true
 
 var rpcClient *jsonrpc.RPCClient
@@ -70,29 +72,29 @@ func Run_Communication_Engine() {
 
 	// if user provided endpoint has error, use default
 	if endpoint == "" {
-		endpoint = "127.0.0.1:9999"
-		if !globals.IsMainnet() {
-			endpoint = "127.0.0.1:28091"
-		}
+		endpoint = fmt.Sprintf("127.0.0.1:%d", globals.Config.RPC_Default_Port)
 	}
 
 	globals.Logger.Debugf("Daemon endpoint %s", endpoint)
 
+	// decoys and tx status are fetched from the same daemon, using same login and TLS settings
 	// TODO enable socks support here
-	var netTransport = &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout: 5 * time.Second, // 5 second timeout
-		}).Dial,
-		TLSHandshakeTimeout: 5 * time.Second,
+	var login, fingerprint string
+	if globals.Arguments["--daemon-login"] != nil {
+		login = globals.Arguments["--daemon-login"].(string)
 	}
-
-	netClient = &http.Client{
-		Timeout:   time.Second * 10,
-		Transport: netTransport,
+	if globals.Arguments["--daemon-ssl-fingerprint"] != nil {
+		fingerprint = globals.Arguments["--daemon-ssl-fingerprint"].(string)
+	}
+	if err := walletapi.Setup_Daemon(endpoint, login, globals.Arguments["--daemon-ssl"].(bool), fingerprint); err != nil {
+		globals.Logger.Fatalf("Daemon connection could not be setup err %s", err)
 	}
 
+	netClient = walletapi.Daemon_Client(10 * time.Second)
+
 	// create client
-	rpcClient = jsonrpc.NewRPCClient("http://" + endpoint + "/json_rpc")
+	rpcClient = jsonrpc.NewRPCClient(walletapi.Daemon_URL("/json_rpc"))
+	rpcClient.SetHTTPClient(netClient)
 
 	for {
 		time.Sleep(1 * time.Second) // ping server every second
@@ -126,6 +128,11 @@ func Run_Communication_Engine() {
 			globals.Logger.Warnf("Mainnet/TestNet  is different between wallet/daemon.Please run daemon/wallet without --testnet")
 		}
 		Daemon_Height = info.Height
+		Dynamic_fee_per_kb = info.Dynamic_fee_per_kb
+
+		if released := account.Release_Stale_Spends(); released > 0 {
+			globals.Logger.Infof("%d outputs of txs which were never mined are available again", released)
+		}
 
 	}
 
@@ -142,7 +149,7 @@ func Get_Outputs(start uint64, stop uint64) {
 	defer output_lock.Unlock()
 	if Connected { // only execute query if we are connected
 
-		response, err := http.Get(fmt.Sprintf("http://%s/getoutputs.bin?start=%d", endpoint, start))
+		response, err := walletapi.Daemon_Client(0).Get(walletapi.Daemon_URL(fmt.Sprintf("/getoutputs.bin?start=%d", start)))
 		if err != nil {
 			globals.Logger.Warnf("Error while requesting outputs from daemon err %s", err)
 			// os.Exit(1)
@@ -83,7 +83,22 @@ func handle_easymenu_post_open_command(l *readline.Instance, line string) {
 
 	case "4": // display user keys to create view only wallet
 		display_viewwallet_key(l)
-	case "5", "6", "7":
+	case "5", "6": // transfer, exchanges require payment id
+		if !account.ViewOnly {
+			address_string := read_line_with_prompt(l, "Enter Destination Address: ")
+			amount_string := read_line_with_prompt(l, "Enter Amount (in DERO): ")
+			payment_id := ""
+			if command == "6" {
+				payment_id = strings.TrimSpace(read_line_with_prompt(l, "Enter Payment ID (64 or 16 hex chars): "))
+				if payment_id == "" {
+					globals.Logger.Warnf("Payment ID is mandatory while sending to exchanges")
+					break
+				}
+			}
+			handle_transfer(l, address_string, amount_string, payment_id)
+		}
+
+	case "7":
 		if !account.ViewOnly {
 			globals.Logger.Warnf("This command is NOT yet implemented")
 		}
@@ -49,7 +49,7 @@ var command_line string = `dero-wallet-cli
 DERO : A secure, private blockchain with smart-contracts
 
 Usage:
-  derod [--help] [--version] [--offline] [--offline_datafile=<file>] [--testnet] [--prompt] [--debug] [--daemon-address=<host:port>] [--restore-deterministic-wallet] [--electrum-seed=<recovery-seed>] [--socks-proxy=<socks_ip:port>]  
+  derod [--help] [--version] [--offline] [--offline_datafile=<file>] [--testnet] [--prompt] [--debug] [--daemon-address=<host:port>] [--daemon-login=<user:password>] [--daemon-ssl] [--daemon-ssl-fingerprint=<sha256>] [--restore-deterministic-wallet] [--electrum-seed=<recovery-seed>] [--socks-proxy=<socks_ip:port>]  
   derod -h | --help
   derod --version
 
@@ -66,6 +66,9 @@ Options:
   --password     Password to unlock the wallet
   --socks-proxy=<socks_ip:port>  Use a proxy to connect to Daemon.
   --daemon-address=<host:port>    Use daemon instance at <host>:<port>
+  --daemon-login=<user:password>  Login to daemon rpc, same as daemon --rpc-login.
+  --daemon-ssl   Connect to daemon rpc over TLS, needed if daemon runs with --rpc-ssl.
+  --daemon-ssl-fingerprint=<sha256>  Accept only daemon certificate with this SHA256 fingerprint, as logged by daemon, required for generated certificates.
 
   `
 var menu_mode bool = true                             // default display menu mode
@@ -262,43 +265,16 @@ func blockchain_data_consumer() {
 			}
 
 			sync_time = time.Now()
-			if account.Index_Global < output.Index_Global { // process tx if it has not been processed earlier
-
+			if account.Get_Index_Global() < output.Index_Global { // process tx if it has not been processed earlier
 				Wallet_Height = output.Height
-				account.Height = output.Height
-				if account.Is_Output_Ours(output.Tx_Public_Key, output.Index_within_tx, crypto.Key(output.InKey.Destination)) {
-					amount, _, result := account.Decode_RingCT_Output(output.Tx_Public_Key,
-						output.Index_within_tx,
-						crypto.Key(output.InKey.Mask),
-						output.ECDHTuple,
-						output.SigType)
-
-					if result == false {
-						globals.Logger.Warnf("Internal error occurred, amount cannot be spent")
-					}
-					globals.Logger.Infof(color_green+"Height %d transaction %s received %s DERO"+color_white, output.Height, output.TXID, globals.FormatMoney(amount))
-
-					// add tx to wallet
-					account.Add_Transaction_Record_Funds(&output)
-
-				}
 
-				// check this keyimage represents our funds
-				// if yes we have consumed that specific funds, mark them as such
-				amount_spent := uint64(0)
-				for i := range output.Key_Images {
-					amount_per_keyimage, result := account.Is_Our_Fund_Consumed(output.Key_Images[i])
-					if result {
-						amount_spent += amount_per_keyimage
-						account.Consume_Transaction_Record_Funds(&output, output.Key_Images[i]) // decrease fund from our wallet
-					}
+				amount_received, amount_spent := account.Process_Output(&output)
+				if amount_received > 0 {
+					globals.Logger.Infof(color_green+"Height %d transaction %s received %s DERO"+color_white, output.Height, output.TXID, globals.FormatMoney(amount_received))
 				}
-
 				if amount_spent > 0 {
 					globals.Logger.Infof(color_magenta+"Height %d transaction %s Spent %s DERO"+color_white, output.Height, output.TXID, globals.FormatMoney(amount_spent))
 				}
-
-				account.Index_Global = output.Index_Global
 			}
 
 		}
@@ -364,7 +340,7 @@ func update_prompt(l *readline.Instance) {
 
 		if time.Since(sync_time) > (2*time.Second) && Wallet_Height < Daemon_Height {
 			if !offline_mode { // if offline mode, never connect anywhere
-				go Get_Outputs(account.Index_Global, 0) // start sync
+				go Get_Outputs(account.Get_Index_Global(), 0) // start sync
 			}
 
 			sync_time = time.Now()
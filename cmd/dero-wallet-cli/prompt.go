@@ -71,6 +71,17 @@ func handle_prompt_command(l *readline.Instance, line string) {
 	case "walletviewkey":
 		display_viewwallet_key(l)
 
+	case "transfer": // transfer <address> <amount> [payment_id]
+		if len(line_parts) < 3 || len(line_parts) > 4 {
+			fmt.Fprintf(l.Stderr(), "usage: transfer <address> <amount> [payment_id]\n")
+			break
+		}
+		payment_id := ""
+		if len(line_parts) == 4 {
+			payment_id = line_parts[3]
+		}
+		handle_transfer(l, line_parts[1], line_parts[2], payment_id)
+
 	case "set": // set different settings
 	case "close": // close the account
 		account_valid = false
@@ -124,6 +135,7 @@ var completer = readline.NewPrefixCompleter(
 	readline.PcItem("rescan_spent"),
 	readline.PcItem("print_height"),
 	readline.PcItem("seed"),
+	readline.PcItem("transfer"),
 	readline.PcItem("menu"),
 	readline.PcItem("set",
 		readline.PcItem("priority",
@@ -156,6 +168,7 @@ func usage(w io.Writer) {
 	io.WriteString(w, "\t\033[1mseed\033[0m\tDisplay seed\n")
 	io.WriteString(w, "\t\033[1mset\033[0m\tSet various settings\n")
 	io.WriteString(w, "\t\033[1mstatus\033[0m\t\tShow genereal information\n")
+	io.WriteString(w, "\t\033[1mtransfer\033[0m\tTransfer DERO, transfer <address> <amount> [payment_id]\n")
 	io.WriteString(w, "\t\033[1mspendkey\033[0m\tView secret key\n")
 	io.WriteString(w, "\t\033[1mviewkey\033[0m\tView view key\n")
 	io.WriteString(w, "\t\033[1mwalletviewkey\033[0m\tWallet view key, used to create watchable view only wallet\n")
@@ -169,7 +182,7 @@ func usage(w io.Writer) {
 func display_seed(l *readline.Instance) {
 	if account_valid {
 		seed := account.GetSeed()
-		fmt.Fprintf(l.Stderr(), color_green+"PLEASE NOTE: the following 25 words can be used to recover access to your wallet. Please write them down and store them somewhere safe and secure. Please do not store them in your email or on file storage services outside of your immediate control."+color_white+"\n")
+		fmt.Fprint(l.Stderr(), color_green+"PLEASE NOTE: the following 25 words can be used to recover access to your wallet. Please write them down and store them somewhere safe and secure. Please do not store them in your email or on file storage services outside of your immediate control."+color_white+"\n")
 		fmt.Fprintf(os.Stderr, color_red+"%s"+color_white+"\n", seed)
 	}
 
@@ -0,0 +1,117 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+/* this file handles sending funds
+ * the transaction is created and signed locally and then relayed using the daemon
+ */
+import "fmt"
+import "strings"
+import "encoding/hex"
+
+import "github.com/chzyer/readline"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/globals"
+import deroaddress "github.com/arnaucode/derosuite/address" // address is already used as global
+import "github.com/arnaucode/derosuite/blockchain/rpcserver"
+
+// ring size used while creating transactions
+var default_mixin = uint64(5)
+
+// create, confirm and relay a transaction
+func handle_transfer(l *readline.Instance, address_string string, amount_string string, payment_id string) {
+	if !account_valid {
+		return
+	}
+	if account.ViewOnly {
+		globals.Logger.Warnf("View only wallet cannot send funds")
+		return
+	}
+	if offline_mode || !Connected {
+		globals.Logger.Warnf("Wallet is not connected to daemon, transfer is not possible")
+		return
+	}
+
+	addr, err := deroaddress.NewAddress(strings.TrimSpace(address_string))
+	if err != nil {
+		globals.Logger.Warnf("Invalid address \"%s\" err %s", address_string, err)
+		return
+	}
+	if addr.Network != globals.Config.Public_Address_Prefix && addr.Network != globals.Config.Public_Address_Prefix_Integrated {
+		globals.Logger.Warnf("Address \"%s\" belongs to different network", address_string)
+		return
+	}
+
+	amount, err := globals.ParseAmount(amount_string)
+	if err != nil {
+		globals.Logger.Warnf("%s", err)
+		return
+	}
+
+	fees_per_kb := Dynamic_fee_per_kb
+	if fees_per_kb == 0 { // daemon did not report fee, use base fee
+		fees_per_kb = config.DYNAMIC_FEE_PER_KB_BASE_FEE_V5
+	}
+
+	tx, serialized, change, err := account.Transfer([]deroaddress.Address{*addr}, []uint64{amount}, 0, strings.TrimSpace(payment_id), fees_per_kb, default_mixin)
+	if err != nil {
+		globals.Logger.Warnf("Transaction could not be created err %s", err)
+		return
+	}
+
+	fee := tx.RctSignature.Get_TX_Fee()
+	fmt.Fprintf(l.Stderr(), "Sending %s DERO to %s\n", globals.FormatMoney(amount), addr)
+	if payment_id != "" {
+		fmt.Fprintf(l.Stderr(), "Payment ID %s\n", payment_id)
+	} else if addr.IsIntegratedAddress() {
+		fmt.Fprintf(l.Stderr(), "Payment ID %x ( from integrated address )\n", addr.PaymentID)
+	}
+	fmt.Fprintf(l.Stderr(), "Fee %s DERO  Change %s DERO  TX size %d bytes\n", globals.FormatMoney(fee), globals.FormatMoney(change), len(serialized))
+
+	confirm := read_line_with_prompt(l, "Confirm Transaction (y/N) : ")
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		globals.Logger.Infof("Transaction cancelled")
+		return
+	}
+
+	result, err := send_raw_transaction(serialized)
+	if err != nil {
+		globals.Logger.Warnf("Transaction could not be relayed err %s", err)
+		return
+	}
+	if result.Status != "OK" {
+		globals.Logger.Warnf("Transaction rejected by daemon, reason: %s", result.Reason)
+		return
+	}
+	account.Mark_Outputs_Spent(tx) // inputs must not be selected again by next transfer
+	globals.Logger.Infof(color_green+"Transaction %s successfully relayed"+color_white, result.TXID)
+}
+
+// send the tx to daemon
+func send_raw_transaction(serialized []byte) (result rpcserver.SendRawTransaction_Result, err error) {
+	response, err := rpcClient.CallNamed("sendrawtransaction", map[string]interface{}{"tx_as_hex": hex.EncodeToString(serialized)})
+	if err != nil {
+		return
+	}
+	if response.Error != nil {
+		err = response.Error
+		return
+	}
+	err = response.GetObject(&result)
+	return
+}
@@ -0,0 +1,188 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+/* this file handles communication with the daemon
+ * the wallet is kept in sync by streaming outputs from the daemon
+ */
+import "io"
+import "fmt"
+import "net"
+import "time"
+import "net/http"
+import "sync/atomic"
+import "compress/gzip"
+import "encoding/hex"
+
+import "github.com/ybbus/jsonrpc"
+import "github.com/vmihailenco/msgpack"
+
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/walletapi"
+import "github.com/arnaucode/derosuite/blockchain/rpcserver"
+
+// these are updated by the communication engine and read by rpc calls, so use atomics
+var Daemon_Height uint64      // height of daemon
+var Dynamic_fee_per_kb uint64 // fee per kb as reported by daemon
+
+var rpcClient *jsonrpc.RPCClient
+var netClient *http.Client
+var endpoint string
+
+// setup the daemon endpoint, this must be done before any rpc call is served
+func setup_daemon_client() {
+	endpoint = fmt.Sprintf("127.0.0.1:%d", globals.Config.RPC_Default_Port)
+
+	// check if user specified  daemon address explicitly
+	if globals.Arguments["--daemon-address"] != nil {
+		daemon_address := globals.Arguments["--daemon-address"].(string)
+
+		remote_end, err := net.ResolveTCPAddr("tcp", daemon_address)
+		if err != nil {
+			logger.Warnf("Daemon address \"%s\" is invalid, using default. parse err %s", daemon_address, err)
+		} else {
+			if remote_end.IP == nil || remote_end.IP.IsUnspecified() { // user never provided an ipaddress, use loopback
+				remote_end.IP = net.IPv4(127, 0, 0, 1)
+			}
+			endpoint = remote_end.String()
+		}
+	}
+
+	logger.Infof("Daemon endpoint %s", endpoint)
+
+	// decoys and tx status are fetched from the same daemon, using same login and TLS settings
+	if err := walletapi.Setup_Daemon(endpoint, daemon_login(), globals.Arguments["--daemon-ssl"].(bool), daemon_fingerprint()); err != nil {
+		logger.Fatalf("Daemon connection could not be setup err %s", err)
+	}
+
+	netClient = walletapi.Daemon_Client(0) // outputs are streamed, so no overall timeout
+	rpcClient = jsonrpc.NewRPCClient(walletapi.Daemon_URL("/json_rpc"))
+	rpcClient.SetHTTPClient(walletapi.Daemon_Client(10 * time.Second))
+}
+
+func daemon_login() string {
+	if globals.Arguments["--daemon-login"] != nil {
+		return globals.Arguments["--daemon-login"].(string)
+	}
+	return ""
+}
+
+func daemon_fingerprint() string {
+	if globals.Arguments["--daemon-ssl-fingerprint"] != nil {
+		return globals.Arguments["--daemon-ssl-fingerprint"].(string)
+	}
+	return ""
+}
+
+// poll the daemon every second and sync the wallet whenever the daemon has new blocks
+func Run_Communication_Engine() {
+	connected := true
+	for !globals.Exit_In_Progress {
+		err := update_daemon_info()
+
+		// notify user of any state change
+		if err == nil && !connected {
+			logger.Infof("Connection to daemon successful")
+		} else if err != nil && connected {
+			logger.Warnf("Connection to daemon failed err %s", err)
+		}
+		connected = err == nil
+
+		if connected && account.Get_Height() < atomic.LoadUint64(&Daemon_Height) {
+			if err = sync_outputs(); err != nil {
+				logger.Warnf("Error while syncing wallet err %s", err)
+			}
+			save_state()
+		}
+		if connected {
+			if released := account.Release_Stale_Spends(); released > 0 {
+				logger.Infof("%d outputs of txs which were never mined are available again", released)
+				save_state()
+			}
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+}
+
+func update_daemon_info() error {
+	response, err := rpcClient.Call("get_info")
+	if err != nil {
+		return err
+	}
+
+	var info rpcserver.GetInfo_Result
+	if err = response.GetObject(&info); err != nil {
+		return err
+	}
+
+	if info.Testnet != !globals.IsMainnet() {
+		logger.Warnf("Mainnet/TestNet  is different between wallet/daemon.Please run daemon/wallet without --testnet")
+	}
+	atomic.StoreUint64(&Daemon_Height, info.Height)
+	atomic.StoreUint64(&Dynamic_fee_per_kb, info.Dynamic_fee_per_kb)
+	return nil
+}
+
+// stream all outputs after the ones already processed and play them on the wallet
+// this is the only place where the wallet gets updated from the chain
+func sync_outputs() error {
+	response, err := netClient.Get(walletapi.Daemon_URL(fmt.Sprintf("/getoutputs.bin?start=%d", account.Get_Index_Global())))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	gzipreader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		return err
+	}
+	defer gzipreader.Close()
+
+	decoder := msgpack.NewDecoder(gzipreader)
+	for !globals.Exit_In_Progress {
+		var output globals.TX_Output_Data
+		if err = decoder.Decode(&output); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		received, spent := account.Process_Output(&output)
+		if received > 0 {
+			logger.Infof("Height %d transaction %s received %s DERO", output.Height, output.TXID, globals.FormatMoney(received))
+		}
+		if spent > 0 {
+			logger.Infof("Height %d transaction %s spent %s DERO", output.Height, output.TXID, globals.FormatMoney(spent))
+		}
+	}
+	return nil
+}
+
+// send the tx to daemon
+func send_raw_transaction(serialized []byte) (result rpcserver.SendRawTransaction_Result, err error) {
+	response, err := rpcClient.CallNamed("sendrawtransaction", map[string]interface{}{"tx_as_hex": hex.EncodeToString(serialized)})
+	if err != nil {
+		return
+	}
+	if response.Error != nil {
+		err = response.Error
+		return
+	}
+	err = response.GetObject(&result)
+	return
+}
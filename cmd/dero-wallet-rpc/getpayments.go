@@ -0,0 +1,66 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import "context"
+import "encoding/hex"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+// incoming payments carrying the payment id, encrypted payment ids are already decrypted
+type (
+	GetPayments_Handler struct{}
+	GetPayments_Params  struct {
+		Payment_ID string `json:"payment_id"`
+	}
+	GetPayments_Result struct {
+		Payments []Payment_Details `json:"payments,omitempty"`
+	}
+
+	Payment_Details struct {
+		Payment_ID   string `json:"payment_id"`
+		TX_Hash      string `json:"tx_hash"`
+		Amount       uint64 `json:"amount"`
+		Block_Height uint64 `json:"block_height"`
+		Unlock_Time  uint64 `json:"unlock_time"`
+	}
+)
+
+func (h GetPayments_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p GetPayments_Params
+	var result GetPayments_Result
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	payment_id, err := hex.DecodeString(p.Payment_ID)
+	if err != nil || (len(payment_id) != 8 && len(payment_id) != 32) {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "payment_id must be 16 or 64 hex chars"}
+	}
+
+	for _, entry := range account.Get_Payments(payment_id) {
+		result.Payments = append(result.Payments, Payment_Details{
+			Payment_ID:   hex.EncodeToString(payment_id),
+			TX_Hash:      entry.TXID.String(),
+			Amount:       entry.Amount,
+			Block_Height: entry.Height,
+			Unlock_Time:  entry.Unlock_Height,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import "context"
+import "encoding/hex"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/walletapi"
+
+// outgoing amount is total spent including fee, since fee is not known to the wallet
+// like monero, min_height is exclusive and max_height inclusive
+type (
+	GetTransfers_Handler struct{}
+	GetTransfers_Params  struct {
+		In               bool   `json:"in"`
+		Out              bool   `json:"out"`
+		Filter_By_Height bool   `json:"filter_by_height"`
+		Min_Height       uint64 `json:"min_height"`
+		Max_Height       uint64 `json:"max_height"`
+	}
+	GetTransfers_Result struct {
+		In  []Transfer_Details `json:"in,omitempty"`
+		Out []Transfer_Details `json:"out,omitempty"`
+	}
+
+	Transfer_Details struct {
+		TXID        string `json:"txid"`
+		Payment_ID  string `json:"payment_id"`
+		Height      uint64 `json:"height"`
+		Timestamp   uint64 `json:"timestamp"`
+		Amount      uint64 `json:"amount"`
+		Type        string `json:"type"` // in or out
+		Unlock_Time uint64 `json:"unlock_time"`
+	}
+)
+
+func (h GetTransfers_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p GetTransfers_Params
+	var result GetTransfers_Result
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	min_height, max_height := uint64(0), uint64(0)
+	if p.Filter_By_Height {
+		if p.Max_Height != 0 && p.Max_Height <= p.Min_Height {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "max_height must be more than min_height"}
+		}
+		min_height, max_height = p.Min_Height+1, p.Max_Height
+	}
+
+	for _, entry := range account.Get_Transfers(p.In, p.Out, min_height, max_height) {
+		if entry.Incoming {
+			result.In = append(result.In, transfer_details(entry))
+		} else {
+			result.Out = append(result.Out, transfer_details(entry))
+		}
+	}
+	return result, nil
+}
+
+func transfer_details(entry walletapi.Transfer_Entry) (details Transfer_Details) {
+	details.TXID = entry.TXID.String()
+	details.Payment_ID = hex.EncodeToString(entry.Payment_ID)
+	details.Height = entry.Height
+	details.Timestamp = entry.Block_Time
+	details.Amount = entry.Amount
+	details.Type = "out"
+	if entry.Incoming {
+		details.Type = "in"
+		details.Unlock_Time = entry.Unlock_Height
+	}
+	return
+}
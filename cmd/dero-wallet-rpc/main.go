@@ -0,0 +1,178 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+/* this file implements the wallet rpc server, which serves a single wallet over json rpc
+ * so as exchanges, pools and merchants can use the wallet without the interactive cli
+ */
+import "io"
+import "os"
+import "fmt"
+import "bufio"
+import "strings"
+import "syscall"
+import "runtime"
+import "io/ioutil"
+import "os/signal"
+import "encoding/hex"
+
+import "github.com/docopt/docopt-go"
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/walletapi"
+
+var command_line string = `dero-wallet-rpc
+DERO : A secure, private blockchain with smart-contracts
+
+Usage:
+  dero-wallet-rpc [--help] [--version] [--testnet] [--debug] [--daemon-address=<host:port>] [--daemon-login=<user:password>] [--daemon-ssl] [--daemon-ssl-fingerprint=<sha256>] [--rpc-bind=<127.0.0.1:18092>] [--rpc-login=<user:password>] [--rpc-allow-no-login] [--rpc-ssl] [--rpc-ssl-certificate=<file>] [--rpc-ssl-private-key=<file>] [--electrum-seed-file=<file>] [--view-key=<key>] [--wallet-state=<file>] [--socks-proxy=<socks_ip:port>]
+  dero-wallet-rpc -h | --help
+  dero-wallet-rpc --version
+
+Options:
+  -h --help     Show this screen.
+  --version     Show version.
+  --testnet  	Run in testnet mode.
+  --debug       Debug mode enabled, print log messages
+  --daemon-address=<host:port>    Use daemon instance at <host>:<port>
+  --daemon-login=<user:password>  Login to daemon rpc, same as daemon --rpc-login.
+  --daemon-ssl   Connect to daemon rpc over TLS, needed if daemon runs with --rpc-ssl.
+  --daemon-ssl-fingerprint=<sha256>  Accept only daemon certificate with this SHA256 fingerprint, as logged by daemon, required for generated certificates.
+  --rpc-bind=<127.0.0.1:18092>  Wallet RPC server listens on this ip:port, default port is 18092 for mainnet and 28092 for testnet.
+  --rpc-login=<user:password>  RPC clients must authenticate using http basic auth with these credentials.
+  --rpc-allow-no-login  Allow RPC without login on non loopback address, anyone who can reach it can spend the funds.
+  --rpc-ssl                  Serve RPC over TLS, a self-signed certificate is generated unless one is provided.
+  --rpc-ssl-certificate=<file>  TLS certificate file in PEM format, used with --rpc-ssl.
+  --rpc-ssl-private-key=<file>  TLS private key file in PEM format, used with --rpc-ssl.
+  --electrum-seed-file=<file>   Open wallet using recovery seed (25 words) read from this file, use - to read it from stdin.
+                               Seed can also be provided in DERO_WALLET_SEED environment variable. Seed is never accepted as argument, since arguments are visible to all users.
+  --view-key=<key>   Open view only wallet using this view only key (hex 128 chars), such wallet cannot send funds.
+  --wallet-state=<file>  Wallet state ( outputs and pending spends, no keys ) is saved here, default is dero-wallet-rpc-<network>-<address prefix>.state in current dir.
+  --socks-proxy=<socks_ip:port>  Use a proxy to connect to Daemon.
+  `
+
+var account *walletapi.Account // all methods of account take care of locking, so it can be used from all rpc calls
+var logger *log.Entry
+
+var state_filename string // wallet state is saved here, see save_state
+
+func main() {
+	var err error
+	globals.Arguments, err = docopt.Parse(command_line, nil, true, "DERO wallet rpc : work in progress", false)
+	if err != nil {
+		log.Fatalf("Error while parsing options err: %s\n", err)
+	}
+
+	// parse arguments and setup testnet mainnet
+	globals.Initialize()     // setup network and proxy
+	globals.Logger.Infof("") // a dummy write is required to fully activate logrus
+	logger = globals.Logger.WithFields(log.Fields{"com": "WALLETRPC"})
+
+	globals.Logger.Debugf("Arguments %+v", globals.Arguments)
+	globals.Logger.Infof("DERO Wallet RPC :  This version is under heavy development, use it for testing/evaluations purpose only")
+	globals.Logger.Infof("Copyright 2017-2018 DERO Project. All rights reserved.")
+	globals.Logger.Infof("OS:%s ARCH:%s GOMAXPROCS:%d", runtime.GOOS, runtime.GOARCH, runtime.GOMAXPROCS(0))
+	globals.Logger.Infof("Wallet in %s mode", globals.Config.Name)
+
+	if account, err = open_account(); err != nil {
+		globals.Logger.Fatalf("Wallet could not be opened err %s", err)
+	}
+	globals.Logger.Infof("Wallet %s opened, view only %t", account.GetAddress(), account.ViewOnly)
+
+	state_filename = fmt.Sprintf("dero-wallet-rpc-%s-%s.state", globals.Config.Name, account.GetAddress().String()[:12])
+	if globals.Arguments["--wallet-state"] != nil {
+		state_filename = globals.Arguments["--wallet-state"].(string)
+	}
+	if err = account.Load_State(state_filename); err != nil {
+		globals.Logger.Fatalf("Wallet state could not be loaded, use --wallet-state to choose another file err %s", err)
+	}
+	globals.Logger.Infof("Wallet state %s, synced upto height %d", state_filename, account.Get_Height())
+
+	setup_daemon_client()
+	go Run_Communication_Engine()
+
+	server, err := RPCServer_Start()
+	if err != nil {
+		globals.Logger.Fatalf("Wallet RPC server could not be started err %s", err)
+	}
+
+	// run till we are asked to exit
+	exit := make(chan os.Signal, 1)
+	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
+	<-exit
+
+	globals.Logger.Infof("Exit in Progress, Please wait")
+	globals.Exit_In_Progress = true
+	server.RPCServer_Stop()
+	save_state()
+}
+
+// save wallet state, so restarts neither resync nor spend outputs of pending txs again
+func save_state() {
+	if err := account.Save_State(state_filename); err != nil {
+		logger.Warnf("Wallet state could not be saved to %s err %s", state_filename, err)
+	}
+}
+
+// keys are not stored anywhere, the wallet is opened from the seed or view key on every start
+// seed is read from a file, stdin or environment, so it does not show up in process list
+func open_account() (*walletapi.Account, error) {
+	if globals.Arguments["--electrum-seed-file"] != nil {
+		seed, err := read_seed(globals.Arguments["--electrum-seed-file"].(string))
+		if err != nil {
+			return nil, err
+		}
+		return walletapi.Generate_Account_From_Recovery_Words(seed)
+	}
+	if seed := os.Getenv("DERO_WALLET_SEED"); seed != "" {
+		return walletapi.Generate_Account_From_Recovery_Words(seed)
+	}
+
+	if globals.Arguments["--view-key"] != nil {
+		key := strings.TrimSpace(globals.Arguments["--view-key"].(string))
+		key_raw, err := hex.DecodeString(key)
+		if len(key) != 128 || err != nil {
+			return nil, fmt.Errorf("View Only key must be 128 chars hexadecimal chars")
+		}
+
+		var spendkey_public, viewkey_secret crypto.Key
+		copy(spendkey_public[:], key_raw[:32])
+		copy(viewkey_secret[:], key_raw[32:64])
+		return walletapi.Generate_Account_View_Only(spendkey_public, viewkey_secret)
+	}
+
+	return nil, fmt.Errorf("use --electrum-seed-file, DERO_WALLET_SEED or --view-key to open the wallet")
+}
+
+// read recovery seed from file, - reads the first line from stdin
+func read_seed(filename string) (string, error) {
+	if filename == "-" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("seed could not be read from stdin err %s", err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("seed could not be read err %s", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import "context"
+import "crypto/rand"
+import "encoding/hex"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/globals"
+
+// integrated address carries a 8 byte payment id, a random one is used if not provided
+type (
+	MakeIntegratedAddress_Handler struct{}
+	MakeIntegratedAddress_Params  struct {
+		Payment_ID string `json:"payment_id"`
+	}
+	MakeIntegratedAddress_Result struct {
+		Integrated_Address string `json:"integrated_address"`
+		Payment_ID         string `json:"payment_id"`
+	}
+)
+
+func (h MakeIntegratedAddress_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p MakeIntegratedAddress_Params
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	payment_id := make([]byte, address.INTEGRATED_PAYMENT_ID_LENGTH, address.INTEGRATED_PAYMENT_ID_LENGTH)
+	if p.Payment_ID == "" {
+		if _, err := rand.Read(payment_id); err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: err.Error()}
+		}
+	} else {
+		var err error
+		if payment_id, err = hex.DecodeString(p.Payment_ID); err != nil || len(payment_id) != address.INTEGRATED_PAYMENT_ID_LENGTH {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "payment_id must be 16 hex chars"}
+		}
+	}
+
+	addr := account.GetAddress()
+	addr.Network = globals.Config.Public_Address_Prefix_Integrated
+	addr.PaymentID = payment_id
+
+	return MakeIntegratedAddress_Result{Integrated_Address: addr.String(), Payment_ID: hex.EncodeToString(payment_id)}, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import "io"
+import "fmt"
+import "net"
+import "sync"
+import "net/http"
+import "crypto/tls"
+
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/globals"
+
+/* this file implements the wallet rpc server, method names are kept compatible with monero wallet rpc */
+
+type RPCServer struct {
+	Address    string // rpc server listens on this address
+	Login_User string // if set, clients must authenticate
+	Login_Pass string
+	TLS_Config *tls.Config // if set, rpc is served over TLS
+	server     *http.Server
+	sync.RWMutex
+}
+
+// transfers are created one at a time, so as concurrent calls do not select the same outputs
+var transfer_lock sync.Mutex
+
+func RPCServer_Start() (*RPCServer, error) {
+	var err error
+	var r RPCServer
+
+	// rpc server listens on loopback on network specific default port, unless user provided an address
+	r.Address = fmt.Sprintf("127.0.0.1:%d", globals.Config.Wallet_RPC_Default_Port)
+	if globals.Arguments["--rpc-bind"] != nil {
+		if r.Address, err = globals.ParseBindAddress(globals.Arguments["--rpc-bind"].(string)); err != nil {
+			return nil, err
+		}
+	}
+
+	if globals.Arguments["--rpc-login"] != nil {
+		if r.Login_User, r.Login_Pass, err = globals.Parse_RPC_Login(globals.Arguments["--rpc-login"].(string)); err != nil {
+			return nil, err
+		}
+	}
+
+	if globals.Arguments["--rpc-ssl"].(bool) {
+		cert_file, _ := globals.Arguments["--rpc-ssl-certificate"].(string)
+		key_file, _ := globals.Arguments["--rpc-ssl-private-key"].(string)
+		if r.TLS_Config, err = globals.RPC_TLS_Config(cert_file, key_file); err != nil {
+			return nil, err
+		}
+	}
+
+	// anyone who can reach the wallet rpc can spend the funds, so refuse to expose it without login
+	if !is_loopback_address(r.Address) {
+		if r.Login_User == "" {
+			if allow, _ := globals.Arguments["--rpc-allow-no-login"].(bool); !allow {
+				return nil, fmt.Errorf("Wallet RPC on %s is reachable from other hosts, use --rpc-login or --rpc-allow-no-login", r.Address)
+			}
+			logger.Warnf("Wallet RPC is exposed without login, use --rpc-login")
+		} else if r.TLS_Config == nil {
+			logger.Warnf("Wallet RPC login is sent in plain text, use --rpc-ssl when rpc is exposed")
+		}
+	}
+
+	go r.Run()
+	logger.Infof("Wallet RPC server started")
+	return &r, nil
+}
+
+// only loopback addresses are not reachable from other hosts
+func is_loopback_address(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	return net.ParseIP(host).IsLoopback()
+}
+
+// shutdown the rpc server
+func (r *RPCServer) RPCServer_Stop() {
+	r.RLock()
+	if r.server != nil {
+		r.server.Close()
+	}
+	r.RUnlock()
+	logger.Infof("Wallet RPC Shutdown")
+}
+
+func (r *RPCServer) Run() {
+	mr := jsonrpc.NewMethodRepository()
+
+	// newer monero wallets use the names with underscore, register both
+	if err := mr.RegisterMethod("getbalance", GetBalance_Handler{}, GetBalance_Params{}, GetBalance_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+	if err := mr.RegisterMethod("get_balance", GetBalance_Handler{}, GetBalance_Params{}, GetBalance_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("getaddress", GetAddress_Handler{}, GetAddress_Params{}, GetAddress_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+	if err := mr.RegisterMethod("get_address", GetAddress_Handler{}, GetAddress_Params{}, GetAddress_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("getheight", GetHeight_Handler{}, GetHeight_Params{}, GetHeight_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+	if err := mr.RegisterMethod("get_height", GetHeight_Handler{}, GetHeight_Params{}, GetHeight_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_transfers", GetTransfers_Handler{}, GetTransfers_Params{}, GetTransfers_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("get_payments", GetPayments_Handler{}, GetPayments_Params{}, GetPayments_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("make_integrated_address", MakeIntegratedAddress_Handler{}, MakeIntegratedAddress_Params{}, MakeIntegratedAddress_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("query_key", QueryKey_Handler{}, QueryKey_Params{}, QueryKey_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	if err := mr.RegisterMethod("transfer", Transfer_Handler{}, Transfer_Params{}, Transfer_Result{}); err != nil {
+		logger.Fatalln(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", hello)
+	mux.Handle("/json_rpc", mr)
+
+	var handler http.Handler = mux
+	if r.Login_User != "" {
+		handler = globals.RPC_Auth_Handler("dero-wallet-rpc", r.Login_User, r.Login_Pass, handler)
+	}
+
+	r.Lock()
+	r.server = &http.Server{Addr: r.Address, Handler: handler, TLSConfig: r.TLS_Config}
+	r.Unlock()
+
+	logger.Infof("Wallet RPC server listening on %s TLS %t", r.Address, r.TLS_Config != nil)
+
+	var err error
+	if r.TLS_Config != nil {
+		err = r.server.ListenAndServeTLS("", "") // certificate is already in config
+	} else {
+		err = r.server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		logger.Fatalln(err)
+	}
+}
+
+func hello(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, "DERO Wallet RPC")
+}
@@ -0,0 +1,131 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+/* this file handles sending funds
+ * the transaction is created and signed locally and then relayed using the daemon
+ */
+import "fmt"
+import "context"
+import "strings"
+import "sync/atomic"
+import "encoding/hex"
+
+import "github.com/intel-go/fastjson"
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/globals"
+
+// ring size used while creating transactions, if caller does not provide one
+const DEFAULT_MIXIN = 5
+
+// amount in result is the total sent to destinations, tx_blob is returned if requested or tx is not relayed
+type (
+	Transfer_Handler     struct{}
+	Transfer_Destination struct {
+		Amount  uint64 `json:"amount"`
+		Address string `json:"address"`
+	}
+	Transfer_Params struct {
+		Destinations []Transfer_Destination `json:"destinations"`
+		Mixin        uint64                 `json:"mixin"`
+		Unlock_Time  uint64                 `json:"unlock_time"`
+		Payment_ID   string                 `json:"payment_id"`
+		Get_Tx_Hex   bool                   `json:"get_tx_hex"`
+		Do_Not_Relay bool                   `json:"do_not_relay"`
+	}
+	Transfer_Result struct {
+		Fee     uint64 `json:"fee"`
+		Tx_Hash string `json:"tx_hash"`
+		Amount  uint64 `json:"amount"`
+		Tx_Blob string `json:"tx_blob,omitempty"`
+	}
+)
+
+func (h Transfer_Handler) ServeJSONRPC(c context.Context, params *fastjson.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p Transfer_Params
+	var result Transfer_Result
+	if err := jsonrpc.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if account.ViewOnly {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "View only wallet cannot send funds"}
+	}
+	if len(p.Destinations) < 1 {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "atleast 1 destination is required"}
+	}
+
+	var addrs []address.Address
+	var amounts []uint64
+	for _, destination := range p.Destinations {
+		addr, err := address.NewAddress(strings.TrimSpace(destination.Address))
+		if err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Invalid address \"%s\" err %s", destination.Address, err)}
+		}
+		if addr.Network != globals.Config.Public_Address_Prefix && addr.Network != globals.Config.Public_Address_Prefix_Integrated {
+			return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Address \"%s\" belongs to different network", destination.Address)}
+		}
+		addrs = append(addrs, *addr)
+		amounts = append(amounts, destination.Amount)
+		result.Amount += destination.Amount
+	}
+
+	mixin := p.Mixin
+	if mixin == 0 {
+		mixin = DEFAULT_MIXIN
+	}
+
+	fees_per_kb := atomic.LoadUint64(&Dynamic_fee_per_kb)
+	if fees_per_kb == 0 { // daemon did not report fee, use base fee
+		fees_per_kb = config.DYNAMIC_FEE_PER_KB_BASE_FEE_V5
+	}
+
+	// hold the lock till the inputs are marked spent, otherwise parallel transfer will double spend them
+	transfer_lock.Lock()
+	defer transfer_lock.Unlock()
+
+	tx, serialized, _, err := account.Transfer(addrs, amounts, p.Unlock_Time, strings.TrimSpace(p.Payment_ID), fees_per_kb, mixin)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: fmt.Sprintf("Transaction could not be created err %s", err)}
+	}
+
+	result.Fee = tx.RctSignature.Get_TX_Fee()
+	result.Tx_Hash = tx.GetHash().String()
+	if p.Get_Tx_Hex || p.Do_Not_Relay {
+		result.Tx_Blob = hex.EncodeToString(serialized)
+	}
+
+	if p.Do_Not_Relay {
+		return result, nil
+	}
+
+	relayed, err := send_raw_transaction(serialized)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: fmt.Sprintf("Transaction could not be relayed err %s", err)}
+	}
+	if relayed.Status != "OK" {
+		return nil, &jsonrpc.Error{Code: jsonrpc.ErrorCodeInternal, Message: fmt.Sprintf("Transaction rejected by daemon, reason: %s", relayed.Reason)}
+	}
+
+	account.Mark_Outputs_Spent(tx)
+	save_state() // spent marks must survive a crash
+	logger.Infof("Transaction %s relayed, sent %s DERO fee %s DERO", result.Tx_Hash, globals.FormatMoney(result.Amount), globals.FormatMoney(result.Fee))
+	return result, nil
+}
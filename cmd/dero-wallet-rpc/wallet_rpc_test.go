@@ -0,0 +1,138 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import "os"
+import "strings"
+import "testing"
+import "net/http"
+import "encoding/json"
+import "net/http/httptest"
+import "io/ioutil"
+import "path/filepath"
+
+import "github.com/osamingo/jsonrpc"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/walletapi"
+
+// call a method on the handler and decode the result
+func test_call(t *testing.T, handler http.Handler, user, password, method, params string, result interface{}) (status int, rpc_error *jsonrpc.Error) {
+	req := httptest.NewRequest("POST", "/json_rpc", strings.NewReader(`{"jsonrpc":"2.0","id":"1","method":"`+method+`","params":`+params+`}`))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		return rw.Code, nil
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *jsonrpc.Error  `json:"error"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s response could not be parsed err %s", method, err)
+	}
+	if response.Error == nil && result != nil {
+		json.Unmarshal(response.Result, result)
+	}
+	return rw.Code, response.Error
+}
+
+func Test_Wallet_RPC(t *testing.T) {
+	globals.Config = config.Mainnet
+	account, _ = walletapi.Generate_Keys_From_Random()
+
+	mr := jsonrpc.NewMethodRepository()
+	mr.RegisterMethod("getaddress", GetAddress_Handler{}, GetAddress_Params{}, GetAddress_Result{})
+	mr.RegisterMethod("make_integrated_address", MakeIntegratedAddress_Handler{}, MakeIntegratedAddress_Params{}, MakeIntegratedAddress_Result{})
+	mr.RegisterMethod("get_payments", GetPayments_Handler{}, GetPayments_Params{}, GetPayments_Result{})
+	mr.RegisterMethod("query_key", QueryKey_Handler{}, QueryKey_Params{}, QueryKey_Result{})
+	handler := globals.RPC_Auth_Handler("dero-wallet-rpc", "user", "pass", mr)
+
+	if status, _ := test_call(t, handler, "", "", "getaddress", "{}", nil); status != http.StatusUnauthorized {
+		t.Fatalf("call without login returned %d", status)
+	}
+	if status, _ := test_call(t, handler, "user", "wrong", "getaddress", "{}", nil); status != http.StatusUnauthorized {
+		t.Fatalf("call with wrong login returned %d", status)
+	}
+
+	var addr GetAddress_Result
+	if _, rerr := test_call(t, handler, "user", "pass", "getaddress", "{}", &addr); rerr != nil || addr.Address != account.GetAddress().String() {
+		t.Fatalf("getaddress failed %+v %+v", addr, rerr)
+	}
+
+	var integrated MakeIntegratedAddress_Result
+	if _, rerr := test_call(t, handler, "user", "pass", "make_integrated_address", `{"payment_id":"0123456789abcdef"}`, &integrated); rerr != nil {
+		t.Fatalf("make_integrated_address failed %+v", rerr)
+	}
+	parsed, err := address.NewAddress(integrated.Integrated_Address)
+	if err != nil || parsed.Network != config.Mainnet.Public_Address_Prefix_Integrated || integrated.Payment_ID != "0123456789abcdef" {
+		t.Fatalf("integrated address is invalid %+v err %s", integrated, err)
+	}
+	if _, rerr := test_call(t, handler, "user", "pass", "make_integrated_address", `{"payment_id":"1234"}`, nil); rerr == nil {
+		t.Fatalf("make_integrated_address accepted bad payment id")
+	}
+
+	if _, rerr := test_call(t, handler, "user", "pass", "get_payments", `{"payment_id":"xyz"}`, nil); rerr == nil {
+		t.Fatalf("get_payments accepted bad payment id")
+	}
+
+	var key QueryKey_Result
+	if _, rerr := test_call(t, handler, "user", "pass", "query_key", `{"key_type":"view_key"}`, &key); rerr != nil || key.Key != account.Keys.Viewkey_Secret.String() {
+		t.Fatalf("query_key failed %+v", rerr)
+	}
+	account.ViewOnly = true
+	if _, rerr := test_call(t, handler, "user", "pass", "query_key", `{"key_type":"spend_key"}`, nil); rerr == nil {
+		t.Fatalf("view only wallet returned spend key")
+	}
+}
+
+// seed is read from file, surrounding whitespace is ignored
+func Test_Read_Seed(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "dero_test_wallet_seed")
+	defer os.Remove(filename)
+	ioutil.WriteFile(filename, []byte("  some seed words\n"), 0600)
+
+	if seed, err := read_seed(filename); err != nil || seed != "some seed words" {
+		t.Fatalf("seed not read from file %q err %v", seed, err)
+	}
+	if _, err := read_seed(filename + ".missing"); err == nil {
+		t.Fatalf("missing seed file should fail")
+	}
+}
+
+func Test_RPC_Exposed_Without_Login(t *testing.T) {
+	for address, expected := range map[string]bool{"127.0.0.1:18092": true, "[::1]:18092": true, "localhost:18092": true, "0.0.0.0:18092": false, "192.168.1.1:18092": false, "example.com:18092": false} {
+		if is_loopback_address(address) != expected {
+			t.Fatalf("loopback check failed for %s expected %v", address, expected)
+		}
+	}
+
+	arguments := globals.Arguments
+	defer func() { globals.Arguments = arguments }()
+	globals.Arguments = map[string]interface{}{"--rpc-bind": "0.0.0.0:18092", "--rpc-ssl": false, "--rpc-allow-no-login": false}
+
+	if _, err := RPCServer_Start(); err == nil {
+		t.Fatalf("wallet rpc must not start on non loopback address without login")
+	}
+}
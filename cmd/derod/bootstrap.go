@@ -0,0 +1,76 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import "os"
+import "fmt"
+import "syscall"
+import "os/signal"
+
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/blockchain"
+
+// handles export-blockchain and import-blockchain sub-commands
+func run_bootstrap_command(chain *blockchain.Blockchain) error {
+	filename := globals.Arguments["<file>"].(string)
+
+	if globals.Arguments["export-blockchain"].(bool) {
+		file, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("Cannot create bootstrap file err %s", err)
+		}
+		count, err := chain.Export_Blockchain(file)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("Export failed after %d blocks err %s", count, err)
+		}
+		globals.Logger.Infof("Exported %d blocks to %s", count, filename)
+		return nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("Cannot open bootstrap file err %s", err)
+	}
+	defer file.Close()
+
+	// import stops at the next block on ctrl+c, so the database is left consistent and import can be resumed
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(interrupt)
+		close(interrupt)
+	}()
+	go func() {
+		if _, ok := <-interrupt; ok {
+			globals.Logger.Infof("Exit in Progress, stopping import at next block")
+			globals.Exit_In_Progress = true
+		}
+	}()
+
+	trust := globals.Arguments["--trust-checkpoints"].(bool)
+	if trust {
+		globals.Logger.Warnf("Blocks upto the last checkpoint will not be verified")
+	}
+	count, err := chain.Import_Blockchain(file, trust)
+	if err != nil {
+		return fmt.Errorf("Import failed after %d blocks, run import again to resume err %s", count, err)
+	}
+	return nil
+}
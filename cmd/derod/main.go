@@ -34,6 +34,8 @@ import "github.com/docopt/docopt-go"
 import log "github.com/sirupsen/logrus"
 
 import "github.com/arnaucode/derosuite/p2p"
+import "github.com/arnaucode/derosuite/p2pv2"
+import "github.com/arnaucode/derosuite/stratum"
 import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/blockchain"
 
@@ -42,13 +44,16 @@ import "github.com/arnaucode/derosuite/crypto"
 import "github.com/arnaucode/derosuite/crypto/ringct"
 import "github.com/arnaucode/derosuite/blockchain/rpcserver"
 
-//import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/address"
 
 var command_line string = `derod
 DERO : A secure, private blockchain with smart-contracts
 
 Usage:
-  derod [--help] [--version] [--testnet] [--debug] [--disable-checkpoints] [--socks-proxy=<socks_ip:port>]  [--p2p-bind-port=<18090>] [--add-exclusive-node=<ip:port>]...
+  derod [--help] [--version] [--testnet] [--debug] [--disable-checkpoints] [--socks-proxy=<socks_ip:port>]  [--p2p-bind=<0.0.0.0:18090>] [--rpc-bind=<127.0.0.1:18091>] [--p2p-bind-port=<18090>] [--tx-rebroadcast=<600>] [--mempool-size=<bytes>] [--mempool-ttl=<259200>] [--stratum-bind=<ip:port>] [--add-exclusive-node=<ip:port>]... [--add-priority-node=<ip:port>]... [--ban-time=<86400>] [--prune] [--prune-depth=<10000>] [--rpc-login=<user:password>] [--rpc-ssl] [--rpc-ssl-certificate=<file>] [--rpc-ssl-private-key=<file>] [--restricted-rpc] [--rpc-rate-limit=<requests>]
+  derod export-blockchain <file> [--testnet] [--debug]
+  derod import-blockchain <file> [--testnet] [--debug] [--trust-checkpoints] [--prune] [--prune-depth=<10000>]
+  derod verify-db [--testnet] [--debug] [--repair]
   derod -h | --help
   derod --version
 
@@ -59,8 +64,26 @@ Options:
   --debug       Debug mode enabled, print log messages
   --disable-checkpoints  Disable checkpoints, work in truly async, slow mode 1 block at a time
   --socks-proxy=<socks_ip:port>  Use a proxy to connect to network.
-  --p2p-bind-port=<18090>    p2p server listens on this port.
-  --add-exclusive-node=<ip:port>	Connect to this peer only (disabled for this version)`
+  --p2p-bind=<0.0.0.0:18090>    p2p server listens on this ip:port, default port is 18090 for mainnet and 28090 for testnet.
+  --rpc-bind=<127.0.0.1:18091>  RPC server listens on this ip:port, default port is 18091 for mainnet and 28091 for testnet.
+  --p2p-bind-port=<18090>    p2p server listens on this port ( deprecated, use --p2p-bind ).
+  --tx-rebroadcast=<600>     Rebroadcast unconfirmed mempool transactions after this many seconds.
+  --mempool-size=<bytes>     Maximum mempool size in bytes, lowest fee transactions are evicted when full.
+  --mempool-ttl=<259200>     Drop unconfirmed mempool transactions older than this many seconds.
+  --stratum-bind=<ip:port>   Start stratum server on this address, so pool style miners can solo mine.
+  --add-exclusive-node=<ip:port>	Connect to these peers only, no other peers are connected or discovered.
+  --add-priority-node=<ip:port>	Always keep connections to these peers, in addition to discovered peers.
+  --ban-time=<86400>         Misbehaving peers are banned for this many seconds.
+  --prune                    Discard prunable ringct data of old blocks to save disk space, pruned database cannot be unpruned.
+  --prune-depth=<10000>      In pruning mode, blocks deeper than this are pruned, minimum 1000.
+  --rpc-login=<user:password>  RPC clients must authenticate using http basic auth with these credentials.
+  --rpc-ssl                  Serve RPC over TLS, a self-signed certificate is generated unless one is provided.
+  --rpc-ssl-certificate=<file>  TLS certificate file in PEM format, used with --rpc-ssl.
+  --rpc-ssl-private-key=<file>  TLS private key file in PEM format, used with --rpc-ssl.
+  --restricted-rpc           Hide admin/mining RPC methods and cap request sizes, use this for public nodes.
+  --rpc-rate-limit=<requests>  Max RPC requests per second per ip, default unlimited ( 50 in restricted mode ).
+  --trust-checkpoints        While importing, skip verification of blocks upto the last checkpoint, use only with trusted files.
+  --repair                   While verifying database, rewrite derived data which is wrong or missing.`
 
 func main() {
 	var err error
@@ -107,12 +130,74 @@ func main() {
 	params := map[string]interface{}{}
 
 	params["--disable-checkpoints"] = globals.Arguments["--disable-checkpoints"].(bool)
+	params["--prune"] = globals.Arguments["--prune"].(bool)
+	if globals.Arguments["--prune-depth"] != nil {
+		depth, err := strconv.ParseUint(globals.Arguments["--prune-depth"].(string), 10, 64)
+		if err != nil {
+			globals.Logger.Fatalf("Invalid --prune-depth value err %s", err)
+		}
+		params["--prune-depth"] = depth
+	}
 	chain, _ := blockchain.Blockchain_Start(params)
 
+	// bootstrap sub-commands work offline and exit when done
+	if globals.Arguments["export-blockchain"].(bool) || globals.Arguments["import-blockchain"].(bool) {
+		err := run_bootstrap_command(chain)
+		chain.Shutdown()
+		if err != nil {
+			globals.Logger.Fatalf("%s", err)
+		}
+		return
+	}
+
+	if globals.Arguments["verify-db"].(bool) { // check database and exit
+		issues, err := chain.Verify_DB(globals.Arguments["--repair"].(bool))
+		chain.Shutdown()
+		if err != nil {
+			globals.Logger.Fatalf("%s", err)
+		}
+		if issues > 0 && !globals.Arguments["--repair"].(bool) {
+			globals.Logger.Warnf("Database has %d issues, run verify-db --repair to rebuild derived data", issues)
+		}
+		return
+	}
+
 	params["chain"] = chain
 	p2p.P2P_Init(params)
+	if err := p2pv2.P2P_Init(params); err != nil { // levin continues to work without v2
+		globals.Logger.Warnf("Could not start P2P v2 err %s", err)
+	}
 
-	rpc, _ := rpcserver.RPCServer_Start(params)
+	if globals.Arguments["--rpc-bind"] != nil { // rpc server uses network specific default, if not provided
+		params["--rpc-bind"] = globals.Arguments["--rpc-bind"].(string)
+	}
+	if globals.Arguments["--rpc-login"] != nil {
+		params["--rpc-login"] = globals.Arguments["--rpc-login"].(string)
+	}
+	params["--rpc-ssl"] = globals.Arguments["--rpc-ssl"].(bool)
+	if globals.Arguments["--rpc-ssl-certificate"] != nil {
+		params["--rpc-ssl-certificate"] = globals.Arguments["--rpc-ssl-certificate"].(string)
+	}
+	if globals.Arguments["--rpc-ssl-private-key"] != nil {
+		params["--rpc-ssl-private-key"] = globals.Arguments["--rpc-ssl-private-key"].(string)
+	}
+	params["--restricted-rpc"] = globals.Arguments["--restricted-rpc"].(bool)
+	if globals.Arguments["--rpc-rate-limit"] != nil {
+		rate_limit, err := strconv.ParseUint(globals.Arguments["--rpc-rate-limit"].(string), 10, 64)
+		if err != nil {
+			globals.Logger.Fatalf("Invalid --rpc-rate-limit value err %s", err)
+		}
+		params["--rpc-rate-limit"] = rate_limit
+	}
+	rpc, err := rpcserver.RPCServer_Start(params)
+	if err != nil {
+		globals.Logger.Fatalf("Could not start RPC server err %s", err)
+	}
+
+	if globals.Arguments["--stratum-bind"] != nil { // stratum is optional
+		params["--stratum-bind"] = globals.Arguments["--stratum-bind"].(string)
+		stratum.Stratum_Start(params)
+	}
 
 	// This tiny goroutine continuously updates status as required
 	go func() {
@@ -126,7 +211,10 @@ func main() {
 			}
 			our_height := chain.Get_Height()
 			best_height := p2p.Best_Peer_Height()
-			peer_count := p2p.Peer_Count()
+			if v2_height := p2pv2.Best_Peer_Height(); v2_height > best_height {
+				best_height = v2_height
+			}
+			peer_count := p2p.Peer_Count() + p2pv2.Peer_Count()
 			mempool_tx_count := len(chain.Mempool.Mempool_List_TX())
 
 			// only update prompt if needed
@@ -376,8 +464,75 @@ func main() {
 			supply := chain.Load_Already_Generated_Coins_for_BL_ID(chain.Get_Top_ID())
 			supply -= (2000000 * 1000000000000) // remove premine
 			fmt.Printf("Network %s Height %d NW Hashrate %0.03f MH/sec TH %s Peers %d inc, %d out MEMPOOL size %d Total Circulating Supply %s DERO \n", globals.Config.Name, chain.Get_Height(), float64(chain.Get_Network_HashRate())/1000000.0, chain.Get_Top_ID(), inc, out, len(chain.Mempool.Mempool_List_TX()), globals.FormatMoney(supply))
+			if inc, out := p2pv2.Peer_Direction_Count(); inc+out > 0 {
+				fmt.Printf("P2P v2 Peers %d inc, %d out\n", inc, out)
+			}
+			if chain.Is_Pruned() {
+				fmt.Printf("Pruned database, blocks below height %d are pruned\n", chain.Load_Pruned_Height())
+			}
+			if count := chain.Orphan_Count(); count > 0 {
+				fmt.Printf("Orphan blocks waiting for parent %d\n", count)
+			}
+			if count := stratum.Miner_Count(); count > 0 {
+				fmt.Printf("Stratum miners connected %d\n", count)
+			}
+			if running, threads, hash_rate, blocks := miner_status(); running {
+				fmt.Printf("Mining with %d threads, Hashrate %d H/sec, Blocks found %d\n", threads, hash_rate, blocks)
+			}
+		case command == "start_mining": // start_mining <address> [threads]
+			if len(line_parts) < 2 || len(line_parts) > 3 {
+				fmt.Printf("start_mining needs address and optionally number of threads, start_mining <address> <threads>\n")
+				continue
+			}
+			miner_address, err := address.NewAddress(line_parts[1])
+			if err != nil {
+				fmt.Printf("Invalid address err %s\n", err)
+				continue
+			}
+			if miner_address.Network != globals.Config.Public_Address_Prefix {
+				fmt.Printf("Address belongs to different network\n")
+				continue
+			}
+			threads := runtime.GOMAXPROCS(0)
+			if len(line_parts) == 3 {
+				if threads, err = strconv.Atoi(line_parts[2]); err != nil || threads < 1 {
+					fmt.Printf("Invalid number of threads\n")
+					continue
+				}
+			}
+			start_miner(chain, *miner_address, threads)
+		case command == "stop_mining":
+			stop_miner()
 		case strings.ToLower(line) == "sync_info":
 			p2p.Connection_Print()
+			p2pv2.Connection_Print()
+		case command == "ban": // ban <ip> [seconds]
+			if len(line_parts) < 2 || len(line_parts) > 3 {
+				fmt.Printf("ban needs ip and optionally seconds, ban <ip> <seconds>\n")
+				continue
+			}
+			duration := p2p.Ban_Duration
+			if len(line_parts) == 3 {
+				seconds, err := strconv.ParseUint(line_parts[2], 10, 64)
+				if err != nil || seconds == 0 {
+					fmt.Printf("Invalid number of seconds\n")
+					continue
+				}
+				duration = time.Duration(seconds) * time.Second
+			}
+			if err := p2p.Ban_Address(line_parts[1], duration); err != nil {
+				fmt.Printf("Ban failed err %s\n", err)
+			}
+		case command == "unban": // unban <ip>
+			if len(line_parts) != 2 {
+				fmt.Printf("unban needs a single ip as argument\n")
+				continue
+			}
+			if err := p2p.Unban_Address(line_parts[1]); err != nil {
+				fmt.Printf("Unban failed err %s\n", err)
+			}
+		case command == "bans":
+			p2p.Ban_Print()
 		case strings.ToLower(line) == "bye":
 			fallthrough
 		case strings.ToLower(line) == "exit":
@@ -430,12 +585,15 @@ func main() {
 exit:
 
 	globals.Logger.Infof("Exit in Progress, Please wait")
+	stop_miner()                       // miner must stop before chain shuts down
 	time.Sleep(100 * time.Millisecond) // give prompt update time to finish
 
 	rpc.RPCServer_Stop()
+	stratum.Stratum_Shutdown()
 
-	p2p.P2P_Shutdown() // shutdown p2p subsystem
-	chain.Shutdown()   // shutdown chain subsysem
+	p2pv2.P2P_Shutdown() // v2 must stop first, as levin hands connections to it
+	p2p.P2P_Shutdown()   // shutdown p2p subsystem
+	chain.Shutdown()     // shutdown chain subsysem
 
 	for globals.Subsystem_Active > 0 {
 		time.Sleep(100 * time.Millisecond)
@@ -453,13 +611,18 @@ func usage(w io.Writer) {
 	io.WriteString(w, "commands:\n")
 	//io.WriteString(w, completer.Tree("    "))
 	io.WriteString(w, "\t\033[1mhelp\033[0m\t\tthis help\n")
+	io.WriteString(w, "\t\033[1mban\033[0m\t\tBan a peer ip, ban <ip> <seconds>\n")
+	io.WriteString(w, "\t\033[1mbans\033[0m\t\tPrint banned peer ips\n")
 	io.WriteString(w, "\t\033[1mdiff\033[0m\t\tShow difficulty\n")
 	io.WriteString(w, "\t\033[1mprint_bc\033[0m\tPrint blockchain info in a given blocks range, print_bc <begin_height> <end_height>\n")
 	io.WriteString(w, "\t\033[1mprint_block\033[0m\tPrint block, print_block <block_hash> or <block_height>\n")
 	io.WriteString(w, "\t\033[1mprint_height\033[0m\tPrint local blockchain height\n")
 	io.WriteString(w, "\t\033[1mprint_tx\033[0m\tPrint transaction, print_tx <transaction_hash>\n")
+	io.WriteString(w, "\t\033[1mstart_mining\033[0m\tStart mining, start_mining <address> <threads>\n")
 	io.WriteString(w, "\t\033[1mstatus\033[0m\t\tShow genereal information\n")
+	io.WriteString(w, "\t\033[1mstop_mining\033[0m\tStop mining\n")
 	io.WriteString(w, "\t\033[1msync_info\033[0m\tPrint information about connected peers and their state\n")
+	io.WriteString(w, "\t\033[1munban\033[0m\t\tRemove ban of a peer ip, unban <ip>\n")
 	io.WriteString(w, "\t\033[1mbye\033[0m\t\tQuit the daemon\n")
 	io.WriteString(w, "\t\033[1mexit\033[0m\t\tQuit the daemon\n")
 	io.WriteString(w, "\t\033[1mquit\033[0m\t\tQuit the daemon\n")
@@ -492,13 +655,18 @@ var completer = readline.NewPrefixCompleter(
 		),
 		readline.PcItem("sleep"),
 	*/
+	readline.PcItem("ban"),
+	readline.PcItem("bans"),
 	readline.PcItem("diff"),
 	readline.PcItem("print_bc"),
 	readline.PcItem("print_block"),
 	readline.PcItem("print_height"),
 	readline.PcItem("print_tx"),
+	readline.PcItem("start_mining"),
 	readline.PcItem("status"),
+	readline.PcItem("stop_mining"),
 	readline.PcItem("sync_info"),
+	readline.PcItem("unban"),
 	readline.PcItem("bye"),
 	readline.PcItem("exit"),
 	readline.PcItem("quit"),
@@ -0,0 +1,216 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+// this file implements a simple cpu miner, useful for private testnets and regtests
+
+import "time"
+import "sync"
+import "runtime"
+import "sync/atomic"
+import "encoding/binary"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/difficulty"
+import "github.com/arnaucode/derosuite/cryptonight"
+
+// a job is refreshed if it becomes older than this, so as new txs from pool get mined
+const MINER_JOB_REFRESH = 10 * time.Second
+
+// each job is a block template, workers iterate nonces over it
+type miner_job struct {
+	id         uint64
+	bl         block.Block
+	difficulty uint64
+}
+
+var miner_mutex sync.Mutex // protects start and stop
+var miner_exit chan bool   // closed to stop all miner goroutines
+var miner_wg sync.WaitGroup
+var miner_running bool
+var miner_threads int
+
+var miner_current_job atomic.Value // always holds *miner_job
+var miner_hash_count uint64        // total hashes done, atomic
+var miner_hash_rate uint64         // hashes per second, atomic
+var miner_blocks_found uint64      // blocks accepted by chain, atomic
+
+// start mining to address with specified number of threads
+// if the miner is already running, it is restarted
+func start_miner(chain *blockchain.Blockchain, miner_address address.Address, threads int) {
+	stop_miner()
+
+	if threads <= 0 || threads > runtime.GOMAXPROCS(0) {
+		threads = runtime.GOMAXPROCS(0)
+	}
+
+	miner_mutex.Lock()
+	defer miner_mutex.Unlock()
+
+	miner_exit = make(chan bool)
+	miner_running = true
+	miner_threads = threads
+	atomic.StoreUint64(&miner_hash_rate, 0)
+
+	// create first job, before starting workers
+	if !miner_refresh_job(chain, miner_address) {
+		globals.Logger.Warnf("Miner could not create first job")
+	}
+
+	miner_wg.Add(1 + threads)
+	go miner_job_loop(chain, miner_address, miner_exit)
+	for i := 0; i < threads; i++ {
+		go miner_worker(chain, uint32(i), uint32(threads), miner_exit)
+	}
+
+	globals.Logger.Infof("Mining started to %s with %d threads", miner_address.String(), threads)
+}
+
+// stop the miner and wait for all goroutines to finish
+func stop_miner() {
+	miner_mutex.Lock()
+	defer miner_mutex.Unlock()
+
+	if !miner_running {
+		return
+	}
+	close(miner_exit)
+	miner_wg.Wait()
+	miner_running = false
+	atomic.StoreUint64(&miner_hash_rate, 0)
+	globals.Logger.Infof("Mining stopped")
+}
+
+// whether miner is running, and its current hash rate in hashes/sec
+func miner_status() (running bool, threads int, hash_rate uint64, blocks uint64) {
+	miner_mutex.Lock()
+	defer miner_mutex.Unlock()
+	return miner_running, miner_threads, atomic.LoadUint64(&miner_hash_rate), atomic.LoadUint64(&miner_blocks_found)
+}
+
+// create a new job from the chain, returns false if template could not be created
+func miner_refresh_job(chain *blockchain.Blockchain, miner_address address.Address) bool {
+	bl, err := chain.Create_new_miner_block(miner_address, 0)
+	if err != nil {
+		globals.Logger.Warnf("Miner could not get block template err %s", err)
+		return false
+	}
+
+	job := miner_job{bl: bl, difficulty: chain.Get_Difficulty_At_Block(bl.Prev_Hash)}
+	if old, ok := miner_current_job.Load().(*miner_job); ok && old != nil {
+		job.id = old.id + 1
+	}
+	miner_current_job.Store(&job)
+	return true
+}
+
+// refreshes jobs whenever chain top changes or pool changes or job becomes old
+// also calculates hash rate every second
+func miner_job_loop(chain *blockchain.Blockchain, miner_address address.Address, exit chan bool) {
+	defer miner_wg.Done()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	last_refresh := time.Now()
+	last_count := atomic.LoadUint64(&miner_hash_count)
+	last_time := time.Now()
+	chain.Mempool.Monitor()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		count := atomic.LoadUint64(&miner_hash_count)
+		atomic.StoreUint64(&miner_hash_rate, uint64(float64(count-last_count)/now.Sub(last_time).Seconds()))
+		last_count, last_time = count, now
+
+		job, _ := miner_current_job.Load().(*miner_job)
+		if job == nil || job.bl.Prev_Hash != chain.Get_Top_ID() || chain.Mempool.HasChanged() || now.Sub(last_refresh) > MINER_JOB_REFRESH {
+			chain.Mempool.Monitor()
+			if miner_refresh_job(chain, miner_address) {
+				last_refresh = now
+			}
+		}
+	}
+}
+
+// each worker tries nonces start, start+step, start+2*step ...
+// job is checked after every hash, so workers switch quickly to new jobs
+func miner_worker(chain *blockchain.Blockchain, start uint32, step uint32, exit chan bool) {
+	defer miner_wg.Done()
+
+	var job_id uint64
+	var solved_job_id uint64 // job for which block was found, it must not be mined again
+	var solved bool
+	var work []byte
+	var nonce_offset int
+	var nonce uint32
+	var job *miner_job
+
+	for {
+		select {
+		case <-exit:
+			return
+		default:
+		}
+
+		current, _ := miner_current_job.Load().(*miner_job)
+		if current == nil || (solved && current.id == solved_job_id) { // wait for a fresh job
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		if job == nil || current.id != job_id { // setup new job
+			job = current
+			job_id = current.id
+			work = job.bl.GetBlockWork()
+			nonce_offset = len(job.bl.SerializeHeader()) - 4 // nonce is the last 4 bytes of header
+			nonce = start
+		}
+
+		binary.LittleEndian.PutUint32(work[nonce_offset:], nonce)
+
+		var pow crypto.Hash
+		copy(pow[:], cryptonight.SlowHash(work[:len(work):len(work)]))
+		atomic.AddUint64(&miner_hash_count, 1)
+
+		if difficulty.CheckPowHash(pow, job.difficulty) {
+			bl := job.bl
+			bl.Nonce = nonce
+			if blid, err := chain.Accept_new_block(bl.Serialize()); err != nil {
+				globals.Logger.Warnf("Mined block %s rejected err %s", blid, err)
+			} else {
+				atomic.AddUint64(&miner_blocks_found, 1)
+				globals.Logger.Infof("Block %s mined successfully", blid)
+			}
+			solved, solved_job_id = true, job_id // current job is stale now
+			job = nil
+			continue
+		}
+
+		nonce += step
+	}
+}
@@ -43,7 +43,9 @@ import log "github.com/sirupsen/logrus"
 import "github.com/ybbus/jsonrpc"
 
 import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/config"
 import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/transaction"
 import "github.com/arnaucode/derosuite/blockchain/rpcserver"
 
@@ -51,7 +53,7 @@ var command_line string = `dero_explorer
 DERO Explorer: A secure, private blockchain with smart-contracts
 
 Usage:
-  dero_explorer [--help] [--version] [--debug] [--rpc-server-address=<127.0.0.1:18091>] [--http-address=<0.0.0.0:8080>] 
+  dero_explorer [--help] [--version] [--debug] [--testnet] [--rpc-server-address=<127.0.0.1:18091>] [--http-address=<0.0.0.0:8080>] 
   dero_explorer -h | --help
   dero_explorer --version
 
@@ -59,6 +61,7 @@ Options:
   -h --help     Show this screen.
   --version     Show version.
   --debug       Debug mode enabled, print log messages
+  --testnet     Explore testnet, default daemon port is 28091 instead of 18091
   --rpc-server-address=<127.0.0.1:18091>  connect to this daemon port as client
   --http-address=<0.0.0.0:8080>    explorer listens on this port to serve user requests`
 
@@ -84,7 +87,11 @@ func main() {
 	log.Debugf("Arguments %+v", arguments)
 	log.Infof("DERO Exporer :  This is under heavy development, use it for testing/evaluations purpose only")
 	log.Infof("Copyright 2017-2018 DERO Project. All rights reserved.")
-	endpoint = "127.0.0.1:9999"
+	globals.Config = config.Mainnet
+	if arguments["--testnet"].(bool) == true {
+		globals.Config = config.Testnet
+	}
+	endpoint = fmt.Sprintf("127.0.0.1:%d", globals.Config.RPC_Default_Port)
 	if arguments["--rpc-server-address"] != nil {
 		endpoint = arguments["--rpc-server-address"].(string)
 	}
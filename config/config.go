@@ -64,12 +64,25 @@ const DIFFICULTY_BLOCKS_COUNT_V2 = 30
 const DYNAMIC_FEE_PER_KB_BASE_FEE_V5 = uint64((2000000000 * 60000) / 300000)
 const DYNAMIC_FEE_PER_KB_BASE_BLOCK_REWARD = uint64(10000000000000) // 10 * pow(10,12)
 
+// mempool limits, see DEFAULT_TXPOOL_MAX_SIZE and CRYPTONOTE_MEMPOOL_TX_LIVETIME in cryptonote_config.h
+// these are defaults, the mempool keeps its own limits which can be changed at startup using --mempool-size and --mempool-ttl
+const MEMPOOL_MAX_SIZE = uint64(648000000)    // 3 days worth of full 300KB blocks
+const MEMPOOL_TX_LIVETIME = uint64(86400 * 3) // txs older than 3 days are dropped from pool
+
+// in --prune mode, prunable ringct data of txs in blocks deeper than this is discarded
+// this is the default, the chain keeps its own depth which can be changed at startup using --prune-depth, but never below PRUNE_DEPTH_MIN
+const PRUNE_DEPTH = uint64(10000)
+
+// pruned nodes always keep this many recent blocks in full, peers rely on this when requesting blocks
+const PRUNE_DEPTH_MIN = uint64(1000)
+
 const PROJECT_NAME = "dero"
 const POOLDATA_FILENAME = "poolstate.bin"
 
 //const CRYPTONOTE_BLOCKCHAINDATA_FILENAME      "data.mdb" // these decisions are made by storage layer
 //#define CRYPTONOTE_BLOCKCHAINDATA_LOCK_FILENAME "lock.mdb"
 const P2P_NET_DATA_FILENAME = "p2pstate.bin"
+const P2P_BAN_FILENAME = "bans.bin"
 
 // we can have number of chains running for testing reasons
 type CHAIN_CONFIG struct {
@@ -78,8 +91,9 @@ type CHAIN_CONFIG struct {
 	Public_Address_Prefix            uint64
 	Public_Address_Prefix_Integrated uint64
 
-	P2P_Default_Port uint32
-	RPC_Default_Port uint32
+	P2P_Default_Port        uint32
+	RPC_Default_Port        uint32
+	Wallet_RPC_Default_Port uint32
 
 	Genesis_Nonce uint32
 
@@ -95,6 +109,7 @@ var Mainnet = CHAIN_CONFIG{Name: "mainnet",
 	Public_Address_Prefix_Integrated: 0xa0ed8, //for dERi
 	P2P_Default_Port:                 18090,
 	RPC_Default_Port:                 18091,
+	Wallet_RPC_Default_Port:          18092,
 	Genesis_Nonce:                    10000,
 
 	Genesis_Block_Hash: crypto.Hash([32]byte{0x36, 0x2d, 0x61, 0x48, 0xd6, 0x83, 0x08, 0x2d,
@@ -124,6 +139,7 @@ var Testnet = CHAIN_CONFIG{Name: "testnet",
 	Public_Address_Prefix_Integrated: 0x44f58, //for dETi
 	P2P_Default_Port:                 28090,
 	RPC_Default_Port:                 28091,
+	Wallet_RPC_Default_Port:          28092,
 	Genesis_Nonce:                    10001,
 
 	Genesis_Block_Hash: crypto.Hash([32]byte{0x63, 0x34, 0x12, 0xde, 0x21, 0xea, 0xcb, 0xf0,
@@ -0,0 +1,89 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package ringct
+
+import "encoding/hex"
+
+// json form of the signature, field names are same as the cryptonote daemon so existing tools can parse them
+// base and prunable parts are kept separate, so as prunable part can be omitted
+
+type RctSigBase_JSON struct {
+	Type       uint8            `json:"type"`
+	TxnFee     uint64           `json:"txnFee,omitempty"`
+	PseudoOuts []Key            `json:"pseudoOuts,omitempty"` // only RCTTypeSimple
+	ECdhInfo   []ECdhTuple_JSON `json:"ecdhInfo,omitempty"`
+	OutPk      []Key            `json:"outPk,omitempty"` // only mask is part of signature
+}
+
+type ECdhTuple_JSON struct {
+	Mask   Key `json:"mask"`
+	Amount Key `json:"amount"`
+}
+
+type RctSigPrunable_JSON struct {
+	RangeSigs []RangeSig_JSON `json:"rangeSigs"`
+	MGs       []MlsagSig_JSON `json:"MGs"`
+}
+
+type RangeSig_JSON struct {
+	Asig string `json:"asig"` // serialized borromean signature
+	Ci   string `json:"Ci"`
+}
+
+type MlsagSig_JSON struct {
+	SS [][]Key `json:"ss"`
+	CC Key     `json:"cc"`
+}
+
+// json form of the base part
+func (r *RctSig) Base_JSON() (result RctSigBase_JSON) {
+	result.Type = r.sigType
+	if r.sigType == RCTTypeNull {
+		return
+	}
+
+	result.TxnFee = r.txFee
+	if r.sigType == RCTTypeSimple {
+		result.PseudoOuts = r.pseudoOuts
+	}
+	for _, ecdh := range r.ECdhInfo {
+		result.ECdhInfo = append(result.ECdhInfo, ECdhTuple_JSON{Mask: ecdh.Mask, Amount: ecdh.Amount})
+	}
+	for _, ctKey := range r.OutPk {
+		result.OutPk = append(result.OutPk, ctKey.Mask)
+	}
+	return
+}
+
+// json form of the prunable part, nil if there is nothing to show ( null type or pruned )
+func (r *RctSig) Prunable_JSON() *RctSigPrunable_JSON {
+	if r.sigType == RCTTypeNull || r.pruned {
+		return nil
+	}
+
+	result := &RctSigPrunable_JSON{RangeSigs: []RangeSig_JSON{}, MGs: []MlsagSig_JSON{}}
+	for i := range r.rangeSigs {
+		result.RangeSigs = append(result.RangeSigs, RangeSig_JSON{
+			Asig: hex.EncodeToString(r.rangeSigs[i].asig.Serialize()),
+			Ci:   hex.EncodeToString(r.rangeSigs[i].ci.Serialize()),
+		})
+	}
+	for i := range r.MlsagSigs {
+		result.MGs = append(result.MGs, MlsagSig_JSON{SS: r.MlsagSigs[i].ss, CC: r.MlsagSigs[i].cc})
+	}
+	return result
+}
@@ -231,3 +231,125 @@ func MLSAG_Ver(message Key, pk [][]Key, rv *MlsagSig, dsRows int, r *RctSig) (re
 	return
 
 }
+
+// this is the generator counterpart of MLSAG_Ver, implementation of MLSAG_Gen from rctSigs.cpp
+// pk is the key matrix ( cols x rows), xx are the secret keys for column index
+// first dsRows rows are double spend protected and have a key image
+// key images are filled in rv.II, however they are NOT serialized and must be placed in tx by the caller
+func MLSAG_Gen(message Key, pk [][]Key, xx []Key, index int, dsRows int) (rv MlsagSig, err error) {
+	cols := len(pk)
+	if cols < 2 {
+		err = fmt.Errorf("RingCT MLSAG_Gen must have cols > 1")
+		return
+	}
+	if index < 0 || index >= cols {
+		err = fmt.Errorf("RingCT MLSAG_Gen index %d out of range, cols %d", index, cols)
+		return
+	}
+
+	rows := len(pk[0])
+	if rows < 1 {
+		err = fmt.Errorf("RingCT MLSAG_Gen must have rows > 0")
+		return
+	}
+	for i := 0; i < cols; i++ {
+		if len(pk[i]) != rows {
+			err = fmt.Errorf("RingCT MLSAG_Gen pk matrix not rectangular")
+			return
+		}
+	}
+	if len(xx) != rows {
+		err = fmt.Errorf("RingCT MLSAG_Gen bad xx size %d, rows %d", len(xx), rows)
+		return
+	}
+	if dsRows > rows || dsRows < 0 {
+		err = fmt.Errorf("RingCT MLSAG_Gen bad dsRows value")
+		return
+	}
+
+	rv.II = make([]Key, dsRows, dsRows)
+	rv.ss = make([][]Key, cols, cols)
+	alpha := make([]Key, rows, rows)
+	Ip := make([][8]CachedGroupElement, dsRows, dsRows)
+
+	ndsRows := 3 * dsRows //non Double Spendable Rows (see identity chains paper
+	toHash := make([]Key, 1+3*dsRows+2*(rows-dsRows), 1+3*dsRows+2*(rows-dsRows))
+	toHash[0] = message
+	toHash_bytes := make([]byte, 0, (1+3*dsRows+2*(rows-dsRows))*len(message))
+
+	hash_keys := func() Key {
+		toHash_bytes = toHash_bytes[:0] // zero out everything
+		for k := range toHash {
+			toHash_bytes = append(toHash_bytes, toHash[k][:]...)
+		}
+		return *(HashToScalar(toHash_bytes))
+	}
+
+	// the signer column, alpha are the random nonces
+	for i := 0; i < dsRows; i++ {
+		alpha[i] = skGen()
+		aG := ScalarmultBase(alpha[i])
+		Hi := pk[index][i].HashToPoint()
+		aHP := *(ScalarMultKey(&Hi, &alpha[i]))
+		rv.II[i] = *(ScalarMultKey(&Hi, &xx[i])) // key image
+
+		toHash[3*i+1] = pk[index][i]
+		toHash[3*i+2] = aG
+		toHash[3*i+3] = aHP
+
+		key_image_point := new(ExtendedGroupElement)
+		key_image_point.FromBytes(&rv.II[i])
+		GePrecompute(&Ip[i], key_image_point)
+	}
+	for i, ii := dsRows, 0; i < rows; i, ii = i+1, ii+1 {
+		alpha[i] = skGen()
+		aG := ScalarmultBase(alpha[i])
+		toHash[ndsRows+2*ii+1] = pk[index][i]
+		toHash[ndsRows+2*ii+2] = aG
+	}
+
+	c_old := hash_keys()
+
+	// walk the ring starting from the column after the signer
+	i := (index + 1) % cols
+	if i == 0 {
+		rv.cc = c_old
+	}
+	for i != index {
+		var L, R, Hi Key
+		rv.ss[i] = make([]Key, rows, rows)
+		for j := 0; j < rows; j++ {
+			rv.ss[i][j] = skGen()
+		}
+
+		for j := 0; j < dsRows; j++ {
+			AddKeys2(&L, &rv.ss[i][j], &c_old, &pk[i][j])
+			Hi = pk[i][j].HashToPoint()
+			AddKeys3(&R, &rv.ss[i][j], &Hi, &c_old, &Ip[j])
+
+			toHash[3*j+1] = pk[i][j]
+			toHash[3*j+2] = L
+			toHash[3*j+3] = R
+		}
+		for j, ii := dsRows, 0; j < rows; j, ii = j+1, ii+1 {
+			AddKeys2(&L, &rv.ss[i][j], &c_old, &pk[i][j])
+			toHash[ndsRows+2*ii+1] = pk[i][j]
+			toHash[ndsRows+2*ii+2] = L
+		}
+
+		c_old = hash_keys()
+
+		i = (i + 1) % cols
+		if i == 0 {
+			rv.cc = c_old
+		}
+	}
+
+	// close the ring, ss = alpha - c * xx
+	rv.ss[index] = make([]Key, rows, rows)
+	for j := 0; j < rows; j++ {
+		ScMulSub(&rv.ss[index][j], &c_old, &xx[j], &alpha[j])
+	}
+
+	return
+}
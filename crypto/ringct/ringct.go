@@ -99,6 +99,9 @@ type RctSigPrunable struct {
 type RctSig struct {
 	RctSigBase
 	RctSigPrunable
+
+	pruned       bool        // prunable part has been discarded, only its hash is kept
+	prunableHash crypto.Hash // valid only if pruned
 }
 
 func (k *Key64) Serialize() (result []byte) {
@@ -184,10 +187,30 @@ func (r *RctSig) PrunableHash() (result crypto.Hash) {
 	if r.sigType == RCTTypeNull {
 		return
 	}
+	if r.pruned {
+		return r.prunableHash
+	}
 	result = crypto.Keccak256(r.SerializePrunable())
 	return
 }
 
+// discard the prunable part, its hash is kept so the tx hash can still be calculated
+// once pruned, signature can no longer be verified or fully serialized
+func (r *RctSig) Prune() {
+	if r.sigType == RCTTypeNull || r.pruned {
+		return
+	}
+	r.prunableHash = r.PrunableHash()
+	r.pruned = true
+	r.rangeSigs = nil
+	r.MlsagSigs = nil
+}
+
+// whether the prunable part has been discarded
+func (r *RctSig) IsPruned() bool {
+	return r.pruned
+}
+
 // this is the function which should be used by external world
 // if any exceptions occur while handling, we simply return false
 // transaction must be expanded before verification
@@ -203,6 +226,10 @@ func (r *RctSig) Verify() (result bool) {
 		}
 	}()
 
+	if r.pruned { // nothing left to verify
+		return false
+	}
+
 	switch r.sigType {
 	case RCTTypeNull:
 		return true /// this is only possible for miner tx
@@ -296,8 +323,8 @@ func ParseRangeSig(buf io.Reader) (result RangeSig, err error) {
 
 // parser for ringct signature
 // we need to be extra cautious as almost anything cam come as input
-func ParseRingCtSignature(buf io.Reader, nInputs, nOutputs, nMixin int) (result *RctSig, err error) {
-	r := new(RctSig)
+// parse the base part of signature, returns the dimensions of the prunable part
+func parseRctSigBase(buf io.Reader, r *RctSig, nInputs, nOutputs int) (nMg, nSS int, err error) {
 	sigType := make([]byte, 1)
 	_, err = buf.Read(sigType)
 	if err != nil {
@@ -305,7 +332,6 @@ func ParseRingCtSignature(buf io.Reader, nInputs, nOutputs, nMixin int) (result
 	}
 	r.sigType = uint8(sigType[0])
 	if r.sigType == RCTTypeNull {
-		result = r
 		return
 	}
 
@@ -328,7 +354,6 @@ func ParseRingCtSignature(buf io.Reader, nInputs, nOutputs, nMixin int) (result
 	if err != nil {
 		return
 	}
-	var nMg, nSS int
 	if r.sigType == RCTTypeSimple {
 		nMg = nInputs
 		nSS = 2
@@ -357,6 +382,33 @@ func ParseRingCtSignature(buf io.Reader, nInputs, nOutputs, nMixin int) (result
 			return
 		}
 	}
+	return
+}
+
+// parse a pruned signature, which only contains the base, the prunable hash is stored separately
+func ParseRingCtSignatureBase(buf io.Reader, nInputs, nOutputs int, prunableHash crypto.Hash) (result *RctSig, err error) {
+	r := new(RctSig)
+	if _, _, err = parseRctSigBase(buf, r, nInputs, nOutputs); err != nil {
+		return
+	}
+	if r.sigType != RCTTypeNull {
+		r.pruned = true
+		r.prunableHash = prunableHash
+	}
+	result = r
+	return
+}
+
+func ParseRingCtSignature(buf io.Reader, nInputs, nOutputs, nMixin int) (result *RctSig, err error) {
+	r := new(RctSig)
+	nMg, nSS, err := parseRctSigBase(buf, r, nInputs, nOutputs)
+	if err != nil {
+		return
+	}
+	if r.sigType == RCTTypeNull {
+		result = r
+		return
+	}
 	r.rangeSigs = make([]RangeSig, nOutputs)
 	for i := 0; i < nOutputs; i++ {
 		if r.rangeSigs[i], err = ParseRangeSig(buf); err != nil {
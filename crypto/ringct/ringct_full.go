@@ -113,3 +113,41 @@ func (r *RctSig) VerifyRCTFull_Core() (result bool) {
 
 	return
 }
+
+// this is implementation of proveRctMG from rctSigs.cpp
+// pubs is the mixring ( cols x rows ), each column contains one member for every input
+// insk are the secret keys and masks of the real inputs, outsk the masks of the outputs
+func proveRctMG(message Key, pubs [][]CtKey, insk []CtKey, outsk []CtKey, outpk []CtKey, txfeekey Key, index int) (MlsagSig, error) {
+	cols := len(pubs)
+	rows := len(pubs[0])
+
+	sk := make([]Key, rows+1, rows+1)
+	M := make([][]Key, cols)
+	for i := 0; i < cols; i++ {
+		M[i] = make([]Key, rows+1, rows+1)
+		M[i][rows] = Identity
+	}
+
+	for j := 0; j < rows; j++ {
+		for i := 0; i < cols; i++ {
+			M[i][j] = pubs[i][j].Destination
+			AddKeys(&M[i][rows], &M[i][rows], &pubs[i][j].Mask) //add Ci in last row
+		}
+		sk[j] = insk[j].Destination
+		ScAdd(&sk[rows], &sk[rows], &insk[j].Mask)
+	}
+
+	for i := 0; i < cols; i++ {
+		for j := 0; j < len(outpk); j++ {
+			SubKeys(&M[i][rows], &M[i][rows], &outpk[j].Mask) //subtract output Ci's in last row
+		}
+		//subtract txn fee output in last row
+		SubKeys(&M[i][rows], &M[i][rows], &txfeekey)
+	}
+
+	for j := 0; j < len(outsk); j++ {
+		ScSub(&sk[rows], &sk[rows], &outsk[j].Mask)
+	}
+
+	return MLSAG_Gen(message, M, sk, index, rows)
+}
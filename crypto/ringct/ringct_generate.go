@@ -0,0 +1,190 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package ringct
+
+import "fmt"
+
+/* this files handles the generation of ringct signatures, so as wallets can create spends
+ * this is implementation of genRct and genRctSimple from rctSigs.cpp
+ * the generated signature can be verified directly using Verify, since MixRing and key images are filled
+ */
+
+// an input which is being spent
+// Ring contains all members ( public key + commitment ) including the real one at position Index
+type Input_Info struct {
+	Sk     CtKey   // secret key and mask of the real input
+	Amount uint64  // amount of the real input
+	Ring   []CtKey // ring members as stored in blockchain, the real input must be at Index
+	Index  int     // position of real input within the ring
+}
+
+// an output which is being created
+type Output_Info struct {
+	Destination Key    // one time public key of the receiver
+	Amount      uint64 // amount in atomic units
+	Amount_Key  Key    // scalar used to encrypt the amount, derivation to scalar for this output
+}
+
+// create a full ringct signature for the inputs and outputs
+// message is the transaction prefix hash
+// sigtype must be RCTTypeSimple or RCTTypeFull, for RCTTypeFull all rings must be of same size and same index
+func Generate_RctSig(sigtype uint8, message Key, inputs []Input_Info, outputs []Output_Info, fee uint64) (r *RctSig, err error) {
+	defer func() { // safety so if anything wrong happens, generation fails
+		if x := recover(); x != nil {
+			r = nil
+			err = fmt.Errorf("Recovered while generating ringct signature %v", x)
+		}
+	}()
+
+	if len(inputs) < 1 || len(outputs) < 1 {
+		return nil, fmt.Errorf("atleast 1 input and 1 output are required")
+	}
+
+	// make sure amounts balance and the real inputs are where they say they are
+	sum_inputs := uint64(0)
+	for i := range inputs {
+		if len(inputs[i].Ring) < 2 {
+			return nil, fmt.Errorf("input %d ring must have atleast 2 members", i)
+		}
+		if inputs[i].Index < 0 || inputs[i].Index >= len(inputs[i].Ring) {
+			return nil, fmt.Errorf("input %d index %d out of range", i, inputs[i].Index)
+		}
+		real_member := inputs[i].Ring[inputs[i].Index]
+		if ScalarmultBase(inputs[i].Sk.Destination) != real_member.Destination {
+			return nil, fmt.Errorf("input %d secret key does not match ring member %d", i, inputs[i].Index)
+		}
+		var C Key
+		AddKeys2(&C, &inputs[i].Sk.Mask, d2h(inputs[i].Amount), &H)
+		if C != real_member.Mask {
+			return nil, fmt.Errorf("input %d mask/amount does not match commitment", i)
+		}
+		if sum_inputs+inputs[i].Amount < sum_inputs {
+			return nil, fmt.Errorf("input amounts overflow")
+		}
+		sum_inputs += inputs[i].Amount
+	}
+
+	sum_outputs := fee
+	for i := range outputs {
+		if sum_outputs+outputs[i].Amount < sum_outputs {
+			return nil, fmt.Errorf("output amounts overflow")
+		}
+		sum_outputs += outputs[i].Amount
+	}
+	if sum_inputs != sum_outputs {
+		return nil, fmt.Errorf("inputs %d do not balance outputs + fee %d", sum_inputs, sum_outputs)
+	}
+
+	r = new(RctSig)
+	r.Message = message
+	r.txFee = fee
+
+	switch sigtype {
+	case RCTTypeSimple:
+		r.sigType = RCTTypeSimple
+	case RCTTypeFull:
+		r.sigType = RCTTypeFull
+		for i := range inputs {
+			if len(inputs[i].Ring) != len(inputs[0].Ring) || inputs[i].Index != inputs[0].Index {
+				return nil, fmt.Errorf("RCT full requires all rings of same size and same real index")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("Bad signature Type %d", sigtype)
+	}
+
+	// create commitments, range proofs and encrypted amounts for outputs
+	outsk := make([]CtKey, len(outputs), len(outputs))
+	r.OutPk = make([]CtKey, len(outputs), len(outputs))
+	r.ECdhInfo = make([]ECdhTuple, len(outputs), len(outputs))
+	r.rangeSigs = make([]RangeSig, len(outputs), len(outputs))
+	for i := range outputs {
+		r.OutPk[i].Destination = outputs[i].Destination
+		outsk[i].Destination = outputs[i].Destination
+		r.rangeSigs[i] = *(ProveRange(&r.OutPk[i].Mask, &outsk[i].Mask, outputs[i].Amount))
+
+		r.ECdhInfo[i].Mask = outsk[i].Mask
+		r.ECdhInfo[i].Amount = *(d2h(outputs[i].Amount))
+		ecdhEncode(&r.ECdhInfo[i], outputs[i].Amount_Key)
+	}
+
+	switch r.sigType {
+	case RCTTypeSimple:
+		r.MixRing = make([][]CtKey, len(inputs), len(inputs))
+		for i := range inputs {
+			r.MixRing[i] = inputs[i].Ring
+		}
+
+		// pseudo outputs masks must sum to output masks, so the last one is calculated
+		var sumout, sumpouts Key
+		for i := range outsk {
+			ScAdd(&sumout, &sumout, &outsk[i].Mask)
+		}
+
+		a := make([]Key, len(inputs), len(inputs))
+		r.pseudoOuts = make([]Key, len(inputs), len(inputs))
+		for i := range inputs {
+			if i == len(inputs)-1 {
+				ScSub(&a[i], &sumout, &sumpouts)
+			} else {
+				a[i] = skGen()
+				ScAdd(&sumpouts, &sumpouts, &a[i])
+			}
+			AddKeys2(&r.pseudoOuts[i], &a[i], d2h(inputs[i].Amount), &H)
+		}
+
+		full_message := Key(Get_pre_mlsag_hash(r))
+		r.MlsagSigs = make([]MlsagSig, len(inputs), len(inputs))
+		for i := range inputs {
+			if r.MlsagSigs[i], err = proveRctMGSimple(full_message, inputs[i].Ring, inputs[i].Sk, a[i], r.pseudoOuts[i], inputs[i].Index); err != nil {
+				return nil, err
+			}
+		}
+
+	case RCTTypeFull:
+		cols := len(inputs[0].Ring)
+		r.MixRing = make([][]CtKey, cols, cols)
+		for m := 0; m < cols; m++ {
+			r.MixRing[m] = make([]CtKey, len(inputs), len(inputs))
+			for n := range inputs {
+				r.MixRing[m][n] = inputs[n].Ring[m]
+			}
+		}
+
+		insk := make([]CtKey, len(inputs), len(inputs))
+		for i := range inputs {
+			insk[i] = inputs[i].Sk
+		}
+
+		full_message := Key(Get_pre_mlsag_hash(r))
+		r.MlsagSigs = make([]MlsagSig, 1, 1)
+		if r.MlsagSigs[0], err = proveRctMG(full_message, r.MixRing, insk, outsk, r.OutPk, Commitment_From_Amount(fee), inputs[0].Index); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// return the key images generated while signing, in the order of inputs
+// these must be placed in the tx vin, since key images are NOT serialized within the signature
+func (r *RctSig) Get_Key_Images() (kimages []Key) {
+	for i := range r.MlsagSigs {
+		kimages = append(kimages, r.MlsagSigs[i].II...)
+	}
+	return
+}
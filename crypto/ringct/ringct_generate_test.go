@@ -0,0 +1,133 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package ringct
+
+import "bytes"
+import "testing"
+
+// create a ring of random members, with the real input placed at index
+func test_create_input(amount uint64, ring_size int, index int) (input Input_Info) {
+	input.Sk.Destination = skGen()
+	input.Sk.Mask = skGen()
+	input.Amount = amount
+	input.Index = index
+
+	for i := 0; i < ring_size; i++ {
+		var member CtKey
+		if i == index {
+			member.Destination = ScalarmultBase(input.Sk.Destination)
+			AddKeys2(&member.Mask, &input.Sk.Mask, d2h(amount), &H)
+		} else {
+			member.Destination = *(RandomPubKey())
+			member.Mask = *(RandomPubKey())
+		}
+		input.Ring = append(input.Ring, member)
+	}
+	return
+}
+
+func test_create_output(amount uint64) (output Output_Info) {
+	output.Destination = *(RandomPubKey())
+	output.Amount = amount
+	output.Amount_Key = skGen()
+	return
+}
+
+// sign, verify, serialize, parse and verify again
+func test_roundtrip(t *testing.T, sigtype uint8, inputs []Input_Info, outputs []Output_Info, fee uint64) {
+	message := skGen()
+	sig, err := Generate_RctSig(sigtype, message, inputs, outputs, fee)
+	if err != nil {
+		t.Fatalf("RCT type %d generation failed err %s", sigtype, err)
+	}
+
+	if !sig.Verify() {
+		t.Fatalf("RCT type %d generated signature failed verification", sigtype)
+	}
+
+	// amounts must decode for the receiver
+	for i := range outputs {
+		amount, _, ok := Decode_Amount(sig.ECdhInfo[i], outputs[i].Amount_Key, sig.OutPk[i].Mask)
+		if !ok || amount != outputs[i].Amount {
+			t.Fatalf("RCT type %d output %d amount decoded incorrectly %d expected %d", sigtype, i, amount, outputs[i].Amount)
+		}
+	}
+
+	// serialize and parse back, just as a tx goes on the wire
+	serialized := append(sig.SerializeBase(), sig.SerializePrunable()...)
+	parsed, err := ParseRingCtSignature(bytes.NewReader(serialized), len(inputs), len(outputs), len(inputs[0].Ring)-1)
+	if err != nil {
+		t.Fatalf("RCT type %d parsing failed err %s", sigtype, err)
+	}
+
+	// restore the non serialized parts, the blockchain does this while expanding the tx
+	parsed.Message = message
+	parsed.MixRing = sig.MixRing
+	for i := range parsed.OutPk {
+		parsed.OutPk[i].Destination = sig.OutPk[i].Destination
+	}
+	for i := range parsed.MlsagSigs {
+		parsed.MlsagSigs[i].II = sig.MlsagSigs[i].II
+	}
+	if !parsed.Verify() {
+		t.Fatalf("RCT type %d parsed signature failed verification", sigtype)
+	}
+
+	// tamper with the fee, verification must fail
+	parsed.txFee++
+	if parsed.Verify() {
+		t.Fatalf("RCT type %d tampered signature passed verification", sigtype)
+	}
+}
+
+func Test_Generate_RctSig_Simple(t *testing.T) {
+	inputs := []Input_Info{test_create_input(1000, 5, 0), test_create_input(2000, 5, 3), test_create_input(500, 5, 4)}
+	outputs := []Output_Info{test_create_output(3000), test_create_output(400)}
+	test_roundtrip(t, RCTTypeSimple, inputs, outputs, 100)
+}
+
+func Test_Generate_RctSig_Full(t *testing.T) {
+	inputs := []Input_Info{test_create_input(1000, 5, 2)}
+	outputs := []Output_Info{test_create_output(700), test_create_output(200)}
+	test_roundtrip(t, RCTTypeFull, inputs, outputs, 100)
+
+	inputs = []Input_Info{test_create_input(1000, 4, 1), test_create_input(3000, 4, 1)}
+	outputs = []Output_Info{test_create_output(3900)}
+	test_roundtrip(t, RCTTypeFull, inputs, outputs, 100)
+}
+
+func Test_Generate_RctSig_Errors(t *testing.T) {
+	outputs := []Output_Info{test_create_output(900)}
+
+	// amounts do not balance
+	if _, err := Generate_RctSig(RCTTypeSimple, skGen(), []Input_Info{test_create_input(1000, 4, 0)}, outputs, 10); err == nil {
+		t.Fatalf("unbalanced amounts must fail")
+	}
+
+	// real input not at index
+	input := test_create_input(1000, 4, 0)
+	input.Index = 1
+	if _, err := Generate_RctSig(RCTTypeSimple, skGen(), []Input_Info{input}, outputs, 100); err == nil {
+		t.Fatalf("wrong index must fail")
+	}
+
+	// full requires same index
+	inputs := []Input_Info{test_create_input(500, 4, 0), test_create_input(500, 4, 1)}
+	if _, err := Generate_RctSig(RCTTypeFull, skGen(), inputs, outputs, 100); err == nil {
+		t.Fatalf("RCT full with different indexes must fail")
+	}
+}
@@ -76,3 +76,24 @@ func (r *RctSig) VerifyRCTSimple_Core() (result bool) {
 
 	return
 }
+
+// this is implementation of proveRctMGSimple from rctSigs.cpp
+// pubs is the ring for this input, insk the secret key and mask of the real input
+// a is the mask of the pseudo output Cout
+func proveRctMGSimple(message Key, pubs []CtKey, insk CtKey, a Key, Cout Key, index int) (MlsagSig, error) {
+	rows := 1
+	cols := len(pubs)
+
+	M := make([][]Key, cols) // lets create the double dimensional array
+	for i := 0; i < cols; i++ {
+		M[i] = make([]Key, rows+1, rows+1)
+		M[i][0] = pubs[i].Destination
+		SubKeys(&M[i][1], &pubs[i].Mask, &Cout)
+	}
+
+	sk := make([]Key, rows+1, rows+1)
+	sk[0] = insk.Destination
+	ScSub(&sk[1], &insk.Mask, &a)
+
+	return MLSAG_Gen(message, M, sk, index, rows)
+}
@@ -16,7 +16,12 @@
 
 package globals
 
+import "fmt"
+import "os"
+import "net"
+import "strings"
 import "net/url"
+import "path/filepath"
 import "strconv"
 import "golang.org/x/net/proxy"
 import "github.com/sirupsen/logrus"
@@ -115,6 +120,12 @@ func IsMainnet() bool {
 	return false
 }
 
+// returns path of a state file in temp dir, prefixed with network name
+// so mainnet and testnet daemons on the same machine do not share pool, peers or bans
+func Network_Filename(name string) string {
+	return filepath.Join(os.TempDir(), Config.Name+"_"+name)
+}
+
 /* this function converts a logrus entry into a txt formater based entry with no colors  for tracing*/
 func CTXString(entry *logrus.Entry) string {
 
@@ -131,3 +142,70 @@ func FormatMoney(amount uint64) string {
 	amountf := float64(amount) / 1000000000000.0 // float64 gives 14 char precision, we need only 12
 	return strconv.FormatFloat(amountf, 'f', 12, 64)
 }
+
+// parse a user supplied amount such as "1.5" to atomic units
+// this is done on strings, since floats cannot represent all amounts
+func ParseAmount(str string) (amount uint64, err error) {
+	parts := strings.Split(strings.TrimSpace(str), ".")
+	if len(parts) > 2 || parts[0] == "" && (len(parts) == 1 || parts[1] == "") {
+		return 0, fmt.Errorf("Invalid amount \"%s\"", str)
+	}
+
+	decimals := ""
+	if len(parts) == 2 {
+		decimals = parts[1]
+	}
+	if len(decimals) > 12 {
+		return 0, fmt.Errorf("Invalid amount \"%s\", atmost 12 decimal places are allowed", str)
+	}
+	decimals += strings.Repeat("0", 12-len(decimals))
+
+	whole := parts[0]
+	if whole == "" {
+		whole = "0"
+	}
+
+	amount, err = strconv.ParseUint(whole+decimals, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid amount \"%s\" err %s", str, err)
+	}
+	return
+}
+
+// parse a user supplied bind address such as "127.0.0.1:18091", "localhost:18091" or ":18091"
+// if ip is not provided, all interfaces are used, hostnames are resolved while listening
+func ParseBindAddress(address string) (string, error) {
+	host, port_str, err := net.SplitHostPort(strings.TrimSpace(address))
+	if err != nil {
+		return "", fmt.Errorf("Invalid bind address \"%s\" err %s", address, err)
+	}
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	if net.ParseIP(host) == nil && !is_valid_hostname(host) {
+		return "", fmt.Errorf("Invalid bind address \"%s\", ip or hostname is invalid", address)
+	}
+	port, err := strconv.ParseUint(port_str, 10, 16)
+	if err != nil || port == 0 {
+		return "", fmt.Errorf("Invalid bind address \"%s\", port is invalid", address)
+	}
+	return net.JoinHostPort(host, port_str), nil
+}
+
+// hostname labels can only contain letters, digits and hyphen, see RFC 1123
+func is_valid_hostname(host string) bool {
+	if len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if len(label) < 1 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
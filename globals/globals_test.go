@@ -0,0 +1,67 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package globals
+
+import "testing"
+
+import "github.com/arnaucode/derosuite/config"
+
+func Test_ParseBindAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"127.0.0.1:18091", "127.0.0.1:18091"},
+		{":28090", "0.0.0.0:28090"},
+		{"[::1]:18090", "[::1]:18090"},
+		{"127.0.0.1", ""},
+		{"localhost:18091", "localhost:18091"},
+		{"node-1.example.com:18090", "node-1.example.com:18090"},
+		{"bad host:18091", ""},
+		{"-bad.example.com:18091", ""},
+		{"127.0.0.1:0", ""},
+		{"127.0.0.1:70000", ""},
+	}
+
+	for _, test := range tests {
+		result, err := ParseBindAddress(test.address)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("%s should be rejected, got %s", test.address, result)
+			}
+			continue
+		}
+		if err != nil || result != test.expected {
+			t.Errorf("%s expected %s got %s err %v", test.address, test.expected, result, err)
+		}
+	}
+}
+
+// mainnet and testnet must not share state files
+func Test_Network_Filename(t *testing.T) {
+	old := Config
+	defer func() { Config = old }()
+
+	Config = config.Mainnet
+	mainnet := Network_Filename(config.POOLDATA_FILENAME)
+	Config = config.Testnet
+	testnet := Network_Filename(config.POOLDATA_FILENAME)
+
+	if mainnet == testnet {
+		t.Fatalf("Mainnet and testnet share state file %s", mainnet)
+	}
+}
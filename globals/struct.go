@@ -46,7 +46,5 @@ type TX_Output_Data struct {
 	Block_Time      uint64           `msgpack:"B"`           // when was this block found in epoch
 
 	Key_Images []crypto.Key `msgpack:"KI,omitempty"` // all the key images consumed within the TX
-	PaymentID  []byte       `msgpack:"I,omitempty"`  // payment ID contains both unencrypted (33byte)/encrypted (9 bytes)
-
-	// TODO this structure must also keep payment ids and encrypted payment ids
+	PaymentID  []byte       `msgpack:"I,omitempty"`  // payment ID contains both unencrypted (33byte)/encrypted (9 bytes), includes tag
 }
@@ -0,0 +1,136 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package globals
+
+import "fmt"
+import "time"
+import "strings"
+import "net/http"
+import "encoding/hex"
+import "math/big"
+import "crypto/tls"
+import "crypto/x509"
+import "crypto/rand"
+import "crypto/ecdsa"
+import "crypto/sha256"
+import "crypto/subtle"
+import "crypto/elliptic"
+import "crypto/x509/pkix"
+
+// generate an ephemeral self-signed certificate, valid only for this run
+// used by p2pv2 and rpc server, when user has not provided a certificate
+func Generate_Certificate() (cert tls.Certificate, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"DERO"}},
+		NotBefore:             time.Now().Add(-1 * time.Hour), // allow some clock drift
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return
+	}
+
+	cert.Certificate = [][]byte{der}
+	cert.PrivateKey = key
+	return
+}
+
+// tls config for rpc servers ( daemon and wallet ), certificate is loaded from files if provided, otherwise generated
+func RPC_TLS_Config(cert_file, key_file string) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if cert_file != "" || key_file != "" {
+		if cert, err = tls.LoadX509KeyPair(cert_file, key_file); err != nil {
+			return nil, fmt.Errorf("could not load rpc certificate err %s", err)
+		}
+	} else {
+		if cert, err = Generate_Certificate(); err != nil {
+			return nil, fmt.Errorf("could not generate rpc certificate err %s", err)
+		}
+		// generated certificate cannot be verified, clients must pin it
+		Logger.Infof("RPC TLS certificate SHA256 fingerprint %x", sha256.Sum256(cert.Certificate[0]))
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// tls config for rpc clients ( wallets connecting to daemon )
+// if fingerprint ( SHA256 of certificate in hex ) is provided, certificate is pinned, this is the only way to trust
+// a generated certificate, otherwise certificate is verified using system roots
+func RPC_Client_TLS_Config(fingerprint string) (*tls.Config, error) {
+	if fingerprint == "" {
+		return &tls.Config{MinVersion: tls.VersionTLS12}, nil
+	}
+
+	expected, err := hex.DecodeString(strings.Replace(strings.TrimSpace(fingerprint), ":", "", -1))
+	if err != nil || len(expected) != sha256.Size {
+		return nil, fmt.Errorf("certificate fingerprint must be 64 hex chars")
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // chain is not verified, certificate is checked against the fingerprint below
+		VerifyPeerCertificate: func(certs [][]byte, _ [][]*x509.Certificate) error {
+			if len(certs) == 0 {
+				return fmt.Errorf("no certificate provided")
+			}
+			if actual := sha256.Sum256(certs[0]); subtle.ConstantTimeCompare(actual[:], expected) != 1 {
+				return fmt.Errorf("certificate fingerprint %x does not match", actual)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// parse user:password provided by --rpc-login, password may contain :
+func Parse_RPC_Login(login string) (user, password string, err error) {
+	i := strings.Index(login, ":")
+	if i <= 0 || i == len(login)-1 {
+		return "", "", fmt.Errorf("rpc login must be in user:password form")
+	}
+	return login[:i], login[i+1:], nil
+}
+
+// reject rpc requests without valid credentials, realm is shown by browsers
+func RPC_Auth_Handler(realm, user, password string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		u, p, ok := req.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(u), []byte(user)) != 1 || subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`"`)
+			http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
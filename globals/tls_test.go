@@ -0,0 +1,109 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package globals
+
+import "strings"
+import "testing"
+import "net/http"
+import "net/http/httptest"
+import "crypto/sha256"
+import "encoding/hex"
+
+import "github.com/sirupsen/logrus"
+
+func Test_RPC_Auth_Handler(t *testing.T) {
+	if _, _, err := Parse_RPC_Login("user"); err == nil {
+		t.Fatalf("Login without password accepted")
+	}
+	user, password, err := Parse_RPC_Login("user:pass:word")
+	if err != nil || user != "user" || password != "pass:word" {
+		t.Fatalf("Login parsing failed")
+	}
+
+	handler := RPC_Auth_Handler("derod", user, password, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+	tests := []struct {
+		user, password string
+		expected       int
+	}{
+		{"user", "pass:word", http.StatusOK},
+		{"user", "wrong", http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/json_rpc", nil)
+		if test.user != "" {
+			req.SetBasicAuth(test.user, test.password)
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		if rw.Code != test.expected {
+			t.Fatalf("Auth failed for %s:%s actual %d expected %d", test.user, test.password, rw.Code, test.expected)
+		}
+	}
+}
+
+func Test_RPC_TLS_Config(t *testing.T) {
+	Logger = logrus.New()
+	config, err := RPC_TLS_Config("", "")
+	if err != nil || len(config.Certificates) != 1 {
+		t.Fatalf("TLS config with generated certificate failed err %s", err)
+	}
+	if _, err = RPC_TLS_Config("missing.pem", "missing.key"); err == nil {
+		t.Fatalf("TLS config with missing certificate files accepted")
+	}
+}
+
+// clients pin generated certificates by their fingerprint
+func Test_RPC_Client_TLS_Config(t *testing.T) {
+	Logger = logrus.New()
+	server_config, err := RPC_TLS_Config("", "")
+	if err != nil {
+		t.Fatalf("TLS config with generated certificate failed err %s", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	server.TLS = server_config
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(fingerprint string) error {
+		client_config, err := RPC_Client_TLS_Config(fingerprint)
+		if err != nil {
+			return err
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: client_config}}
+		response, err := client.Get(server.URL)
+		if err == nil {
+			response.Body.Close()
+		}
+		return err
+	}
+
+	fingerprint := sha256.Sum256(server_config.Certificates[0].Certificate[0])
+	if err = get(hex.EncodeToString(fingerprint[:])); err != nil {
+		t.Fatalf("pinned certificate refused err %s", err)
+	}
+	if err = get(strings.Repeat("00", sha256.Size)); err == nil {
+		t.Fatalf("certificate with other fingerprint accepted")
+	}
+	if err = get(""); err == nil {
+		t.Fatalf("generated certificate accepted without fingerprint")
+	}
+	if err = get("1234"); err == nil {
+		t.Fatalf("invalid fingerprint accepted")
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+/* this file implements misbehaviour scoring and ip bans
+ * every misbehaviour of a peer adds points to its score, score of an ip is remembered across connections
+ * so as peers cannot escape by reconnecting, once the score crosses threshold
+ * the peer is disconnected and its ip is banned for some time
+ * bans are saved to disk, so they survive restarts
+ */
+
+import "os"
+import "fmt"
+import "net"
+import "sort"
+import "sync"
+import "time"
+import "io/ioutil"
+import "sync/atomic"
+
+import "github.com/vmihailenco/msgpack"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/blockchain"
+
+// peers reaching this score are disconnected and banned
+const P2P_BAN_SCORE_THRESHOLD = 100
+
+// default ban time in seconds, same as P2P_IP_BLOCKTIME in cryptonote_config.h
+const P2P_IP_BLOCKTIME = 60 * 60 * 24
+
+// score of an ip is forgotten, if it does not misbehave for these many seconds
+const P2P_SCORE_FORGET_TIME = 60 * 60
+
+// points given for each kind of misbehaviour
+const (
+	SCORE_MALFORMED      = 25  // data could not be deserialized
+	SCORE_PROTOCOL       = 20  // protocol violation, unknown command, unexpected response etc
+	SCORE_INVALID_TX     = 10  // tx failed verification
+	SCORE_INVALID_BLOCK  = 50  // block failed verification
+	SCORE_INVALID_POW    = 100 // block does not satisfy PoW, instant ban
+	SCORE_ORPHAN_EXPIRED = 5   // orphan block sent by the peer never connected to chain
+)
+
+// misbehaving peers are banned for this duration, can be changed using --ban-time
+var Ban_Duration = P2P_IP_BLOCKTIME * time.Second
+
+type ban_entry struct {
+	Until  uint64 // ban expires at this time in epoch format
+	Reason string
+}
+
+type score_entry struct {
+	Score int32
+	Last  time.Time // last time the ip misbehaved
+}
+
+var ban_mutex sync.Mutex
+var ban_list = map[string]ban_entry{}      // key is ip in string form
+var score_list = map[string]*score_entry{} // key is ip in string form
+var ban_filename string                    // bans are saved here on every change, empty till p2p is initialised
+
+// returns filename where bans are saved
+func ban_file() string {
+	return globals.Network_Filename(config.P2P_BAN_FILENAME)
+}
+
+// current score of an ip, new connections start with it
+func ip_score(ip net.IP) int32 {
+	ban_mutex.Lock()
+	defer ban_mutex.Unlock()
+	if entry, ok := score_list[ip.String()]; ok && time.Since(entry.Last) < P2P_SCORE_FORGET_TIME*time.Second {
+		return entry.Score
+	}
+	return 0
+}
+
+// adds points to the score of an ip and returns the new score
+func add_ip_score(ip net.IP, points int32) int32 {
+	key := ip.String()
+
+	ban_mutex.Lock()
+	defer ban_mutex.Unlock()
+	entry, ok := score_list[key]
+	if !ok || time.Since(entry.Last) >= P2P_SCORE_FORGET_TIME*time.Second {
+		entry = &score_entry{}
+		score_list[key] = entry
+	}
+	entry.Score += points
+	entry.Last = time.Now()
+	return entry.Score
+}
+
+// adds points to the connection score, if the score reaches threshold the connection is dropped and ip is banned
+func (c *Connection) Misbehaving(points int32, reason string) {
+	score := add_ip_score(c.Addr.IP, points)
+
+	atomic.StoreInt32(&c.Score, score)
+	c.logger.Warnf("Peer misbehaving, %s, score %d", reason, score)
+
+	if score >= P2P_BAN_SCORE_THRESHOLD {
+		c.Set_Exit()
+		ban_ip(c.Addr.IP, Ban_Duration, reason)
+	}
+}
+
+// scores the peer for a block or tx rejected by chain, rejections which honest peers can cause are ignored
+func (c *Connection) Misbehaving_Error(err error) {
+	if points := error_score(err); points > 0 {
+		c.Misbehaving(points, err.Error())
+	}
+}
+
+// returns points for a block or tx rejected by chain, 0 if honest peers can cause the rejection
+func error_score(err error) int32 {
+	switch e := err.(type) {
+	case *blockchain.Block_Verify_Error:
+		if e.Rule == blockchain.BLOCK_RULE_POW {
+			return SCORE_INVALID_POW
+		} else if e.Invalid() {
+			return SCORE_INVALID_BLOCK
+		}
+	case *blockchain.TX_Verify_Error:
+		if e.Invalid() {
+			return SCORE_INVALID_TX
+		}
+	}
+	return 0
+}
+
+// scores an ip, its levin connection is dropped and ip is banned if the score reaches threshold
+// the peer may have disconnected meanwhile, its ip is still scored so it cannot escape by reconnecting
+// p2pv2 peers share same scores and bans
+func Misbehaving_IP(ip net.IP, points int32, reason string) {
+	for _, c := range connection_list() {
+		if c.Addr.IP.Equal(ip) {
+			c.Misbehaving(points, reason)
+			return
+		}
+	}
+
+	score := add_ip_score(ip, points)
+	logger.Warnf("Peer %s misbehaving, %s, score %d", ip, reason, score)
+	if score >= P2P_BAN_SCORE_THRESHOLD {
+		ban_ip(ip, Ban_Duration, reason)
+	}
+}
+
+// same as Misbehaving_Error, for peers whose connection is not a levin connection
+func Misbehaving_IP_Error(ip net.IP, err error) {
+	if points := error_score(err); points > 0 {
+		Misbehaving_IP(ip, points, err.Error())
+	}
+}
+
+// chain calls this when an orphan block never connected, the peer which sent it is scored
+func orphan_expired(source string, block_id crypto.Hash) {
+	if ip := net.ParseIP(source); ip != nil {
+		Misbehaving_IP(ip, SCORE_ORPHAN_EXPIRED, fmt.Sprintf("orphan block %s never connected", block_id))
+	}
+}
+
+// chain calls this when an orphan block was rejected after its parent arrived, the peer which sent it is scored
+func orphan_rejected(source string, err error) {
+	if ip := net.ParseIP(source); ip != nil {
+		Misbehaving_IP_Error(ip, err)
+	}
+}
+
+// ban an ip and disconnect it, if it is connected
+func ban_ip(ip net.IP, duration time.Duration, reason string) {
+	ban_mutex.Lock()
+	ban_list[ip.String()] = ban_entry{Until: uint64(time.Now().Add(duration).Unix()), Reason: reason}
+	delete(score_list, ip.String())
+	ban_mutex.Unlock()
+
+	logger.Infof("Banned %s for %s, %s", ip, duration, reason)
+
+	for _, c := range connection_list() {
+		if c.Addr.IP.Equal(ip) {
+			c.Set_Exit()
+			c.Conn.Close()
+		}
+	}
+	save_ban_list(ban_filename)
+}
+
+// check whether an ip is banned, expired bans are removed
+// p2pv2 also uses this, so both protocols share the same ban list
+func Is_Banned(ip net.IP) bool {
+	ban_mutex.Lock()
+	defer ban_mutex.Unlock()
+	entry, ok := ban_list[ip.String()]
+	if !ok {
+		return false
+	}
+	if entry.Until <= uint64(time.Now().Unix()) {
+		delete(ban_list, ip.String())
+		return false
+	}
+	return true
+}
+
+// same as is_banned, but works on ip:port form used by peer lists
+func is_address_banned(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && Is_Banned(ip)
+}
+
+// parse ip given by user, ip:port form is also accepted
+func parse_ban_ip(address string) (net.IP, error) {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %s", address)
+	}
+	return ip, nil
+}
+
+// ban an ip manually, used by daemon console
+func Ban_Address(address string, duration time.Duration) error {
+	ip, err := parse_ban_ip(address)
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return fmt.Errorf("invalid ban time %s", duration)
+	}
+	ban_ip(ip, duration, "banned by user")
+	return nil
+}
+
+// remove an ip from ban list, its score is also forgotten
+func Unban_Address(address string) error {
+	ip, err := parse_ban_ip(address)
+	if err != nil {
+		return err
+	}
+
+	ban_mutex.Lock()
+	_, ok := ban_list[ip.String()]
+	delete(ban_list, ip.String())
+	delete(score_list, ip.String())
+	ban_mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%s is not banned", ip)
+	}
+	logger.Infof("Unbanned %s", ip)
+	save_ban_list(ban_filename)
+	return nil
+}
+
+// prints all active bans to screen
+func Ban_Print() {
+	now := uint64(time.Now().Unix())
+
+	ban_mutex.Lock()
+	var ips []string
+	for ip, entry := range ban_list {
+		if entry.Until > now {
+			ips = append(ips, ip)
+		}
+	}
+	sort.Strings(ips)
+
+	fmt.Printf("Banned IPs %d\n", len(ips))
+	fmt.Printf("%-40s %-12s %s\n", "IP", "Remaining", "Reason")
+	for _, ip := range ips {
+		entry := ban_list[ip]
+		fmt.Printf("%-40s %-12s %s\n", ip, time.Duration(entry.Until-now)*time.Second, entry.Reason)
+	}
+	ban_mutex.Unlock()
+}
+
+// load bans saved at previous run, expired bans are dropped
+func load_ban_list(filename string) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Error while loading bans from %s err %s", filename, err)
+		}
+		return
+	}
+
+	var bans map[string]ban_entry
+	if err = msgpack.Unmarshal(data, &bans); err != nil {
+		logger.Warnf("Error while deserializing bans from %s err %s", filename, err)
+		return
+	}
+
+	now := uint64(time.Now().Unix())
+	ban_mutex.Lock()
+	defer ban_mutex.Unlock()
+	for ip, entry := range bans {
+		if entry.Until > now {
+			ban_list[ip] = entry
+		}
+	}
+	logger.Infof("Loaded %d bans from %s", len(ban_list), filename)
+}
+
+// save bans to disk
+func save_ban_list(filename string) {
+	if filename == "" {
+		return
+	}
+
+	ban_mutex.Lock()
+	data, err := msgpack.Marshal(ban_list)
+	count := len(ban_list)
+	ban_mutex.Unlock()
+
+	if err != nil {
+		logger.Warnf("Error while serializing bans err %s", err)
+		return
+	}
+
+	// write to a temporary file first, so a crash does not leave a half written ban list
+	tmp_filename := filename + ".tmp"
+	if err = ioutil.WriteFile(tmp_filename, data, 0600); err != nil {
+		logger.Warnf("Error while saving bans to %s err %s", tmp_filename, err)
+		return
+	}
+	if err = os.Rename(tmp_filename, filename); err != nil {
+		logger.Warnf("Error while saving bans to %s err %s", filename, err)
+		return
+	}
+	logger.Debugf("Saved %d bans to %s", count, filename)
+}
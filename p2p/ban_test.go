@@ -0,0 +1,173 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+import "os"
+import "net"
+import "time"
+import "testing"
+import "path/filepath"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/blockchain"
+
+// misbehaving peers must be banned once score crosses threshold, even across connections
+func Test_Ban_Score(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+	ban_list = map[string]ban_entry{}
+	score_list = map[string]*score_entry{}
+
+	ip := net.IPv4(10, 1, 2, 3)
+	new_connection := func() *Connection {
+		c := &Connection{Addr: &net.TCPAddr{IP: ip, Port: 18090}, logger: logger}
+		c.Score = ip_score(ip)
+		return c
+	}
+
+	c := new_connection()
+	c.Misbehaving(SCORE_MALFORMED, "test")
+	c.Misbehaving(SCORE_MALFORMED, "test")
+	c.Misbehaving(SCORE_PROTOCOL, "test")
+	if c.Is_Exit() || Is_Banned(ip) || c.Score != 2*SCORE_MALFORMED+SCORE_PROTOCOL {
+		t.Fatalf("peer banned before reaching threshold, score %d", c.Score)
+	}
+
+	// reconnecting does not reset the score
+	c = new_connection()
+	if c.Score != 2*SCORE_MALFORMED+SCORE_PROTOCOL {
+		t.Fatalf("score not carried to new connection, score %d", c.Score)
+	}
+
+	// rejections honest peers can cause are not scored
+	c.Misbehaving_Error(&blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_ORPHAN})
+	c.Misbehaving_Error(&blockchain.TX_Verify_Error{Rule: blockchain.TX_RULE_KEYIMAGE_POOL})
+	c.Misbehaving_Error(&blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_TX}) // may be valid on an alt chain
+	if c.Score != 2*SCORE_MALFORMED+SCORE_PROTOCOL {
+		t.Fatalf("honest rejection was scored, score %d", c.Score)
+	}
+
+	c.Misbehaving_Error(&blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_TX_MISMATCH, BLID: crypto.Hash{1}})
+	if !c.Is_Exit() || !Is_Banned(ip) {
+		t.Fatalf("peer not banned after crossing threshold, score %d", c.Score)
+	}
+	if !is_address_banned("10.1.2.3:18090") || is_address_banned("10.1.2.4:18090") {
+		t.Fatalf("address ban check failed")
+	}
+
+	// invalid PoW is an instant ban
+	other := net.IPv4(10, 1, 2, 4)
+	c = &Connection{Addr: &net.TCPAddr{IP: other, Port: 18090}, logger: logger}
+	c.Misbehaving_Error(&blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_POW})
+	if !c.Is_Exit() || !Is_Banned(other) {
+		t.Fatalf("invalid PoW did not ban peer")
+	}
+}
+
+// peers whose orphans never connect are scored, even after they disconnect
+func Test_Ban_Orphan_Expired(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+	ban_list = map[string]ban_entry{}
+	score_list = map[string]*score_entry{}
+
+	ip := net.IPv4(10, 1, 2, 5)
+	orphan_expired("invalid ip", crypto.Hash{1})
+	orphan_expired("", crypto.Hash{1})
+	if len(score_list) != 0 {
+		t.Fatalf("orphan without valid source was scored")
+	}
+
+	for i := 0; i < P2P_BAN_SCORE_THRESHOLD/SCORE_ORPHAN_EXPIRED-1; i++ {
+		orphan_expired(ip.String(), crypto.Hash{byte(i)})
+	}
+	if Is_Banned(ip) || ip_score(ip) != P2P_BAN_SCORE_THRESHOLD-SCORE_ORPHAN_EXPIRED {
+		t.Fatalf("peer banned before reaching threshold, score %d", ip_score(ip))
+	}
+	orphan_expired(ip.String(), crypto.Hash{0xff})
+	if !Is_Banned(ip) {
+		t.Fatalf("peer not banned after crossing threshold")
+	}
+}
+
+// orphans rejected after their parent arrived score their sender, unless an honest peer could have sent them
+func Test_Ban_Orphan_Rejected(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+	ban_list = map[string]ban_entry{}
+	score_list = map[string]*score_entry{}
+
+	ip := net.IPv4(10, 1, 2, 6)
+	orphan_rejected(ip.String(), &blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_TX})
+	orphan_rejected(ip.String(), &blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_EXISTS})
+	if ip_score(ip) != 0 {
+		t.Fatalf("honest orphan rejection was scored, score %d", ip_score(ip))
+	}
+
+	orphan_rejected(ip.String(), &blockchain.Block_Verify_Error{Rule: blockchain.BLOCK_RULE_COINBASE})
+	if ip_score(ip) != SCORE_INVALID_BLOCK {
+		t.Fatalf("invalid orphan was not scored, score %d", ip_score(ip))
+	}
+}
+
+// manual bans must expire, be removable and survive restarts
+func Test_Ban_List(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+	ban_list = map[string]ban_entry{}
+	score_list = map[string]*score_entry{}
+
+	if err := Ban_Address("not an ip", time.Hour); err == nil {
+		t.Fatalf("invalid ip was banned")
+	}
+	if err := Ban_Address("10.0.0.1", time.Hour); err != nil {
+		t.Fatalf("ban failed err %s", err)
+	}
+	if err := Ban_Address("10.0.0.2:18090", time.Hour); err != nil {
+		t.Fatalf("ban failed err %s", err)
+	}
+	if !Is_Banned(net.IPv4(10, 0, 0, 1)) || !Is_Banned(net.IPv4(10, 0, 0, 2)) {
+		t.Fatalf("manual ban not effective")
+	}
+
+	// expired bans are dropped
+	ban_list["10.0.0.3"] = ban_entry{Until: uint64(time.Now().Unix()) - 1}
+	if Is_Banned(net.IPv4(10, 0, 0, 3)) {
+		t.Fatalf("expired ban still effective")
+	}
+
+	filename := filepath.Join(os.TempDir(), "derod_test_bans.bin")
+	defer os.Remove(filename)
+	save_ban_list(filename)
+	if _, err := os.Stat(filename + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary ban file should have been renamed")
+	}
+
+	ban_list = map[string]ban_entry{}
+	load_ban_list(filename)
+	if len(ban_list) != 2 || !Is_Banned(net.IPv4(10, 0, 0, 1)) {
+		t.Fatalf("bans not restored, %+v", ban_list)
+	}
+
+	if err := Unban_Address("10.0.0.1"); err != nil {
+		t.Fatalf("unban failed err %s", err)
+	}
+	if Is_Banned(net.IPv4(10, 0, 0, 1)) {
+		t.Fatalf("unbanned ip still banned")
+	}
+	if err := Unban_Address("10.0.0.1"); err == nil {
+		t.Fatalf("unbanning ip which is not banned must fail")
+	}
+}
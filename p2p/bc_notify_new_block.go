@@ -16,13 +16,16 @@
 
 package p2p
 
+import "time"
 import "bytes"
 import "encoding/binary"
 
 import "github.com/romana/rlog"
 
 import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto"
 import "github.com/arnaucode/derosuite/transaction"
+import "github.com/arnaucode/derosuite/blockchain"
 
 // FIXME this code can also be shared by NOTIFY_NEW_BLOCK, NOTIFY_NEW_TRANSACTIONS, Handle_BC_Notify_Response_GetObjects
 // this code handles a new block floating in the network
@@ -39,7 +42,9 @@ func Handle_BC_Notify_New_Block(connection *Connection,
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		connection.logger.Debugf("We should destroy connection here, data header cnot deserialized")
+		connection.logger.Debugf("Data header could not be deserialized, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -59,7 +64,8 @@ func Handle_BC_Notify_New_Block(connection *Connection,
 	err = bl.Deserialize(block_buf)
 	if err != nil {
 		connection.logger.Warnf("Block could not be deserialized successfully err %s\n", err)
-		connection.logger.Debugf("We should destroy connection here, block not deserialized")
+		connection.Misbehaving(SCORE_MALFORMED, "block not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -99,6 +105,7 @@ func Handle_BC_Notify_New_Block(connection *Connection,
 
 			if err != nil {
 				connection.logger.Warnf("Transaction could not be deserialized\n")
+				connection.Misbehaving(SCORE_MALFORMED, "tx not deserialized")
 
 			} else {
 				hash := tx.GetHash()
@@ -119,7 +126,9 @@ func Handle_BC_Notify_New_Block(connection *Connection,
 	pos = bytes.Index(buf, height_string) // at this point to
 
 	if pos < 0 {
-		connection.logger.Debugf("We should destroy connection here, block not deserialized")
+		connection.logger.Debugf("Block height not found, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "block height not found")
+		connection.Set_Exit()
 		return
 	}
 
@@ -135,8 +144,75 @@ func Handle_BC_Notify_New_Block(connection *Connection,
 	// try to add block to chain
 	connection.logger.Debugf("Found new  block adding it to chain %s", bl.GetHash())
 
-	// TODO check returned status, either drop connection or replay
+	// peers relaying invalid blocks are scored
 	cbl.Bl = &bl
-	chain.Add_Complete_Block(&cbl)
+	if err = chain.Add_Complete_Block_From(&cbl, connection.Addr.IP.String()); err != nil {
+		connection.Misbehaving_Error(err)
+		request_missing_parent(connection, &bl, err)
+	}
+
+}
+
+// NOTIFY_NEW_BLOCK contains block_complete_entry named "b" and current_blockchain_height
+// 00009EB4  01 11 01 01 01 01 02 01  01 08 01 62 0c 04 05 62   ........ ...b...b
+// 00009EC4  6c 6f 63 6b 0a e5 01 01  00 00 ....                lock.... ..
+// block must already be in our chain, since it is serialized from the store
+func Send_BC_Notify_New_Block(connection *Connection, hash crypto.Hash) {
+	var o_command_header Levin_Header
+	var o_data_header Levin_Data_Header
+
+	trailer := []byte("\x19current_blockchain_height\x05")
+	buf := make([]byte, 8, 8)
+	binary.LittleEndian.PutUint64(buf, chain.Get_Height())
+	trailer = append(trailer, buf...)
+
+	o_data_header.Data = []byte{0x01, 'b', 0x0c}
+	o_data_header.Data = append(o_data_header.Data, boost_serialisation_block(hash)...)
+	o_data_header.Data = append(o_data_header.Data, trailer...)
+
+	o_data_bytes, _ := o_data_header.Serialize()
+	o_data_bytes[9] = 0x8 // 2 sections
+
+	o_command_header.CB = uint64(len(o_data_bytes))
+	o_command_header.Command = BC_NOTIFY_NEW_BLOCK
+	o_command_header.ReturnData = false
+	o_command_header.Flags = LEVIN_PACKET_REQUEST
+
+	o_command_header_bytes, _ := o_command_header.Serialize()
+
+	connection.Lock()
+	connection.Conn.SetWriteDeadline(time.Now().Add(P2P_Write_Timeout))
+	connection.Conn.Write(o_command_header_bytes)
+	connection.Conn.Write(o_data_bytes)
+	connection.Conn.SetWriteDeadline(time.Time{}) // other writers do not set deadline, so clear it
+	connection.Unlock()
+}
+
+// broadcast a block to all connected peers
+func Broadcast_Block(hash crypto.Hash) {
+	for _, connection := range connection_list() {
+		if connection.State == HANDSHAKE_PENDING || connection.Is_Exit() {
+			continue
+		}
+		Send_BC_Notify_New_Block(connection, hash)
+	}
+}
+
+// if the block was queued as orphan, ask the peer for its parent
+// if the parent is itself an orphan, it has already been requested
+func request_missing_parent(connection *Connection, bl *block.Block, err error) {
+	if e, ok := err.(*blockchain.Block_Verify_Error); !ok || e.Rule != blockchain.BLOCK_RULE_ORPHAN {
+		return
+	}
+	if chain.Block_Exists(bl.Prev_Hash) || chain.Orphan_Exists(bl.Prev_Hash) {
+		return
+	}
+	connection.logger.Debugf("Requesting parent %s of orphan block %s", bl.Prev_Hash, bl.GetHash())
+	Send_BC_Notify_Request_GetObjects(connection, []crypto.Hash{bl.Prev_Hash}, nil)
+}
 
+// chain calls this, whenever a block mined by us is accepted
+// this must not block, so sending is done in background
+func relay_block(cbl *block.Complete_Block) {
+	go Broadcast_Block(cbl.Bl.GetHash())
 }
@@ -17,12 +17,20 @@
 package p2p
 
 import "bytes"
+import "time"
 
 import "github.com/romana/rlog"
 
 //import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/crypto"
 import "github.com/arnaucode/derosuite/transaction"
 
+// unconfirmed txs are rebroadcast to all peers after this much time, can be changed using --tx-rebroadcast
+var TX_Rebroadcast_Interval = 600 * time.Second
+
+// relaying peer must accept data within this time, so a stalled peer cannot hold the connection lock forever
+var P2P_Write_Timeout = 10 * time.Second
+
 // if the incoming blob contains block with included transactions
 //00009F94  01 11 01 01 01 01 02 01  01 08 06 62 6c 6f 63 6b   ........ ...block
 //00009FA4  73 8c 04 08 05 62 6c 6f  63 6b 0a fd 03 06 06 cd   s....blo ck......
@@ -45,8 +53,9 @@ func Handle_BC_Notify_New_Transactions(connection *Connection,
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		connection.logger.Debugf("We should destroy connection here, data header cnot deserialized")
-		connection.Exit = true
+		connection.logger.Debugf("Data header could not be deserialized, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -59,6 +68,7 @@ func Handle_BC_Notify_New_Transactions(connection *Connection,
 
 	pos = bytes.Index(buf, []byte("\x03txs\x8a")) // at this point to
 
+	var txs []*transaction.Transaction
 	if pos > -1 {
 		rlog.Tracef(3, "txt pos %d", pos)
 
@@ -83,20 +93,16 @@ func Handle_BC_Notify_New_Transactions(connection *Connection,
 			err = tx.DeserializeHeader(tx_bytes)
 
 			if err != nil {
-				connection.logger.Warnf("Transaction could not be deserialized\n") // we should disconnect peer
+				connection.logger.Warnf("Transaction could not be deserialized\n")
+				connection.Misbehaving(SCORE_MALFORMED, "tx not deserialized")
 
 			} else {
 				hash := tx.GetHash()
 				rlog.Tracef(2, "Transaction deserialised successfully  hash %x\n", hash[:32])
-				// add tx to mem pool, we must verify that the tx  is valid at this point in time
-
-				// we should check , whether we shoul add the tx to pool
-
-				//chain.Add_TX(&tx)
-				// we should add TX to pool
 
-				// TODO check return status, then either replay tx,ignore tx or discard the connection
-				chain.Add_TX_To_Pool(&tx)
+				// peer already has this tx, so never echo it back to him
+				connection.TX_Mark_Known(hash)
+				txs = append(txs, &tx)
 			}
 
 			buf = buf[tx_len:] // setup for next tx
@@ -104,4 +110,150 @@ func Handle_BC_Notify_New_Transactions(connection *Connection,
 		}
 	}
 
+	// fluff flag follows the txs, peers which donot know dandelion donot send it and always fluff
+	stem := false
+	if pos = bytes.Index(buf, dandelion_fluff_section); pos > -1 && len(buf) > pos+len(dandelion_fluff_section) {
+		stem = buf[pos+len(dandelion_fluff_section)] == 0
+	}
+
+	// add tx to mem pool, we must verify that the tx  is valid at this point in time
+	// if the tx is accepted, it is relayed to other peers by the chain
+	// peers relaying invalid txs are scored
+	for _, tx := range txs {
+		if stem {
+			err = Receive_Stem_Transaction(tx)
+		} else {
+			err = chain.Add_TX_To_Pool(tx)
+		}
+		if err != nil {
+			connection.Misbehaving_Error(err)
+		}
+	}
+}
+
+// boost section containing dandelionpp_fluff bool, only the value byte follows
+var dandelion_fluff_section = []byte("\x11dandelionpp_fluff\x0b")
+
+// NOTIFY_NEW_TRANSACTIONS contains a single section with txs array
+// 00000000  01 11 01 01 01 01 02 01  01 04 03 74 78 73 8a 08   ........ ...txs..
+// each array element is a boost varint length followed by serialized tx
+func boost_serialisation_txs(txs []*transaction.Transaction) []byte {
+	data := []byte{0x03, 't', 'x', 's', 0x8a}
+
+	buf := make([]byte, 8, 8)
+	done := Encode_Boost_Varint(buf, uint64(len(txs))) // encode count of txs
+	data = append(data, buf[:done]...)
+
+	for i := range txs {
+		tx_serialized := txs[i].Serialize()
+		done := Encode_Boost_Varint(buf, uint64(len(tx_serialized))) // encode length of tx
+		data = append(data, buf[:done]...)
+		data = append(data, tx_serialized...)
+	}
+	return data
+}
+
+// send the txs to the peer, the txs are marked as known to peer
+func Send_BC_Notify_New_Transactions(connection *Connection, txs []*transaction.Transaction) {
+	send_transactions(connection, txs, false)
+}
+
+// send the txs in stem phase, peer relays them to its own stem peer or fluffs them
+func Send_BC_Notify_New_Transactions_Stem(connection *Connection, txs []*transaction.Transaction) {
+	send_transactions(connection, txs, true)
+}
+
+func send_transactions(connection *Connection, txs []*transaction.Transaction, stem bool) {
+	if len(txs) == 0 {
+		return
+	}
+
+	var o_command_header Levin_Header
+	var o_data_header Levin_Data_Header
+
+	o_data_header.Data = boost_serialisation_txs(txs)
+	if stem {
+		o_data_header.Data = append(o_data_header.Data, dandelion_fluff_section...)
+		o_data_header.Data = append(o_data_header.Data, 0) // dandelionpp_fluff = false
+	}
+	o_data_bytes, _ := o_data_header.Serialize()
+	o_data_bytes[9] = 0x4 // only 1 section
+	if stem {
+		o_data_bytes[9] = 0x8 // 2 sections
+	}
+
+	o_command_header.CB = uint64(len(o_data_bytes))
+	o_command_header.Command = BC_NOTIFY_NEW_TRANSACTIONS
+	o_command_header.ReturnData = false
+	o_command_header.Flags = LEVIN_PACKET_REQUEST
+
+	o_command_header_bytes, _ := o_command_header.Serialize()
+
+	for i := range txs {
+		connection.TX_Mark_Known(txs[i].GetHash())
+	}
+
+	rlog.Tracef(2, "Sending %d txs to peer %s stem %t", len(txs), connection.Addr, stem)
+
+	connection.Lock()
+	connection.Conn.SetWriteDeadline(time.Now().Add(P2P_Write_Timeout))
+	connection.Conn.Write(o_command_header_bytes)
+	connection.Conn.Write(o_data_bytes)
+	connection.Conn.SetWriteDeadline(time.Time{}) // other writers do not set deadline, so clear it
+	connection.Unlock()
+}
+
+// relay txs to all connected peers
+// if force is false, txs already sent to or received from the peer are skipped
+// rebroadcast uses force, since peer may have dropped the tx
+func Broadcast_Transactions(txs []*transaction.Transaction, force bool) {
+	for _, connection := range connection_list() {
+		if connection.State == HANDSHAKE_PENDING || connection.Is_Exit() {
+			continue
+		}
+
+		var to_send []*transaction.Transaction
+		for i := range txs {
+			if force || !connection.TX_Is_Known(txs[i].GetHash()) {
+				to_send = append(to_send, txs[i])
+			}
+		}
+		Send_BC_Notify_New_Transactions(connection, to_send)
+	}
+}
+
+// chain calls this, whenever it accepts a tx into the pool
+// stem txs are sent to the stem peer, others are broadcast
+// this must not block, so sending is done in background
+func relay_transaction(tx *transaction.Transaction, stem bool) {
+	if stem {
+		go stem_transaction(tx)
+		return
+	}
+	go Broadcast_Transactions([]*transaction.Transaction{tx}, false)
+}
+
+// rebroadcast txs which stay unconfirmed in pool for long
+// also drops txs from per peer known sets, which are no longer in the pool
+func tx_rebroadcast_loop() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			txs := chain.Mempool.Mempool_Rebroadcast_List(TX_Rebroadcast_Interval)
+			if len(txs) > 0 {
+				logger.Debugf("Rebroadcasting %d unconfirmed txs", len(txs))
+				Broadcast_Transactions(txs, true)
+			}
+
+			for _, connection := range connection_list() {
+				connection.TX_Prune_Known(func(txid crypto.Hash) bool {
+					return chain.Mempool.Mempool_TX_Exist(txid)
+				})
+			}
+		case <-Exit_Event:
+			return
+		}
+	}
 }
@@ -0,0 +1,137 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+import "io"
+import "net"
+import "time"
+import "bytes"
+import "testing"
+import "io/ioutil"
+
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/transaction"
+
+// send txs over a pipe and parse them back the way Handle_BC_Notify_New_Transactions does
+func Test_Send_BC_Notify_New_Transactions(t *testing.T) {
+	var txs []*transaction.Transaction
+	for i := uint64(0); i < 3; i++ {
+		tx := &transaction.Transaction{Version: 2, RctSignature: &ringct.RctSig{}}
+		tx.Vin = append(tx.Vin, transaction.Txin_gen{Height: 1000 + i})
+		tx.Vout = append(tx.Vout, transaction.Tx_out{Target: transaction.Txout_to_key{}})
+		txs = append(txs, tx)
+	}
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	connection := &Connection{Conn: client, Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}}
+	go Send_BC_Notify_New_Transactions(connection, txs)
+
+	header_data := make([]byte, 33, 33)
+	if _, err := io.ReadFull(server, header_data); err != nil {
+		t.Fatalf("reading levin header failed err %s", err)
+	}
+
+	var levin_header Levin_Header
+	if err := levin_header.DeSerialize(header_data); err != nil {
+		t.Fatalf("DeSerialize Levin header Failed err %s", err)
+	}
+	if levin_header.Command != BC_NOTIFY_NEW_TRANSACTIONS || levin_header.Flags != LEVIN_PACKET_REQUEST {
+		t.Fatalf("Invalid levin header %+v", levin_header)
+	}
+
+	data := make([]byte, levin_header.CB, levin_header.CB)
+	if _, err := io.ReadFull(server, data); err != nil {
+		t.Fatalf("reading levin data failed err %s", err)
+	}
+
+	var data_header Levin_Data_Header
+	if err := data_header.DeSerialize(data); err != nil {
+		t.Fatalf("DeSerialize Levin Data header Failed err %s", err)
+	}
+
+	buf := data_header.Data
+	pos := bytes.Index(buf, []byte("\x03txs\x8a"))
+	if pos < 0 {
+		t.Fatalf("txs section missing")
+	}
+	buf = buf[pos+5:]
+
+	tx_count, done := Decode_Boost_Varint(buf)
+	buf = buf[done:]
+	if tx_count != uint64(len(txs)) {
+		t.Fatalf("tx count mismatch expected %d actual %d", len(txs), tx_count)
+	}
+
+	for i := uint64(0); i < tx_count; i++ {
+		var tx transaction.Transaction
+		tx_len, done := Decode_Boost_Varint(buf)
+		buf = buf[done:]
+
+		if err := tx.DeserializeHeader(buf[:tx_len]); err != nil {
+			t.Fatalf("tx %d could not be deserialized err %s", i, err)
+		}
+		if tx.GetHash() != txs[i].GetHash() {
+			t.Errorf("tx %d hash mismatch", i)
+		}
+		buf = buf[tx_len:]
+	}
+
+	// all sent txs must now be known to peer, so they are not echoed again
+	for i := range txs {
+		if !connection.TX_Is_Known(txs[i].GetHash()) {
+			t.Errorf("tx %d should be known to peer", i)
+		}
+	}
+}
+
+// a peer which does not read must not block the sender forever
+func Test_Send_BC_Notify_New_Transactions_Stalled_Peer(t *testing.T) {
+	old_timeout := P2P_Write_Timeout
+	P2P_Write_Timeout = 100 * time.Millisecond
+	defer func() { P2P_Write_Timeout = old_timeout }()
+
+	tx := &transaction.Transaction{Version: 2, RctSignature: &ringct.RctSig{}}
+	tx.Vin = append(tx.Vin, transaction.Txin_gen{Height: 1000})
+	tx.Vout = append(tx.Vout, transaction.Tx_out{Target: transaction.Txout_to_key{}})
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	connection := &Connection{Conn: client, Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}}
+	done := make(chan bool)
+	go func() {
+		Send_BC_Notify_New_Transactions(connection, []*transaction.Transaction{tx})
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sending to stalled peer did not time out")
+	}
+
+	// deadline must be cleared, so later writes are not failed
+	go io.Copy(ioutil.Discard, server)
+	time.Sleep(2 * P2P_Write_Timeout)
+	if _, err := client.Write([]byte{1}); err != nil {
+		t.Fatalf("write after timeout failed err %s", err)
+	}
+}
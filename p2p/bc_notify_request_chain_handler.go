@@ -33,8 +33,9 @@ func Handle_BC_Notify_Chain(connection *Connection,
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		connection.logger.Debugf("We should destroy connection here, data header cnot deserialized")
-		connection.Exit = true
+		connection.logger.Debugf("Data header could not be deserialized, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -47,12 +48,13 @@ func Handle_BC_Notify_Chain(connection *Connection,
 
 	if data_length == 0 {
 		rlog.Tracef(4, "Peer says it does not have even genesis block, so disconnect")
-		connection.Exit = true
+		connection.Set_Exit()
 		return
 	}
 	if (data_length % 32) != 0 { // sanity check
-		rlog.Tracef(4, "We should destroy connection here, packet mismatch")
-		connection.Exit = true
+		rlog.Tracef(4, "Packet mismatch, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "packet mismatch")
+		connection.Set_Exit()
 		return
 	}
 
@@ -76,7 +78,7 @@ func Handle_BC_Notify_Chain(connection *Connection,
 
 	if block_list[len(block_list)-1] != globals.Config.Genesis_Block_Hash {
 		connection.logger.Debugf("Peer's genesis block is different from our, so disconnect")
-		connection.Exit = true
+		connection.Set_Exit()
 		return
 	}
 
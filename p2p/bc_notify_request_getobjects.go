@@ -35,8 +35,9 @@ func Handle_BC_Notify_Request_GetObjects(connection *Connection,
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		connection.logger.Debugf("We should destroy connection here, data header cnot deserialized")
-		connection.Exit = true
+		connection.logger.Debugf("Data header could not be deserialized, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -53,14 +54,15 @@ func Handle_BC_Notify_Request_GetObjects(connection *Connection,
 
 		if data_length == 0 {
 			rlog.Tracef(4, "Peer says it does not have even genesis block, so disconnect")
-			connection.Exit = true
+			connection.Set_Exit()
 			return
 		}
 		rlog.Tracef(4, "Data size %d", data_length)
 
 		if (data_length % 32) != 0 { // sanity check
-			rlog.Tracef(4, "We should destroy connection here, packet mismatch")
-			connection.Exit = true
+			rlog.Tracef(4, "Packet mismatch, disconnecting peer")
+			connection.Misbehaving(SCORE_MALFORMED, "packet mismatch")
+			connection.Set_Exit()
 			return
 		}
 
@@ -72,6 +74,11 @@ func Handle_BC_Notify_Request_GetObjects(connection *Connection,
 			var bhash crypto.Hash
 			copy(bhash[:], tmp_slice[i*32:(i+1)*32])
 
+			if chain.Block_Pruned(bhash) { // we cannot serve full txs of pruned blocks
+				rlog.Tracef(2, "Not serving pruned block %x", bhash[:])
+				continue
+			}
+
 			block_list = append(block_list, bhash)
 
 			rlog.Tracef(4, "%2d hash  %x\n", i, bhash[:])
@@ -89,7 +96,8 @@ func Handle_BC_Notify_Request_GetObjects(connection *Connection,
 
 	}
 
-	// we must give user  data
+	// txs requested outside of blocks are not served, pool txs may still be in stem phase
+	// and serving them would reveal that we are on the stem, see dandelion.go
 
 }
 
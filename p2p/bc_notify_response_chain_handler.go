@@ -52,16 +52,17 @@ func Handle_BC_Notify_Response_Chain_Entry(connection *Connection,
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		//fmt.Printf("We should destroy connection here, data header cnot deserialized")
 		rlog.Tracef(4, "Data header deserialisation failed. Disconnect peer \n")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
 	pos := bytes.Index(i_data_header.Data, []byte("cumulative_difficulty")) // at this point to
 	if pos == -1 {
 		rlog.Tracef(4, "Cumulative difficulty deserialisation failed. Disconnect peer \n")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "cumulative difficulty not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -92,12 +93,13 @@ func Handle_BC_Notify_Response_Chain_Entry(connection *Connection,
 
 	if data_length == 0 {
 		rlog.Tracef(4, "Peer says it does not have even genesis block, so disconnect")
-		connection.Exit = true
+		connection.Set_Exit()
 		return
 	}
 	if (data_length % 32) != 0 { // sanity check
-		rlog.Tracef(2, "We should destroy connection here, packet mismatch")
-		connection.Exit = true
+		rlog.Tracef(2, "Packet mismatch, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "packet mismatch")
+		connection.Set_Exit()
 		return
 	}
 
@@ -108,18 +110,8 @@ func Handle_BC_Notify_Response_Chain_Entry(connection *Connection,
 	for i := uint64(0); i < data_length/32; i++ {
 		var bhash crypto.Hash
 		copy(bhash[:], buf[i*32:(i+1)*32])
-		// only request block that we donot have
-
-		if chain.Get_Height() < 20 {
-			block_list = append(block_list, bhash)
-			rlog.Tracef(5, "%2d hash  %x\n", i, bhash[:])
-		} else {
-			if !chain.Block_Exists(bhash) {
-				block_list = append(block_list, bhash)
-				rlog.Tracef(5, "%2d hash  %x\n", i, bhash[:])
-			}
-		}
-
+		block_list = append(block_list, bhash)
+		rlog.Tracef(5, "%2d hash  %x\n", i, bhash[:])
 	}
 
 	// server will kill us, if we queue more than 1000 blocks
@@ -131,43 +123,12 @@ func Handle_BC_Notify_Response_Chain_Entry(connection *Connection,
 	// if peer provided us a genesis block make sure, its ours
 	if start_height == 0 && block_list[0] != globals.Config.Genesis_Block_Hash {
 		rlog.Tracef(4, "Peer's genesis block is different from our, so disconnect")
-		connection.Exit = true
+		connection.Set_Exit()
 		return
 	}
 
-	// we must queue the hashes so as to fetch them
-
-	//block_list = block_list[:0]
-	var hash crypto.Hash
-
-	/*
-	   //big_block ,_ := hex.DecodeString("9ba23efe505f9674dc24c150edbdbe57abc3ec6636aa4c1659e811b389c0b30b") // zero tx
-	   //big_block ,_ := hex.DecodeString("14371eeddca0f3ce9b992b3e2a0e482920497d87dd20002456f3a844b04a3318") // single tx
-
-	   big_block ,_ := hex.DecodeString("a31a17bb26b2ec37479ee3a02f53dd94860611457811d0c23ce892f4e87e1697") // 4 tx
-
-	   copy(hash[:],big_block[:32])
-	   block_list = append(block_list, hash)
-
-
-	   big_tx, _ := hex.DecodeString("af6f12d56f32f58623a834c4f12c5443976346a2f877ef78798c39496bd00559") //  single tx
-
-
-
-
-
-	   var tx_list []ringct.Hash
-	   copy(hash[:],big_tx[:32])
-	   tx_list = append(tx_list, hash)
-	*/
-	_ = hash
-	var tx_list []crypto.Hash
-
-	/*if len(block_list) > 5 {
-	    block_list= block_list[:5]
-	}*/
-
-	Send_BC_Notify_Request_GetObjects(connection, block_list, tx_list[:0])
+	// the sync manager downloads the blocks we donot have, from all peers in parallel
+	syncer.add_chain(connection, start_height, block_list)
 
 }
 
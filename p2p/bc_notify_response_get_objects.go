@@ -16,6 +16,7 @@
 
 package p2p
 
+import "fmt"
 import "bytes"
 
 import "github.com/romana/rlog"
@@ -39,111 +40,121 @@ import "github.com/arnaucode/derosuite/transaction"
 // FIXME this code can also be shared by NOTIFY_NEW_BLOCK, NOTIFY_NEW_TRANSACTIONS
 
 // we trigger this if we want to request any TX or block from the peer
+// a response carries a span of blocks ( see Send_Blocks_to_Peer ), every block is processed
 func Handle_BC_Notify_Response_GetObjects(connection *Connection,
 	i_command_header *Levin_Header, buf []byte) {
 
-	var bl block.Block
-	var complete_bl block.Complete_Block
-
-	complete_block := false
-
 	// deserialize data header
 	var i_data_header Levin_Data_Header // incoming data header
 
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		log.Debugf("We should destroy connection here, data header cnot deserialized")
+		connection.logger.Debugf("Data header could not be deserialized, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
-	// check whether the response contains block
-	pos := bytes.Index(i_data_header.Data, []byte("blocks")) // at this point to
-
-	buf = i_data_header.Data
-
-	if pos > 0 { // the data contains atleast 1 block
-		pos += 6
-		buf = i_data_header.Data[pos:]
-		pos := bytes.Index(buf, []byte("block"))
-		// find inner position of block
-		pos = pos + 6 // jump to varint length position and decode
-
-		buf = buf[pos:]
-		block_length, done := Decode_Boost_Varint(buf)
-		rlog.Tracef(9, "Block length %d %x\n", block_length, buf[:8])
-		buf = buf[done:]
+	blocks, err := parse_block_entries(i_data_header.Data)
+	if err != nil {
+		log.Debugf("Blocks could not be deserialized successfully err %s\n", err)
+		connection.Misbehaving(SCORE_MALFORMED, "block not deserialized")
+		connection.Set_Exit()
+		return
+	}
 
-		block_buf := buf[:block_length]
+	// at this point, we should try to add the blocks to block chain
+	for _, complete_bl := range blocks {
+		hash := complete_bl.Bl.GetHash()
+		rlog.Tracef(9, "Block deserialized successfully  %x txs %d\n", hash[:32], len(complete_bl.Txs))
 
-		err = bl.Deserialize(block_buf)
-		if err != nil {
-			log.Debugf("Block could not be deserialized successfully err %s\n", err)
-			log.Debugf("We should destroy connection here, block not deserialized")
-			return
+		if syncer.block_received(connection, complete_bl) { // sync manager adds it to chain in height order
+			continue
 		}
-
-		hash := bl.GetHash()
-		rlog.Tracef(9, "Block deserialized successfully  %x\n", hash[:32])
-		rlog.Tracef(9, "Tx hash length %d\n", len(bl.Tx_hashes))
-		for i := range bl.Tx_hashes {
-			rlog.Tracef(9, "%d tx %x\n", i, bl.Tx_hashes[i][:32])
+		if err = chain.Add_Complete_Block_From(complete_bl, connection.Addr.IP.String()); err != nil { // dispatch the event to block chain
+			connection.Misbehaving_Error(err)
+			request_missing_parent(connection, complete_bl.Bl, err)
 		}
-		// point buffer to check whether any more tx exist
-		buf = buf[block_length:]
-		complete_block = true
 	}
+}
 
-	pos = bytes.Index(buf, []byte("\x03txs\x8a")) // at this point to
-
-	if pos > -1 {
-		rlog.Tracef(9, "txt pos %d\n", pos)
-
-		buf = buf[pos+5:]
-		// decode remain data length ( though we know it from buffer size, but still verify it )
-
-		tx_count, done := Decode_Boost_Varint(buf)
-		buf = buf[done:]
-
-		for i := uint64(0); i < tx_count; i++ {
-
-			var tx transaction.Transaction
-
-			tx_len, done := Decode_Boost_Varint(buf)
-			buf = buf[done:]
-			rlog.Tracef(9, "tx count %d  i %d  tx_len %d\n", tx_count, i, tx_len)
-
-			tx_bytes := buf[:tx_len]
-
-			// deserialize and verrify transaction
+// parse all block entries of a response, each entry is a section containing block and its txs
+// 06 blocks 8c <count> { 04 05 block 0a <len> <block> } or { 08 05 block 0a <len> <block> 03 txs 8a <count> <len> <tx>... }
+func parse_block_entries(data []byte) (blocks []*block.Complete_Block, err error) {
+	defer func() { // malformed varints may read beyond buffer
+		if r := recover(); r != nil {
+			blocks = nil
+			err = fmt.Errorf("Recovered while parsing block entries %v", r)
+		}
+	}()
 
-			err = tx.DeserializeHeader(tx_bytes)
+	pos := bytes.Index(data, []byte("\x06blocks\x8c"))
+	if pos < 0 { // response does not contain any block
+		return nil, nil
+	}
+	buf := data[pos+8:]
 
-			if err != nil {
-				log.Debugf("Transaction could not be deserialized\n")
+	block_count, done := Decode_Boost_Varint(buf)
+	buf = buf[done:]
 
-			} else {
-				hash := tx.GetHash()
-				rlog.Tracef(9, "Transaction deserialised successfully  hash %x\n", hash[:32])
+	for i := uint64(0); i < block_count; i++ {
+		var complete_bl block.Complete_Block
 
-				// add tx to block chain, we must verify that the tx has been mined
+		field_count, done := Decode_Boost_Varint(buf)
+		buf = buf[done:]
 
-				complete_bl.Txs = append(complete_bl.Txs, &tx)
-				//chain.Add_TX(&tx)
+		for j := uint64(0); j < field_count; j++ {
+			name_length := int(buf[0])
+			name := string(buf[1 : 1+name_length])
+			field_type := buf[1+name_length]
+			buf = buf[2+name_length:]
+
+			switch {
+			case name == "block" && field_type == 0x0a:
+				var blob []byte
+				if blob, buf, err = read_boost_blob(buf); err != nil {
+					return nil, err
+				}
+				var bl block.Block
+				if err = bl.Deserialize(blob); err != nil {
+					return nil, fmt.Errorf("block %d could not be deserialized err %s", i, err)
+				}
+				complete_bl.Bl = &bl
+
+			case name == "txs" && field_type == 0x8a:
+				tx_count, done := Decode_Boost_Varint(buf)
+				buf = buf[done:]
+				for k := uint64(0); k < tx_count; k++ {
+					var blob []byte
+					if blob, buf, err = read_boost_blob(buf); err != nil {
+						return nil, err
+					}
+					var tx transaction.Transaction
+					if err = tx.DeserializeHeader(blob); err != nil {
+						return nil, fmt.Errorf("block %d tx %d could not be deserialized err %s", i, k, err)
+					}
+					complete_bl.Txs = append(complete_bl.Txs, &tx)
+				}
+
+			default:
+				return nil, fmt.Errorf("block %d has unknown field %s type %x", i, name, field_type)
 			}
+		}
 
-			buf = buf[tx_len:] // setup for next tx
-
+		if complete_bl.Bl == nil {
+			return nil, fmt.Errorf("entry %d does not contain block", i)
 		}
+		blocks = append(blocks, &complete_bl)
 	}
+	return blocks, nil
+}
 
-	// at this point, if it's a block we should try to add it to block chain
-	if complete_block {
-		// add block to chain
-		log.Debugf("Found a block we should add it to our chain\n")
-		//chain.Chain_Add(&bl)
-		complete_bl.Bl = &bl
-		chain.Add_Complete_Block(&complete_bl) // dispatch the event to block chain
+// read a varint length prefixed blob
+func read_boost_blob(buf []byte) (blob []byte, rest []byte, err error) {
+	length, done := Decode_Boost_Varint(buf)
+	if uint64(len(buf)-done) < length {
+		return nil, nil, fmt.Errorf("blob length %d exceeds available %d bytes", length, len(buf)-done)
 	}
-
+	return buf[done : done+int(length)], buf[done+int(length):], nil
 }
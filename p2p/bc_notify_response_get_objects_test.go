@@ -0,0 +1,132 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+import "bytes"
+import "testing"
+import "encoding/binary"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/transaction"
+
+// serialize a block entry, the same way boost_serialisation_block does
+func test_block_entry(bl *block.Block, txs []*transaction.Transaction) []byte {
+	varint := make([]byte, 8, 8)
+	entry := []byte{0x04, 0x05, 'b', 'l', 'o', 'c', 'k', 0x0a}
+	if len(txs) >= 1 {
+		entry[0] = 0x08
+	}
+	blob := bl.Serialize()
+	entry = append(entry, varint[:Encode_Boost_Varint(varint, uint64(len(blob)))]...)
+	entry = append(entry, blob...)
+	if len(txs) >= 1 {
+		entry = append(entry, 0x03, 't', 'x', 's', 0x8a)
+		entry = append(entry, varint[:Encode_Boost_Varint(varint, uint64(len(txs)))]...)
+		for _, tx := range txs {
+			blob := tx.Serialize()
+			entry = append(entry, varint[:Encode_Boost_Varint(varint, uint64(len(blob)))]...)
+			entry = append(entry, blob...)
+		}
+	}
+	return entry
+}
+
+// a full span of blocks in a single response must be parsed completely
+func Test_Parse_Block_Entries(t *testing.T) {
+	var blocks []*block.Block
+	var block_txs [][]*transaction.Transaction
+	for i := 0; i < SYNC_SPAN_SIZE; i++ {
+		var bl block.Block
+		bl.Miner_tx = transaction.Transaction{Version: 2, RctSignature: &ringct.RctSig{}}
+		bl.Miner_tx.Vin = append(bl.Miner_tx.Vin, transaction.Txin_gen{Height: uint64(i)})
+		bl.Miner_tx.Vout = append(bl.Miner_tx.Vout, transaction.Tx_out{Target: transaction.Txout_to_key{}})
+		bl.Nonce = uint32(i)
+
+		var txs []*transaction.Transaction
+		for j := 0; j < i%3; j++ { // some blocks carry txs, some do not
+			tx := &transaction.Transaction{Version: 2, RctSignature: &ringct.RctSig{}}
+			tx.Vin = append(tx.Vin, transaction.Txin_gen{Height: uint64(1000*i + j)})
+			tx.Vout = append(tx.Vout, transaction.Tx_out{Target: transaction.Txout_to_key{}})
+			bl.Tx_hashes = append(bl.Tx_hashes, tx.GetHash())
+			txs = append(txs, tx)
+		}
+		blocks = append(blocks, &bl)
+		block_txs = append(block_txs, txs)
+	}
+
+	// levin data as built by Send_Blocks_to_Peer
+	data := []byte{0x01, 0x11, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x08, 0x06, 'b', 'l', 'o', 'c', 'k', 's', 0x8c}
+	varint := make([]byte, 8, 8)
+	data = append(data, varint[:Encode_Boost_Varint(varint, uint64(len(blocks)))]...)
+	for i := range blocks {
+		data = append(data, test_block_entry(blocks[i], block_txs[i])...)
+	}
+	data = append(data, 0x19)
+	data = append(data, []byte("current_blockchain_height")...)
+	data = append(data, 0x05)
+	binary.LittleEndian.PutUint64(varint, 12345)
+	data = append(data, varint...)
+
+	var o_command_header Levin_Header
+	o_command_header.CB = uint64(len(data))
+	o_command_header.Command = BC_NOTIFY_RESPONSE_GET_OBJECTS
+	o_command_header.Flags = LEVIN_PACKET_REQUEST
+	packet, _ := o_command_header.Serialize()
+	packet = append(packet, data...)
+
+	// parse it back as the connection handler does
+	var i_command_header Levin_Header
+	if err := DeSerializeLevinHeader(packet[:33], &i_command_header); err != nil {
+		t.Fatalf("levin header could not be deserialized err %s", err)
+	}
+	if i_command_header.Command != BC_NOTIFY_RESPONSE_GET_OBJECTS || i_command_header.CB != uint64(len(data)) {
+		t.Fatalf("invalid levin header %+v", i_command_header)
+	}
+	var i_data_header Levin_Data_Header
+	if err := i_data_header.DeSerialize(packet[33:]); err != nil {
+		t.Fatalf("levin data header could not be deserialized err %s", err)
+	}
+
+	parsed, err := parse_block_entries(i_data_header.Data)
+	if err != nil {
+		t.Fatalf("block entries could not be parsed err %s", err)
+	}
+	if len(parsed) != len(blocks) {
+		t.Fatalf("expected %d blocks, parsed %d", len(blocks), len(parsed))
+	}
+	for i := range parsed {
+		if parsed[i].Bl.GetHash() != blocks[i].GetHash() || len(parsed[i].Txs) != len(block_txs[i]) {
+			t.Fatalf("block %d mismatch", i)
+		}
+		for j := range parsed[i].Txs {
+			if parsed[i].Txs[j].GetHash() != block_txs[i][j].GetHash() {
+				t.Fatalf("block %d tx %d mismatch", i, j)
+			}
+		}
+	}
+
+	// truncated response must be reported as error, not panic
+	if _, err := parse_block_entries(i_data_header.Data[:len(i_data_header.Data)/2]); err == nil {
+		t.Fatalf("truncated response parsed successfully")
+	}
+
+	// response without blocks is fine
+	if parsed, err := parse_block_entries(bytes.Repeat([]byte{0}, 16)); err != nil || len(parsed) != 0 {
+		t.Fatalf("response without blocks failed %d err %v", len(parsed), err)
+	}
+}
@@ -16,6 +16,8 @@
 
 package p2p
 
+import "fmt"
+import "math"
 import "encoding/binary"
 
 // boost varint can encode upto 1 gb size
@@ -86,3 +88,115 @@ func Encode_Boost_Varint(buf []byte, Value uint64) int {
 	return int(bytes_required)
 
 }
+
+// boost portable storage types
+const BOOST_SERIALIZE_TYPE_INT64 = 1
+const BOOST_SERIALIZE_TYPE_INT32 = 2
+const BOOST_SERIALIZE_TYPE_INT16 = 3
+const BOOST_SERIALIZE_TYPE_INT8 = 4
+const BOOST_SERIALIZE_TYPE_UINT64 = 5
+const BOOST_SERIALIZE_TYPE_UINT32 = 6
+const BOOST_SERIALIZE_TYPE_UINT16 = 7
+const BOOST_SERIALIZE_TYPE_UINT8 = 8
+const BOOST_SERIALIZE_TYPE_DOUBLE = 9
+const BOOST_SERIALIZE_TYPE_STRING = 10
+const BOOST_SERIALIZE_TYPE_BOOL = 11
+const BOOST_SERIALIZE_TYPE_OBJECT = 12
+const BOOST_SERIALIZE_FLAG_ARRAY = 0x80
+
+// decode boost varint with bounds checking, returns value and position after the varint
+func boost_read_varint(buf []byte, pos int) (value uint64, next int, err error) {
+	if pos >= len(buf) {
+		return 0, pos, fmt.Errorf("Insufficient buffer for varint")
+	}
+	size := 1 << (buf[pos] & 3) // 1, 2, 4 or 8 bytes
+	if pos+size > len(buf) {
+		return 0, pos, fmt.Errorf("Insufficient buffer for varint")
+	}
+	for i := size - 1; i >= 0; i-- {
+		value = value<<8 | uint64(buf[pos+i])
+	}
+	return value >> 2, pos + size, nil
+}
+
+// decode a single value of given type, objects are returned as map[string]interface{} and arrays as []interface{}
+func boost_read_value(buf []byte, pos int, value_type byte) (value interface{}, next int, err error) {
+	if value_type&BOOST_SERIALIZE_FLAG_ARRAY != 0 {
+		count, pos, err := boost_read_varint(buf, pos)
+		if err != nil {
+			return nil, pos, err
+		}
+		if count > uint64(len(buf)-pos) { // every element takes atleast 1 byte
+			return nil, pos, fmt.Errorf("Invalid array count %d", count)
+		}
+		list := make([]interface{}, 0, count)
+		for i := uint64(0); i < count; i++ {
+			if value, pos, err = boost_read_value(buf, pos, value_type&^BOOST_SERIALIZE_FLAG_ARRAY); err != nil {
+				return nil, pos, err
+			}
+			list = append(list, value)
+		}
+		return list, pos, nil
+	}
+
+	fixed_size := map[byte]int{BOOST_SERIALIZE_TYPE_INT64: 8, BOOST_SERIALIZE_TYPE_INT32: 4, BOOST_SERIALIZE_TYPE_INT16: 2,
+		BOOST_SERIALIZE_TYPE_INT8: 1, BOOST_SERIALIZE_TYPE_UINT64: 8, BOOST_SERIALIZE_TYPE_UINT32: 4,
+		BOOST_SERIALIZE_TYPE_UINT16: 2, BOOST_SERIALIZE_TYPE_UINT8: 1, BOOST_SERIALIZE_TYPE_DOUBLE: 8, BOOST_SERIALIZE_TYPE_BOOL: 1}
+	if size, ok := fixed_size[value_type]; ok {
+		if pos+size > len(buf) {
+			return nil, pos, fmt.Errorf("Insufficient buffer for type %d", value_type)
+		}
+		data := buf[pos : pos+size]
+		switch value_type {
+		case BOOST_SERIALIZE_TYPE_INT64:
+			value = int64(binary.LittleEndian.Uint64(data))
+		case BOOST_SERIALIZE_TYPE_INT32:
+			value = int32(binary.LittleEndian.Uint32(data))
+		case BOOST_SERIALIZE_TYPE_INT16:
+			value = int16(binary.LittleEndian.Uint16(data))
+		case BOOST_SERIALIZE_TYPE_INT8:
+			value = int8(data[0])
+		case BOOST_SERIALIZE_TYPE_UINT64:
+			value = binary.LittleEndian.Uint64(data)
+		case BOOST_SERIALIZE_TYPE_UINT32:
+			value = binary.LittleEndian.Uint32(data)
+		case BOOST_SERIALIZE_TYPE_UINT16:
+			value = binary.LittleEndian.Uint16(data)
+		case BOOST_SERIALIZE_TYPE_UINT8:
+			value = data[0]
+		case BOOST_SERIALIZE_TYPE_DOUBLE:
+			value = math.Float64frombits(binary.LittleEndian.Uint64(data))
+		case BOOST_SERIALIZE_TYPE_BOOL:
+			value = data[0] != 0
+		}
+		return value, pos + size, nil
+	}
+
+	switch value_type {
+	case BOOST_SERIALIZE_TYPE_STRING:
+		length, pos, err := boost_read_varint(buf, pos)
+		if err != nil || length > uint64(len(buf)-pos) {
+			return nil, pos, fmt.Errorf("Insufficient buffer for string")
+		}
+		return buf[pos : pos+int(length)], pos + int(length), nil
+
+	case BOOST_SERIALIZE_TYPE_OBJECT:
+		count, pos, err := boost_read_varint(buf, pos)
+		if err != nil {
+			return nil, pos, err
+		}
+		object := map[string]interface{}{}
+		for i := uint64(0); i < count; i++ {
+			if pos >= len(buf) || pos+1+int(buf[pos])+1 > len(buf) {
+				return nil, pos, fmt.Errorf("Insufficient buffer for object key")
+			}
+			key := string(buf[pos+1 : pos+1+int(buf[pos])])
+			pos += 1 + int(buf[pos])
+			if object[key], pos, err = boost_read_value(buf, pos+1, buf[pos]); err != nil {
+				return nil, pos, err
+			}
+		}
+		return object, pos, nil
+	}
+	return nil, pos, fmt.Errorf("unknown boost encoding '0x%02x'", value_type)
+}
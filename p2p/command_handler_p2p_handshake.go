@@ -17,7 +17,6 @@
 package p2p
 
 import "fmt"
-import "net"
 import "time"
 
 import "github.com/romana/rlog"
@@ -32,7 +31,7 @@ func Send_Handshake(connection *Connection) {
 
 	// first request support flags
 
-	if connection.Exit {
+	if connection.Is_Exit() {
 		return
 	}
 
@@ -47,7 +46,9 @@ func Send_Handshake(connection *Connection) {
 	var levin_header Levin_Header
 
 	d.Network_UUID = globals.Config.Network_ID
-	d.Peer_ID = (uint64)(time.Now().Unix())
+	d.Peer_ID = OUR_PEER_ID
+	d.Local_Port = our_Port // advertise our listening port, so peer can connect back
+	d.Local_time = uint64(time.Now().Unix())
 	c.Current_Height = chain.Get_Height()
 	c.Cumulative_Difficulty = chain.Get_Difficulty()
 	c.Top_Version = 6
@@ -95,6 +96,7 @@ func Handle_P2P_Handshake_Command(connection *Connection,
 
 	reply.NodeData.Network_UUID = globals.Config.Network_ID
 	reply.NodeData.Peer_ID = (uint64)(OUR_PEER_ID)
+	reply.NodeData.Local_Port = our_Port
 	reply.NodeData.Local_time = uint64(time.Now().Unix())
 
 	reply.CoreData.Current_Height = chain.Get_Height()
@@ -106,10 +108,7 @@ func Handle_P2P_Handshake_Command(connection *Connection,
 	// this data must be the top block that we see till now
 	reply.CoreData.Top_ID = chain.Get_Top_ID()
 
-	for i := 0; i < 250; i++ {
-		reply.PeerArray = append(reply.PeerArray,
-			Peer_Info{IP: net.IPv4(byte(i), byte(i), byte(i), byte(i)), Port: 0, ID: 0, LastSeen: 0})
-	}
+	reply.PeerArray = peer_list_for_handshake()
 
 	var o_command_header Levin_Header
 	var o_data_header Levin_Data_Header
@@ -155,7 +154,8 @@ func Handle_P2P_Handshake_Command_Response(connection *Connection,
 		connection.logger.WithFields(log.Fields{
 			"ip": connection.Addr.IP,
 		}).Debugf("Disconnecting client, handshake could not be deserialized")
-
+		connection.Misbehaving(SCORE_MALFORMED, "handshake not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -164,7 +164,8 @@ func Handle_P2P_Handshake_Command_Response(connection *Connection,
 		logger.WithFields(log.Fields{
 			"ip": connection.Addr.IP,
 		}).Debugf("Disconnecting client, handshake could not be deserialized")
-
+		connection.Misbehaving(SCORE_MALFORMED, "handshake not deserialized")
+		connection.Set_Exit()
 		return
 	}
 
@@ -174,11 +175,21 @@ func Handle_P2P_Handshake_Command_Response(connection *Connection,
 			"ip": connection.Addr.IP,
 			"id": reply.NodeData.Network_UUID,
 		}).Debugf("Disconnecting client, Wrong network ID")
-
+		connection.Set_Exit()
 		return
 
 	}
 
+	// we connected to ourselves, never try this address again
+	if reply.NodeData.Peer_ID == OUR_PEER_ID {
+		connection.logger.Debugf("Disconnecting, we connected to ourselves")
+		if !connection.Incoming {
+			peer_remove(connection.Addr.String())
+		}
+		connection.Set_Exit()
+		return
+	}
+
 	// we need to kick the peer if the height is something specific and peer id is less than ours
 	// TODO right we are not doing it
 	connection.Peer_ID = reply.NodeData.Peer_ID
@@ -195,6 +206,16 @@ func Handle_P2P_Handshake_Command_Response(connection *Connection,
 		"Top_ID":      fmt.Sprintf("%x", reply.CoreData.Top_ID),
 	}).Debugf("Successful Handshake with Peer")
 
+	// peers we could connect to go to white list, incoming peers only advertise their port, so they go to grey list
+	if !connection.Incoming {
+		peer_add_white_list(connection.Addr.String(), reply.NodeData.Peer_ID)
+	} else if reply.NodeData.Local_Port != 0 {
+		peer_add_grey_list([]Peer_Info{{IP: connection.Addr.IP, Port: reply.NodeData.Local_Port, ID: reply.NodeData.Peer_ID, LastSeen: uint64(time.Now().Unix())}})
+	}
+	peer_add_grey_list(reply.PeerArray)
+
+	Send_SupportFlags_Command(connection) // find out whether peer is pruned
+
 	// lets check whether we need to resync with this peer
 	if chain.IsLagging(reply.CoreData.Cumulative_Difficulty, reply.CoreData.Current_Height, reply.CoreData.Top_ID) {
 		logger.Debugf("We need to resync with the peer")
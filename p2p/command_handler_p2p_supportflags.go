@@ -16,9 +16,16 @@
 
 package p2p
 
+import "bytes"
 import "encoding/binary"
 
-var FLAGS_VALUE uint32 = 0 // we donot support fluffly blocks at this point in time
+import "github.com/romana/rlog"
+
+const P2P_SUPPORT_FLAG_FLUFFY_BLOCKS = 0x01 // we donot support fluffly blocks at this point in time
+const P2P_SUPPORT_FLAG_V2 = 0x40            // peer accepts p2pv2 connections on its levin port + p2pv2 port offset
+const P2P_SUPPORT_FLAG_PRUNED = 0x80        // peer is pruned, it cannot serve txs of blocks deeper than config.PRUNE_DEPTH_MIN
+
+var FLAGS_VALUE uint32 = 0 // set during init, depending on our mode
 var support_response_bytes = [29]byte{0x01, 0x11, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x04, 0x0d, 0x73, 0x75, 0x70, 0x70,
 	0x6f, 0x72, 0x74, 0x5f, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x06, 0x00, 0x00, 0x00, 0x00}
 
@@ -32,8 +39,9 @@ func Handle_P2P_Support_Flags(connection *Connection,
 	err := i_data_header.DeSerialize(buf)
 
 	if err != nil {
-		connection.logger.Debugf("We should destroy connection here, data header cnot deserialized")
-		connection.Exit = true
+		connection.logger.Debugf("Data header could not be deserialized, disconnecting peer")
+		connection.Misbehaving(SCORE_MALFORMED, "data header not deserialized")
+		connection.Set_Exit()
 		return
 	}
 	// make sure  data is length 10
@@ -57,6 +65,25 @@ func Handle_P2P_Support_Flags(connection *Connection,
 
 }
 
+// support_flags key with type uint32, value follows
+var support_flags_key = []byte("\x0dsupport_flags\x06")
+
+// handle response of P2P_COMMAND_REQUEST_SUPPORT_FLAGS
+func Handle_P2P_Support_Flags_Response(connection *Connection,
+	i_command_header *Levin_Header, buf []byte) {
+
+	pos := bytes.Index(buf, support_flags_key)
+	if pos < 0 || len(buf) < pos+len(support_flags_key)+4 {
+		connection.logger.Debugf("Support flags response could not be parsed")
+		return
+	}
+	connection.Support_Flags = binary.LittleEndian.Uint32(buf[pos+len(support_flags_key):])
+	rlog.Tracef(2, "Peer support flags 0x%x", connection.Support_Flags)
+	if !connection.Incoming { // peer list is keyed by address we connected to, so next connection can use v2
+		peer_set_flags(connection.Addr.String(), connection.Support_Flags)
+	}
+}
+
 // send the hand shake
 func Send_SupportFlags_Command(connection *Connection) {
 
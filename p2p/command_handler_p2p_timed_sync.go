@@ -49,7 +49,8 @@ func Handle_P2P_Timed_Sync(connection *Connection,
 
 	if err != nil {
 		log.Debugf("Invalid P2P_COMMAND_TIMED_SYNC_T, disconnecting peer")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "invalid timed sync")
+		connection.Set_Exit()
 		return
 	}
 
@@ -61,19 +62,21 @@ func Handle_P2P_Timed_Sync(connection *Connection,
 
 	if pos < 0 {
 		log.Debugf("Invalid P2P_COMMAND_TIMED_SYNC_T, disconnecting peer")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "invalid timed sync")
+		connection.Set_Exit()
 		return
 	}
 	err = peer_core_data.DeSerialize(i_data_header.Data[pos-1:])
 
 	if err != nil {
 		log.Debugf("Invalid P2P_COMMAND_TIMED_SYNC_T, disconnecting peer")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "invalid timed sync")
+		connection.Set_Exit()
 		return
 
 	}
 
-	rlog.Trace(5, "Incoming core data %+v \n", peer_core_data)
+	rlog.Tracef(5, "Incoming core data %+v \n", peer_core_data)
 
 	// TODO if cumulative difficulty at this top mismatches ours start resync
 	// if height is more than ours, start resync
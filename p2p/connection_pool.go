@@ -23,6 +23,8 @@ package p2p
 import "fmt"
 import "net"
 import "sync"
+import "sync/atomic"
+import "time"
 import "container/list"
 
 import log "github.com/sirupsen/logrus"
@@ -40,20 +42,23 @@ const (
 
 // This structure is used to do book keeping for the connection and keeps other DATA related to peer
 type Connection struct {
-	Incoming              bool         // is connection incoming or outgoing
-	Addr                  *net.TCPAddr // endpoint on the other end
-	Port                  uint32       // port advertised by other end as its server,if it's 0 server cannot accept connections
-	Peer_ID               uint64       // Remote peer id
-	Last_Height           uint64       // last height sent by peer
-	Top_Version           uint64       // current hard fork version supported by peer
-	Exit                  bool         // Exit marker that connection needs to be killed
-	State                 Conn_State   // state of the connection
-	Top_ID                crypto.Hash  // top block id of the connection
-	Cumulative_Difficulty uint64       // cumulative difficulty of top block of peer
-	logger                *log.Entry   // connection specific logger
-	Requested_Objects     [][32]byte   // currently unused as we sync up with a single peer at a time
-	Conn                  net.Conn     // actual object to talk
-	Command_queue         *list.List   // LEVIN protocol is syncronous
+	Incoming              bool                 // is connection incoming or outgoing
+	Addr                  *net.TCPAddr         // endpoint on the other end
+	Port                  uint32               // port advertised by other end as its server,if it's 0 server cannot accept connections
+	Peer_ID               uint64               // Remote peer id
+	Last_Height           uint64               // last height sent by peer
+	Top_Version           uint64               // current hard fork version supported by peer
+	exit                  uint32               // Exit marker that connection needs to be killed, use Set_Exit/Is_Exit
+	State                 Conn_State           // state of the connection
+	Top_ID                crypto.Hash          // top block id of the connection
+	Cumulative_Difficulty uint64               // cumulative difficulty of top block of peer
+	logger                *log.Entry           // connection specific logger
+	Conn                  net.Conn             // actual object to talk
+	Command_queue         *list.List           // LEVIN protocol is syncronous
+	TXs_Known             map[crypto.Hash]bool // txs sent to or received from peer, used to avoid echo loops
+	Score                 int32                // misbehaviour score, peer is banned once it reaches P2P_BAN_SCORE_THRESHOLD
+	Support_Flags         uint32               // flags advertised by peer, see P2P_SUPPORT_FLAG_*
+	Created               time.Time            // when the connection was made
 	sync.Mutex
 }
 
@@ -89,11 +94,62 @@ func Connection_Delete(c *Connection) {
 	delete(connection_map, Key(c.Addr.IP))
 }
 
+// return a snapshot of all connections, so callers do not hold the pool lock while talking to peers
+func connection_list() (list []*Connection) {
+	connection_mutex.Lock()
+	defer connection_mutex.Unlock()
+	for _, v := range connection_map {
+		list = append(list, v)
+	}
+	return
+}
+
+// mark the connection to be killed, this is safe to call from any goroutine
+func (c *Connection) Set_Exit() {
+	atomic.StoreUint32(&c.exit, 1)
+}
+
+// whether the connection needs to be killed
+func (c *Connection) Is_Exit() bool {
+	return atomic.LoadUint32(&c.exit) != 0
+}
+
+// mark a tx as known to peer
+func (c *Connection) TX_Mark_Known(txid crypto.Hash) {
+	c.Lock()
+	defer c.Unlock()
+	if c.TXs_Known == nil {
+		c.TXs_Known = map[crypto.Hash]bool{}
+	}
+	c.TXs_Known[txid] = true
+}
+
+// check whether a tx has already been sent to or received from peer
+func (c *Connection) TX_Is_Known(txid crypto.Hash) bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.TXs_Known[txid]
+}
+
+// drop all known txs for which keep returns false
+func (c *Connection) TX_Prune_Known(keep func(crypto.Hash) bool) {
+	c.Lock()
+	defer c.Unlock()
+	for txid := range c.TXs_Known {
+		if !keep(txid) {
+			delete(c.TXs_Known, txid)
+		}
+	}
+}
+
 // prints all the connection info to screen
 func Connection_Print() {
 	connection_mutex.Lock()
 	defer connection_mutex.Unlock()
 	fmt.Printf("Connection info for peers\n")
+	if queued, in_flight := syncer.status(); queued > 0 {
+		fmt.Printf("Syncing %d blocks, %d spans in flight\n", queued, in_flight)
+	}
 	fmt.Printf("%-20s %-16s %-5s %-7s %9s %3s\n", "Remote Addr", "PEER ID", "PORT", " State", "Height", "DIR")
 	for _, v := range connection_map {
 		dir := "OUT"
@@ -144,3 +200,29 @@ func Peer_Direction_Count() (Incoming uint64, Outgoing uint64) {
 	connection_mutex.Unlock()
 	return
 }
+
+// snapshot of a connection, used by rpc to list connections
+type Connection_Info struct {
+	Addr           *net.TCPAddr
+	Port           uint32 // port advertised by peer
+	Peer_ID        uint64
+	Incoming       bool
+	Height         uint64
+	State          string
+	Support_Flags  uint32
+	Created        time.Time
+	Bytes_Sent     uint64 // not tracked by levin connections
+	Bytes_Received uint64
+}
+
+// details of all connections which have completed handshake
+func Connection_List() (list []Connection_Info) {
+	for _, v := range connection_list() {
+		if v.State == HANDSHAKE_PENDING {
+			continue
+		}
+		list = append(list, Connection_Info{Addr: v.Addr, Port: v.Port, Peer_ID: v.Peer_ID, Incoming: v.Incoming,
+			Height: v.Last_Height, State: string(v.State), Support_Flags: v.Support_Flags, Created: v.Created})
+	}
+	return
+}
@@ -16,9 +16,14 @@
 
 package p2p
 
+import "fmt"
 import "net"
+import "sync"
 import "time"
+import "strconv"
 import "sync/atomic"
+import "encoding/binary"
+import cryptorand "crypto/rand"
 
 import log "github.com/sirupsen/logrus"
 
@@ -30,82 +35,243 @@ var chain *blockchain.Blockchain // external reference to chain
 var Exit_Event = make(chan bool) // causes all threads to exit
 var Exit_In_Progress bool        // marks we are doing exit
 var logger *log.Entry            // global logger, every logger in this package is a child of this
+var p2p_bind_address string      // p2p server listens on this address
+
+// p2pv2 registers this, so outgoing connections are made using v2 protocol if peer supports it
+// it returns false, if v2 connection could not be established and levin must be used
+var Connect_V2 func(remote_addr *net.TCPAddr) bool
 
 // Initialize P2P subsystem
 func P2P_Init(params map[string]interface{}) error {
 	logger = globals.Logger.WithFields(log.Fields{"com": "P2P"}) // all components must use this logger
 	chain = params["chain"].(*blockchain.Blockchain)
-	go P2P_engine()    // start outgoing engine
-	go P2P_Server_v1() // start accepting connections
+
+	if _, ok := globals.Arguments["--tx-rebroadcast"]; ok { // check if parameter is supported
+		if globals.Arguments["--tx-rebroadcast"] != nil {
+			seconds, err := strconv.ParseUint(globals.Arguments["--tx-rebroadcast"].(string), 10, 64)
+			if err != nil || seconds == 0 {
+				logger.Warnf("Invalid --tx-rebroadcast value, using default %s", TX_Rebroadcast_Interval)
+			} else {
+				TX_Rebroadcast_Interval = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	if _, ok := globals.Arguments["--ban-time"]; ok && globals.Arguments["--ban-time"] != nil {
+		seconds, err := strconv.ParseUint(globals.Arguments["--ban-time"].(string), 10, 64)
+		if err != nil || seconds == 0 {
+			logger.Warnf("Invalid --ban-time value, using default %s", Ban_Duration)
+		} else {
+			Ban_Duration = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if chain.Is_Pruned() { // peers should not ask us for old blocks
+		FLAGS_VALUE |= P2P_SUPPORT_FLAG_PRUNED
+	}
+
+	// p2p server listens on network specific default port, unless user provided an address
+	// --p2p-bind-port is still supported for old setups
+	p2p_bind_address = fmt.Sprintf("0.0.0.0:%d", globals.Config.P2P_Default_Port)
+	if _, ok := globals.Arguments["--p2p-bind-port"]; ok && globals.Arguments["--p2p-bind-port"] != nil {
+		p2p_bind_address = "0.0.0.0:" + globals.Arguments["--p2p-bind-port"].(string)
+	}
+	if _, ok := globals.Arguments["--p2p-bind"]; ok && globals.Arguments["--p2p-bind"] != nil {
+		p2p_bind_address = globals.Arguments["--p2p-bind"].(string)
+	}
+	address, err := globals.ParseBindAddress(p2p_bind_address)
+	if err != nil {
+		logger.Fatalf("Could not setup p2p server err %s", err)
+	}
+	p2p_bind_address = address
+
+	// our listening port is advertised to peers in handshake
+	_, port, _ := net.SplitHostPort(p2p_bind_address)
+	port_value, _ := strconv.ParseUint(port, 10, 16)
+	our_Port = uint32(port_value)
+
+	// random peer id, used by peers to identify us and by us to detect connections to ourselves
+	var peer_id [8]byte
+	cryptorand.Read(peer_id[:])
+	OUR_PEER_ID = binary.LittleEndian.Uint64(peer_id[:])
+
+	load_peer_list(peer_file())
+	ban_filename = ban_file()
+	load_ban_list(ban_filename)
+
+	chain.P2P_TX_Relayer = relay_transaction    // chain relays accepted txs through us
+	chain.P2P_Block_Relayer = relay_block       // chain broadcasts mined blocks through us
+	chain.P2P_Orphan_Expired = orphan_expired   // chain reports peers whose orphans never connected
+	chain.P2P_Orphan_Rejected = orphan_rejected // chain reports peers whose orphans were invalid
+
+	// stem txs restored from disk get a fresh embargo, so they are fluffed if their stem got lost
+	for _, txid := range chain.Mempool.Mempool_List_Stem_TX() {
+		dandelion.embargo_add(txid, time.Now())
+	}
+
+	go P2P_engine()          // start outgoing engine
+	go P2P_Server_v1()       // start accepting connections
+	go tx_rebroadcast_loop() // rebroadcast unconfirmed txs
+	go sync_loop()           // download blocks from peers in parallel
+	go dandelion_loop()      // fluff stem txs whose embargo expired
 	logger.Infof("P2P started")
 	atomic.AddUint32(&globals.Subsystem_Active, 1) // increment subsystem
 	return nil
 }
 
+// keeps outgoing connections upto the target count
+// if exclusive nodes are given, we connect only to them
+// priority nodes are always kept connected, other connections are picked from peer lists or seed nodes
 func P2P_engine() {
+	exclusive_nodes := argument_list("--add-exclusive-node")
+	priority_nodes := argument_list("--add-priority-node")
 
-	// if user provided ips at command line , use them, currently we use only the first one
-
-	var end_point_list []string
-
-	if _, ok := globals.Arguments["--add-exclusive-node"]; ok { // check if parameter is supported
-		if globals.Arguments["--add-exclusive-node"] != nil {
-			tmp_list := globals.Arguments["--add-exclusive-node"].([]string)
-			for i := range tmp_list {
-				end_point_list = append(end_point_list, tmp_list[i])
-			}
-		}
+	seed_nodes := seed_nodes_mainnet
+	if !globals.IsMainnet() {
+		seed_nodes = seed_nodes_testnet
 	}
-
-	// add hard-coded seeds
-	//end_point_list = append(end_point_list, "212.8.242.60:18090")
-	end_point_list = append(end_point_list, "127.0.0.1:18090")
+	seed_last_try := map[string]time.Time{}
 
 	for {
 		if Exit_In_Progress {
 			return
 		}
-		//remote_addr := "localhost:18090"
-		//remote_addr := "192.168.56.1:18090"
 
-		remote_addr := end_point_list[0]
-
-		remote_ip, err := net.ResolveTCPAddr("tcp", remote_addr)
+		if len(exclusive_nodes) > 0 {
+			for _, address := range exclusive_nodes {
+				if !is_connecting(address) {
+					go connect_to(address)
+				}
+			}
+		} else {
+			for _, address := range priority_nodes {
+				if !is_connecting(address) {
+					go connect_to(address)
+				}
+			}
 
-		if err != nil {
-			if Exit_In_Progress {
-				return
+			if outgoing_count() < P2P_DEFAULT_CONNECTIONS_COUNT {
+				address := peer_pick_candidate(func(address string) bool {
+					return is_connecting(address) || is_address_banned(address)
+				})
+				if address == "" { // we donot know any usable peers, ask the seed nodes
+					for _, seed := range seed_nodes {
+						if !is_connecting(seed) && time.Since(seed_last_try[seed]) > P2P_PEER_RETRY_DELAY*time.Second {
+							seed_last_try[seed] = time.Now()
+							address = seed
+							break
+						}
+					}
+				}
+				if address != "" {
+					go connect_to(address)
+				}
 			}
-			logger.Debugf("Resolve address failed:", err.Error())
-			time.Sleep(2 * time.Second)
-			continue
 		}
 
-		// since we may be connecting through socks, grab the remote ip for our purpose rightnow
-		conn, err := globals.Dialer.Dial("tcp", remote_ip.String())
-		if err != nil {
-			if Exit_In_Progress {
-				return
-			}
-			logger.Debugf("Dial failed err %s", err.Error())
-			time.Sleep(2 * time.Second)
-			continue
+		select {
+		case <-Exit_Event:
+			return
+		case <-time.After(2 * time.Second):
 		}
+	}
+}
 
-		logger.Debugf("Connection established to %s", remote_ip)
-		Handle_Connection(conn, remote_ip, false) // handle  connection
-		time.Sleep(4 * time.Second)
+// addresses we are currently connected to or connecting to, through connect_to
+var outgoing_map = map[string]bool{}
+var outgoing_mutex sync.Mutex
+
+// returns number of outgoing connections including those still connecting
+func outgoing_count() int {
+	outgoing_mutex.Lock()
+	defer outgoing_mutex.Unlock()
+	return len(outgoing_map)
+}
+
+// check whether we are already connected to or connecting to this address
+func is_connecting(address string) bool {
+	outgoing_mutex.Lock()
+	connecting := outgoing_map[address]
+	outgoing_mutex.Unlock()
+	if connecting {
+		return true
 	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			return IsConnected(ip)
+		}
+	}
+	return false
+}
+
+// connect to the peer and handle the connection, returns when connection is closed
+func connect_to(address string) {
+	outgoing_mutex.Lock()
+	if outgoing_map[address] {
+		outgoing_mutex.Unlock()
+		return
+	}
+	outgoing_map[address] = true
+	outgoing_mutex.Unlock()
 
+	defer func() {
+		outgoing_mutex.Lock()
+		delete(outgoing_map, address)
+		outgoing_mutex.Unlock()
+	}()
+
+	remote_ip, err := net.ResolveTCPAddr("tcp", address)
+	if err != nil {
+		logger.Debugf("Resolve address %s failed: %s", address, err)
+		peer_failed(address)
+		return
+	}
+
+	if Is_Banned(remote_ip.IP) {
+		logger.Debugf("Not connecting to %s, it is banned", address)
+		return
+	}
+
+	// prefer v2 protocol if peer advertised it, connection is handled there till it closes
+	if Connect_V2 != nil && peer_supports_v2(address) && Connect_V2(remote_ip) {
+		return
+	}
+
+	// since we may be connecting through socks, grab the remote ip for our purpose rightnow
+	conn, err := globals.Dialer.Dial("tcp", remote_ip.String())
+	if err != nil {
+		logger.Debugf("Dial %s failed err %s", address, err)
+		peer_failed(address)
+		return
+	}
+
+	logger.Debugf("Connection established to %s", remote_ip)
+	Handle_Connection(conn, remote_ip, false) // handle  connection
+}
+
+// returns list of values for a repeatable command line argument
+func argument_list(name string) (list []string) {
+	if _, ok := globals.Arguments[name]; ok { // check if parameter is supported
+		if globals.Arguments[name] != nil {
+			list = append(list, globals.Arguments[name].([]string)...)
+		}
+	}
+	return
+}
+
+// address on which p2p server listens, p2pv2 server listens on a port relative to it
+func Bind_Address() string {
+	return p2p_bind_address
 }
 
 func P2P_Server_v1() {
 
 	// listen to incoming tcp connections
-	l, err := net.Listen("tcp", "0.0.0.0:30000")
+	l, err := net.Listen("tcp", p2p_bind_address)
 	if err != nil {
-		logger.Fatalf("Could not listen on port 30000, errr %s", err)
+		logger.Fatalf("Could not listen on %s, errr %s", p2p_bind_address, err)
 	}
+	logger.Infof("P2P server listening on %s", p2p_bind_address)
 	defer l.Close()
 
 	// p2p is shutting down, close the listening socket
@@ -125,6 +291,11 @@ func P2P_Server_v1() {
 			continue
 		}
 		raddr := conn.RemoteAddr().(*net.TCPAddr)
+		if Is_Banned(raddr.IP) {
+			logger.Debugf("Rejecting connection from banned ip %s", raddr.IP)
+			conn.Close()
+			continue
+		}
 		go Handle_Connection(conn, raddr, true) // handle connection in a different go routine
 	}
 
@@ -136,6 +307,8 @@ func P2P_Shutdown() {
 	close(Exit_Event) // send signal to all connections to exit
 	// TODO we  must wait for connections to kill themselves
 	time.Sleep(1 * time.Second)
+	save_peer_list(peer_file())
+	save_ban_list(ban_filename)
 	logger.Infof("P2P Shutdown")
 	atomic.AddUint32(&globals.Subsystem_Active, ^uint32(0)) // this decrement 1 fom subsystem
 
@@ -0,0 +1,191 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+/* this file implements dandelion style tx propagation, so the origin of a tx cannot be found by watching the network
+ * stem phase: tx is forwarded to a single peer, chosen randomly every epoch
+ * fluff phase: tx is broadcast to all peers, as it used to be
+ * every epoch, a node decides by coin flip, whether it continues stems or fluffs them
+ * txs created locally are always stemmed, a stem which stalls is fluffed once its embargo expires
+ */
+
+import "sync"
+import "time"
+import "math/rand"
+
+import "github.com/romana/rlog"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/transaction"
+
+const DANDELION_EPOCH = 600            // seconds, stem peer and fluff decision change every epoch
+const DANDELION_FLUFF_PROBABILITY = 10 // percent, chance that we fluff all stem txs received during an epoch
+const DANDELION_EMBARGO_MIN = 30       // seconds, stem txs not seen fluffed within embargo are fluffed by us
+const DANDELION_EMBARGO_RANDOM = 30    // seconds, random part of embargo, so the origin does not always fluff first
+
+// p2pv2 registers this, so its outgoing peers can also be chosen as stem peers
+// it returns a function per peer address, which sends a stem tx to that peer
+var Stem_Peers_V2 func() map[string]func(tx *transaction.Transaction)
+
+type dandelion_state struct {
+	sync.Mutex
+	epoch_end time.Time                 // current epoch expires at this time
+	fluff     bool                      // during this epoch, stem txs received are fluffed
+	stem_peer string                    // address of the stem peer for this epoch
+	embargo   map[crypto.Hash]time.Time // stem txs are fluffed by us after this time
+}
+
+var dandelion dandelion_state
+
+// start a new epoch if the current one has expired
+// caller must hold the lock
+func (d *dandelion_state) epoch(now time.Time) {
+	if now.Before(d.epoch_end) {
+		return
+	}
+	d.epoch_end = now.Add(DANDELION_EPOCH * time.Second)
+	d.fluff = rand.Intn(100) < DANDELION_FLUFF_PROBABILITY
+	d.stem_peer = ""
+}
+
+// whether stem txs received from peers during this epoch should be fluffed
+func (d *dandelion_state) fluff_epoch(now time.Time) bool {
+	d.Lock()
+	defer d.Unlock()
+	d.epoch(now)
+	return d.fluff
+}
+
+// returns the stem peer for this epoch, a new one is picked if the current one is no longer connected
+// returns empty string if there are no candidates
+func (d *dandelion_state) pick_stem_peer(now time.Time, candidates map[string]func(tx *transaction.Transaction)) string {
+	d.Lock()
+	defer d.Unlock()
+	d.epoch(now)
+
+	if _, ok := candidates[d.stem_peer]; ok {
+		return d.stem_peer
+	}
+
+	d.stem_peer = ""
+	if len(candidates) > 0 {
+		var list []string
+		for address := range candidates {
+			list = append(list, address)
+		}
+		d.stem_peer = list[rand.Intn(len(list))]
+	}
+	return d.stem_peer
+}
+
+// start embargo timer for a stem tx
+func (d *dandelion_state) embargo_add(txid crypto.Hash, now time.Time) {
+	d.Lock()
+	defer d.Unlock()
+	if d.embargo == nil {
+		d.embargo = map[crypto.Hash]time.Time{}
+	}
+	if _, ok := d.embargo[txid]; !ok {
+		d.embargo[txid] = now.Add(time.Duration(DANDELION_EMBARGO_MIN+rand.Intn(DANDELION_EMBARGO_RANDOM+1)) * time.Second)
+	}
+}
+
+// remove and return txs whose embargo has expired
+func (d *dandelion_state) embargo_expired(now time.Time) (list []crypto.Hash) {
+	d.Lock()
+	defer d.Unlock()
+	for txid, until := range d.embargo {
+		if !now.Before(until) {
+			list = append(list, txid)
+			delete(d.embargo, txid)
+		}
+	}
+	return
+}
+
+// outgoing peers which completed handshake, they can be used as stem peers
+// incoming peers are not used, since an attacker can easily make many incoming connections
+func stem_candidates() map[string]func(tx *transaction.Transaction) {
+	candidates := map[string]func(tx *transaction.Transaction){}
+	for _, connection := range connection_list() {
+		if connection.Incoming || connection.State == HANDSHAKE_PENDING || connection.Is_Exit() {
+			continue
+		}
+		c := connection
+		candidates[c.Addr.String()] = func(tx *transaction.Transaction) {
+			Send_BC_Notify_New_Transactions_Stem(c, []*transaction.Transaction{tx})
+		}
+	}
+	if Stem_Peers_V2 != nil {
+		for address, send := range Stem_Peers_V2() {
+			candidates[address] = send
+		}
+	}
+	return candidates
+}
+
+// a stem tx has been received from a peer, depending on the epoch either continue the stem or fluff it
+// p2pv2 also uses this for stem txs received by it
+func Receive_Stem_Transaction(tx *transaction.Transaction) error {
+	if dandelion.fluff_epoch(time.Now()) {
+		return chain.Add_TX_To_Pool(tx)
+	}
+	return chain.Add_TX_To_Pool_Stem(tx)
+}
+
+// send the stem tx to the stem peer, if there is no stem peer, fluff it immediately
+func stem_transaction(tx *transaction.Transaction) {
+	now := time.Now()
+	txid := tx.GetHash()
+	candidates := stem_candidates()
+	address := dandelion.pick_stem_peer(now, candidates)
+	if address == "" {
+		rlog.Tracef(2, "No stem peer available, fluffing tx %s", txid)
+		fluff_transaction(txid)
+		return
+	}
+
+	rlog.Tracef(2, "Stemming tx %s to %s", txid, address)
+	dandelion.embargo_add(txid, now)
+	candidates[address](tx)
+}
+
+// make a stem tx public and broadcast it to all peers
+func fluff_transaction(txid crypto.Hash) {
+	if tx := chain.Mempool.Mempool_Fluff_TX(txid); tx != nil && chain.P2P_TX_Relayer != nil {
+		chain.P2P_TX_Relayer(tx, false) // relayer is shared with p2pv2, so it broadcasts on both
+	}
+}
+
+// fluff stem txs whose embargo has expired, the stem must have stalled somewhere
+func dandelion_loop() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, txid := range dandelion.embargo_expired(time.Now()) {
+				if chain.Mempool.Mempool_TX_Is_Stem(txid) {
+					logger.Debugf("Embargo expired for stem tx %s, fluffing it", txid)
+					fluff_transaction(txid)
+				}
+			}
+		case <-Exit_Event:
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+import "time"
+import "testing"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/transaction"
+
+func Test_Dandelion(t *testing.T) {
+	var d dandelion_state
+	now := time.Now()
+	noop := func(tx *transaction.Transaction) {}
+
+	if d.pick_stem_peer(now, nil) != "" {
+		t.Fatalf("No stem peer should be picked without candidates")
+	}
+
+	candidates := map[string]func(tx *transaction.Transaction){"1.1.1.1:18090": noop, "2.2.2.2:18090": noop}
+	peer := d.pick_stem_peer(now, candidates)
+	if _, ok := candidates[peer]; !ok {
+		t.Fatalf("Stem peer must be one of the candidates")
+	}
+
+	// stem peer stays the same for the entire epoch
+	for i := 0; i < 20; i++ {
+		if d.pick_stem_peer(now.Add(time.Duration(i)*time.Second), candidates) != peer {
+			t.Fatalf("Stem peer changed within epoch")
+		}
+	}
+
+	// stem peer disconnected, another one must be picked
+	delete(candidates, peer)
+	if next := d.pick_stem_peer(now, candidates); next == peer || next == "" {
+		t.Fatalf("Disconnected stem peer must be replaced")
+	}
+
+	// coin flip is made once per epoch
+	fluff := d.fluff_epoch(now)
+	for i := 0; i < 20; i++ {
+		if d.fluff_epoch(now) != fluff {
+			t.Fatalf("Fluff decision changed within epoch")
+		}
+	}
+	epoch_end := d.epoch_end
+	d.fluff_epoch(now.Add(DANDELION_EPOCH * time.Second))
+	if !d.epoch_end.After(epoch_end) {
+		t.Fatalf("New epoch did not start")
+	}
+
+	var txid crypto.Hash
+	txid[0] = 1
+	d.embargo_add(txid, now)
+	if len(d.embargo_expired(now.Add((DANDELION_EMBARGO_MIN-1)*time.Second))) != 0 {
+		t.Fatalf("Embargo expired too early")
+	}
+	if list := d.embargo_expired(now.Add((DANDELION_EMBARGO_MIN + DANDELION_EMBARGO_RANDOM) * time.Second)); len(list) != 1 || list[0] != txid {
+		t.Fatalf("Embargo did not expire")
+	}
+	if len(d.embargo) != 0 {
+		t.Fatalf("Expired embargo must be removed")
+	}
+}
@@ -78,6 +78,8 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 	connection.Addr = remote_addr         //  since we may be connecting via socks, get target IP
 	connection.Command_queue = list.New() // init command queue
 	connection.State = HANDSHAKE_PENDING
+	connection.Score = ip_score(remote_addr.IP) // peer carries its score across connections
+	connection.Created = time.Now()
 	if incoming {
 		connection.logger = logger.WithFields(log.Fields{"RIP": remote_addr.String(), "DIR": "INC"})
 	} else {
@@ -86,9 +88,11 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 
 	defer func() {
 		if r := recover(); r != nil {
-			connection.logger.Warnf("Recovered while handling connection, Stack trace below", r)
+			connection.logger.Warnf("Recovered while handling connection, Stack trace below %+v", r)
 			connection.logger.Warnf("Stack trace  \n%s", debug.Stack())
-
+			connection.Misbehaving(SCORE_MALFORMED, "malformed data")
+			connection.Set_Exit()
+			conn.Close()
 		}
 	}()
 
@@ -114,13 +118,13 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 					idle = 0
 				}
 			case <-Exit_Event: // p2p is shutting down, close the connection
-				connection.Exit = true
+				connection.Set_Exit()
 				ticker.Stop() // release resources of timer
 				Connection_Delete(&connection)
 				conn.Close()
 				return // close the connection and close the routine
 			}
-			if connection.Exit { // release resources of timer
+			if connection.Is_Exit() { // release resources of timer
 				ticker.Stop()
 				Connection_Delete(&connection)
 				return
@@ -129,7 +133,7 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 	}()
 
 	for {
-		if connection.Exit {
+		if connection.Is_Exit() {
 			connection.logger.Debugf("Connection exited")
 			conn.Close()
 			return
@@ -144,7 +148,7 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 
 		if err != nil {
 			rlog.Tracef(4, "Error while reading levin header exiting err:%s\n", err)
-			connection.Exit = true
+			connection.Set_Exit()
 			continue
 		}
 		rlog.Tracef(10, "Read %d bytes from network\n", read_bytes)
@@ -157,7 +161,8 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 
 		if err != nil {
 			rlog.Tracef(4, "Error while DeSerializing levin header exiting err:%s\n", err)
-			connection.Exit = true
+			connection.Misbehaving(SCORE_MALFORMED, "invalid levin header")
+			connection.Set_Exit()
 			continue
 		}
 
@@ -169,14 +174,15 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 		rlog.Tracef(10, "Read %d bytes from network for data \n", read_bytes)
 		if err != nil {
 			rlog.Tracef(4, "Error while reading levin data exiting err:%s\n", err)
-			connection.Exit = true
+			connection.Set_Exit()
 			continue
 		}
 
 		name := COMMAND_NAME[levin_header.Command]
 		if name == "" {
 			connection.logger.Warnf("No Such command %d exiting\n", levin_header.Command)
-			connection.Exit = true
+			connection.Misbehaving(SCORE_PROTOCOL, "unknown command")
+			connection.Set_Exit()
 			continue
 		}
 
@@ -187,14 +193,16 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 		if levin_header.Flags == LEVIN_PACKET_RESPONSE {
 			if connection.Command_queue.Len() < 1 {
 				connection.logger.Warnf("Invalid Response ( we have not queued anything\n")
-				connection.Exit = true
+				connection.Misbehaving(SCORE_PROTOCOL, "unexpected response")
+				connection.Set_Exit()
 				continue
 			}
 
 			front_command := connection.Command_queue.Front()
 			if levin_header.Command != front_command.Value.(uint32) {
 				connection.logger.Warnf("Invalid Response (  we queued some other command\n")
-				connection.Exit = true
+				connection.Misbehaving(SCORE_PROTOCOL, "unexpected response")
+				connection.Set_Exit()
 				continue
 			}
 
@@ -213,7 +221,8 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 
 			case P2P_COMMAND_PING: // we never send ping packets
 
-			case P2P_COMMAND_REQUEST_SUPPORT_FLAGS: // we never send flags packet
+			case P2P_COMMAND_REQUEST_SUPPORT_FLAGS: // we ask flags after handshake
+				Handle_P2P_Support_Flags_Response(&connection, &levin_header, data)
 
 			}
 
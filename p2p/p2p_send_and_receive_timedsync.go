@@ -153,7 +153,8 @@ func Handle_P2P_Timed_Sync_Response(connection *Connection,
 
 	if err != nil {
 		connection.logger.Debugf("Invalid Levin Data header, disconnecting peer")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "invalid timed sync response")
+		connection.Set_Exit()
 		return
 	}
 
@@ -162,19 +163,27 @@ func Handle_P2P_Timed_Sync_Response(connection *Connection,
 
 	if pos < 0 {
 		connection.logger.Debugf("Invalid P2P_COMMAND_TIMED_SYNC_T, could not find payload_data, disconnecting peer")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "invalid timed sync response")
+		connection.Set_Exit()
 		return
 	}
 	err = peer_core_data.DeSerialize(i_data_header.Data[pos-1:])
 
 	if err != nil {
 		connection.logger.Debugf("Invalid P2P_COMMAND_TIMED_SYNC_T, could not deserialize core_data, disconnecting peer")
-		connection.Exit = true
+		connection.Misbehaving(SCORE_MALFORMED, "invalid timed sync response")
+		connection.Set_Exit()
 		return
 
 	}
 
-	rlog.Trace(5, "Incoming core data %+v \n", peer_core_data)
+	rlog.Tracef(5, "Incoming core data %+v \n", peer_core_data)
+
+	// merge peers sent by peer and mark peer as alive
+	peer_add_grey_list(parse_peerlist(i_data_header.Data))
+	if !connection.Incoming {
+		peer_seen(connection.Addr.String())
+	}
 
 	// lets check whether we need to resync with this peer
 	if chain.IsLagging(peer_core_data.Cumulative_Difficulty, peer_core_data.Current_Height, peer_core_data.Top_ID) {
@@ -0,0 +1,452 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+/* this file implements peer list management
+ * peers we have successfully connected to are kept in white list
+ * peers we have only heard about from other peers are kept in grey list
+ * both lists are saved to disk at shutdown and loaded back at startup
+ */
+
+import "os"
+import "net"
+import "bytes"
+import "sort"
+import "sync"
+import "time"
+import "strconv"
+import "io/ioutil"
+import "math/rand"
+import "encoding/binary"
+
+import "github.com/vmihailenco/msgpack"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/globals"
+
+// these are same as cryptonote_config.h
+const P2P_LOCAL_WHITE_PEERLIST_LIMIT = 1000
+const P2P_LOCAL_GRAY_PEERLIST_LIMIT = 5000
+const P2P_DEFAULT_PEERS_IN_HANDSHAKE = 250
+const P2P_DEFAULT_CONNECTIONS_COUNT = 8 // we try to keep these many outgoing connections
+
+// failed peers are not retried before this many seconds
+const P2P_PEER_RETRY_DELAY = 60
+
+// grey peers failing these many times in a row are removed
+const P2P_PEER_MAX_FAILURES = 3
+
+// seed nodes are used when we donot know any other peers
+var seed_nodes_mainnet = []string{"212.8.242.60:18090"}
+var seed_nodes_testnet = []string{}
+
+// every known peer is tracked as below
+type Peer struct {
+	Address  string // ip:port
+	ID       uint64 // peer id as reported by peer
+	LastSeen uint64 // last time, peer was seen active in epoch format
+	Failures uint32 // number of failed connection attempts in a row
+	LastTry  uint64 // last time we tried to connect in epoch format
+	Flags    uint32 // support flags advertised by peer, see P2P_SUPPORT_FLAG_*
+}
+
+// this is how peer lists are saved to disk
+type peer_state struct {
+	White []Peer
+	Grey  []Peer
+}
+
+var peer_mutex sync.Mutex
+var white_list = map[string]*Peer{}
+var grey_list = map[string]*Peer{}
+
+// returns filename where peers are saved
+func peer_file() string {
+	return globals.Network_Filename(config.P2P_NET_DATA_FILENAME)
+}
+
+// load peers saved at previous run
+func load_peer_list(filename string) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Error while loading peers from %s err %s", filename, err)
+		}
+		return
+	}
+
+	var state peer_state
+	if err = msgpack.Unmarshal(data, &state); err != nil {
+		logger.Warnf("Error while deserializing peers from %s err %s", filename, err)
+		return
+	}
+
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+	for i := range state.White {
+		p := state.White[i]
+		white_list[p.Address] = &p
+	}
+	for i := range state.Grey {
+		p := state.Grey[i]
+		if _, ok := white_list[p.Address]; !ok {
+			grey_list[p.Address] = &p
+		}
+	}
+	logger.Infof("Loaded %d white and %d grey peers from %s", len(white_list), len(grey_list), filename)
+}
+
+// save peers to disk, so we donot need to depend on seed nodes next time
+func save_peer_list(filename string) {
+	var state peer_state
+
+	peer_mutex.Lock()
+	for _, p := range white_list {
+		state.White = append(state.White, *p)
+	}
+	for _, p := range grey_list {
+		state.Grey = append(state.Grey, *p)
+	}
+	peer_mutex.Unlock()
+
+	data, err := msgpack.Marshal(&state)
+	if err != nil {
+		logger.Warnf("Error while serializing peers err %s", err)
+		return
+	}
+
+	// write to a temporary file first, so a crash does not leave a half written peer list
+	tmp_filename := filename + ".tmp"
+	if err = ioutil.WriteFile(tmp_filename, data, 0600); err != nil {
+		logger.Warnf("Error while saving peers to %s err %s", tmp_filename, err)
+		return
+	}
+	if err = os.Rename(tmp_filename, filename); err != nil {
+		logger.Warnf("Error while saving peers to %s err %s", filename, err)
+		return
+	}
+	logger.Debugf("Saved %d white and %d grey peers to %s", len(state.White), len(state.Grey), filename)
+}
+
+// reject addresses which cannot be used to connect to peer
+func is_peer_address_valid(ip net.IP, port uint32) bool {
+	if ip == nil || ip.To4() == nil || port == 0 || port > 65535 {
+		return false
+	}
+	if ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	// loopback is useless on mainnet, but testnets are often run with several nodes on a single machine
+	if ip.IsLoopback() && globals.IsMainnet() {
+		return false
+	}
+	return true
+}
+
+// add peers received from other peers to grey list, peers already in white list are only refreshed
+func peer_add_grey_list(peers []Peer_Info) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+
+	for i := range peers {
+		if !is_peer_address_valid(peers[i].IP, peers[i].Port) {
+			continue
+		}
+		address := net.JoinHostPort(peers[i].IP.String(), strconv.Itoa(int(peers[i].Port)))
+		if p, ok := white_list[address]; ok {
+			if peers[i].LastSeen > p.LastSeen && peers[i].LastSeen <= uint64(time.Now().Unix()) {
+				p.LastSeen = peers[i].LastSeen
+			}
+			continue
+		}
+		if p, ok := grey_list[address]; ok {
+			if peers[i].LastSeen > p.LastSeen && peers[i].LastSeen <= uint64(time.Now().Unix()) {
+				p.LastSeen = peers[i].LastSeen
+			}
+			continue
+		}
+		grey_list[address] = &Peer{Address: address, ID: peers[i].ID, LastSeen: peers[i].LastSeen}
+	}
+	trim_peer_list(grey_list, P2P_LOCAL_GRAY_PEERLIST_LIMIT)
+}
+
+// a successful handshake moves the peer to white list
+func peer_add_white_list(address string, id uint64) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+
+	p, ok := white_list[address]
+	if !ok {
+		if p, ok = grey_list[address]; ok {
+			delete(grey_list, address)
+		} else {
+			p = &Peer{Address: address}
+		}
+		white_list[address] = p
+	}
+	p.ID = id
+	p.LastSeen = uint64(time.Now().Unix())
+	p.Failures = 0
+	trim_peer_list(white_list, P2P_LOCAL_WHITE_PEERLIST_LIMIT)
+}
+
+// update last seen time of a white peer
+func peer_seen(address string) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+	if p, ok := white_list[address]; ok {
+		p.LastSeen = uint64(time.Now().Unix())
+	}
+}
+
+// remember support flags advertised by peer
+func peer_set_flags(address string, flags uint32) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+	if p, ok := white_list[address]; ok {
+		p.Flags = flags
+	} else if p, ok := grey_list[address]; ok {
+		p.Flags = flags
+	}
+}
+
+// whether peer advertised p2pv2 support, unknown peers are connected using levin
+func peer_supports_v2(address string) bool {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+	if p, ok := white_list[address]; ok {
+		return p.Flags&P2P_SUPPORT_FLAG_V2 != 0
+	}
+	if p, ok := grey_list[address]; ok {
+		return p.Flags&P2P_SUPPORT_FLAG_V2 != 0
+	}
+	return false
+}
+
+// connection to peer failed, white peers are moved to grey list, grey peers are removed after some failures
+func peer_failed(address string) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+
+	if p, ok := white_list[address]; ok {
+		delete(white_list, address)
+		p.Failures = 1
+		grey_list[address] = p
+		return
+	}
+	if p, ok := grey_list[address]; ok {
+		p.Failures++
+		if p.Failures >= P2P_PEER_MAX_FAILURES {
+			delete(grey_list, address)
+		}
+	}
+}
+
+// remove a peer from both lists, used when we connect to ourselves
+func peer_remove(address string) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+	delete(white_list, address)
+	delete(grey_list, address)
+}
+
+// keep only the most recently seen peers, caller must hold the lock
+func trim_peer_list(list map[string]*Peer, limit int) {
+	if len(list) <= limit {
+		return
+	}
+	peers := make([]*Peer, 0, len(list))
+	for _, p := range list {
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].LastSeen > peers[j].LastSeen })
+	for _, p := range peers[limit:] {
+		delete(list, p.Address)
+	}
+}
+
+// returns most recently seen white peers, these are sent to other peers in handshake
+func peer_list_for_handshake() (peers []Peer_Info) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+
+	list := make([]*Peer, 0, len(white_list))
+	for _, p := range white_list {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastSeen > list[j].LastSeen })
+
+	for _, p := range list {
+		if len(peers) >= P2P_DEFAULT_PEERS_IN_HANDSHAKE {
+			break
+		}
+		host, port_str, err := net.SplitHostPort(p.Address)
+		if err != nil {
+			continue
+		}
+		port, err := strconv.ParseUint(port_str, 10, 16)
+		ip := net.ParseIP(host)
+		if err != nil || ip == nil || ip.To4() == nil {
+			continue
+		}
+		peers = append(peers, Peer_Info{IP: ip, Port: uint32(port), ID: p.ID, LastSeen: p.LastSeen})
+	}
+	return
+}
+
+// peers known to us, p2pv2 advertises these in its handshake
+func Peer_List() []Peer_Info {
+	return peer_list_for_handshake()
+}
+
+// merge peers learnt through p2pv2
+func Peer_List_Add(peers []Peer_Info) {
+	peer_add_grey_list(peers)
+}
+
+// pick a peer to connect to, white peers are preferred over grey ones
+// skip tells whether an address is already connected or being connected
+// returns empty string if no peer is available
+func peer_pick_candidate(skip func(address string) bool) string {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+
+	now := uint64(time.Now().Unix())
+	for _, list := range []map[string]*Peer{white_list, grey_list} {
+		var candidates []*Peer
+		for _, p := range list {
+			if p.LastTry+P2P_PEER_RETRY_DELAY > now || skip(p.Address) {
+				continue
+			}
+			candidates = append(candidates, p)
+		}
+		if len(candidates) > 0 {
+			p := candidates[rand.Intn(len(candidates))]
+			p.LastTry = now
+			return p.Address
+		}
+	}
+	return ""
+}
+
+// returns count of peers in white and grey list
+func Peer_List_Count() (white int, grey int) {
+	peer_mutex.Lock()
+	defer peer_mutex.Unlock()
+	return len(white_list), len(grey_list)
+}
+
+// peer lists are exchanged in 2 formats, local_peerlist is an old packed format with 24 bytes per peer
+// local_peerlist_new is an array of objects, both are serialized for compatibility with old daemons
+func serialize_peerlist(peers []Peer_Info) []byte {
+	var buf bytes.Buffer
+	var varint [4]byte
+	var entry [24]byte
+
+	var ipv4_peers []Peer_Info
+	for i := range peers {
+		if peers[i].IP.To4() != nil {
+			ipv4_peers = append(ipv4_peers, peers[i])
+		}
+	}
+
+	buf.WriteString("\x0elocal_peerlist\x0a")
+	buf.Write(varint[:Encode_Boost_Varint(varint[:], uint64(len(ipv4_peers)*24))])
+	for _, p := range ipv4_peers {
+		copy(entry[0:], p.IP.To4())
+		binary.LittleEndian.PutUint32(entry[4:], p.Port)
+		binary.LittleEndian.PutUint64(entry[8:], p.ID)
+		binary.LittleEndian.PutUint64(entry[16:], p.LastSeen)
+		buf.Write(entry[:])
+	}
+
+	buf.WriteString("\x12local_peerlist_new")
+	buf.WriteByte(BOOST_SERIALIZE_TYPE_OBJECT | BOOST_SERIALIZE_FLAG_ARRAY)
+	buf.Write(varint[:Encode_Boost_Varint(varint[:], uint64(len(ipv4_peers)))])
+	for _, p := range ipv4_peers {
+		buf.WriteByte(0x0c) // 3 fields adr, id, last_seen
+		buf.WriteString("\x03adr\x0c\x08")
+		buf.WriteString("\x23template as<ipv4_network_address>()\x0c\x08")
+		buf.WriteString("\x04m_ip\x06")
+		buf.Write(p.IP.To4())
+		buf.WriteString("\x06m_port\x07")
+		binary.LittleEndian.PutUint16(entry[:], uint16(p.Port))
+		buf.Write(entry[:2])
+		buf.WriteString("\x04type\x08\x01") // 1 is ipv4
+		buf.WriteString("\x02id\x05")
+		binary.LittleEndian.PutUint64(entry[:], p.ID)
+		buf.Write(entry[:8])
+		buf.WriteString("\x09last_seen\x01")
+		binary.LittleEndian.PutUint64(entry[:], p.LastSeen)
+		buf.Write(entry[:8])
+	}
+	return buf.Bytes()
+}
+
+// extract peers from handshake or timed sync data, local_peerlist_new is preferred
+// malformed peer lists are ignored, since peer list is not mandatory
+func parse_peerlist(buf []byte) (peers []Peer_Info) {
+	if pos := bytes.Index(buf, []byte("\x12local_peerlist_new")); pos >= 0 && pos+20 < len(buf) {
+		value, _, err := boost_read_value(buf, pos+20, buf[pos+19])
+		if err != nil {
+			return nil
+		}
+		entries, _ := value.([]interface{})
+		for i := range entries {
+			entry, _ := entries[i].(map[string]interface{})
+			if p, ok := parse_peerlist_entry(entry); ok {
+				peers = append(peers, p)
+			}
+		}
+		return peers
+	}
+
+	if pos := bytes.Index(buf, []byte("\x0elocal_peerlist\x0a")); pos >= 0 {
+		length, pos, err := boost_read_varint(buf, pos+16)
+		if err != nil || pos+int(length) > len(buf) {
+			return nil
+		}
+		for i := pos; i+24 <= pos+int(length); i += 24 {
+			peers = append(peers, Peer_Info{IP: net.IPv4(buf[i], buf[i+1], buf[i+2], buf[i+3]),
+				Port:     binary.LittleEndian.Uint32(buf[i+4:]),
+				ID:       binary.LittleEndian.Uint64(buf[i+8:]),
+				LastSeen: binary.LittleEndian.Uint64(buf[i+16:])})
+		}
+	}
+	return peers
+}
+
+// a single entry of local_peerlist_new looks like
+// { adr: { "template as<ipv4_network_address>()": { m_ip, m_port }, type }, id, last_seen }
+func parse_peerlist_entry(entry map[string]interface{}) (p Peer_Info, ok bool) {
+	adr, _ := entry["adr"].(map[string]interface{})
+	for _, v := range adr {
+		address, _ := v.(map[string]interface{})
+		ip, ip_ok := address["m_ip"].(uint32)
+		port, port_ok := address["m_port"].(uint16)
+		if ip_ok && port_ok {
+			p.IP = net.IPv4(byte(ip), byte(ip>>8), byte(ip>>16), byte(ip>>24))
+			p.Port = uint32(port)
+			ok = true
+		}
+	}
+	p.ID, _ = entry["id"].(uint64)
+	if last_seen, last_seen_ok := entry["last_seen"].(int64); last_seen_ok && last_seen > 0 {
+		p.LastSeen = uint64(last_seen)
+	}
+	return
+}
@@ -0,0 +1,157 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+import "os"
+import "net"
+import "testing"
+import "path/filepath"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/globals"
+
+// peers must survive serialization in both old and new formats
+func Test_Peerlist_Serdes(t *testing.T) {
+	peers := []Peer_Info{{IP: net.IPv4(209, 205, 211, 138), Port: 18090, ID: 0x2512521a245a141e, LastSeen: 1512712758},
+		{IP: net.IPv4(95, 215, 19, 16), Port: 20000, ID: 0xa0400c8c4a3249fa, LastSeen: 1512712753}}
+
+	check := func(name string, parsed []Peer_Info) {
+		if len(parsed) != len(peers) {
+			t.Fatalf("%s: expected %d peers, got %d", name, len(peers), len(parsed))
+		}
+		for i := range peers {
+			if !parsed[i].IP.Equal(peers[i].IP) || parsed[i].Port != peers[i].Port || parsed[i].ID != peers[i].ID || parsed[i].LastSeen != peers[i].LastSeen {
+				t.Errorf("%s: peer %d mismatch %+v expected %+v", name, i, parsed[i], peers[i])
+			}
+		}
+	}
+
+	serialized := serialize_peerlist(peers)
+	check("local_peerlist_new", parse_peerlist(serialized))
+
+	// only old format is present
+	pos := len("\x0elocal_peerlist\x0a") + 2 + 24*len(peers)
+	check("local_peerlist", parse_peerlist(serialized[:pos]))
+
+	// truncated peer lists must not panic
+	for i := range serialized {
+		parse_peerlist(serialized[pos : pos+i/2])
+	}
+
+	var response Node_Data_Response
+	response.PeerArray = peers
+	response.NodeData.Local_Port = 18090
+	response.CoreData.Current_Height = 99
+	data, _ := response.Serialize()
+
+	var parsed_response Node_Data_Response
+	if err := parsed_response.DeSerialize(data); err != nil {
+		t.Fatalf("handshake response deserialization failed err %s", err)
+	}
+	check("handshake", parsed_response.PeerArray)
+	if parsed_response.NodeData.Local_Port != 18090 || parsed_response.CoreData.Current_Height != 99 {
+		t.Errorf("handshake response node data mismatch")
+	}
+}
+
+// test white/grey list management and persistence
+func Test_Peerlist_Management(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+	globals.Config = config.Mainnet
+	white_list = map[string]*Peer{}
+	grey_list = map[string]*Peer{}
+
+	peer_add_grey_list([]Peer_Info{{IP: net.IPv4(1, 2, 3, 4), Port: 18090, ID: 1},
+		{IP: net.IPv4(127, 0, 0, 1), Port: 18090}, // loopback from remote is useless
+		{IP: net.IPv4(5, 6, 7, 8), Port: 0}})      // peer does not accept connections
+
+	if white, grey := Peer_List_Count(); white != 0 || grey != 1 {
+		t.Fatalf("expected 0 white and 1 grey peer, got %d %d", white, grey)
+	}
+
+	// nothing is white, so grey peer is picked, it is not picked again till retry delay
+	if address := peer_pick_candidate(func(string) bool { return false }); address != "1.2.3.4:18090" {
+		t.Errorf("grey peer should be picked, got %s", address)
+	}
+	if address := peer_pick_candidate(func(string) bool { return false }); address != "" {
+		t.Errorf("peer should not be retried immediately, got %s", address)
+	}
+
+	peer_add_white_list("1.2.3.4:18090", 7)
+	if white, grey := Peer_List_Count(); white != 1 || grey != 0 {
+		t.Fatalf("peer should have moved to white list, got %d %d", white, grey)
+	}
+	if list := peer_list_for_handshake(); len(list) != 1 || list[0].Port != 18090 || list[0].ID != 7 {
+		t.Errorf("white peer should be shared in handshake %+v", list)
+	}
+
+	// v2 is used only with peers which advertised it
+	if peer_supports_v2("1.2.3.4:18090") || peer_supports_v2("9.9.9.9:18090") {
+		t.Errorf("peer without flags should not be dialed using v2")
+	}
+	peer_set_flags("1.2.3.4:18090", P2P_SUPPORT_FLAG_V2|P2P_SUPPORT_FLAG_PRUNED)
+
+	filename := filepath.Join(os.TempDir(), "derod_test_p2pstate.bin")
+	defer os.Remove(filename)
+	save_peer_list(filename)
+	if _, err := os.Stat(filename + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary peer file should have been renamed")
+	}
+	white_list = map[string]*Peer{}
+	load_peer_list(filename)
+	if white, _ := Peer_List_Count(); white != 1 {
+		t.Fatalf("white peer should be loaded from disk")
+	}
+	if !peer_supports_v2("1.2.3.4:18090") {
+		t.Errorf("v2 support flag should be loaded from disk")
+	}
+
+	// failed white peer is moved to grey list, grey peer is dropped after repeated failures
+	peer_failed("1.2.3.4:18090")
+	if white, grey := Peer_List_Count(); white != 0 || grey != 1 {
+		t.Fatalf("failed peer should move to grey list, got %d %d", white, grey)
+	}
+	for i := 0; i < P2P_PEER_MAX_FAILURES; i++ {
+		peer_failed("1.2.3.4:18090")
+	}
+	if _, grey := Peer_List_Count(); grey != 0 {
+		t.Errorf("repeatedly failing peer should be dropped")
+	}
+}
+
+// loopback peers are only useful on testnet, where several nodes may run on a single machine
+func Test_Peer_Address_Valid(t *testing.T) {
+	defer func() { globals.Config = config.Mainnet }()
+
+	globals.Config = config.Mainnet
+	if is_peer_address_valid(net.IPv4(127, 0, 0, 1), 18090) {
+		t.Errorf("loopback peer must be rejected on mainnet")
+	}
+	if !is_peer_address_valid(net.IPv4(1, 2, 3, 4), 18090) || is_peer_address_valid(net.IPv4(0, 0, 0, 0), 18090) {
+		t.Errorf("address validation failed on mainnet")
+	}
+
+	globals.Config = config.Testnet
+	if !is_peer_address_valid(net.IPv4(127, 0, 0, 1), 28090) {
+		t.Errorf("loopback peer must be accepted on testnet")
+	}
+	if is_peer_address_valid(net.IPv4(127, 0, 0, 1), 0) || is_peer_address_valid(net.IPv4(224, 0, 0, 1), 28090) {
+		t.Errorf("address validation failed on testnet")
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+/* this file implements the sync manager, which downloads blocks from several peers at once
+ * chain entries received from peers are queued in height order and split into spans
+ * each span is requested from a different peer, spans not delivered in time are requested from other peers
+ * downloaded blocks are fed to the chain strictly in height order
+ */
+
+import "sort"
+import "sync"
+import "time"
+
+import "github.com/romana/rlog"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+
+const SYNC_SPAN_SIZE = 20         // blocks requested from a peer in one go
+const SYNC_MAX_SPANS_PER_PEER = 2 // spans which can be in flight from a single peer
+const SYNC_SPAN_TIMEOUT = 30      // in seconds, after this span is requested from some other peer
+const SYNC_MAX_ATTEMPTS = 5       // spans failing these many times are dropped, chain will be requested again
+const SYNC_MAX_QUEUED = 4 * 900   // we donot queue more blocks than these, till queue is drained
+
+// every block which we need to download
+type sync_item struct {
+	Height uint64
+	Hash   crypto.Hash
+	Block  *block.Complete_Block // nil till downloaded
+	Peer   *Connection           // peer which sent us the block
+	span   *sync_span
+}
+
+// a group of consecutive blocks requested from a single peer
+type sync_span struct {
+	Items    []*sync_item
+	Peer     *Connection          // peer the span is requested from, nil if not requested yet
+	Asked    time.Time            // time when span was requested
+	Attempts int                  // number of times the span was requested
+	Failed   map[*Connection]bool // peers which did not deliver the span
+}
+
+type sync_manager struct {
+	sync.Mutex
+	feed_mutex sync.Mutex // only one feeder can add blocks to chain
+
+	queue []*sync_item               // sorted by height, fed to chain from front
+	items map[crypto.Hash]*sync_item // all queued blocks
+	spans []*sync_span               // spans not yet completely downloaded
+
+	// these link the sync manager to chain and network, tests replace them
+	block_exists func(crypto.Hash) bool
+	add_block    func(*block.Complete_Block) error
+	request      func(*Connection, []crypto.Hash)
+	peers        func() []*Connection
+}
+
+var syncer = new_sync_manager()
+
+func new_sync_manager() *sync_manager {
+	return &sync_manager{
+		items:        map[crypto.Hash]*sync_item{},
+		block_exists: func(hash crypto.Hash) bool { return chain.Block_Exists(hash) },
+		add_block:    func(cbl *block.Complete_Block) error { return chain.Add_Complete_Block(cbl) },
+		request: func(connection *Connection, hashes []crypto.Hash) {
+			Send_BC_Notify_Request_GetObjects(connection, hashes, nil)
+		},
+		peers: sync_peers,
+	}
+}
+
+// peers which have completed handshake and are not exiting
+func sync_peers() (peers []*Connection) {
+	for _, c := range connection_list() {
+		if c.State != HANDSHAKE_PENDING && !c.Is_Exit() {
+			peers = append(peers, c)
+		}
+	}
+	return
+}
+
+// queue the blocks of a chain entry, block at position i is at height start_height+i
+// blocks which we already have or have already queued are skipped
+func (s *sync_manager) add_chain(connection *Connection, start_height uint64, hashes []crypto.Hash) {
+	var fresh []*sync_item
+
+	s.Lock()
+	for i := range hashes {
+		if len(s.items) >= SYNC_MAX_QUEUED {
+			break
+		}
+		if _, ok := s.items[hashes[i]]; ok || s.block_exists(hashes[i]) {
+			continue
+		}
+		item := &sync_item{Height: start_height + uint64(i), Hash: hashes[i]}
+		s.items[item.Hash] = item
+		s.queue = append(s.queue, item)
+		fresh = append(fresh, item)
+	}
+	sort.SliceStable(s.queue, func(i, j int) bool { return s.queue[i].Height < s.queue[j].Height })
+
+	for len(fresh) > 0 {
+		count := SYNC_SPAN_SIZE
+		if count > len(fresh) {
+			count = len(fresh)
+		}
+		span := &sync_span{Items: fresh[:count], Failed: map[*Connection]bool{}}
+		for _, item := range span.Items {
+			item.span = span
+		}
+		s.spans = append(s.spans, span)
+		fresh = fresh[count:]
+	}
+	rlog.Tracef(2, "Sync queue %d blocks in %d spans", len(s.queue), len(s.spans))
+	s.Unlock()
+
+	s.schedule()
+}
+
+// request spans which are not in flight, spans which timed out or whose peer left are given to other peers
+func (s *sync_manager) schedule() {
+	type request struct {
+		peer   *Connection
+		hashes []crypto.Hash
+	}
+	var requests []request
+
+	peers := s.peers()
+
+	s.Lock()
+	load := map[*Connection]int{}
+	for _, span := range s.spans {
+		if span.Peer != nil && !span.Peer.Is_Exit() && time.Since(span.Asked) < SYNC_SPAN_TIMEOUT*time.Second {
+			load[span.Peer]++
+		}
+	}
+
+	var spans []*sync_span
+	for _, span := range s.spans {
+		if span.Peer != nil {
+			if !span.Peer.Is_Exit() && time.Since(span.Asked) < SYNC_SPAN_TIMEOUT*time.Second {
+				spans = append(spans, span)
+				continue
+			}
+			rlog.Tracef(1, "Sync span at height %d not delivered by %s", span.Items[0].Height, span.Peer.Addr)
+			span.Failed[span.Peer] = true
+			span.Peer = nil
+			if span.Attempts >= SYNC_MAX_ATTEMPTS { // nobody could serve it, drop it
+				s.drop_span(span)
+				continue
+			}
+		}
+		spans = append(spans, span)
+
+		if peer := s.pick_peer(span, peers, load); peer != nil {
+			span.Peer = peer
+			span.Asked = time.Now()
+			span.Attempts++
+			load[peer]++
+
+			var hashes []crypto.Hash
+			for _, item := range span.Items {
+				if item.Block == nil {
+					hashes = append(hashes, item.Hash)
+				}
+			}
+			requests = append(requests, request{peer: peer, hashes: hashes})
+		}
+	}
+	s.spans = spans
+	s.Unlock()
+
+	for _, r := range requests { // network is not touched while holding the lock
+		s.request(r.peer, r.hashes)
+	}
+}
+
+// pick least loaded peer which has the blocks, peers which failed the span are avoided
+// caller must hold the lock
+func (s *sync_manager) pick_peer(span *sync_span, peers []*Connection, load map[*Connection]int) (best *Connection) {
+	top_height := span.Items[len(span.Items)-1].Height
+	for _, retry := range []bool{false, true} { // if every peer failed the span, try them again
+		for _, peer := range peers {
+			if peer.Is_Exit() || load[peer] >= SYNC_MAX_SPANS_PER_PEER || (span.Failed[peer] && !retry) {
+				continue
+			}
+			if peer.Last_Height != 0 && peer.Last_Height <= top_height { // peer does not have these blocks
+				continue
+			}
+			if peer.Support_Flags&P2P_SUPPORT_FLAG_PRUNED != 0 && span.Items[0].Height+config.PRUNE_DEPTH_MIN < peer.Last_Height { // pruned peer cannot serve old blocks
+				continue
+			}
+			if best == nil || load[peer] < load[best] {
+				best = peer
+			}
+		}
+		if best != nil {
+			return
+		}
+	}
+	return
+}
+
+// remove blocks of a span which were not downloaded, caller must hold the lock
+func (s *sync_manager) drop_span(span *sync_span) {
+	rlog.Tracef(1, "Dropping sync span at height %d after %d attempts", span.Items[0].Height, span.Attempts)
+	queue := s.queue[:0]
+	for _, item := range s.queue {
+		if item.span == span && item.Block == nil {
+			delete(s.items, item.Hash)
+			continue
+		}
+		queue = append(queue, item)
+	}
+	s.queue = queue
+}
+
+// called for every block received from peers, returns false if the block was not requested by sync manager
+func (s *sync_manager) block_received(connection *Connection, cbl *block.Complete_Block) bool {
+	hash := cbl.Bl.GetHash()
+
+	s.Lock()
+	item, ok := s.items[hash]
+	if !ok {
+		s.Unlock()
+		return false
+	}
+	if item.Block == nil { // a reassigned span may be delivered twice, keep the first one
+		item.Block = cbl
+		item.Peer = connection
+	}
+
+	// if span is completely downloaded, the peer is free for next span
+	complete := true
+	for _, i := range item.span.Items {
+		if i.Block == nil {
+			complete = false
+			break
+		}
+	}
+	if complete {
+		spans := s.spans[:0]
+		for _, span := range s.spans {
+			if span != item.span {
+				spans = append(spans, span)
+			}
+		}
+		s.spans = spans
+	}
+	s.Unlock()
+
+	s.feed()
+	if complete {
+		s.schedule()
+	}
+	return true
+}
+
+// add downloaded blocks to chain in height order, stops at the first block not yet downloaded
+func (s *sync_manager) feed() {
+	s.feed_mutex.Lock()
+	defer s.feed_mutex.Unlock()
+
+	for {
+		s.Lock()
+		if len(s.queue) == 0 {
+			s.Unlock()
+			return
+		}
+		item := s.queue[0]
+		if item.Block == nil && !s.block_exists(item.Hash) {
+			s.Unlock()
+			return
+		}
+		s.queue = s.queue[1:]
+		delete(s.items, item.Hash)
+		s.Unlock()
+
+		if item.Block == nil { // block reached us some other way
+			continue
+		}
+		if err := s.add_block(item.Block); err != nil {
+			rlog.Tracef(1, "Sync block %s at height %d rejected err %s", item.Hash, item.Height, err)
+			item.Peer.Misbehaving_Error(err)
+		}
+	}
+}
+
+// returns number of blocks queued and spans in flight
+func (s *sync_manager) status() (queued int, in_flight int) {
+	s.Lock()
+	defer s.Unlock()
+	for _, span := range s.spans {
+		if span.Peer != nil {
+			in_flight++
+		}
+	}
+	return len(s.queue), in_flight
+}
+
+// reassign timed out spans every second
+func sync_loop() {
+	for {
+		select {
+		case <-Exit_Event:
+			return
+		case <-time.After(1 * time.Second):
+			syncer.schedule()
+		}
+	}
+}
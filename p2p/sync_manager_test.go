@@ -0,0 +1,147 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2p
+
+import "net"
+import "time"
+import "testing"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/transaction"
+
+// spans must be spread over peers, fed to chain in height order and reassigned on timeout
+func Test_Sync_Manager(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+
+	type request struct {
+		peer   *Connection
+		hashes []crypto.Hash
+	}
+	var requests []request
+	var added []crypto.Hash
+	known := map[crypto.Hash]bool{}
+
+	peer_a := &Connection{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1)}, State: IDLE, Last_Height: 1000, logger: logger}
+	peer_b := &Connection{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2)}, State: IDLE, Last_Height: 1000, logger: logger}
+
+	s := new_sync_manager()
+	s.block_exists = func(hash crypto.Hash) bool { return known[hash] }
+	s.add_block = func(cbl *block.Complete_Block) error {
+		added = append(added, cbl.Bl.GetHash())
+		known[cbl.Bl.GetHash()] = true
+		return nil
+	}
+	s.request = func(c *Connection, hashes []crypto.Hash) { requests = append(requests, request{c, hashes}) }
+	s.peers = func() []*Connection { return []*Connection{peer_a, peer_b} }
+
+	var blocks []*block.Complete_Block
+	var hashes []crypto.Hash
+	for i := 0; i < 2*SYNC_SPAN_SIZE+5; i++ {
+		var bl block.Block
+		bl.Miner_tx = transaction.Transaction{Version: 2, RctSignature: &ringct.RctSig{}}
+		bl.Miner_tx.Vin = append(bl.Miner_tx.Vin, transaction.Txin_gen{Height: uint64(i)})
+		bl.Miner_tx.Vout = append(bl.Miner_tx.Vout, transaction.Tx_out{Target: transaction.Txout_to_key{}})
+		bl.Nonce = uint32(i)
+		blocks = append(blocks, &block.Complete_Block{Bl: &bl})
+		hashes = append(hashes, bl.GetHash())
+	}
+
+	known[hashes[0]] = true // we already have the first block
+	s.add_chain(peer_a, 10, hashes)
+
+	if len(requests) != 3 || requests[0].peer != peer_a || requests[1].peer != peer_b || requests[2].peer != peer_a {
+		t.Fatalf("spans not spread over peers %+v", requests)
+	}
+	if len(requests[0].hashes) != SYNC_SPAN_SIZE || requests[0].hashes[0] != hashes[1] || len(requests[2].hashes) != 4 {
+		t.Fatalf("spans not split correctly")
+	}
+
+	// already queued blocks are not queued again
+	s.add_chain(peer_b, 10, hashes)
+	if queued, _ := s.status(); queued != len(hashes)-1 || len(requests) != 3 {
+		t.Fatalf("chain entry queued twice, queued %d", queued)
+	}
+
+	// second span arrives first, nothing can be added till first span arrives
+	for i := SYNC_SPAN_SIZE + 1; i <= 2*SYNC_SPAN_SIZE; i++ {
+		s.block_received(peer_b, blocks[i])
+	}
+	if len(added) != 0 {
+		t.Fatalf("blocks added out of order")
+	}
+	for i := SYNC_SPAN_SIZE; i >= 1; i-- {
+		s.block_received(peer_a, blocks[i])
+	}
+	if len(added) != 2*SYNC_SPAN_SIZE {
+		t.Fatalf("expected %d blocks added, got %d", 2*SYNC_SPAN_SIZE, len(added))
+	}
+	for i := range added {
+		if added[i] != hashes[i+1] {
+			t.Fatalf("block %d added out of height order", i)
+		}
+	}
+
+	// last span times out at peer_a, it must be requested from peer_b
+	s.Lock()
+	s.spans[0].Asked = time.Now().Add(-(SYNC_SPAN_TIMEOUT + 1) * time.Second)
+	s.Unlock()
+	requests = requests[:0]
+	s.schedule()
+	if len(requests) != 1 || requests[0].peer != peer_b || len(requests[0].hashes) != 4 {
+		t.Fatalf("timed out span not reassigned %+v", requests)
+	}
+
+	for i := 2*SYNC_SPAN_SIZE + 1; i < len(blocks); i++ {
+		if !s.block_received(peer_b, blocks[i]) {
+			t.Fatalf("requested block not accepted by sync manager")
+		}
+	}
+	if queued, in_flight := s.status(); queued != 0 || in_flight != 0 || len(added) != len(hashes)-1 {
+		t.Fatalf("sync not complete, queued %d in flight %d added %d", queued, in_flight, len(added))
+	}
+
+	// blocks not requested are left to the caller
+	if s.block_received(peer_a, blocks[1]) {
+		t.Fatalf("unrequested block accepted by sync manager")
+	}
+}
+
+// pruned peers must not be asked for old blocks
+func Test_Sync_Pruned_Peer(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "P2P"})
+
+	full := &Connection{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1)}, State: IDLE, Last_Height: 5000, logger: logger}
+	pruned := &Connection{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2)}, State: IDLE, Last_Height: 5000, Support_Flags: P2P_SUPPORT_FLAG_PRUNED, logger: logger}
+
+	s := new_sync_manager()
+	old_span := &sync_span{Items: []*sync_item{{Height: 100}}, Failed: map[*Connection]bool{}}
+	recent_span := &sync_span{Items: []*sync_item{{Height: 4500}}, Failed: map[*Connection]bool{}}
+
+	if peer := s.pick_peer(old_span, []*Connection{pruned}, map[*Connection]int{}); peer != nil {
+		t.Fatalf("old blocks requested from pruned peer")
+	}
+	if peer := s.pick_peer(old_span, []*Connection{pruned, full}, map[*Connection]int{}); peer != full {
+		t.Fatalf("old blocks not requested from full peer")
+	}
+	if peer := s.pick_peer(recent_span, []*Connection{pruned}, map[*Connection]int{}); peer != pruned {
+		t.Fatalf("recent blocks not requested from pruned peer")
+	}
+}
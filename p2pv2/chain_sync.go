@@ -0,0 +1,321 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2pv2
+
+import "fmt"
+
+import "github.com/romana/rlog"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/p2p"
+import "github.com/arnaucode/derosuite/transaction"
+
+// this file implements chain sync, peer is asked for its chain, missing blocks are then requested as objects
+
+// send our chain in sparse form, first 10 blocks, then block are in 2^n power and the last block is genesis
+func (conn *Connection) Send_Chain_Request_command() {
+	var r Chain_Request
+	fill_common(&r.Common)
+	r.Command = V2_COMMAND_CHAIN_REQUEST
+
+	our_height := chain.Get_Height()
+	for i := uint64(1); i <= 10 && i < our_height; i++ {
+		hash, _ := chain.Load_BL_ID_at_Height(our_height - i)
+		r.Block_list = append(r.Block_list, hash)
+	}
+	if our_height > 11 {
+		for height := (our_height - 11) >> 1; height > 0; height = height >> 1 {
+			hash, _ := chain.Load_BL_ID_at_Height(height)
+			r.Block_list = append(r.Block_list, hash)
+		}
+	}
+
+	// final block is always genesis block
+	r.Block_list = append(r.Block_list, globals.Config.Genesis_Block_Hash)
+
+	conn.send_object(&r)
+}
+
+// peer sent its chain, give it our version of the chain from the common point
+func (conn *Connection) Handle_Chain_Request_command(r *Chain_Request) {
+	if len(r.Block_list) == 0 || len(r.Block_list) > P2PV2_MAX_CHAIN_HASHES {
+		conn.logger.Debugf("Chain request with %d hashes, disconnecting peer", len(r.Block_list))
+		conn.Exit = true
+		return
+	}
+
+	// make sure the genesis block is same
+	if r.Block_list[len(r.Block_list)-1] != globals.Config.Genesis_Block_Hash {
+		conn.logger.Debugf("Peer's genesis block is different from our, so disconnect")
+		conn.Exit = true
+		return
+	}
+
+	start_height := uint64(0)
+	for i := range r.Block_list { // find the common point in our chain
+		if chain.Block_Exists(r.Block_list[i]) {
+			start_height = chain.Load_Height_for_BL_ID(r.Block_list[i])
+			rlog.Tracef(4, "Found common point in chain at hash %x\n", r.Block_list[i])
+			break
+		}
+	}
+
+	stop_height := chain.Get_Height()
+	if stop_height-start_height > P2PV2_MAX_CHAIN_HASHES {
+		stop_height = start_height + P2PV2_MAX_CHAIN_HASHES
+	}
+
+	var block_list [][32]byte
+	for i := start_height; i < stop_height; i++ {
+		hash, _ := chain.Load_BL_ID_at_Height(i)
+		block_list = append(block_list, hash)
+	}
+
+	conn.Send_Chain_Response_command(start_height, block_list)
+}
+
+func (conn *Connection) Send_Chain_Response_command(start_height uint64, block_list [][32]byte) {
+	var r Chain_Response
+	fill_common(&r.Common)
+	r.Command = V2_COMMAND_CHAIN_RESPONSE
+	r.Start_height = start_height
+	r.Block_list = block_list
+	conn.send_object(&r)
+}
+
+// peer sent its chain, request the blocks we do not have
+func (conn *Connection) Handle_Chain_Response_command(r *Chain_Response) {
+	if len(r.Block_list) == 0 || len(r.Block_list) > P2PV2_MAX_CHAIN_HASHES {
+		conn.logger.Debugf("Chain response with %d hashes, disconnecting peer", len(r.Block_list))
+		conn.Exit = true
+		return
+	}
+
+	if r.Start_height == 0 && r.Block_list[0] != globals.Config.Genesis_Block_Hash {
+		conn.logger.Debugf("Peer's genesis block is different from our, so disconnect")
+		conn.Exit = true
+		return
+	}
+
+	var request [][32]byte
+	for _, hash := range r.Block_list {
+		if len(request) >= P2PV2_MAX_OBJECTS {
+			break
+		}
+		if !chain.Block_Exists(hash) {
+			request = append(request, hash)
+		}
+	}
+
+	if len(request) == 0 {
+		return
+	}
+
+	conn.Lock()
+	conn.Requested_Objects = request
+	conn.Unlock()
+
+	rlog.Tracef(2, "Requesting %d blocks from peer\n", len(request))
+	conn.Send_Object_Request_Command(request, nil)
+}
+
+func (conn *Connection) Send_Object_Request_Command(block_list [][32]byte, tx_list [][32]byte) {
+	var r Object_Request
+	fill_common(&r.Common)
+	r.Command = V2_COMMAND_OBJECTS_REQUEST
+	r.Block_list = block_list
+	r.Tx_list = tx_list
+	conn.send_object(&r)
+}
+
+// serialize a block alongwith its txs
+func serialize_block(hash crypto.Hash) (cbl Complete_Block, err error) {
+	bl, err := chain.Load_BL_FROM_ID(hash)
+	if err != nil {
+		return
+	}
+	cbl.Block = bl.Serialize()
+	for i := range bl.Tx_hashes {
+		tx, err := chain.Load_TX_FROM_ID(bl.Tx_hashes[i])
+		if err != nil {
+			return cbl, err
+		}
+		if tx.IsPruned() { // we are a pruned node, peer must ask someone else
+			return cbl, fmt.Errorf("tx %s is pruned", bl.Tx_hashes[i])
+		}
+		cbl.Txs = append(cbl.Txs, tx.Serialize())
+	}
+	return
+}
+
+// deserialize a block alongwith its txs
+func deserialize_block(cb *Complete_Block) (*block.Complete_Block, error) {
+	var cbl block.Complete_Block
+	var bl block.Block
+	if err := bl.Deserialize(cb.Block); err != nil {
+		return nil, err
+	}
+	cbl.Bl = &bl
+	if len(cb.Txs) != len(bl.Tx_hashes) {
+		return nil, fmt.Errorf("block has %d txs, %d received", len(bl.Tx_hashes), len(cb.Txs))
+	}
+	for i := range cb.Txs {
+		tx, err := deserialize_tx(cb.Txs[i])
+		if err != nil {
+			return nil, err
+		}
+		cbl.Txs = append(cbl.Txs, tx)
+	}
+	return &cbl, nil
+}
+
+func deserialize_tx(buf []byte) (*transaction.Transaction, error) {
+	var tx transaction.Transaction
+	if err := tx.DeserializeHeader(buf); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// peer wants some blocks/txs, give them if we have them
+func (conn *Connection) Handle_Object_Request_Command(r *Object_Request) {
+	if len(r.Block_list)+len(r.Tx_list) > P2PV2_MAX_OBJECTS {
+		conn.logger.Debugf("Peer requested %d objects, disconnecting peer", len(r.Block_list)+len(r.Tx_list))
+		conn.Exit = true
+		return
+	}
+
+	var blocks []Complete_Block
+	var txs [][]byte
+	for _, hash := range r.Block_list {
+		cbl, err := serialize_block(hash)
+		if err != nil {
+			rlog.Tracef(1, "Cannot load block %x from DB err %s\n", hash, err)
+			continue
+		}
+		blocks = append(blocks, cbl)
+	}
+	for _, hash := range r.Tx_list {
+		tx, err := chain.Load_TX_FROM_ID(hash)
+		if err != nil {
+			// stem txs are not public yet, serving them would reveal that we are on the stem
+			if tx = chain.Mempool.Mempool_Get_TX(hash); tx == nil || chain.Mempool.Mempool_TX_Is_Stem(hash) {
+				continue
+			}
+		}
+		if tx.IsPruned() {
+			continue
+		}
+		txs = append(txs, tx.Serialize())
+	}
+
+	conn.Send_Object_Response_Command(blocks, txs)
+}
+
+func (conn *Connection) Send_Object_Response_Command(blocks []Complete_Block, txs [][]byte) {
+	var r Object_Response
+	fill_common(&r.Common)
+	r.Command = V2_COMMAND_OBJECTS_RESPONSE
+	r.Blocks = blocks
+	r.Txs = txs
+	conn.send_object(&r)
+}
+
+// peer sent us the objects, add them to chain in order
+func (conn *Connection) Handle_Object_Response_Command(r *Object_Response) {
+	if len(r.Blocks)+len(r.Txs) > P2PV2_MAX_OBJECTS {
+		conn.logger.Debugf("Peer sent %d objects, disconnecting peer", len(r.Blocks)+len(r.Txs))
+		conn.Exit = true
+		return
+	}
+
+	for i := range r.Blocks {
+		cbl, err := deserialize_block(&r.Blocks[i])
+		if err != nil {
+			conn.logger.Debugf("Block could not be deserialized err %s, disconnecting peer", err)
+			conn.Exit = true
+			return
+		}
+		if !conn.add_block(cbl) {
+			return
+		}
+	}
+
+	for i := range r.Txs {
+		tx, err := deserialize_tx(r.Txs[i])
+		if err != nil {
+			conn.logger.Debugf("Transaction could not be deserialized err %s, disconnecting peer", err)
+			conn.Exit = true
+			return
+		}
+		if !conn.check_tx_error(chain.Add_TX_To_Pool(tx)) {
+			return
+		}
+	}
+
+	conn.Lock()
+	conn.Requested_Objects = conn.Requested_Objects[:0]
+	conn.Unlock()
+
+	conn.sync_if_lagging() // continue syncing if peer is still ahead of us
+}
+
+// peers sending invalid objects are scored by p2p, so v2 and levin peers share scores and bans
+var misbehaving_ip_error = p2p.Misbehaving_IP_Error
+
+// checks error of adding a tx to pool, returns false if peer sent invalid tx and has been marked for disconnect
+// txs rejected for reasons honest peers can cause ( already in pool, double spend in pool etc ) are ignored
+func (conn *Connection) check_tx_error(err error) bool {
+	if err == nil {
+		return true
+	}
+	if e, ok := err.(*blockchain.TX_Verify_Error); ok && e.Invalid() {
+		conn.logger.Debugf("Peer sent invalid transaction err %s, disconnecting peer", err)
+		misbehaving_ip_error(conn.Addr.IP, err)
+		conn.Exit = true
+		return false
+	}
+	rlog.Tracef(2, "Transaction not added to pool err %s\n", err)
+	return true
+}
+
+// add a block to chain, returns false if peer sent invalid block and has been marked for disconnect
+func (conn *Connection) add_block(cbl *block.Complete_Block) bool {
+	hash := cbl.Bl.GetHash()
+	err := chain.Add_Complete_Block_From(cbl, conn.Addr.IP.String())
+	if err == nil {
+		return true
+	}
+
+	if e, ok := err.(*blockchain.Block_Verify_Error); ok {
+		if e.Invalid() {
+			conn.logger.Debugf("Peer sent invalid block %s err %s, disconnecting peer", hash, err)
+			misbehaving_ip_error(conn.Addr.IP, err)
+			conn.Exit = true
+			return false
+		}
+		if e.Rule == blockchain.BLOCK_RULE_ORPHAN && !chain.Block_Exists(cbl.Bl.Prev_Hash) && !chain.Orphan_Exists(cbl.Bl.Prev_Hash) {
+			conn.logger.Debugf("Requesting parent %s of orphan block %s", cbl.Bl.Prev_Hash, hash)
+			conn.Send_Object_Request_Command([][32]byte{cbl.Bl.Prev_Hash}, nil)
+		}
+	}
+	rlog.Tracef(2, "Block %s not added err %s\n", hash, err)
+	return true
+}
@@ -0,0 +1,37 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+
+package p2pv2
+
+import "fmt"
+import "net"
+import "testing"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/p2p"
+
+// peers sending invalid txs are disconnected, rejections honest peers can cause are ignored
+func Test_Check_TX_Error(t *testing.T) {
+	conn := &Connection{Addr: &net.TCPAddr{IP: net.IPv4(10, 1, 2, 3)}, logger: log.New().WithFields(log.Fields{"com": "P2PV2"})}
+
+	var scored []error
+	misbehaving_ip_error = func(ip net.IP, err error) { scored = append(scored, err) }
+	defer func() { misbehaving_ip_error = p2p.Misbehaving_IP_Error }()
+
+	if !conn.check_tx_error(nil) || !conn.check_tx_error(fmt.Errorf("some error")) || conn.Exit {
+		t.Fatalf("peer disconnected without invalid tx")
+	}
+	if !conn.check_tx_error(&blockchain.TX_Verify_Error{Rule: blockchain.TX_RULE_KEYIMAGE_POOL}) || conn.Exit {
+		t.Fatalf("peer disconnected for honest rejection")
+	}
+	if conn.check_tx_error(&blockchain.TX_Verify_Error{Rule: blockchain.TX_RULE_SIGNATURE}) || !conn.Exit {
+		t.Fatalf("peer not disconnected for invalid tx")
+	}
+	if len(scored) != 1 {
+		t.Fatalf("peer must be scored once before disconnect, scored %d", len(scored))
+	}
+}
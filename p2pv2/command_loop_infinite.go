@@ -16,26 +16,30 @@
 
 package p2pv2
 
-import "io"
 import "net"
 
 //import "sync"
 import "time"
 import "runtime/debug"
 import "container/list"
-import "encoding/binary"
 
 import log "github.com/sirupsen/logrus"
 import "github.com/romana/rlog"
 import "github.com/vmihailenco/msgpack"
 
+// peer must complete handshake within this time, afterwards it must send something atleast every 300 secs
+const HANDSHAKE_TIMEOUT = 20 * time.Second
+const IDLE_TIMEOUT = 300 * time.Second
+
 // this function waits for any commands from the connection and suitably responds doing sanity checks
 // this is the core of the p2p package
+// returns whether the handshake was completed, so caller can fallback to older protocol
 /* this is the entire connection handler, all incoming/outgoing connections end up here  */
-func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
+func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) (handshake_completed bool) {
 
 	var connection Connection
 	connection.Incoming = incoming
+	connection.Created = time.Now()
 	connection.Conn = conn
 	var idle int
 
@@ -50,10 +54,12 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 
 	defer func() {
 		if r := recover(); r != nil {
-			connection.logger.Warnf("Recovered while handling connection, Stack trace below", r)
+			connection.logger.Warnf("Recovered while handling connection, Stack trace below %+v", r)
 			connection.logger.Warnf("Stack trace  \n%s", debug.Stack())
-
+			connection.Exit = true
+			conn.Close()
 		}
+		handshake_completed = connection.HandShakeCompleted
 	}()
 
 	Connection_Add(&connection) // add connection to pool
@@ -68,13 +74,12 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 			select {
 			case <-ticker.C:
 				idle++
-				// if idle more than 13 secs, we should send a timed sync
+				// if idle more than 13 secs, we should send a sync
 				if idle > 13 {
 					if connection.State != HANDSHAKE_PENDING {
 						connection.State = IDLE
+						connection.Send_Sync_Command()
 					}
-					//Send_Timed_Sync(&connection)
-					//connection.logger.Debugf("We should send a timed sync")
 					idle = 0
 				}
 			case <-Exit_Event: // p2p is shutting down, close the connection
@@ -95,32 +100,24 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 
 	// the infinite loop handler
 	for {
-		length_buf := make([]byte, 4, 4) // prefix length is 4 bytes
 		if connection.Exit {
 			break
 		}
 
-		read_count, err := io.ReadFull(connection.Conn, length_buf)
-		if err != nil {
-			rlog.Tracef(2, "Error while reading command prefix length exiting err:%s\n", err)
-			connection.Exit = true
-			continue
+		// we should not hang for hrs waiting for data to come
+		if connection.HandShakeCompleted {
+			conn.SetReadDeadline(time.Now().Add(IDLE_TIMEOUT))
+		} else {
+			conn.SetReadDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
 		}
 
-		length := binary.LittleEndian.Uint32(length_buf) // convert little endian bytes 4 bytes to length
-
-		// check safety of length, we should not allocate more than 100 MB as that is the limit of the block
-
-		command_buf := make([]byte, length, length)
-		//set_timeout(&connection) // we should not hang for hrs waiting for data to come
-		read_count, err = io.ReadFull(connection.Conn, command_buf)
+		command_buf, err := connection.read_message()
 		if err != nil {
-			rlog.Tracef(2, "Error while reading command data exiting err:%s\n", err)
+			rlog.Tracef(2, "Error while reading command exiting err:%s\n", err)
 			connection.Exit = true
 			continue
 		}
 
-		command_buf = command_buf[:read_count]
 		var dummy_command Common
 		err = msgpack.Unmarshal(command_buf, &dummy_command)
 		if err != nil {
@@ -130,39 +127,82 @@ func Handle_Connection(conn net.Conn, remote_addr *net.TCPAddr, incoming bool) {
 		}
 
 		// if handshake not done, donot process any command
-
 		if !connection.HandShakeCompleted && dummy_command.Command != V2_COMMAND_HANDSHAKE {
-			rlog.Tracef(2, "Peer Sending something but we are waiting for handshake command data exiting err:%s\n", err)
+			rlog.Tracef(2, "Peer Sending something but we are waiting for handshake command %d\n", dummy_command.Command)
 			connection.Exit = true
 			continue
 		}
 
-		switch dummy_command.Command {
-		case V2_COMMAND_HANDSHAKE:
-			var handshake Handshake
-			err = msgpack.Unmarshal(command_buf, &handshake)
-			if err != nil {
-				rlog.Tracef(2, "Error while parsing incoming handshake data exiting err:%s\n", err)
-				connection.Exit = true
-				continue
-			}
+		idle = 0
+		if connection.HandShakeCompleted { // every message carries peer status
+			connection.update_common(&dummy_command)
+			connection.State = ACTIVE
+		}
 
-		case V2_COMMAND_SYNC:
+		if err = connection.dispatch(dummy_command.Command, command_buf); err != nil {
+			rlog.Tracef(2, "Error while parsing command %d exiting err:%s\n", dummy_command.Command, err)
+			connection.Exit = true
+			continue
+		}
+	}
 
-		case V2_COMMAND_CHAIN_REQUEST:
+	return
+}
 
-		case V2_COMMAND_CHAIN_RESPONSE: // this should be verified whether we are waiting for it
+// parse the message fully and call respective handler
+func (connection *Connection) dispatch(command uint64, buf []byte) (err error) {
+	switch command {
+	case V2_COMMAND_HANDSHAKE:
+		var handshake Handshake
+		if err = msgpack.Unmarshal(buf, &handshake); err == nil {
+			connection.update_common(&handshake.Common)
+			connection.Handle_Handshake_Command(&handshake)
+		}
 
-		case V2_COMMAND_OBJECTS_REQUEST:
+	case V2_COMMAND_SYNC:
+		var sync Sync
+		if err = msgpack.Unmarshal(buf, &sync); err == nil {
+			connection.Handle_Sync_Command(&sync)
+		}
 
-		case V2_COMMAND_OBJECTS_RESPONSE: // this should be verified whether we are waiting for it
+	case V2_COMMAND_CHAIN_REQUEST:
+		var request Chain_Request
+		if err = msgpack.Unmarshal(buf, &request); err == nil {
+			connection.Handle_Chain_Request_command(&request)
+		}
 
-		case V2_NOTIFY_NEW_BLOCK:
+	case V2_COMMAND_CHAIN_RESPONSE:
+		var response Chain_Response
+		if err = msgpack.Unmarshal(buf, &response); err == nil {
+			connection.Handle_Chain_Response_command(&response)
+		}
 
-		case V2_NOTIFY_NEW_TX:
+	case V2_COMMAND_OBJECTS_REQUEST:
+		var request Object_Request
+		if err = msgpack.Unmarshal(buf, &request); err == nil {
+			connection.Handle_Object_Request_Command(&request)
+		}
 
+	case V2_COMMAND_OBJECTS_RESPONSE:
+		var response Object_Response
+		if err = msgpack.Unmarshal(buf, &response); err == nil {
+			connection.Handle_Object_Response_Command(&response)
 		}
 
-	}
+	case V2_NOTIFY_NEW_BLOCK:
+		var notify Notify_New_Objects
+		if err = msgpack.Unmarshal(buf, &notify); err == nil {
+			connection.Handle_Notify_New_Block(&notify)
+		}
 
+	case V2_NOTIFY_NEW_TX:
+		var notify Notify_New_Objects
+		if err = msgpack.Unmarshal(buf, &notify); err == nil {
+			connection.Handle_Notify_New_Transaction(&notify)
+		}
+
+	default: // unknown commands are ignored, newer versions may add them
+		rlog.Tracef(2, "Unknown command %d ignored\n", command)
+	}
+	return
 }
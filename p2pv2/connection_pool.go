@@ -23,10 +23,13 @@ package p2pv2
 import "fmt"
 import "net"
 import "sync"
+import "time"
+import "sync/atomic"
 import "container/list"
 
 import log "github.com/sirupsen/logrus"
 
+import "github.com/arnaucode/derosuite/p2p"
 import "github.com/arnaucode/derosuite/crypto"
 
 // any connection incoming/outgoing can only be in this state
@@ -54,15 +57,17 @@ type Connection struct {
 	Top_ID                crypto.Hash // top block id of the connection
 	Cumulative_Difficulty uint64      // cumulative difficulty of top block of peer
 	logger                *log.Entry  // connection specific logger
-	Requested_Objects     [][32]byte  // currently unused as we sync up with a single peer at a time
+	Requested_Objects     [][32]byte  // blocks requested from this peer and not yet received
 	Conn                  net.Conn    // actual object to talk
 	Command_queue         *list.List  // New protocol is partly syncronous/partly asyncronous
 	sync.Mutex
 
-	HandShakeCompleted bool // whether handshake is completed
+	HandShakeCompleted bool   // whether handshake is completed
+	Version            uint64 // protocol version negotiated in handshake
 
-	Bytes_Sent     uint64 // total bytes sent
-	Bytes_Received uint64 // total bytes received
+	Bytes_Sent     uint64    // total bytes sent
+	Bytes_Received uint64    // total bytes received
+	Created        time.Time // when the connection was made
 
 	// TODO a bloom filter that an object has been relayed
 }
@@ -99,6 +104,16 @@ func Connection_Delete(c *Connection) {
 	delete(connection_map, Key(c.Addr.IP))
 }
 
+// return a snapshot of all connections, so callers do not hold the pool lock while talking to peers
+func connection_list() (list []*Connection) {
+	connection_mutex.Lock()
+	defer connection_mutex.Unlock()
+	for _, v := range connection_map {
+		list = append(list, v)
+	}
+	return
+}
+
 // prints all the connection info to screen
 func Connection_Print() {
 	connection_mutex.Lock()
@@ -154,3 +169,16 @@ func Peer_Direction_Count() (Incoming uint64, Outgoing uint64) {
 	connection_mutex.Unlock()
 	return
 }
+
+// details of all connections which have completed handshake, in the same form as levin connections
+func Connection_List() (list []p2p.Connection_Info) {
+	for _, v := range connection_list() {
+		if !v.HandShakeCompleted {
+			continue
+		}
+		list = append(list, p2p.Connection_Info{Addr: v.Addr, Port: v.Port, Peer_ID: v.Peer_ID, Incoming: v.Incoming,
+			Height: v.Last_Height, State: string(v.State), Created: v.Created,
+			Bytes_Sent: atomic.LoadUint64(&v.Bytes_Sent), Bytes_Received: atomic.LoadUint64(&v.Bytes_Received)})
+	}
+	return
+}
@@ -16,14 +16,31 @@
 
 package p2pv2
 
+import "fmt"
 import "net"
+import "sync"
 import "time"
+import "strconv"
+import "crypto/tls"
 import "sync/atomic"
 
 import log "github.com/sirupsen/logrus"
+import "golang.org/x/net/proxy"
 
+import "github.com/arnaucode/derosuite/p2p"
+import "github.com/arnaucode/derosuite/block"
 import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/transaction"
+
+// v2 server listens on levin port + this offset, so peers can derive it from peer lists
+const P2PV2_PORT_OFFSET = 5
+
+// if peer does not speak v2, do not try it again for this duration, levin is used meanwhile
+const P2PV2_RETRY_DELAY = 3600 * time.Second
+
+// dialing a v2 peer, including through socks, is given up after this duration
+const DIAL_TIMEOUT = 10 * time.Second
 
 var chain *blockchain.Blockchain // external reference to chain
 
@@ -31,81 +48,135 @@ var Exit_Event = make(chan bool) // causes all threads to exit
 var Exit_In_Progress bool        // marks we are doing exit
 var logger *log.Entry            // global logger, every logger in this package is a child of this
 
+var server_tls_config *tls.Config
+var client_tls_config *tls.Config
+var bind_address string // v2 server listens on this address
+var our_port uint32     // levin port advertised in handshake
+
+var failed_mutex sync.Mutex
+var failed_list = map[string]time.Time{} // peers which did not speak v2 recently
+
 // Initialize P2P subsystem
+// must be called after p2p has been initialized, as the bind address is derived from it
 func P2P_Init(params map[string]interface{}) error {
-	logger = globals.Logger.WithFields(log.Fields{"com": "P2P"}) // all components must use this logger
+	logger = globals.Logger.WithFields(log.Fields{"com": "P2PV2"}) // all components must use this logger
 	chain = params["chain"].(*blockchain.Blockchain)
-	go P2P_engine()    // start outgoing engine
+
+	cert, err := globals.Generate_Certificate()
+	if err != nil {
+		return fmt.Errorf("could not generate TLS certificate err %s", err)
+	}
+	server_tls_config, client_tls_config = tls_configs(cert)
+
+	host, port_string, err := net.SplitHostPort(p2p.Bind_Address())
+	if err != nil {
+		return fmt.Errorf("invalid p2p bind address err %s", err)
+	}
+	port, _ := strconv.Atoi(port_string)
+	our_port = uint32(port)
+	bind_address = net.JoinHostPort(host, strconv.Itoa(port+P2PV2_PORT_OFFSET))
+
+	// relay whatever levin relays, stem txs are sent by p2p to the stem peer which may be one of ours
+	tx_relayer, block_relayer := chain.P2P_TX_Relayer, chain.P2P_Block_Relayer
+	chain.P2P_TX_Relayer = func(tx *transaction.Transaction, stem bool) {
+		if tx_relayer != nil {
+			tx_relayer(tx, stem)
+		}
+		if !stem {
+			go broadcast_tx(tx)
+		}
+	}
+	chain.P2P_Block_Relayer = func(cbl *block.Complete_Block) {
+		if block_relayer != nil {
+			block_relayer(cbl)
+		}
+		go broadcast_block(cbl)
+	}
+
+	p2p.Connect_V2 = connect       // outgoing connections use v2 for peers advertising it
+	p2p.Stem_Peers_V2 = stem_peers // our peers can be stem peers
+	p2p.FLAGS_VALUE |= p2p.P2P_SUPPORT_FLAG_V2
+
 	go P2P_Server_v2() // start accepting connections
-	logger.Infof("P2P started")
+	logger.Infof("P2P v2 started at %s", bind_address)
 	atomic.AddUint32(&globals.Subsystem_Active, 1) // increment subsystem
 	return nil
 }
 
-func P2P_engine() {
-
-	// if user provided ips at command line , use them, currently we use only the first one
-
-	var end_point_list []string
-
-	/*
-	   if _,ok := globals.Arguments["--add-exclusive-node"] ; ok { // check if parameter is supported
-	       if globals.Arguments["--add-exclusive-node"] != nil {
-	           tmp_list := globals.Arguments["--add-exclusive-node"].([]string)
-	           for i := range tmp_list {
-	               end_point_list = append(end_point_list,tmp_list[i])
-	           }
-	       }
-	   }
-	*/
-	// add hard-coded seeds
-	end_point_list = append(end_point_list, "127.0.0.1:18095")
+// try connecting to peer using v2 protocol, this blocks till the connection is closed
+// returns false if the peer does not speak v2, so levin can be used
+func connect(remote_addr *net.TCPAddr) bool {
+	if Exit_In_Progress {
+		return false
+	}
 
-	for {
-		if Exit_In_Progress {
-			return
-		}
-		//remote_addr := "localhost:18090"
-		//remote_addr := "192.168.56.1:18090"
-		//remote_addr := "76.74.170.128:18090"
-		//remote_addr := "89.38.97.110:18090"
-		remote_addr := end_point_list[0]
+	v2_addr := &net.TCPAddr{IP: remote_addr.IP, Port: remote_addr.Port + P2PV2_PORT_OFFSET}
+	key := v2_addr.String()
 
-		remote_ip, err := net.ResolveTCPAddr("tcp", remote_addr)
+	failed_mutex.Lock()
+	last_failed, failed := failed_list[key]
+	failed_mutex.Unlock()
+	if failed && time.Since(last_failed) < P2PV2_RETRY_DELAY {
+		return false
+	}
 
-		if err != nil {
-			if Exit_In_Progress {
-				return
+	conn, err := dial_timeout(key, DIAL_TIMEOUT)
+	if err == nil {
+		tls_conn := tls.Client(conn, client_tls_config)
+		tls_conn.SetDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+		if err = tls_conn.Handshake(); err == nil {
+			tls_conn.SetDeadline(time.Time{})
+			logger.Debugf("Connection established to %s", key)
+			if Handle_Connection(tls_conn, v2_addr, false) {
+				return true
 			}
-			logger.Debugf("Resolve address failed:", err.Error())
-			time.Sleep(2 * time.Second)
-			continue
+			err = fmt.Errorf("handshake not completed")
 		}
+		conn.Close()
+	}
 
-		// since we may be connecting through socks, grab the remote ip for our purpose rightnow
-		conn, err := globals.Dialer.Dial("tcp", remote_ip.String())
-		if err != nil {
-			if Exit_In_Progress {
-				return
-			}
-			logger.Debugf("Dial failed err %s", err.Error())
-			time.Sleep(2 * time.Second)
-			continue
-		}
+	logger.Debugf("v2 connection to %s failed err %s, using levin", key, err)
+	failed_mutex.Lock()
+	failed_list[key] = time.Now()
+	failed_mutex.Unlock()
+	return false
+}
 
-		logger.Debugf("Connection established to %s", remote_ip)
-		Handle_Connection(conn, remote_ip, false) // handle  connection
-		time.Sleep(4 * time.Second)
+// dial peer directly or through socks, socks dialer has no timeout so it is enforced here
+func dial_timeout(address string, timeout time.Duration) (net.Conn, error) {
+	if globals.Dialer == proxy.Direct {
+		return net.DialTimeout("tcp", address, timeout)
 	}
 
+	type dial_result struct {
+		conn net.Conn
+		err  error
+	}
+	result := make(chan dial_result, 1)
+	go func() {
+		conn, err := globals.Dialer.Dial("tcp", address)
+		result <- dial_result{conn, err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.conn, r.err
+	case <-time.After(timeout):
+		go func() { // close the connection if the dial completes after all
+			if r := <-result; r.err == nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, fmt.Errorf("dial %s timed out", address)
+	}
 }
 
 func P2P_Server_v2() {
 
 	// listen to incoming tcp connections
-	l, err := net.Listen("tcp", "0.0.0.0:18095")
+	l, err := tls.Listen("tcp", bind_address, server_tls_config)
 	if err != nil {
-		logger.Fatalf("Could not listen on port 18095, errr %s", err)
+		logger.Fatalf("Could not listen on %s, errr %s", bind_address, err)
 	}
 	defer l.Close()
 
@@ -126,6 +197,11 @@ func P2P_Server_v2() {
 			continue
 		}
 		raddr := conn.RemoteAddr().(*net.TCPAddr)
+		if p2p.Is_Banned(raddr.IP) {
+			logger.Debugf("Rejecting connection from banned ip %s", raddr.IP)
+			conn.Close()
+			continue
+		}
 		go Handle_Connection(conn, raddr, true) // handle connection in a different go routine
 	}
 
@@ -134,10 +210,13 @@ func P2P_Server_v2() {
 // shutdown the p2p component
 func P2P_Shutdown() {
 	Exit_In_Progress = true
+	p2p.Connect_V2 = nil
+	p2p.Stem_Peers_V2 = nil
+	p2p.FLAGS_VALUE &^= p2p.P2P_SUPPORT_FLAG_V2
 	close(Exit_Event) // send signal to all connections to exit
 	// TODO we  must wait for connections to kill themselves
 	time.Sleep(1 * time.Second)
-	logger.Infof("P2P Shutdown")
+	logger.Infof("P2P v2 Shutdown")
 	atomic.AddUint32(&globals.Subsystem_Active, ^uint32(0)) // this decrement 1 fom subsystem
 
 }
@@ -0,0 +1,34 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+
+package p2pv2
+
+import "net"
+import "time"
+import "testing"
+
+import "github.com/arnaucode/derosuite/globals"
+
+// socks dialer which never answers
+type stalled_dialer struct{}
+
+func (stalled_dialer) Dial(network, address string) (net.Conn, error) {
+	select {}
+}
+
+// dialing through a stalled proxy must give up after the timeout
+func Test_Dial_Timeout(t *testing.T) {
+	old := globals.Dialer
+	globals.Dialer = stalled_dialer{}
+	defer func() { globals.Dialer = old }()
+
+	start := time.Now()
+	if _, err := dial_timeout("10.1.2.3:18095", 100*time.Millisecond); err == nil {
+		t.Fatalf("dial through stalled proxy should fail")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("dial did not time out, took %s", time.Since(start))
+	}
+}
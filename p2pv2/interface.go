@@ -18,27 +18,37 @@ package p2pv2
 
 // This file defines  what all needs to be responded to become a server ( handling incoming requests)
 
-// entire wire protocol is handled using the following  few functions ( 14 )
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/transaction"
+
+// entire wire protocol is handled using the following  few functions ( 16 )
+// handlers are called by the connection loop with the parsed message
 type Wire interface {
 	Send_Handshake_Command()
-	Handle_Handshake_Command()
+	Handle_Handshake_Command(h *Handshake)
 
 	Send_Sync_Command()
-	Handle_Sync_Command()
+	Handle_Sync_Command(s *Sync)
 
 	Send_Chain_Request_command()
-	Handle_Chain_Request_command()
+	Handle_Chain_Request_command(r *Chain_Request)
+
+	Send_Chain_Response_command(start_height uint64, block_list [][32]byte)
+	Handle_Chain_Response_command(r *Chain_Response)
 
-	Send_Chain_Response_command()
-	Handle_Chain_Response_command()
+	Send_Object_Request_Command(block_list [][32]byte, tx_list [][32]byte)
+	Handle_Object_Request_Command(r *Object_Request)
 
-	Send_Object_Request_Command()
-	Handle_Object_Response_Command()
+	Send_Object_Response_Command(blocks []Complete_Block, txs [][]byte)
+	Handle_Object_Response_Command(r *Object_Response)
 
 	// notifications are here
-	Send_Notify_New_Block()
-	Handle_Notify_New_Block()
+	Send_Notify_New_Block(cbl *block.Complete_Block)
+	Handle_Notify_New_Block(n *Notify_New_Objects)
 
-	Send_Notify_New_Transaction()
-	Handle_Notify_New_Transaction()
+	Send_Notify_New_Transaction(tx *transaction.Transaction)
+	Handle_Notify_New_Transaction(n *Notify_New_Objects)
 }
+
+// Connection is the concrete implementation of the wire protocol
+var _ Wire = (*Connection)(nil)
@@ -0,0 +1,122 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package p2pv2
+
+import "github.com/arnaucode/derosuite/p2p"
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/transaction"
+
+// this file implements notifications, these are asyncronous and not responded to
+
+func (conn *Connection) Send_Notify_New_Block(cbl *block.Complete_Block) {
+	var n Notify_New_Objects
+	fill_common(&n.Common)
+	n.Command = V2_NOTIFY_NEW_BLOCK
+	n.Block.Block = cbl.Bl.Serialize()
+	for i := range cbl.Txs {
+		n.Block.Txs = append(n.Block.Txs, cbl.Txs[i].Serialize())
+	}
+	conn.send_object(&n)
+}
+
+// a new block is floating in the network, add it to chain
+func (conn *Connection) Handle_Notify_New_Block(n *Notify_New_Objects) {
+	cbl, err := deserialize_block(&n.Block)
+	if err != nil {
+		conn.logger.Debugf("Block could not be deserialized err %s, disconnecting peer", err)
+		conn.Exit = true
+		return
+	}
+	hash := cbl.Bl.GetHash()
+	if chain.Block_Exists(hash) {
+		return
+	}
+	if conn.add_block(cbl) && chain.Block_Exists(hash) { // relay further, peers which have it ignore it
+		go broadcast_block(cbl)
+	}
+}
+
+func (conn *Connection) Send_Notify_New_Transaction(tx *transaction.Transaction) {
+	conn.send_transaction(tx, false)
+}
+
+// stem txs are sent only to the stem peer chosen by p2p
+func (conn *Connection) send_transaction(tx *transaction.Transaction, stem bool) {
+	var n Notify_New_Objects
+	fill_common(&n.Common)
+	n.Command = V2_NOTIFY_NEW_TX
+	n.Txs = [][]byte{tx.Serialize()}
+	n.Stem = stem
+	conn.send_object(&n)
+}
+
+// new txs are floating in the network, add them to pool
+func (conn *Connection) Handle_Notify_New_Transaction(n *Notify_New_Objects) {
+	if len(n.Txs) > P2PV2_MAX_OBJECTS {
+		conn.logger.Debugf("Peer sent %d txs, disconnecting peer", len(n.Txs))
+		conn.Exit = true
+		return
+	}
+	for i := range n.Txs {
+		tx, err := deserialize_tx(n.Txs[i])
+		if err != nil {
+			conn.logger.Debugf("Transaction could not be deserialized err %s, disconnecting peer", err)
+			conn.Exit = true
+			return
+		}
+		if n.Stem {
+			err = p2p.Receive_Stem_Transaction(tx)
+		} else {
+			err = chain.Add_TX_To_Pool(tx)
+		}
+		if !conn.check_tx_error(err) {
+			return
+		}
+	}
+}
+
+// send a block to all peers which completed handshake
+func broadcast_block(cbl *block.Complete_Block) {
+	for _, conn := range connection_list() {
+		if conn.HandShakeCompleted && !conn.Exit {
+			conn.Send_Notify_New_Block(cbl)
+		}
+	}
+}
+
+// outgoing peers which completed handshake, p2p chooses stem peer from them
+func stem_peers() map[string]func(tx *transaction.Transaction) {
+	peers := map[string]func(tx *transaction.Transaction){}
+	for _, conn := range connection_list() {
+		if !conn.Incoming && conn.HandShakeCompleted && !conn.Exit {
+			c := conn
+			peers[c.Addr.String()] = func(tx *transaction.Transaction) {
+				c.send_transaction(tx, true)
+			}
+		}
+	}
+	return peers
+}
+
+// send a tx to all peers which completed handshake
+func broadcast_tx(tx *transaction.Transaction) {
+	for _, conn := range connection_list() {
+		if conn.HandShakeCompleted && !conn.Exit {
+			conn.Send_Notify_New_Transaction(tx)
+		}
+	}
+}
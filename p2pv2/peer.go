@@ -16,90 +16,160 @@
 
 package p2pv2
 
-//import "net"
-//import "sync"
 import "time"
 
-//import "container/list"
-
-//import log "github.com/sirupsen/logrus"
 import "github.com/vmihailenco/msgpack"
 
-//import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/p2p"
 import "github.com/arnaucode/derosuite/globals"
 
-//import "github.com/arnaucode/derosuite/blockchain"
-
 // This file defines  what all needs to be responded to become a server ( handling incoming requests)
 
+// peers whose clock differs by more than this are rejected
+const P2PV2_MAX_TIME_DIFF = 2 * 60 * 60
+
 // fill the common part from our chain
 func fill_common(common *Common) {
-	common.Height = chain.Get_Height() - 1
-	common.Top_ID, _ = chain.Load_BL_ID_at_Height(common.Height - 1)
-
+	common.Height = chain.Get_Height()
+	common.Top_ID = chain.Get_Top_ID()
 	common.Cumulative_Difficulty = chain.Load_Block_Cumulative_Difficulty(common.Top_ID)
 	common.Top_Version = 6 // this must be taken from the hardfork
+}
+
+// every incoming message carries common part, keep peer state upto date
+func (conn *Connection) update_common(common *Common) {
+	conn.Last_Height = common.Height
+	conn.Top_ID = common.Top_ID
+	conn.Cumulative_Difficulty = common.Cumulative_Difficulty
+	conn.Top_Version = common.Top_Version
+}
+
+// serialize and send a message to peer
+func (conn *Connection) send_object(msg interface{}) {
+	b, err := msgpack.Marshal(msg)
+	if err != nil {
+		conn.logger.Warnf("Error while serializing message err %s", err)
+		return
+	}
+
+	conn.Lock()
+	defer conn.Unlock()
+	conn.Send_Message(b) // send the message to peer
+}
 
+// returns version supported by both ends, 0 if there is no such version
+func negotiate_version(min_version, max_version uint64) uint64 {
+	version := uint64(P2PV2_VERSION)
+	if max_version < version {
+		version = max_version
+	}
+	if version < P2PV2_MIN_VERSION || version < min_version {
+		return 0
+	}
+	return version
 }
 
 // send the handshake command
-// when we initiate a connection, we immediately send a handshake
+// when we initiate a connection, we immediately send a handshake, server responds with its own handshake
 func (conn *Connection) Send_Handshake_Command() {
 	var h Handshake
 	fill_common(&h.Common) // fill common information
-
-	conn.Lock()
-	defer conn.Unlock()
+	h.Command = V2_COMMAND_HANDSHAKE
 
 	// fill other information
-
 	h.Local_Time = time.Now().UTC().Unix()
-	h.Local_Port = 0
-	h.PeerID = 0
+	h.Local_Port = our_port // levin port, v2 port is derived from it
+	h.PeerID = p2p.OUR_PEER_ID
 	h.Network_ID = globals.Config.Network_ID
-	//PeerList       []Peer_Info `msgpack:"PLIST"`
-	//Extension_List []string `msgpack:"EXT"`
+	h.Request = !conn.Incoming
+	h.Version = P2PV2_VERSION
+	h.Min_Version = P2PV2_MIN_VERSION
 
-	//serialize and send
-	b, err := msgpack.Marshal(h)
-	if err != nil {
-		panic(err)
+	for _, peer := range p2p.Peer_List() { // share the peers we know
+		h.PeerList = append(h.PeerList, Peer_Info{IP: peer.IP, Port: peer.Port, ID: peer.ID, LastSeen: peer.LastSeen})
 	}
 
-	conn.Send_Message(b) // send the message to peer
-
+	conn.send_object(&h)
 }
 
 // a handshake command has been received, make the most of it
 func (conn *Connection) Handle_Handshake_Command(h *Handshake) {
 
-	conn.Lock()
-	defer conn.Unlock()
+	if conn.HandShakeCompleted || h.Request != conn.Incoming { // only client sends request and only once
+		conn.logger.Debugf("Unexpected handshake, rejecting peer")
+		conn.Exit = true
+		return
+	}
 
 	if h.Network_ID != globals.Config.Network_ID {
-		logger.Debugf("Connection represents different network %x rejecting peer err:%s\n", h.Network_ID)
+		conn.logger.Debugf("Connection represents different network %x rejecting peer", h.Network_ID)
 		conn.Exit = true
+		return
+	}
+
+	if h.PeerID == p2p.OUR_PEER_ID {
+		conn.logger.Debugf("Disconnecting, we connected to ourselves")
+		conn.Exit = true
+		return
+	}
+
+	version := negotiate_version(h.Min_Version, h.Version)
+	if version == 0 {
+		conn.logger.Debugf("No common protocol version, peer supports %d-%d, rejecting peer", h.Min_Version, h.Version)
+		conn.Exit = true
+		return
 	}
 
 	// TODO check whether the peer represents  current hardfork, if not reject
 
-	// TODO check if the peer time is +- 2hrs from the our time , reject him ,
+	if diff := time.Now().UTC().Unix() - h.Local_Time; diff > P2PV2_MAX_TIME_DIFF || diff < -P2PV2_MAX_TIME_DIFF {
+		conn.logger.Debugf("Peer clock differs by %d seconds, rejecting peer", diff)
+		conn.Exit = true
+		return
+	}
 
-	// fill other information
+	conn.Version = version
+	conn.Peer_ID = h.PeerID
+	conn.Port = h.Local_Port
 
-	h.Local_Time = time.Now().UTC().Unix()
-	h.Local_Port = 0
-	h.PeerID = 0
-	h.Network_ID = globals.Config.Network_ID
-	//PeerList       []Peer_Info `msgpack:"PLIST"`
-	//Extension_List []string `msgpack:"EXT"`
+	var peers []p2p.Peer_Info
+	for _, peer := range h.PeerList {
+		peers = append(peers, p2p.Peer_Info{IP: peer.IP, Port: peer.Port, ID: peer.ID, LastSeen: peer.LastSeen})
+	}
+	p2p.Peer_List_Add(peers)
 
-	//serialize and send
-	b, err := msgpack.Marshal(h)
-	if err != nil {
-		panic(err)
+	if conn.Incoming { // respond with our handshake
+		conn.Send_Handshake_Command()
 	}
 
-	conn.Send_Message(b) // send the message to peer
+	conn.HandShakeCompleted = true
+	conn.State = IDLE
+	conn.logger.Debugf("Handshake completed, protocol version %d", version)
+
+	conn.sync_if_lagging()
+}
+
+// send our chain status to peer, this is sent whenever connection is idle
+func (conn *Connection) Send_Sync_Command() {
+	var s Sync
+	fill_common(&s.Common)
+	s.Command = V2_COMMAND_SYNC
+	conn.send_object(&s)
+}
+
+// peer status has already been updated, check whether we need to sync from it
+func (conn *Connection) Handle_Sync_Command(s *Sync) {
+	conn.sync_if_lagging()
+}
 
+// request chain from peer, if it is ahead of us and we are not already downloading from it
+func (conn *Connection) sync_if_lagging() {
+	conn.Lock()
+	downloading := len(conn.Requested_Objects) > 0
+	conn.Unlock()
+
+	if !downloading && chain.IsLagging(conn.Cumulative_Difficulty, conn.Last_Height, conn.Top_ID) {
+		conn.logger.Debugf("We need to resync with the peer")
+		conn.Send_Chain_Request_command()
+	}
 }
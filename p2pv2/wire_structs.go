@@ -16,7 +16,10 @@
 
 package p2pv2
 
+import "io"
+import "fmt"
 import "net"
+import "sync/atomic"
 import "encoding/binary"
 
 // This file defines the structure for the protocol which is a msgp encoded ( which is standard)
@@ -41,6 +44,17 @@ const V2_COMMAND_OBJECTS_RESPONSE = 6
 const V2_NOTIFY_NEW_BLOCK = 0x80000000 // Notifications are asyncronous all notifications come here, such as new block, new txs
 const V2_NOTIFY_NEW_TX = 0x80000001    // notify tx using this
 
+// protocol versions, both ends use the highest version supported by both
+const P2PV2_VERSION = 1     // highest version we speak
+const P2PV2_MIN_VERSION = 1 // peers not supporting atleast this version are rejected
+
+// no frame can be bigger than this, otherwise peer can make us allocate any amount of memory
+const P2PV2_MAX_MESSAGE_SIZE = 64 * 1024 * 1024
+
+// maximum number of hashes in chain response and objects in object request/response
+const P2PV2_MAX_CHAIN_HASHES = 1000
+const P2PV2_MAX_OBJECTS = 100
+
 // used to parse incoming packet for for command , so as a repective command command could be triggered
 type Common struct {
 	Command               uint64   `msgpack:"C"`
@@ -60,6 +74,8 @@ type Handshake struct {
 	PeerList       []Peer_Info `msgpack:"PLIST"`
 	Extension_List []string    `msgpack:"EXT"`
 	Request        bool        `msgpack:"REQUEST"` //whether this is a request
+	Version        uint64      `msgpack:"V"`       // highest protocol version supported by sender
+	Min_Version    uint64      `msgpack:"MV"`      // lowest protocol version supported by sender
 }
 
 type Peer_Info struct {
@@ -73,16 +89,20 @@ type Sync struct {
 	Common // add all fields of common
 }
 
+// every message carries common part, so peer state is always upto date
 type Chain_Request struct {
+	Common
 	Block_list [][32]byte `msgpack:"BLIST"`
 }
 
 type Chain_Response struct {
+	Common
 	Start_height uint64     `msgpack:"SH"`
 	Block_list   [][32]byte `msgpack:"BLIST"`
 }
 
 type Object_Request struct {
+	Common
 	Block_list [][32]byte `msgpack:"BLIST"`
 	Tx_list    [][32]byte `msgpack:"TXLIST"`
 }
@@ -93,13 +113,16 @@ type Complete_Block struct {
 }
 
 type Object_Response struct {
+	Common
 	Blocks []Complete_Block `msgpack:"CBLOCKS"`
 	Txs    [][]byte         `msgpack:"TXS"`
 }
 
 type Notify_New_Objects struct {
+	Common
 	Block Complete_Block `msgpack:"CBLOCK"`
 	Txs   [][]byte       `msgpack:"TXS"`
+	Stem  bool           `msgpack:"STEM"` // txs are in dandelion stem phase
 }
 
 // each packet has to be parsed twice once for extracting command and then a full parsing
@@ -111,8 +134,27 @@ func (conn *Connection) Send_Message(data_bytes []byte) {
 	var length_bytes [4]byte
 	binary.LittleEndian.PutUint32(length_bytes[:], uint32(len(data_bytes)))
 
-	// send the length prefix
-	conn.Conn.Write(length_bytes[:])
-	conn.Conn.Write(data_bytes) // send the message itself
+	// send the length prefix and the message in a single write
+	conn.Conn.Write(append(length_bytes[:], data_bytes...))
+	atomic.AddUint64(&conn.Bytes_Sent, uint64(len(data_bytes)+4))
+}
 
+// read a single length prefixed message
+func (conn *Connection) read_message() ([]byte, error) {
+	var length_bytes [4]byte
+	if _, err := io.ReadFull(conn.Conn, length_bytes[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.LittleEndian.Uint32(length_bytes[:]) // convert little endian bytes 4 bytes to length
+	if length > P2PV2_MAX_MESSAGE_SIZE {
+		return nil, fmt.Errorf("message size %d exceeds limit %d", length, P2PV2_MAX_MESSAGE_SIZE)
+	}
+
+	data := make([]byte, length, length)
+	if _, err := io.ReadFull(conn.Conn, data); err != nil {
+		return nil, err
+	}
+	atomic.AddUint64(&conn.Bytes_Received, uint64(length+4))
+	return data, nil
 }
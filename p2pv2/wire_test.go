@@ -0,0 +1,99 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+
+package p2pv2
+
+import "net"
+import "testing"
+import "crypto/tls"
+import "encoding/binary"
+
+import "github.com/vmihailenco/msgpack"
+
+import "github.com/arnaucode/derosuite/globals"
+
+// send a message over TLS and parse it on the other end
+func Test_TLS_Framing(t *testing.T) {
+	cert, err := globals.Generate_Certificate()
+	if err != nil {
+		t.Fatalf("certificate generation failed err %s", err)
+	}
+	server_config, client_config := tls_configs(cert)
+
+	client_conn, server_conn := net.Pipe()
+	client := &Connection{Conn: tls.Client(client_conn, client_config)}
+	server := &Connection{Conn: tls.Server(server_conn, server_config)}
+
+	var h Handshake
+	h.Command = V2_COMMAND_HANDSHAKE
+	h.Height = 1234
+	h.PeerID = 0x55aa
+	h.Version = P2PV2_VERSION
+	h.Request = true
+	b, err := msgpack.Marshal(&h)
+	if err != nil {
+		t.Fatalf("marshal failed err %s", err)
+	}
+
+	sent := make(chan bool)
+	go func() {
+		client.Send_Message(b)
+		close(sent)
+	}()
+
+	buf, err := server.read_message()
+	if err != nil {
+		t.Fatalf("read failed err %s", err)
+	}
+
+	<-sent
+
+	var common Common
+	if err = msgpack.Unmarshal(buf, &common); err != nil || common.Command != V2_COMMAND_HANDSHAKE || common.Height != 1234 {
+		t.Fatalf("common part parsed incorrectly %+v err %v", common, err)
+	}
+
+	var received Handshake
+	if err = msgpack.Unmarshal(buf, &received); err != nil || received.PeerID != h.PeerID || !received.Request || received.Version != P2PV2_VERSION {
+		t.Fatalf("handshake parsed incorrectly %+v err %v", received, err)
+	}
+
+	if server.Bytes_Received != uint64(len(b)+4) || client.Bytes_Sent != uint64(len(b)+4) {
+		t.Fatalf("byte counters incorrect sent %d received %d", client.Bytes_Sent, server.Bytes_Received)
+	}
+}
+
+// peer must not be able to make us allocate arbitrary memory
+func Test_Oversized_Message(t *testing.T) {
+	client_conn, server_conn := net.Pipe()
+	server := &Connection{Conn: server_conn}
+
+	go func() {
+		var length_bytes [4]byte
+		binary.LittleEndian.PutUint32(length_bytes[:], P2PV2_MAX_MESSAGE_SIZE+1)
+		client_conn.Write(length_bytes[:])
+	}()
+
+	if _, err := server.read_message(); err == nil {
+		t.Fatalf("oversized message must be rejected")
+	}
+}
+
+func Test_Negotiate_Version(t *testing.T) {
+	tests := []struct {
+		min_version, max_version, expected uint64
+	}{
+		{P2PV2_MIN_VERSION, P2PV2_VERSION, P2PV2_VERSION},
+		{P2PV2_MIN_VERSION, P2PV2_VERSION + 5, P2PV2_VERSION}, // newer peer talks our version
+		{P2PV2_VERSION + 1, P2PV2_VERSION + 5, 0},             // peer dropped support for our version
+		{0, P2PV2_MIN_VERSION - 1, 0},                         // peer too old
+	}
+
+	for i, test := range tests {
+		if version := negotiate_version(test.min_version, test.max_version); version != test.expected {
+			t.Fatalf("%d negotiate_version(%d,%d) = %d expected %d", i, test.min_version, test.max_version, version, test.expected)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package stratum
+
+import "fmt"
+import "sync"
+import "time"
+import "sync/atomic"
+import "encoding/hex"
+import "encoding/binary"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/blockchain"
+import "github.com/arnaucode/derosuite/transaction"
+
+// space reserved in miner tx extra nonce, first 4 bytes carry the unique extra nonce of the miner
+const RESERVE_SIZE = 8
+
+// templates are refreshed after this time if pool has changed, so new txs are mined
+const TEMPLATE_REFRESH_TIME = 30 * time.Second
+
+// vardiff tries to get a share from each miner every VARDIFF_TARGET_TIME
+const VARDIFF_TARGET_TIME = 15 * time.Second
+const VARDIFF_RETARGET_TIME = 60 * time.Second
+const MIN_DIFFICULTY = 100
+
+// every miner starts at this share difficulty, vardiff adjusts it later on
+var Start_Difficulty = uint64(5000)
+
+// block template for a specific miner address
+type template struct {
+	bl              block.Block
+	blob            []byte // serialized block
+	reserved_offset int
+	difficulty      uint64 // network difficulty
+	height          uint64
+}
+
+// job is a template with miner extra nonce applied
+type job struct {
+	id           string
+	template     *template
+	bl           block.Block // block with extra nonce of the miner
+	hashing_blob []byte
+	nonce_offset int
+	difficulty   uint64 // share difficulty
+
+	submitted map[uint32]bool // nonces already submitted, to reject duplicate shares
+	sync.Mutex
+}
+
+// job as sent to miner
+type job_message struct {
+	Blob   string `json:"blob"`
+	Job_ID string `json:"job_id"`
+	Target string `json:"target"`
+	Height uint64 `json:"height"`
+}
+
+var job_counter uint64
+
+// templates are shared by all miners mining to the same address
+// cleared whenever chain top changes
+var template_map = map[string]*template{}
+var template_mutex sync.Mutex
+
+// get current template for address, creating it if required
+func get_template(miner_address address.Address) (*template, error) {
+	template_mutex.Lock()
+	defer template_mutex.Unlock()
+
+	key := miner_address.String()
+	if t, ok := template_map[key]; ok {
+		return t, nil
+	}
+
+	bl, err := chain.Create_new_miner_block(miner_address, RESERVE_SIZE)
+	if err != nil {
+		return nil, fmt.Errorf("Block template could not be created err %s", err)
+	}
+
+	reserved_offset, err := blockchain.Get_Reserved_Offset(&bl)
+	if err != nil || reserved_offset == 0 {
+		return nil, fmt.Errorf("Reserved offset could not be found err %s", err)
+	}
+
+	t := &template{
+		bl:              bl,
+		blob:            bl.Serialize(),
+		reserved_offset: reserved_offset,
+		difficulty:      chain.Get_Difficulty_At_Block(bl.Prev_Hash),
+		height:          bl.Miner_tx.Vin[0].(transaction.Txin_gen).Height,
+	}
+	template_map[key] = t
+	return t, nil
+}
+
+// place extra nonce in reserved space and create a job at given share difficulty
+func (t *template) create_job(extra_nonce uint32, share_difficulty uint64) (*job, error) {
+	blob := make([]byte, len(t.blob), len(t.blob))
+	copy(blob, t.blob)
+	binary.LittleEndian.PutUint32(blob[t.reserved_offset:], extra_nonce)
+
+	j := &job{template: t, difficulty: share_difficulty, submitted: map[uint32]bool{}}
+	if err := j.bl.Deserialize(blob); err != nil {
+		return nil, fmt.Errorf("Job could not be created err %s", err)
+	}
+
+	j.id = fmt.Sprintf("%d", atomic.AddUint64(&job_counter, 1))
+	j.hashing_blob = j.bl.GetBlockWork()
+	j.nonce_offset = len(j.bl.SerializeHeader()) - 4 // nonce is the last 4 bytes of header
+	return j, nil
+}
+
+// mark nonce as submitted, returns false if it was already submitted
+func (j *job) mark_submitted(nonce uint32) bool {
+	j.Lock()
+	defer j.Unlock()
+	if j.submitted[nonce] {
+		return false
+	}
+	j.submitted[nonce] = true
+	return true
+}
+
+func (j *job) message() job_message {
+	return job_message{
+		Blob:   hex.EncodeToString(j.hashing_blob),
+		Job_ID: j.id,
+		Target: difficulty_to_target(j.difficulty),
+		Height: j.template.height,
+	}
+}
+
+// miners use 32 bit compact target, target = 2^32 / difficulty, sent as little endian hex
+func difficulty_to_target(difficulty uint64) string {
+	if difficulty == 0 {
+		difficulty = 1
+	}
+	target := uint64(0xffffffff) / difficulty
+	if target == 0 { // difficulty is beyond 32 bits, miner will submit only blocks
+		target = 1
+	}
+	buf := make([]byte, 4, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(target))
+	return hex.EncodeToString(buf)
+}
+
+// calculate new share difficulty, based on shares submitted during elapsed time
+// change is limited to 2x per retarget, so a single lucky share does not disturb things much
+func vardiff_retarget(current uint64, shares uint64, elapsed time.Duration) uint64 {
+	expected := float64(elapsed) / float64(VARDIFF_TARGET_TIME) // shares expected during elapsed time
+	ratio := float64(shares) / expected
+
+	if ratio > 2 {
+		ratio = 2
+	}
+	if ratio < 0.5 {
+		ratio = 0.5
+	}
+
+	next := uint64(float64(current) * ratio)
+	if next < MIN_DIFFICULTY {
+		next = MIN_DIFFICULTY
+	}
+	return next
+}
+
+// throw away all templates, and push new jobs to all miners
+func refresh_jobs() {
+	template_mutex.Lock()
+	template_map = map[string]*template{}
+	template_mutex.Unlock()
+
+	for _, c := range connection_list() {
+		c.push_job()
+	}
+}
+
+// push new jobs whenever chain top changes, or pool changes for a while
+func job_refresh_loop() {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	last_top := chain.Get_Top_ID()
+	last_pool_count := len(chain.Mempool.Mempool_List_TX())
+	last_refresh := time.Now()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-Exit_Event:
+			return
+		}
+
+		top := chain.Get_Top_ID()
+		pool_count := len(chain.Mempool.Mempool_List_TX())
+
+		if top != last_top || (pool_count != last_pool_count && time.Since(last_refresh) > TEMPLATE_REFRESH_TIME) {
+			if top != last_top {
+				logger.Debugf("Chain top changed to %s, pushing new jobs", top)
+			}
+			refresh_jobs()
+			last_top, last_pool_count, last_refresh = top, pool_count, time.Now()
+		}
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package stratum
+
+import "fmt"
+import "net"
+import "sync"
+import "time"
+import "strings"
+import "sync/atomic"
+import "encoding/hex"
+import "encoding/json"
+import "encoding/binary"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/difficulty"
+import "github.com/arnaucode/derosuite/cryptonight"
+
+// only these many jobs are kept per miner, shares for older jobs are rejected
+const MAX_JOBS_PER_MINER = 4
+
+var extra_nonce_counter uint32 // every miner gets a unique extra nonce, so miners never do duplicate work
+
+// every connected miner is tracked using this
+type Miner_Connection struct {
+	conn        net.Conn
+	logger      *log.Entry
+	session_id  string
+	logged_in   bool
+	address     address.Address
+	extra_nonce uint32
+
+	difficulty     uint64    // current share difficulty
+	shares         uint64    // shares since last retarget
+	last_retarget  time.Time // time of last retarget
+	valid_shares   uint64
+	invalid_shares uint64
+	blocks_found   uint64
+	jobs           []*job // latest job is last
+	write_mutex    sync.Mutex
+	sync.Mutex
+}
+
+type login_params struct {
+	Login string `json:"login"`
+	Pass  string `json:"pass"`
+	Agent string `json:"agent"`
+}
+
+type login_result struct {
+	ID     string      `json:"id"`
+	Job    job_message `json:"job"`
+	Status string      `json:"status"`
+}
+
+type submit_params struct {
+	ID     string `json:"id"`
+	Job_ID string `json:"job_id"`
+	Nonce  string `json:"nonce"`
+	Result string `json:"result"`
+}
+
+type status_result struct {
+	Status string `json:"status"`
+}
+
+func new_miner_connection(conn net.Conn) *Miner_Connection {
+	c := &Miner_Connection{conn: conn}
+	c.extra_nonce = atomic.AddUint32(&extra_nonce_counter, 1)
+	c.session_id = fmt.Sprintf("%x", crypto.RandomScalar()[:8])
+	c.difficulty = Start_Difficulty
+	c.last_retarget = time.Now()
+	c.logger = logger.WithFields(log.Fields{"RIP": conn.RemoteAddr().String()})
+	return c
+}
+
+// serialize and send a message to miner, a single message is written at a time
+func (c *Miner_Connection) send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.write_mutex.Lock()
+	defer c.write_mutex.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err = c.conn.Write(append(data, '\n'))
+	return err
+}
+
+// process a request and return result or error
+func (c *Miner_Connection) dispatch(req *request) (result interface{}, serr *stratum_error) {
+	switch req.Method {
+	case "login":
+		var p login_params
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &stratum_error{Code: -1, Message: "Invalid login params"}
+		}
+		return c.login(&p)
+
+	case "getjob":
+		if !c.logged_in {
+			return nil, &stratum_error{Code: -1, Message: "Unauthenticated"}
+		}
+		job, err := c.new_job()
+		if err != nil {
+			return nil, &stratum_error{Code: -1, Message: err.Error()}
+		}
+		return job.message(), nil
+
+	case "submit":
+		if !c.logged_in {
+			return nil, &stratum_error{Code: -1, Message: "Unauthenticated"}
+		}
+		var p submit_params
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &stratum_error{Code: -1, Message: "Invalid submit params"}
+		}
+		if err := c.submit(&p); err != nil {
+			atomic.AddUint64(&c.invalid_shares, 1)
+			c.logger.Debugf("Share rejected err %s", err)
+			return nil, &stratum_error{Code: -1, Message: err.Error()}
+		}
+		return status_result{Status: "OK"}, nil
+
+	case "keepalived":
+		return status_result{Status: "KEEPALIVED"}, nil
+	}
+
+	return nil, &stratum_error{Code: -1, Message: fmt.Sprintf("Unknown method %s", req.Method)}
+}
+
+// miner login with the wallet address where the rewards go
+func (c *Miner_Connection) login(p *login_params) (result interface{}, serr *stratum_error) {
+	// some miners append worker name or difficulty after a dot or plus
+	addr_string := strings.TrimSpace(p.Login)
+	if i := strings.IndexAny(addr_string, ".+"); i > 0 {
+		addr_string = addr_string[:i]
+	}
+
+	addr, err := address.NewAddress(addr_string)
+	if err != nil {
+		return nil, &stratum_error{Code: -1, Message: fmt.Sprintf("Invalid address err %s", err)}
+	}
+	if addr.Network != globals.Config.Public_Address_Prefix {
+		return nil, &stratum_error{Code: -1, Message: "Address belongs to different network"}
+	}
+
+	c.Lock()
+	c.address = *addr
+	c.logged_in = true
+	c.logger = c.logger.WithFields(log.Fields{"miner": addr_string[:12]})
+	c.Unlock()
+
+	c.logger.Infof("Miner logged in, agent %s", p.Agent)
+
+	job, err := c.new_job()
+	if err != nil {
+		return nil, &stratum_error{Code: -1, Message: err.Error()}
+	}
+	return login_result{ID: c.session_id, Job: job.message(), Status: "OK"}, nil
+}
+
+// create a new job for the miner from latest template, using current vardiff
+func (c *Miner_Connection) new_job() (*job, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	t, err := get_template(c.address)
+	if err != nil {
+		return nil, err
+	}
+
+	// retarget share difficulty, it must never be more than network difficulty
+	c.retarget(t.difficulty)
+
+	j, err := t.create_job(c.extra_nonce, c.difficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	c.jobs = append(c.jobs, j)
+	if len(c.jobs) > MAX_JOBS_PER_MINER {
+		c.jobs = c.jobs[len(c.jobs)-MAX_JOBS_PER_MINER:]
+	}
+	return j, nil
+}
+
+// push a new job to the miner, called when the chain top changes
+// this runs outside the connection goroutine, so login state is read under the lock
+func (c *Miner_Connection) push_job() {
+	c.Lock()
+	logged_in, miner_logger := c.logged_in, c.logger
+	c.Unlock()
+
+	if !logged_in {
+		return
+	}
+	j, err := c.new_job()
+	if err != nil {
+		miner_logger.Warnf("Job could not be created err %s", err)
+		return
+	}
+	if err = c.send(notification{JSONRPC: "2.0", Method: "job", Params: j.message()}); err != nil {
+		miner_logger.Debugf("Job could not be pushed err %s", err)
+	}
+}
+
+// retarget the share difficulty, must be called with lock held
+func (c *Miner_Connection) retarget(network_difficulty uint64) {
+	if elapsed := time.Since(c.last_retarget); elapsed >= VARDIFF_RETARGET_TIME {
+		c.difficulty = vardiff_retarget(c.difficulty, c.shares, elapsed)
+		c.shares = 0
+		c.last_retarget = time.Now()
+	}
+	if c.difficulty > network_difficulty {
+		c.difficulty = network_difficulty
+	}
+}
+
+// find job using its id
+func (c *Miner_Connection) find_job(job_id string) *job {
+	c.Lock()
+	defer c.Unlock()
+	for _, j := range c.jobs {
+		if j.id == job_id {
+			return j
+		}
+	}
+	return nil
+}
+
+// verify the share, if it satisfies network difficulty, add the block to chain
+func (c *Miner_Connection) submit(p *submit_params) error {
+	j := c.find_job(p.Job_ID)
+	if j == nil {
+		return fmt.Errorf("Invalid job id")
+	}
+
+	nonce_bytes, err := hex.DecodeString(p.Nonce)
+	if err != nil || len(nonce_bytes) != 4 {
+		return fmt.Errorf("Invalid nonce")
+	}
+	nonce := binary.LittleEndian.Uint32(nonce_bytes)
+
+	if !j.mark_submitted(nonce) {
+		return fmt.Errorf("Duplicate share")
+	}
+
+	work := make([]byte, len(j.hashing_blob), len(j.hashing_blob))
+	copy(work, j.hashing_blob)
+	copy(work[j.nonce_offset:], nonce_bytes)
+
+	var pow crypto.Hash
+	copy(pow[:], cryptonight.SlowHash(work))
+
+	if !difficulty.CheckPowHash(pow, j.difficulty) {
+		return fmt.Errorf("Low difficulty share")
+	}
+
+	c.Lock()
+	c.shares++
+	c.Unlock()
+	atomic.AddUint64(&c.valid_shares, 1)
+
+	if difficulty.CheckPowHash(pow, j.template.difficulty) { // we found a block
+		bl := j.bl
+		bl.Nonce = nonce
+		blid, err := chain.Accept_new_block(bl.Serialize())
+		if err != nil {
+			c.logger.Warnf("Block %s found by miner rejected err %s", blid, err)
+		} else {
+			atomic.AddUint64(&c.blocks_found, 1)
+			c.logger.Infof("Block %s found by miner at height %d", blid, j.template.height)
+			go refresh_jobs() // everyone should move to the new top now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package stratum
+
+// this file implements a stratum server, so as pool style miners can solo mine directly against the daemon
+// protocol is line based json rpc, same as used by monero pools ( login, getjob, submit, keepalived )
+// each miner mines to the address it logs in with
+
+import "net"
+import "sync"
+import "time"
+import "bufio"
+import "sync/atomic"
+import "encoding/json"
+import "runtime/debug"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/blockchain"
+
+const MAX_REQUEST_SIZE = 16 * 1024          // requests bigger than this are rejected
+const CONNECTION_TIMEOUT = 10 * time.Minute // miners which do not talk for this long are disconnected
+
+var chain *blockchain.Blockchain // external reference to chain
+var logger *log.Entry            // global logger, every logger in this package is a child of this
+
+var listener net.Listener
+var Exit_In_Progress bool
+var Exit_Event = make(chan bool) // causes all threads to exit
+
+var connection_map = map[*Miner_Connection]bool{}
+var connection_mutex sync.Mutex
+
+// incoming request from miner
+type request struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+// response to a request
+type response struct {
+	ID      *json.RawMessage `json:"id"`
+	JSONRPC string           `json:"jsonrpc"`
+	Result  interface{}      `json:"result"`
+	Error   interface{}      `json:"error"`
+}
+
+// new jobs are pushed to miners using this
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type stratum_error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// start the stratum server on the address given by --stratum-bind
+func Stratum_Start(params map[string]interface{}) (err error) {
+	logger = globals.Logger.WithFields(log.Fields{"com": "STRATUM"}) // all components must use this logger
+	chain = params["chain"].(*blockchain.Blockchain)
+
+	bind_address := params["--stratum-bind"].(string)
+	listener, err = net.Listen("tcp", bind_address)
+	if err != nil {
+		logger.Warnf("Could not listen on %s, err %s", bind_address, err)
+		return
+	}
+
+	go stratum_server()
+	go job_refresh_loop()
+
+	logger.Infof("Stratum server started on %s", bind_address)
+	atomic.AddUint32(&globals.Subsystem_Active, 1) // increment subsystem
+	return nil
+}
+
+// shutdown the stratum component, all miners are disconnected
+func Stratum_Shutdown() {
+	if listener == nil {
+		return
+	}
+	Exit_In_Progress = true
+	close(Exit_Event)
+	listener.Close()
+
+	for _, c := range connection_list() {
+		c.conn.Close()
+	}
+	logger.Infof("Stratum Shutdown")
+	atomic.AddUint32(&globals.Subsystem_Active, ^uint32(0)) // this decrement 1 fom subsystem
+}
+
+// accept connections till we are shutdown
+func stratum_server() {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if Exit_In_Progress { // break the loop, since we are exiting
+				return
+			}
+			logger.Warnf("Err while accepting incoming connection errr %s", err)
+			continue
+		}
+		go handle_connection(conn)
+	}
+}
+
+// return a snapshot of all connections
+func connection_list() (list []*Miner_Connection) {
+	connection_mutex.Lock()
+	defer connection_mutex.Unlock()
+	for c := range connection_map {
+		list = append(list, c)
+	}
+	return
+}
+
+// number of miners connected
+func Miner_Count() int {
+	connection_mutex.Lock()
+	defer connection_mutex.Unlock()
+	return len(connection_map)
+}
+
+// reads requests line by line and responds to them
+func handle_connection(conn net.Conn) {
+	c := new_miner_connection(conn)
+
+	connection_mutex.Lock()
+	connection_map[c] = true
+	connection_mutex.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Warnf("Recovered while handling miner connection, Stack trace below %+v", r)
+			c.logger.Warnf("Stack trace  \n%s", debug.Stack())
+		}
+		connection_mutex.Lock()
+		delete(connection_map, c)
+		connection_mutex.Unlock()
+		conn.Close()
+		c.logger.Debugf("Miner disconnected")
+	}()
+
+	c.logger.Debugf("Miner connected")
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 1024), MAX_REQUEST_SIZE)
+	for {
+		conn.SetReadDeadline(time.Now().Add(CONNECTION_TIMEOUT))
+		if !scanner.Scan() {
+			return
+		}
+
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			c.logger.Debugf("Invalid request from miner err %s", err)
+			return
+		}
+
+		result, serr := c.dispatch(&req)
+		if err := c.send(response{ID: req.ID, JSONRPC: "2.0", Result: result, Error: serr}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package stratum
+
+import "net"
+import "time"
+import "testing"
+import "encoding/binary"
+
+import log "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/block"
+import "github.com/arnaucode/derosuite/walletapi"
+import "github.com/arnaucode/derosuite/blockchain"
+
+func Test_Difficulty_To_Target(t *testing.T) {
+	tests := []struct {
+		difficulty uint64
+		target     string
+	}{
+		{1, "ffffffff"},
+		{2, "ffffff7f"},
+		{5000, "711b0d00"},
+		{0x100000000, "01000000"}, // beyond 32 bits
+	}
+
+	for _, test := range tests {
+		if target := difficulty_to_target(test.difficulty); target != test.target {
+			t.Errorf("difficulty %d target expected %s actual %s", test.difficulty, test.target, target)
+		}
+	}
+}
+
+func Test_Vardiff_Retarget(t *testing.T) {
+	tests := []struct {
+		current  uint64
+		shares   uint64
+		elapsed  time.Duration
+		expected uint64
+	}{
+		{10000, 4, 60 * time.Second, 10000},   // on target
+		{10000, 8, 60 * time.Second, 20000},   // twice as fast
+		{10000, 100, 60 * time.Second, 20000}, // change is limited to 2x
+		{10000, 0, 60 * time.Second, 5000},    // no shares
+		{150, 0, 60 * time.Second, MIN_DIFFICULTY},
+	}
+
+	for _, test := range tests {
+		if next := vardiff_retarget(test.current, test.shares, test.elapsed); next != test.expected {
+			t.Errorf("vardiff %+v actual %d", test, next)
+		}
+	}
+}
+
+// extra nonce must land in reserved space, without disturbing anything else
+func Test_Create_Job(t *testing.T) {
+	account, _ := walletapi.Generate_Keys_From_Random()
+
+	var bl block.Block
+	var err error
+	bl.Major_Version = 6
+	bl.Minor_Version = 6
+	bl.Timestamp = uint64(time.Now().Unix())
+	bl.Miner_tx, err = blockchain.Create_Miner_TX(6, 1000, 123456789, account.GetAddress(), RESERVE_SIZE)
+	if err != nil {
+		t.Fatalf("error creating miner tx, err :%s", err)
+	}
+
+	reserved_offset, err := blockchain.Get_Reserved_Offset(&bl)
+	if err != nil {
+		t.Fatalf("reserved offset could not be found err %s", err)
+	}
+	tmpl := &template{bl: bl, blob: bl.Serialize(), reserved_offset: reserved_offset, difficulty: 100000, height: 1000}
+
+	job1, err := tmpl.create_job(1, 5000)
+	if err != nil {
+		t.Fatalf("job could not be created err %s", err)
+	}
+	job2, err := tmpl.create_job(2, 5000)
+	if err != nil {
+		t.Fatalf("job could not be created err %s", err)
+	}
+
+	if job1.id == job2.id {
+		t.Fatalf("job ids must be unique")
+	}
+
+	// different extra nonce means different miner tx, so different work
+	if string(job1.hashing_blob) == string(job2.hashing_blob) {
+		t.Fatalf("jobs with different extra nonce must have different hashing blob")
+	}
+
+	extra := job1.bl.Miner_tx.Extra
+	if binary.LittleEndian.Uint32(extra[len(extra)-RESERVE_SIZE:]) != 1 {
+		t.Fatalf("extra nonce not placed in reserved space")
+	}
+
+	if job1.nonce_offset != 39 {
+		t.Fatalf("nonce offset expected 39 actual %d", job1.nonce_offset)
+	}
+
+	if !job1.mark_submitted(7) || job1.mark_submitted(7) {
+		t.Fatalf("duplicate shares must be detected")
+	}
+}
+
+// miners must login before requesting jobs or submitting shares
+func Test_Dispatch_Unauthenticated(t *testing.T) {
+	logger = log.New().WithFields(log.Fields{"com": "STRATUM"})
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	c := new_miner_connection(server)
+
+	for _, method := range []string{"getjob", "submit"} {
+		if _, serr := c.dispatch(&request{Method: method}); serr == nil || serr.Message != "Unauthenticated" {
+			t.Errorf("%s must fail for unauthenticated miner", method)
+		}
+	}
+
+	if _, serr := c.dispatch(&request{Method: "login", Params: []byte(`{"login":"invalid address"}`)}); serr == nil {
+		t.Errorf("login with invalid address must fail")
+	}
+
+	if _, serr := c.dispatch(&request{Method: "no_such_method"}); serr == nil {
+		t.Errorf("unknown method must fail")
+	}
+
+	if result, serr := c.dispatch(&request{Method: "keepalived"}); serr != nil || result.(status_result).Status != "KEEPALIVED" {
+		t.Errorf("keepalived failed")
+	}
+}
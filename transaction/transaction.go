@@ -209,6 +209,16 @@ func (tx *Transaction) IsCoinbase() (result bool) {
 }
 
 func (tx *Transaction) DeserializeHeader(buf []byte) (err error) {
+	return tx.deserialize(buf, nil)
+}
+
+// deserialize a pruned tx, which only contains prefix and ringct base, prunable hash is stored separately
+func (tx *Transaction) DeserializePruned(buf []byte, prunable_hash crypto.Hash) (err error) {
+	return tx.deserialize(buf, &prunable_hash)
+}
+
+// if prunable_hash is not nil, the tx is pruned
+func (tx *Transaction) deserialize(buf []byte, prunable_hash *crypto.Hash) (err error) {
 
 	Key_offset_count := uint64(0) // used to calculate expected signatures in v1
 
@@ -440,7 +450,11 @@ func (tx *Transaction) DeserializeHeader(buf []byte) (err error) {
 
 		Mixin -= 1 // one is ours, rest are mixin
 
-		tx.RctSignature, err = ringct.ParseRingCtSignature(bufreader, len(tx.Vin), len(tx.Vout), Mixin)
+		if prunable_hash != nil {
+			tx.RctSignature, err = ringct.ParseRingCtSignatureBase(bufreader, len(tx.Vin), len(tx.Vout), *prunable_hash)
+		} else {
+			tx.RctSignature, err = ringct.ParseRingCtSignature(bufreader, len(tx.Vin), len(tx.Vout), Mixin)
+		}
 		if err != nil {
 			return err
 		}
@@ -654,10 +668,33 @@ func (tx *Transaction) SerializeHeader() []byte {
 
 }
 
+// serialize tx without the prunable part of signature, this is how pruned txs are stored
+func (tx *Transaction) SerializePruned() []byte {
+	if tx.Version == 1 { // signatures are the prunable part of version 1
+		return tx.SerializeHeader()
+	}
+	return append(tx.SerializeHeader(), tx.RctSignature.SerializeBase()...)
+}
+
+// whether the prunable part of signature has been discarded
+func (tx *Transaction) IsPruned() bool {
+	return tx.Version == 2 && tx.RctSignature != nil && tx.RctSignature.IsPruned()
+}
+
 // serialize entire transaction include signature
+// NOTE: pruned txs serialize without prunable part, check IsPruned
 func (tx *Transaction) Serialize() []byte {
 
 	header_bytes := tx.SerializeHeader()
+
+	if tx.Version == 1 { // old style signatures
+		for _, s := range tx.Signature {
+			header_bytes = append(header_bytes, s.R[:]...)
+			header_bytes = append(header_bytes, s.C[:]...)
+		}
+		return header_bytes
+	}
+
 	base_bytes := tx.RctSignature.SerializeBase()
 	prunable := tx.RctSignature.SerializePrunable()
 
@@ -0,0 +1,162 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package transaction
+
+import "fmt"
+import "encoding/hex"
+import "encoding/json"
+
+import "github.com/arnaucode/derosuite/crypto/ringct"
+
+// canonical json encoding of transaction, same layout as the cryptonote daemon "as_json"
+// variants are encoded as single key objects, eg {"key": {...}} for txin_to_key
+
+type Transaction_JSON struct {
+	Version         uint64                      `json:"version"`
+	Unlock_Time     uint64                      `json:"unlock_time"`
+	Vin             []map[string]interface{}    `json:"vin"`
+	Vout            []Tx_out_JSON               `json:"vout"`
+	Extra           []uint                      `json:"extra"`                     // json cannot show []byte as array
+	Signatures      []string                    `json:"signatures,omitempty"`      // version 1, one entry per input
+	Rct_Signatures  *ringct.RctSigBase_JSON     `json:"rct_signatures,omitempty"`  // version 2
+	Rctsig_Prunable *ringct.RctSigPrunable_JSON `json:"rctsig_prunable,omitempty"` // version 2, absent if pruned
+}
+
+type Tx_out_JSON struct {
+	Amount uint64                 `json:"amount"`
+	Target map[string]interface{} `json:"target"`
+}
+
+type txout_to_script_json struct {
+	Keys   []string `json:"keys"`
+	Script string   `json:"script"`
+}
+
+// build json form of the tx, if prune is set prunable data is omitted
+func (tx *Transaction) JSON(prune bool) (result Transaction_JSON, err error) {
+	result.Version = tx.Version
+	result.Unlock_Time = tx.Unlock_Time
+	result.Vin = []map[string]interface{}{}
+	result.Vout = []Tx_out_JSON{}
+	result.Extra = []uint{}
+
+	for _, current_vin := range tx.Vin {
+		switch vin := current_vin.(type) {
+		case Txin_gen:
+			result.Vin = append(result.Vin, map[string]interface{}{"gen": map[string]interface{}{"height": vin.Height}})
+		case Txin_to_key:
+			offsets := vin.Key_offsets
+			if offsets == nil {
+				offsets = []uint64{}
+			}
+			result.Vin = append(result.Vin, map[string]interface{}{"key": map[string]interface{}{
+				"amount":      vin.Amount,
+				"key_offsets": offsets,
+				"k_image":     vin.K_image,
+			}})
+		case Txin_to_script:
+			result.Vin = append(result.Vin, map[string]interface{}{"script": map[string]interface{}{
+				"prev":    hex.EncodeToString(vin.Prev[:]),
+				"prevout": vin.Prevout,
+				"sigset":  hex.EncodeToString(vin.Sigset),
+			}})
+		case Txin_to_scripthash:
+			result.Vin = append(result.Vin, map[string]interface{}{"scripthash": map[string]interface{}{
+				"prev":    hex.EncodeToString(vin.Prev[:]),
+				"prevout": vin.Prevout,
+				"script":  script_json(vin.Script),
+				"sigset":  hex.EncodeToString(vin.Sigset),
+			}})
+		default:
+			return result, fmt.Errorf("Unknown vin type %T", current_vin)
+		}
+	}
+
+	for _, current_vout := range tx.Vout {
+		out := Tx_out_JSON{Amount: current_vout.Amount}
+		switch target := current_vout.Target.(type) {
+		case Txout_to_key:
+			out.Target = map[string]interface{}{"key": target.Key}
+		case Txout_to_script:
+			out.Target = map[string]interface{}{"script": script_json(target)}
+		case Txout_to_scripthash:
+			out.Target = map[string]interface{}{"scripthash": hex.EncodeToString(target.Hash[:])}
+		default:
+			return result, fmt.Errorf("Unknown vout type %T", current_vout.Target)
+		}
+		result.Vout = append(result.Vout, out)
+	}
+
+	for _, b := range tx.Extra {
+		result.Extra = append(result.Extra, uint(b))
+	}
+
+	switch tx.Version {
+	case 1:
+		if prune {
+			break
+		}
+		// signatures are stored flat, ring size of each input tells how many belong to it
+		sigs := tx.Signature
+		for _, current_vin := range tx.Vin {
+			vin, ok := current_vin.(Txin_to_key)
+			if !ok {
+				continue
+			}
+			ring_size := len(vin.Key_offsets)
+			if ring_size > len(sigs) {
+				return result, fmt.Errorf("Transaction has fewer signatures than ring members")
+			}
+			var sig_bytes []byte
+			for _, s := range sigs[:ring_size] {
+				sig_bytes = append(sig_bytes, s.R[:]...)
+				sig_bytes = append(sig_bytes, s.C[:]...)
+			}
+			result.Signatures = append(result.Signatures, hex.EncodeToString(sig_bytes))
+			sigs = sigs[ring_size:]
+		}
+	case 2:
+		if tx.RctSignature == nil {
+			return result, fmt.Errorf("Transaction is missing ringct signature")
+		}
+		base := tx.RctSignature.Base_JSON()
+		result.Rct_Signatures = &base
+		if !prune {
+			result.Rctsig_Prunable = tx.RctSignature.Prunable_JSON()
+		}
+	}
+
+	return
+}
+
+// json encoded form of tx, indented same as the cryptonote daemon
+func (tx *Transaction) Serialize_JSON(prune bool) ([]byte, error) {
+	result, err := tx.JSON(prune)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
+
+func script_json(script Txout_to_script) (result txout_to_script_json) {
+	result.Keys = []string{}
+	for i := range script.Keys {
+		result.Keys = append(result.Keys, hex.EncodeToString(script.Keys[i][:]))
+	}
+	result.Script = hex.EncodeToString(script.Script)
+	return
+}
@@ -16,10 +16,11 @@
 
 package transaction
 
-//import "fmt"
+import "fmt"
 import "bytes"
 import "testing"
 import "encoding/hex"
+import "encoding/json"
 
 import "github.com/arnaucode/derosuite/crypto/ringct"
 
@@ -151,3 +152,115 @@ func Test_RingCT_simple_block_726_2649e0eac5b836ed36b3f4e512824855e222cff7b84652
 	}
 
 }
+
+// pruned tx must keep its hash, so pruned nodes can still serve tx ids and outputs
+func Test_RingCT_Pruning(t *testing.T) {
+
+	// this tx is from block 726
+	// tx_id 2649e0eac5b836ed36b3f4e512824855e222cff7b84652452c769bbed9d06b73
+	tx_hex := "020002020005098e0178a201b901ea57543450b2b743e729af2daf02c07d65928d25965fec14be1b70464ec2ebf10200054c20b601d302247beed79270b09d389e28307c0360971d0bbc946e16e453d32178ba0c398b88ec020002ad00e3246f39a133b4200aad5a282da042b35d1882bfed68876187621fdfbd62000257a50356a0c27dd5b916f8850abb6d559051513fa66d2f5b0cddedad0e4125a52101ea3881b1c46e415db7223b596f7cafa34589ba01ce0146ba407ea1fca5cddc840280f6a7b48502841a8e93587ba92028de21dda0ede1e8ff4b371021a3637b02aa83d23b5f7af39578053192e8412f81975706bc36e84d0a6a4ee3b37edba70be5968ce4e04fdb049e6838e9c1e6e8cb8aad4c8cb7394b6f179b6b01453e1ce30feb5d5376e808ea3a7ebb7ccead06cc1740a5e0318c6d65fb573025b9a6928addb8265474e50451ab1917dfbde37fe7b40c307bbd0968997e77d0c2f10cfb1c9dcf18eea2810d67ea074e3a07c983658e857d3bd7f945f883a95af577552f76c5b81c39c4c306589ee909095855b26ec9264554bd6d61cca132a61bd2996cd7dcef5b9372429633e54d60b037c1720e10075c4be2432bab398fed87eeb85913bd0c44fc2121f134473d7a9b00f9cfcfff7b0027f595547ed1bf695fd7b0defd8bc394c07f37098499e30dcffc85f79df6d8ee5169ab3c074dad7818211f25bff13b870cf02c0ba62d2124e3a33b28c5d292575fd1802c918941491a3b590e87168d989e21ed08421ae138d70c244cbdcbaa87ab962368880425e8e649c9e65682b230d9cf2a08309861de615972770630dd84d2c2fcbb64426b5f02062994613252dab13cf205a9a9f260a67b12525efe5632e4c41b527da4e655ffa4a95a75c25c8f07c4520d2e4983b9b036df1ebfa001787c5ee0f376f6f34cd987011b1dd39b4b71dc350d01710b18d6225a616e3f62381f60ad1113c7ae493889e073204d3d3967a7840de9b55aee29d4d4b0dc5eb3899e774f4b415c3212208fc6e8e4199fd9ec7b9a018c77034574d388e7317e48492c7bdf3f1f4f3dbcf1a4851a16c46e8a8b313309979d168e9b8bbe6779368680a732adecf82c9d0fbfee1ce2c3e37c3a760e990aeaf7aa9608680add4de4b8d250d15dd92d029d9c2f7dbdfbee6a5fccde1f6709dbc1060057ddfd9d581d8b87dd89a6256337140fd59aed7fe699f30f7c2c450a8f8d5972c9f39af524e4b39976b8b88c1bfe9e2131f24f3d631c6452a199680fcec538779f2d043762ec468993201567bfbc17e1132b0bad4cb797d3a2322808e7dbcf1d2dc55dd795184565b5d43a36d0b348c62d93434ae7d6d6ed8e0bfc0640e3d8ff7924d62b2a7d1c67089eb1bbe9d01fc811d8ca3ead2f7954b31af50409072246ff67b970cdc7a767f552caf581f1eb7ff5ac84a16a5876b4ab0bfe02233e591ab8d7a484817ce1289aea4383c356ef2de31e5bedb006aa53d498c40b78a56b67f3f8671fc0ee28d32672cf5b8c6f74589c204603fab3ebabca86310b3205f718cbfac39453e70d23f2288b490533ab82fbe207f8251c5dfc8ac2fc01ee38d4bdba4e936c88b813a05252dcefa40509ce599ccee49271298522457b047a886c24eb9fb465f1e156300915d7b67b19d1e653fa463b1725b728f9dd7b04ac1781d0dac1e825a17a08026769e01c3d098054be8a3aa248165eb30df2d7042a8703d4487667184445f37d6c604c20757da6834d0d1ca2cda258f7fd870a01ec3aaad4ebc0e9d082c718c17702cf8a380237ed4de57c322b521347c2256b01d74ae31196913f8e246330b6816c1b1ad6b9117037b6673a1446dbb43f27c30e920779499d62286028a9fd86751d37c8b868ce390ede7cb28faf99aae085300caa358a87478afc21a920b007515a9b3de67ef869336285bd6f1c485d4b73d70c756936b71ba3f11b051e780421fba2675b93a05368ac6fcf47f65d4f9006c6092f2bff91426309eb640502fccfba4ae41b831b07f2b6264be97e3df738111c09c8468b0b18b1827f65faa9022bb9b71189be0da19dc06209249a05072489b20274a5bffed30730638699159c92763b89e6addd40b6b6deb2526129a3fda54a09d3a52cc0e72fd23f2f4c8f07a75544ac45421240656aa2b7476eb0ae644e430f367aa0eeefc6dd88664bd4e604dd354a997103540b1211755dd127ceba13f10d4708340ab276b37a4604322c2ffc6e1c75fa4f7a269e7211e9125ee1f8c33f0da7a76783df38b7a97bb3b690689aad5bbda4f8870d3b8e37cb5fcf557d90200983527f96855d880129509097c7887bb68bd6fed252ecbc2aac1caa4314abfa027bc704afc2e932baf64df21a9a2f1b5122af0eed56f3e91e99934500fc64f10df527275fae7cfe96c37d5cc0e2790852cb02c591ec8d6b200996563c84ebbd08b1747bcbb687f2fa942129f0c214d986ae3ce6a7f43a4b5696fefab7333ec70163092514503aae6e50da0a4894c4b9accf396fc3404177c862e4a184da2ee504025be5073804234edc086d59d8e864624307978be8a135f6d02f6fff98d177095dd57f11fa9e99921b7c28de6deb2a869cd02aa4cbb7235889ea550c73ae96006b2230099f9863bdc6798d5581c705a3d509f7abcf2432768e01151c90161903d785c6b6985aa0c9d01ac38f71e7849fb602bf7e94c382da5f19b551a3d6f703ead04cc1799b7f143f012ec819753fcd29236558885d1c875574af72fbe6020f7d6b1c6dc9641c27b2d8b0bb1b457db8b44efeb68d9d95c7cec5559a57f8e104e41327a810d0411b0f95917990ed7a9c7b75aa7c720338cc716b511037242c0beaf0424bdf61be9c1eb8ec7f189251db203f366f1bb993ae068bd34f63d0020a5cde88b140fa5dcc1d920e7a265374d8b6bbe86d84b8b92761e6c5e514a9f701006c04a59d4c9b8e2b2c3ca93a04883a67969b2c9be4405e2ac366f6750ea8052a99478fe7a8dbb2c4a9096fc94f02e032b75d2154cdab2a4d7cfc19183f0f0d71b6fa1993b32a46aaa8e3709ad081836e0a5a52e93adc28672ff10b537fa7053692dc6831c056ee153a76a307dbffa04d8ce911e395230a7ef20ae884728105f7639a17b02d6e9278627116f2480ffd9f654055d9e5aa7873f56dd0770de30d7f97ab6a18d25f7501970f0b77d126b829327693cb487ebb7792753629771e0fe0182c370f545b355e12164395c80cde580c12bed9163eae126d15afe936920d5e5650665cc29ccfd0496ba5e3c29938f18a788c50b30cf54f68929207c4de0a23ac43aa30bd4009ded612f93c96614247f75f4a13fad90b57fe362eb561070184ac25f3225f51facc68b04fbff2d776bfe804311ae61c6028c2a536e150dc071d7cf6142b15684093cfb345ea22b8526adfbf7177694a9f62fd2031256d990afe874566f498e9b9f676033812d3f5443c6b3e6bbfbd4353efff2a5098e6770e9a1a4f70def6a85dab2afc12b948b7b7a2156c81a8ba3ab86625d4d00d62e80aa8865996447ffaf3ca3d2cd313438e0e41e243d2bbfeae4222059dd67c73c1051d8e9a128df49564e8a6f251ce86f039fb12c1ec21d4b1ee6654c32e699fbd0c5a029d017d935a15d46cf2e1b64cac6921f94e8fcda66614d302a841b2e8fc00fdcae8222a4411e8017fd1eefc21e4a0ad01635704b7ac3628a227981f9fa0094c77c3cb38f8ee471db3c85735bc1a90ea1b9bf045bd4fd5b5d2525874a9160eba3305433f222c7a539b7ad183c35fa58ae8fbd85e2c9999848df48cd865a80a0b46204a0cc406ba74eca22fca04d77a2cd87067f172031d29094e32f2c6ee06d9c3a51ba06b914b01556ffb5601cfd05aa1adf3e439ceaaaf21ed6352a0660c9e8cc16288249b9c7920077f5d4531d55c7cb7cc960bfb8b86367be0efe30d09c18e81a0b4316853730c29d16dd817718f6ec7e8b4734d620f2db0086906f80149c490590bef70db7e152dc557685ed977f4b08a5f710301c6aa0900b53026024ac513ffb7b8421801d455267ab433b06e3d0da1b912a04f4efe0401d27dd403db915a1c313c685f7afcfbe88040380f4180442cd367c8a1a88fa74694acfa086dc7e205597a80776caa9a51dbfc0fdfd999fd24a9886be5e45b0681fce11a0f94bc42fe8f5186157a8cede291acd10cb29b221903baed878d378791210a760e0af7fbcf97977e88276954f7d72fbb9aceb02ecba33d80fda9339e9e02945e07b71d8399311b7f24c24845f4b1ee6867a58091bef4132440ec68c18483f75b03562fbcbb3234cd45c68175d9eeb5a698881d48309d6c7d7b58959783c074cd0de565afcbe0eb530474dce9dfa03a24018be90b3f20510c16be2e856a89264b0e9bbf137585c341dfded0adcd7925dcc65be96802d67838b5cd023683adca7f08f8268c54b1cc3b535c2b71e8bfd8729a87df0cf042a025e059e9e8a5b95b350788cc46496b230e9a460236cbef153f938a7abfd3331308f9d1f6ed0acc82f30ef7d5772e7b99441a896b0e05372692d3ab32640fa78f46f0bc36dcefd53ca9098fc067352c7e873b9f0d19a4f27453c9b62700262700a3b4c0a351c8a29d8406ac7daa5e44af140c3c8b1f3d2ebb4b845097cfa9782a4715b2768de4dfe73d0d79ad67c345674538d7ec75b835f6a701ab95d304935185a3ef906f0b1c19a70206e6b03af99b833a00be005eb80de223fa640e14013d0fd7052b0cf7b43578023a598090ec0b5d08e3f9b3d81ad148a49ee319f91069a681c545742fe4970500e9ce4c9b6858d18246bf958ff7211ebca07a1b30dfcda9bba99fd66134357806500d3d820a7ab3c3ad35226efa2d2712fa0edbf87966fd625de6e9184f240d0a650a5455d49368a3f7677554ec87dff121ef1ddaf16ccfd1c703b032a3f8d006bc61e558d0364cf6f867ac76dafa66b059710c7f9147e3e07190bd63bf9c73060a2b51a4fc0e057611f57c6d1d6089433ec896edf5372fd993bff5b5d8b8960e972119e09b6d84b955181a02499f98a6eaa8b97cc044ab993f4b4e7f0fd2680866b9613c7336e45fd3076d0fd428819c2efdd76db297d7c240a52d54c0a0850c2cab511b8144321c234c11fe1decb78448c684367309ba1cf5a4d22ea1cf2b067ad7b2d81c0fc5ef96ac80d5ed35ddc29222d5f89b3ffb0aee7a310dc82a890b2b957ebfa95747a86137a8a662fc1aeb78732d57e16d6850797970945f80c40de6cded7477fdd2008998328d1bf49e0506ea87ac8e54d884b9ccf90eab320404478221f419e7ad162d0566613d9eb894d250efd4c15a9a26ba3e2a48137add02bee1f44bd8077358fe028675883966fbe3b09e2b46344440688d187f5b3967051d3db7085c5ce0c47884b1b433c9aa71de17b8ed48525de4502db1c47b3e2f05801007c868e0b823dbfeb25ebaec26214e03630c8b101f5145b989500099bd06ce5a44d7f18f7848acf7d2592b1dd53918aab2ea5c93f7c7887bafb25d77bb0a89ea20d51986e3bc847022f4bb6ca12ecc6e3f2ab1e780813b57df5bc84b53079324e81ab53187453065758f279601dd1a852db0c95c68bbe388445923d65609f9bb51fdd2f4ad2ab8a2c97ee338ca86a3d45462c8f1f9345d9a073694b0960e57a547d670956249f34770e62b945aa337f722059b4a6d59b7093495416e230d16cac7e498ee2ed399411f0f48f500aebe96934f514e1a08b73e591447cb850db6a000715dbb9541d0c32a286c391fcae23a2145c1ae48f75cc5c0c532709a01457926de113a42ec274157936e10f2d48d6c235d7245cab78588c2c4f6baec061952ab08e2a09619e106d0295094fef72d719e04f8ab73c8ee18a5ac3e71200478ddf20439885849561f098d7e81e8f00517d7657ec37ee72c950e4e8a9402072b072660e5a2cc8f138ab2c91d38c0c2846324171b97381f04682e4cbb6e6e06e492bc18d8b807772514bed262104b835e718d608b0c052f492fd9988e23d607dd9a9539bfb38661321f27bffaa6b64624d80d8f0481d6e11def4f279fdd3605e73ecf3205365fa548caf48be95491df36fc89392f3f0463146d61accf1d0d045f262c3b586ca2d8206fb54121f864bfd3a369ac30b3c5d4e54128991cd24b00a320e442c46d54a6206ed75fe136566e2e9a5f6d15eddbd5adb1cfa02d3d9902c175a03c50529aaca0379f98759e228f813d1faadead687116e9d7cce019ee0b2657fa7117b29361dbd849e8aa8c9bbaf9663179b92028059f76149dcc5dfc04dda34b17ffec9e7284fc2835e1596366cdec13dd7f711b5cd07a9a5f67bbef056afe2c73cc23e31b08028fb949fe10823cb325d8acbe078e0187a5fb0c860c00aa84c32c57739e719884d14139f2e67ad1f330be3a17d37180d208091b521f05ee083c237e317741cfabaa7ca46372951d944062158aba3482cbda4b4fa0d4094e287845e3fb0443086a7249612230e68c34de2b72526fd6bbb2ee6ca493d24ea2f5e02d985931abb3ea2325ede77d55de400cb7ac21f35a8bf4f4229a662443d68dd15bc1054fbe581518de734da565f0eeb8085cc3fb6df9acb0385d6a5d6df6b5c68c139e1d8ad931c74679c511582765d894312e3d1177cd7e00b6094fd3bf78699ee5c4bf285b8e535c4dacaf13d20bd96ce90c2c56e97e458b6f95528f2a08068d8de84b2ef50466984e78cc62fff47f54e9ba1037a8c4f295eeb3f6b98f903664e5d2fa7035c4a9398caf475f2ea3047318ad48e4cee730e8315d08d2d75f43e65153d3623f2464c2c8656cdc71a58b35cbe51536133c0b283310aff55ad9018230fdc8cc8d189dc3d6411a3663ebbf52560ad94325cb7b1b9638a90b669bc0ae3436cea9fef783f6cc58eb6604ada97febd35910482dcc0db31cf5632057a5603cc3ffc57212424529ea1bafde40ece5baf40cf3f93ceed623a528eaa348195d48181d2796f2741dcd2621a85de40acc3595c0b3a685d72c9acf8117b863490f6ef8da0b32e55a476e5b075fb668a515b5875dec4fc74c784420fe337196907cc0a05374f8ae479b5fd9c33fa3ffe564552c24584fe9d4b5fd4d1c1b3f49837d185d01805bb8e68366d1b92eef3ec9bc52c0b6754cb1f17ed3e66fc1508a0a429d23da1fff5ee9a072b7c9200e42691f0a1862667f2dc0adf227f8ab883fd26540bab532106a202963c1d6454381f1a57345e3ed92483d819ab4761b081a81d76fdbe62c63a35120f610d272aae8ef1223c4b5127d43fdf765923be61eca6d7470ec98449ff6d4238c3be8de62f50ac76749db2372390204462713b977ccda8f3918ec489eaeb322c099f9748a030911699da51d257c435b444cee789d53d22ae0b8ddedd2ef658aac38f2fb0da37b2be48a421f0f05a5591274fde71d7b998628611adf0d5d5df4e5a7bd95826715da9d02fef6805bd9b74a4cac3c04c785edb414200ef501e78a9df73db42e2f262d525988b4b640cfbd5add9ee8086482fcde0a6c533f407fe54fe63ed5e4dadcca181531421f354c48ab2675abf240e85f18ff6e5e8204d8ba65f3d97889307859ff145da746b799f4f43708f95d0370e62bc1f612180c888c7d8f95f93c9bb7953af56004606d97f7078fb12efb39612659f6068b357a11831a78cf73559bcfb27db229d179a177284f04560275137d0712489df2907b7093eb61f2ca9f4a903a9734de567f6671e6b9047d6aab50f75496a625de59c9d05b4b87eaea473e302f521f77a98c595d985fb1c7ada6cd278ff78bd517ef7fd096afdb9d19fbc0741a145418c58bff551da37d75fcb2ae3200cd8e68dce8483a825b21b985eca6f05c95e06cf54a2dac431a8ec8b3c851218b584a00136c83bb3e3d0d9faa43bf780b2b7467cbf3e5ae31d090944b0a63bea115c8ffdd9c82b9eb6a1db53c5fb74358f8cc876e66d8682788625b457aeed9af1c58c472dae24d41caf4ea3274d7be1c36dba647a1d6fefc6d87b0305f93bfc94fdaf97ca1cfad8758491a2ddae54056e89ad11f3c9bda51bc425dad52ff4938793a9e69d738a0a8f774834e776e57c87ef92f2c25dc7dbc7525e5617d9336fa3a484aa7315a971e8026c228e25a0048919086dee53c692dd394519313879b7c498821ce857e4ef98a1807c2d5bad64b2b06ba5a73d538b76968457a018fbfbf928232becea93dd67b13da7b0f9af55d4f2eb744d99a930431bbddf2110756c9d3a5cb4e0f1ad60f24b91f0a90d9eb75ada68f855d46c0616d97ecbf80e7cbbc94f7988eb43f95d87afe6dc75762085a01520159d8407f3371b3eb9f467efbe340ace9f325055bf14f9131e701c3dcec50336bd21b9d736fa60aee60ef9fe6d3822c2406b7da3574ac141b3e24ecdc27c29160805d5acd8996e953a79d8760f7c65f2def129bb03ed560a155bc3e1d3ebaedbff33657991c9c68834aafbef28771831a8ebf8e44dee70a329b697d7a3d822d85738625431b9ec786aa198389379b73552ed0c030872f3a8a1e8f67d27c686ddbad7c24608d44c9bfa1e6e5ffe3aedb597669fba50b18218535504f256531c356d677ad79acff96c5a8aae86614429dfcd27b25a142ecf871b03956c4f3cf4443a54613c598a4668aa210fc354e0f44726a8315bae4988ffff858e2a9eb65301730ff7c32536fefb0fa28b5ea29e165f009dfcca3d9e8a0f44a4e4d5a5566aa973b891b6dd98e2160b6cb0961f1c53cfe75423660efd74cfd9c26850d67a0264de4c0b1b418f22777cae593a48d9e7248910364b359d7e32ece97ad40c35b5de5566627d2d2688dc8fec0066c29c59bebc326c1573f690ffc67d7078d2f147d4e48d1a751ab9a157be67169cb38d2aa28603ea8ce797f8d6c79353989bd2837d55fbe4187edc73f4da1c47c7408068791c6336abe9f26e42893f19c953e195b7694389da03485720c4dd16ffd506796886804ce40ce977a2ab54684152fe30d3a35b2aba5b5d8ccf424329aaa5105291ca7ffb54329789c0b97b690b83f88fedf4e7daf91e51c4e7cff1b50092e17328909c911c452dd41c62990706dfaea588b943086ef76d8512a713974256f9a7b5159953e946c9c32e263c218902cbafb84150aa73448cc5b8cccba681e72cdf2d4d95ae762e24b6712e26bd4552f721a324978b7e7e2a539049db092994b439a3bc796bc722fd2191b2955fb10993c702cbe19738aa4011b6c6de09c0c10a13ce66cc0dd533a12ee54177bd5d5f69ccf2d365115a69481f59f0e2c2eef133fa246a08a6e3c7e807a441e2b1bf7b4842f1e7e8cd4bf16dba58bd378cbdb6d77acc2cd2832f3d5865562835b37634737b4aa0f973d13c4ce9cf8288d8b825d7b39c9cec5c413ef31345a4cfd9d142c9328a2487705c6081de8b40f263c4d63743e121780fdb160230d48f62986dd528e6fa58720538c9687aa0f0b1f2ff49a6fed0b96b309a1c1619c4a845e00141d0c26ff9756800e8b98de3b0d0c832d99298254d3453690d869df8bbf5e83d442c031f6c58017f7c0e5d5a303360fd32cb05bc147d69071ec969721adbd606165f7f3b4ecaee9431306c60e06ed0826acb1d491c3f2eee24e9956696b92574f7089041643dc3d081ae51379035d8688681eaa74282648f969afc98adf719a505d2e4cc461e9e505fae094b6090ad44ad9f10de2cadb596558d0975e1ae52db69add177addc906b78ff0a83e0fcf54140723cbc7c7fecfe37374cc4eeee4a08fd4db026200f123f9cdf9842c0933ad6f97bfbbb7303603666200b7126687d199e224334866b1d2b19e82266100943c180f934cfb97f65d2db529573d075eabd31dff0bca9af76b96e33c3a4e01ddf7ce8c8ca6fae6ca1c85782454cc2d23c353956b623e8193bd323ba9ec550f9c62b663a5510c818c7e0555c8605841bda70c347f0ff3c37bb34d21095c620ba6995ece411c4ddfdbf03bd08a614a5de9c13bb2bce63a6dfe5288ff409f9f00516116114e795057ec8dbdf61ac4b877b9afd99ea484e5c59dcfb03b6534360bc81c7658c0d4de5d84d7bf9d6df3c64bc5fef8cd9d429f8fa1db19f5e33218049a7fe536a1ab0932de6eeabb2b60f9b7b5432c7fe56dff9100c7c8296bee360a2143dc1ade2f92f4ab1c585f77f5cfc53323944b07ab026d8dd439265c38560d29eb98d26fe032ab233beabafba2e1525da3bb0e632bbac598efba0b38b8b504ebfe4fac3b5f51807579948978d1af50a4c5df66b35894505cdb7a679ff2e5090a6536d98549a27ced11b634a9dd379b0d7791dcce11c268eae8ebaf29e6cd01e4f7f00407bfa1dc790415756436ac46b077eb6a10364b9450a07811231a3f014cf777c0eebab8900a2478d9cb7be11f72827e7715cfe72a9b26aa9acc07e508980dedb34727aa180e323158835e962da2c5d046e546a7b35d3a71ae2573e10f05acf0f5caf8aa6dabfc9a1574a1eef15fc34a478c572b1eaff4cf901f63fb070b1d3f7d5f525b8aa8d34023f92cb309f137415b96054f9e57f0a46635ca9702303dc9dd7cbdc24b46c59841aa809bd373dbdf32488a3e3f356b41c501a5f90b94a1e1b8cf3e61009e18e94210b83b32e0e1812efe43210b5f384ba4c15de30e934d31bdbe9cff3ed4975b0c4da0d3d674f751e309c96ff38c49bf202e2cb60269651b9404a62420c374b988ca006612303b4debbd4595f6d0637e69237561017173ecba76aa843d7e876070f73941359b46c65cb4983932c01587a5d2c2f7081172b9e20652ff73a81733710e017b2b50707f2a6fbab6cefdbc9e5129a5ae04049b53ba7382a2d5c78fdce9938b246b6882cad116811d8c0c7be87a4ed12707820afc236cecbe4c553c4bf6f4a518d58b59a3a697bcd5f878ab303d200ffb00049d00586fac0882e21960c383683ac3f2c86aca0ff581197ee5c56b12fbb3060d40d9f450a5c5a5007ac922559a234d41f9298ce40667723865c4f1ff0b1b096913451d7b343174902a52751b80c923b427d41913042e0ce4eaf0fea0642200235e199eff66432bf4d53a26012ea8a8d619927f7c9f0a9c9990772c19e3cf04217f8883fc8061b694cbd30caf1afe91301961183a24bad7a53fbdf2b437850b17e6236a2beb861f840f6ef0076f07c831dbd9d895303b9a699fef7a118eee085a08bb61d83b4e641a869dc6615a9b626c2016af3c8f4107ce3771dfa1f9920b3842e0f4f130c456113060a4c3e071928ebd90ce3775d56336afb8b1eb03a1077e60786b75109abe273b959e443983deffb0f930f143f0153e85e0598bfdf80e09907fbb27ad08b7db046b19be630822bab48310030dac904d7a999c5e1a32056611b334d75164789a73a75bea1d5d6f2c7cef0756a5ea621bf02eaa950e9d06d47f6dd9af33920c33e488ec6e739aade25b860a0060f7b540672d39f46e64092507f8d758787b2bb16a7ec2bf7b65a70b05fb68bfa6c6b6decf3d777e6b5b0b9253e0ba20c199bbc3cf60fc24ca4ed71694836652ee6e7a8a5935c64e696d06ee72fa3696a46d2078c2128da007c1fad57417f1c2cb626f34a2dc393b08c70fff8668ee0cd9c3f08a205a80e20eac5437670628c9413bb68ad8a89f442bb70b92ac0c9b962d0f330e0de2b2f82fbd3730e95bbd4e50a8ff4f792862351c1c07d15b7f3e0ce0bd8fe013e3760701f8178a2ee86110e2be9afce91419efc8620551af717ce387b72a9ba83bd068bfcc21f55401c4a6dc80fde44351831423de0795090005e22520888519b57e83e600fcd360ec7fa9885b465caa820e05880708ba4217d2fc831f2d1d101c03f05b010975236bb93fda29b8129ce717a895ca016f4af15553dcacc7afbf2f64e6bb666d23f7e783b6539e48bccaeddbe83aa70c813c787581a64bb55b75a1cabe5033c7b9fa0e388c0b8de7ffecc2b23ee87601f2dc5d192da223feea7de3cba76edc3f57536a70b025e815e4c9af7fc4f5ec0d691b106d040d9a93c04e223858d95e2abadb53cd420d29eaac89962275e58b09b0462ff94e6f3ca4fdc2a0045ecdbda8218f0c04c8aad23dc82725923c98350c15d2470aa170e9ac3984ec62df285b05969aa32eecfe8b4e6fd5b38a1abf0803e2130716f0df48f381d56bb573d623858658c768207bb127975d70028cb8470e21f370b46de84ec1dfcfbf66addc9406a7ac86b5ed1096607b7562ffb3010f0ea7d0fa3de904a95f10720a28a2293ac57594b0bd91aa1a008c9e3be0b0c09c0dd0fc3d2361be613b0cb06c84f994018b963256d07d21985256e22d754e61d301970fed0781cf931aed808938f5103c84cd2e34ad4fb23d5470a1e8bd69c2d400c52b634d8061b2539473df8bb474e477abfd39f76b7e1126aee80def49427f036c50a7d37e2d6b56131248df2b8397d8f7c9847b1b5b93f9fd48c4f997ea320af4c274198cf3b466f315a3735a233461edbfc4ffca6478ba4fa32dbf4e2ef70904fbffe6d13466be5d0152d371cacb061198366ab7b43985f49871c2f41f1304d7a4517e8e300f75b71a655e405ec41d3b1994c57ef495983fc5e30c5557dc07dbd78b7e8c582c129a5d77204dea70692456c0e7bd811833a9afc9c42ab4fb0814bc9bd6087228f6cced657636114944f46e85bd3df766ab0feb726b72faf1025b418edf15550b96158b173c37158e0ba93679ee1af43a836e758b949ca843024ca0d3fb770f7104dc4c98446fb4e77ad1992a21dead6c704aca432c8f4cfb0740f9e3c744916b3024121c5928ee44906e87e944d40ac116fc894ae0786c620d6c45797e5d7371e7b1c64f90de11e3e7dc49cdb5d687f7ef99ae94b93482d707a6c8ffacc60966a3d37d6f412c461145d1725a83739db41dc0cce75c4f773703a9f5388a7eb647ed154ff718546958c22609269b155d5e9bbafa6e716c43e103b7118ca730ec5a650d36df4395c4b9c861d928a6f82da228aa1012416948150024a35084a2c836862051381acc687a49723ec84f6df2bca37473d59c7c5f0906d7edf633bb57f49d4a36cfb18cb139a46c72ece777a6c3f4d0c0c8ac652aab0440150deabd873b586c10ef5526fb5c2a6d96b0f25514afc9a004a075197d39085d6a951c68fe5955d800dba3f3db300121e61b227ce0b978b04fd0f3d2fa2c0f38146b0da08833f7fb7bf3276e37e7160d270bc5b52817a34e4a0b148facd301176df44eaf05cbb714f18fb105b5e84ee679486583caf9a34f54c0694cbd36080e410f48e9b523cb0d8aef317d4f689bc9fd0ff02f3cc5740f9aaac0c5b8cd0cbbf70ff6227fbd9a767b87ff182c1ddc94133fa4cb1bc82297dc7d3b1585d20e7f4035f9e5f26a9918dc7fa5aeb29bc0790b426f4cd2a79a39f6adef69b465066a93fd3f2552ab7a750138dc8655d49c0fb4e6684b858397efb4803260733501e1711ffe566cb10d779a0a3649f859844704ea73227b46c5eb365f8864a4b1055e87653c51dc879ad534572de7f8a502ef9fb4bb4e896b3eaefde2456dc3b1028b1f3ed93463514d9ec29491a9ab11cfe6505161fd614f32db12a65418ebca0c0e0cb34b43b1c2b4359e3340b5d2b7ef1cf2a904a5e255f7c78922cd33982e080e16931503175fdbfa43da6c83a47857c3340d2b374acaab85cfe9fe27d9eb09c436f9813f05315aa6671da45cabe411b2cddfff2365e001f6020d462ab32909abcaff0beb63a79539ea4ff10246a222229b2ddf74c299de70d3acbdf97c7f07d2537e2767a5b4455f081a40b766f7ad5d4bab427fa0bae2ddcf564b19e824006f1b0f34ee9a746b0c358cd5104859cdeb32d3332545accbf090d42f094c4c05767e2ad7642ef5d6e1d6343e94fba005b39922465cbde5baa7b65e77c36a4307c80e088c889c2a19fb3ada925ea15d62aa54f89a0438cc6f905a5246e8dcf80ab3c40dce9d3b8f50b11f1a2d60088a53ac8fa39d96a873c7dea0b4246ccee10b5b1db80ccfced77f9275837ff8a0f743bd1990d25f8b7e353027a497705d300ceea2faa499b5a7fbed813a88f0a9308d77c78148c5f9cc88f4437b9961cfd70868afef596604b63efb7033843fd7d245917c4bc18eee1a35229d032684c59207e726e9ad9fe16d1c0c1e51e6052581a1b9b80673080c0568f6969a647786a80379843ff035f44cc3cb46462f897ca8069ffd921e56872fc84a33a4fdb7093c0c890bc177227452130009dc5b87660785776ae083144387555351380db554820ecd9b4b33b3ef394c26d56116cde8688f67339d058c912c88f0e8a7de0084250ef7c686ffdf7ef5ad1829af39acd8c0cf14015398ce192d2dd93fc53b23092700d0a8edbe919297c62efcf00e48f64153c2018a65cc80c3fb6d5252807471650552904c49cbd7de01527c62a97639f0162ba2011b8366fa3433fe7bc454f4c90a92b70852e3573c230def6a75a231277ef73e9f337cfc3f2952d0c9795e8d4806c5e627f0cf24f760d4572826731a496c3b323d6ab98c870efde5c3fe9c690d04edc4027d69f69ebe1656c04e06a3c5265926256cbcb5343df7ca80ad6aa3fc0ac3fc5e2ba29a942202c077282309d3f305b25f81e13d4baa2d16d383bf57080740bc3625b96fe1393f9edd8d956f0339758d0e1746445a3cae301d95b9ecd90b97a9112ca9d52167814e782fa4d7640231ca86086ad40bb9760856fde692bb013fe9672057403055fd91b19f2cc44d7f008a4a573c72c26067e92a40b7ddf50ae5dadefa0a665b216a84160d58aafcd4e345c0ae0f92164b292d1e4754302d0fd277d31d355a6e8106d84868fb83ec62fe323030b189fe671dc9caea869e640cc400673893c99f88e0505da49dc5a0f90eddaf84a66cf4f2f3c6e11ee591d602cc030c63d59bf266c4bebe7c1b66c1bf246143164b4de54744f00f444778af05fa108ef427129339eae3340ec1d43dce8a14d99e83aefab957424e5238e77f031f644990aad3bd17f2b3fb9a816dae7a3c9f2e7fc9dc25d7665ada90adafe50c9cdbad79137a4541e89510778e85926d2d91744b8c090ccfb92efea599ed370e134d775566463bc4d30d163d906d6d19b47bd7bd44eecb89f75b91a9d86cf101caad3fd46b8470e434d96280ffcd670efe57a85cd853bcf5b5598e4ff1ba0f0dfb9450310dcd4774a0009f2b91f0a0603017ba0d32142b0826275d2939379305212767fd00bdbba49e70c1c3c58c5984dcc118e539c4d49740f03e477ef250498fd109944e1b59081b7e839888369fc7a2de206473217683eecb3f51184577bd924cf06d48d02e7e0632894097607a60a21ad87b9e89c1c2aaf38f06098fb28c8e850c1bc78239cb7686ada793b16274f5d8c05cbbfc49104cfca9da730f789ee047d041787bf07e5509301463ea42f520ed3b0bdb5e52e5892cbbe4060cdcf4757ec48c5654b08407de6a25d17b3138af5278ed61076927944aff8516444a1358ad64de92af03560ce5fcae3db166d478c07d407731f850de5ea7209ebece485989433595e9e9c22b11f892135571ad7f43bb92a8a2f68d6d65d182f7178edd25c725eb44437afc971d2ce9720e58658162b6ebc9abc5be81fde2b1e82cd031a8ff3cb624b2bdf17f1da7ceca80b9738a0022a5a325cfa85d77c431f11bf68dc296672989f2ba29a05f5798861835e79dafac3d272815372824aef5d39274b7381da6a3ecc6a09b21deac347694174a0f42a62fcd4df53e51419c42263b3d323185d46ea956321969751357cd53b383e0c3235800c10ef6dc294731eeaca48ec2b88b39728860dd5074cea83f111555dc07ae1257e76b7412f9492ce7923c1eb54cec26cc8c06f64b7e7831960c25d448d886065256d839d503388650c3a18c40c496c5468569ec0d4957c308e85c12d99add9a70979ee48cded650dc39a52e77e980cd6be46c474222b107984cd277417bddb692602e4ba925eaffaec6889c66867b82e5c3484061cd1337e2e245bf5c4a6e5ac2e01316f23d1526ea9b7ce97a720c7e67e276a18a16e11eace58c83edc490f371255568f2c8daac02301648169128c6b492519c5ecaa4f37b48232a811373a804cf23b93c07631a0041989b31021bf77f9cd14a20342d44616790eb85f8337ece742364c6af9f4c604033647ec5d7064ae072487454a1e21ccab8bb948d4fc95493cefdf46f794ad9bad3847487128d932f736dda564703224a1e7fcbeea5f569aa198f84545efc5c6017e4451d4fd65c6241ec7fca593d39ce7853d763ac5cc99be856df039841f8ba6782041153013527db153b5adea418d1b8c32b0fd5a82fc1e6e3bbf6601e520f2750b0eef33047a1a81ecffc658b6032c75d07db9af4e405d7a5841b79851aa6ac7de073c37ffdc44151f486de5982c32be8417e9a4b450df0a5822489dc1fafc506ca39cd6dd86fe83bee7683458171cf0d97be990c49af3bfe4937337f444e940e03f6e244ef3a43f1e6c0523b855df03ffa12b9b0ec34a1c7f17a2bd73870ac7780c0cfe17a0752c195707b139824ab8e5b1643395303e7614e307ff881529df9b5d7d7d99c2e5cc833c56adea2618ab528ef19eaffe08bb0cc07e621ab6c3e8c800e6270b48c1e05dad74733a7579428cf7e76b2f1f16171deab5e4c3b51954c0d18f7eb5c626fc4fa4e70fb7595cfe9261befcc7656dd1bd7acba7416aa1185e6da1ca9570b886ec85a66321608f8ac10596ab5ad948974f851ea7a897d4ea42f375fc5e967c8fd3997411f6bf392e9e9641b59db3bb356de5a34f2ba6a34888c7a9aafde60350f384583d76d55ceb8d6771f986624b0d859959b4a5a74ba85b2ba53ead6d80a44e078ab7fb0c6bdafa07943105ee7d273be9bed14d6127a7164925a78f703001f6a2a7eb11c54f7a342ff0d253e24a6235d06f97061a42a3b68db2d84328df6eee8dc94624292adf942b0183d5f67c44c88800fb1771a08482c32edc510564d6acfcbaa1d7d405a20c4e47f37288398a18b2dae47e9cbcc8c95e5fbd2c070dbb963d0558c3ea844d8c6cba6c84f9e9eeff93dbb72dafffa2a8fed6c4cdeb2ecd6524f54ca31ef4228b560ebe6cf1712597547febe9d5da2eb7017adfaf70e09624fdbf81da5ad7d6298574d311bd900ceb189452277334540a13616cc4a5f1ed14f140715d0047b6df396aa3824e6ea01ef308b1c0760f1423cc1e09eb4b63dda71a1c1f80cc1e191eecf789485b4e89940cb5dd35f4a16c8ec25cbfd1fe50068e8d9c91949cf65ba679784a5d39cc9c6805867afb90f4b76c2d956821752d00d68a16c7f11984e1f54570773936167ddda9add1c8dcd105903ec6315a4e5a28ec3d0f196bec7bf77a0c086285768dad76d3e812921cafb579f5e0c42dcba829a8e3c8f4467ce892b626d32602fd48e6da95ee3e2d923dbab4779664a472b7e42657be27b40f14c3568c56d0ebcc15a5a8ede4b3f43fc057e60f8f4d1ca802d518929734645bab8069016172120970ba2365248d697c5cc768b1107cf015f4528391820a16ba6a2429a0c89a495a45de09f8cb876ef0d6266cae9d50ad0c8ead39152ca425f3dc0712dcb6886e006f5048febcc503d1ce2880e925bcd6e90fe23de7341a12a4bdd253e251412629bbe5f927de264c5efd99a70e098ba05e11c0f0c8aa612b38d42a0944bc4fdeeea518a599aa3526d992d80f4d9574775693bc9ae4ddc5095c176e57fcb74306bdb353fc778c9581f68af828d4b1cff1d0b9f7146b7f1aeef8e55828364c51ded8c4295963baa8331b56a2580115ddce6659f4fc608bf3b44a588342108c33d6d39e0e88d6e3bd52b0d1388794d1b5263000e610122cf5cd3ffa42205053924edda90a4b623da2adebe3ffa3135866a19b7b551a904860d97b2cbcd74adbf1a8cf705bbe6b6601587bf3a4b66a164893793c76f677b79ad5ec9e400362b3d2b1916b16c7193cee13f6541b36a80e45745d2e6a99853e8c74221df7caf1beeaee59087d46c4bb120e2bc70a3f8937236ddf5797de940f9f83c1fdb1c7a6adcb7be1b49eeb2aeee75ecec53576a2984be7d1ba494736d0eb132d1c713ba75a33ce0dc77639aa1eb9c444d0c5f96072d45d391549ef96ce71191797fd1c059886c2110d2386484d43f7a311e062a9406a20c392cc4b3cb193864c9c9b28ff512b6e9300cc210acd00c05a53905f677b862956018b5b4d7507c98cb231cccaa3f1a718665cd353d1cfa36f3d10690bafd53e01275a626fa4ee7afd34d421dc8a545e343bd95a12c192ccff140097fa5a33b21a6f6058065127625574f9ea06a44c8dccf3cf7cca3fdc09766260d2c3e8c7a8d53f90e01d2c1cdd6d22a2e091e8471ecc1f294cc93de807ea7ca0f11f47b293eb24324a0537699bd3a0dfa409eab66833b33cfd4720ca24e86490161662cc5c2554e02b44c7b62c59e1d767b8b0e1438b64ca9ee8fbd9026ba3407177d143dbbc16ee08abce96dfae4f3b8680c5e0e17f17a887e7cff2364348d0505f2a3e05aae4e19ace2ac744fed130264c06c8ab29fbf2ce01870e3be68740858a2fe71c0ca57320b594bab089a5c9610d3d47d556c11e60ed39962e0d78e04092a4687c4eea07de9dcf29185fcce2ca54f755650a6521a163d922686b6e70dfc5617d9a8355692f2940c27b2e387815f17f971131c9f885ab384708739e605620deabb528409495f3241c601bae632054b0fcd96a78305ffe1caa49fecca07ce0cb5560266a06fc3c01bd730f44176a4d40d2b26ddfbfef2a9f6ab0e137805eda7fbda81af8a82723efe5aaabe17b31c963fdf6b8254a944a625ea0348910e4d9109141f6fe01d5fcb471197e55143ce55854412878930add65c69423c6e0f6174d2e5dffaa0504b43ce09ac320d1040fbc06ee5d93cb750e9e547397a8c048fed5e5bae73d4a86b843289e9821148a4aba4fb25b2ed12edd4701894a9430f1a88d25a7fd67dcff77d507f5c07f77fe40e2a941776b455681f9a05e238c904f4615976c47f2bdee35592c6db210452d7ba57091100dbe2b7c3061cbfcc970aa06c954b641b1183765b84d6ecb3e3ff0a901699bd9874869b10266e8c58e409"
+
+	tx_raw, _ := hex.DecodeString(tx_hex)
+
+	var tx Transaction
+	if err := tx.DeserializeHeader(tx_raw); err != nil {
+		t.Fatalf("Tx Deserialisation failed err %s", err)
+	}
+	tx_hash := tx.GetHash()
+	if fmt.Sprintf("%s", tx_hash) != "2649e0eac5b836ed36b3f4e512824855e222cff7b84652452c769bbed9d06b73" {
+		t.Fatalf("Tx hash mismatch %s", tx_hash)
+	}
+
+	tx.RctSignature.Prune()
+	if !tx.IsPruned() || tx.GetHash() != tx_hash {
+		t.Fatalf("Tx hash changed after pruning")
+	}
+
+	pruned_raw := tx.SerializePruned()
+	if len(pruned_raw) >= len(tx_raw) {
+		t.Fatalf("Pruned tx is not smaller, full %d pruned %d", len(tx_raw), len(pruned_raw))
+	}
+
+	var pruned Transaction
+	if err := pruned.DeserializePruned(pruned_raw, tx.RctSignature.PrunableHash()); err != nil {
+		t.Fatalf("Pruned tx Deserialisation failed err %s", err)
+	}
+	if !pruned.IsPruned() || pruned.GetHash() != tx_hash {
+		t.Fatalf("Pruned tx hash mismatch")
+	}
+	if pruned.RctSignature.Get_TX_Fee() != tx.RctSignature.Get_TX_Fee() || len(pruned.Vout) != len(tx.Vout) {
+		t.Fatalf("Pruned tx lost base data")
+	}
+	if pruned.RctSignature.Verify() {
+		t.Fatalf("Pruned tx cannot be verified")
+	}
+	if !bytes.Equal(pruned.Serialize(), pruned_raw) {
+		t.Fatalf("Pruned tx serialization mismatch")
+	}
+
+	// a full parser must reject the pruned blob
+	var full Transaction
+	if err := full.DeserializeHeader(pruned_raw); err == nil {
+		t.Fatalf("Pruned tx parsed as full tx")
+	}
+}
+
+func Test_Transaction_JSON(t *testing.T) {
+
+	// this tx is from block 726
+	tx_hex := "020002020005098e0178a201b901ea57543450b2b743e729af2daf02c07d65928d25965fec14be1b70464ec2ebf10200054c20b601d302247beed79270b09d389e28307c0360971d0bbc946e16e453d32178ba0c398b88ec020002ad00e3246f39a133b4200aad5a282da042b35d1882bfed68876187621fdfbd62000257a50356a0c27dd5b916f8850abb6d559051513fa66d2f5b0cddedad0e4125a52101ea3881b1c46e415db7223b596f7cafa34589ba01ce0146ba407ea1fca5cddc840280f6a7b48502841a8e93587ba92028de21dda0ede1e8ff4b371021a3637b02aa83d23b5f7af39578053192e8412f81975706bc36e84d0a6a4ee3b37edba70be5968ce4e04fdb049e6838e9c1e6e8cb8aad4c8cb7394b6f179b6b01453e1ce30feb5d5376e808ea3a7ebb7ccead06cc1740a5e0318c6d65fb573025b9a6928addb8265474e50451ab1917dfbde37fe7b40c307bbd0968997e77d0c2f10cfb1c9dcf18eea2810d67ea074e3a07c983658e857d3bd7f945f883a95af577552f76c5b81c39c4c306589ee909095855b26ec9264554bd6d61cca132a61bd2996cd7dcef5b9372429633e54d60b037c1720e10075c4be2432bab398fed87eeb85913bd0c44fc2121f134473d7a9b00f9cfcfff7b0027f595547ed1bf695fd7b0defd8bc394c07f37098499e30dcffc85f79df6d8ee5169ab3c074dad7818211f25bff13b870cf02c0ba62d2124e3a33b28c5d292575fd1802c918941491a3b590e87168d989e21ed08421ae138d70c244cbdcbaa87ab962368880425e8e649c9e65682b230d9cf2a08309861de615972770630dd84d2c2fcbb64426b5f02062994613252dab13cf205a9a9f260a67b12525efe5632e4c41b527da4e655ffa4a95a75c25c8f07c4520d2e4983b9b036df1ebfa001787c5ee0f376f6f34cd987011b1dd39b4b71dc350d01710b18d6225a616e3f62381f60ad1113c7ae493889e073204d3d3967a7840de9b55aee29d4d4b0dc5eb3899e774f4b415c3212208fc6e8e4199fd9ec7b9a018c77034574d388e7317e48492c7bdf3f1f4f3dbcf1a4851a16c46e8a8b313309979d168e9b8bbe6779368680a732adecf82c9d0fbfee1ce2c3e37c3a760e990aeaf7aa9608680add4de4b8d250d15dd92d029d9c2f7dbdfbee6a5fccde1f6709dbc1060057ddfd9d581d8b87dd89a6256337140fd59aed7fe699f30f7c2c450a8f8d5972c9f39af524e4b39976b8b88c1bfe9e2131f24f3d631c6452a199680fcec538779f2d043762ec468993201567bfbc17e1132b0bad4cb797d3a2322808e7dbcf1d2dc55dd795184565b5d43a36d0b348c62d93434ae7d6d6ed8e0bfc0640e3d8ff7924d62b2a7d1c67089eb1bbe9d01fc811d8ca3ead2f7954b31af50409072246ff67b970cdc7a767f552caf581f1eb7ff5ac84a16a5876b4ab0bfe02233e591ab8d7a484817ce1289aea4383c356ef2de31e5bedb006aa53d498c40b78a56b67f3f8671fc0ee28d32672cf5b8c6f74589c204603fab3ebabca86310b3205f718cbfac39453e70d23f2288b490533ab82fbe207f8251c5dfc8ac2fc01ee38d4bdba4e936c88b813a05252dcefa40509ce599ccee49271298522457b047a886c24eb9fb465f1e156300915d7b67b19d1e653fa463b1725b728f9dd7b04ac1781d0dac1e825a17a08026769e01c3d098054be8a3aa248165eb30df2d7042a8703d4487667184445f37d6c604c20757da6834d0d1ca2cda258f7fd870a01ec3aaad4ebc0e9d082c718c17702cf8a380237ed4de57c322b521347c2256b01d74ae31196913f8e246330b6816c1b1ad6b9117037b6673a1446dbb43f27c30e920779499d62286028a9fd86751d37c8b868ce390ede7cb28faf99aae085300caa358a87478afc21a920b007515a9b3de67ef869336285bd6f1c485d4b73d70c756936b71ba3f11b051e780421fba2675b93a05368ac6fcf47f65d4f9006c6092f2bff91426309eb640502fccfba4ae41b831b07f2b6264be97e3df738111c09c8468b0b18b1827f65faa9022bb9b71189be0da19dc06209249a05072489b20274a5bffed30730638699159c92763b89e6addd40b6b6deb2526129a3fda54a09d3a52cc0e72fd23f2f4c8f07a75544ac45421240656aa2b7476eb0ae644e430f367aa0eeefc6dd88664bd4e604dd354a997103540b1211755dd127ceba13f10d4708340ab276b37a4604322c2ffc6e1c75fa4f7a269e7211e9125ee1f8c33f0da7a76783df38b7a97bb3b690689aad5bbda4f8870d3b8e37cb5fcf557d90200983527f96855d880129509097c7887bb68bd6fed252ecbc2aac1caa4314abfa027bc704afc2e932baf64df21a9a2f1b5122af0eed56f3e91e99934500fc64f10df527275fae7cfe96c37d5cc0e2790852cb02c591ec8d6b200996563c84ebbd08b1747bcbb687f2fa942129f0c214d986ae3ce6a7f43a4b5696fefab7333ec70163092514503aae6e50da0a4894c4b9accf396fc3404177c862e4a184da2ee504025be5073804234edc086d59d8e864624307978be8a135f6d02f6fff98d177095dd57f11fa9e99921b7c28de6deb2a869cd02aa4cbb7235889ea550c73ae96006b2230099f9863bdc6798d5581c705a3d509f7abcf2432768e01151c90161903d785c6b6985aa0c9d01ac38f71e7849fb602bf7e94c382da5f19b551a3d6f703ead04cc1799b7f143f012ec819753fcd29236558885d1c875574af72fbe6020f7d6b1c6dc9641c27b2d8b0bb1b457db8b44efeb68d9d95c7cec5559a57f8e104e41327a810d0411b0f95917990ed7a9c7b75aa7c720338cc716b511037242c0beaf0424bdf61be9c1eb8ec7f189251db203f366f1bb993ae068bd34f63d0020a5cde88b140fa5dcc1d920e7a265374d8b6bbe86d84b8b92761e6c5e514a9f701006c04a59d4c9b8e2b2c3ca93a04883a67969b2c9be4405e2ac366f6750ea8052a99478fe7a8dbb2c4a9096fc94f02e032b75d2154cdab2a4d7cfc19183f0f0d71b6fa1993b32a46aaa8e3709ad081836e0a5a52e93adc28672ff10b537fa7053692dc6831c056ee153a76a307dbffa04d8ce911e395230a7ef20ae884728105f7639a17b02d6e9278627116f2480ffd9f654055d9e5aa7873f56dd0770de30d7f97ab6a18d25f7501970f0b77d126b829327693cb487ebb7792753629771e0fe0182c370f545b355e12164395c80cde580c12bed9163eae126d15afe936920d5e5650665cc29ccfd0496ba5e3c29938f18a788c50b30cf54f68929207c4de0a23ac43aa30bd4009ded612f93c96614247f75f4a13fad90b57fe362eb561070184ac25f3225f51facc68b04fbff2d776bfe804311ae61c6028c2a536e150dc071d7cf6142b15684093cfb345ea22b8526adfbf7177694a9f62fd2031256d990afe874566f498e9b9f676033812d3f5443c6b3e6bbfbd4353efff2a5098e6770e9a1a4f70def6a85dab2afc12b948b7b7a2156c81a8ba3ab86625d4d00d62e80aa8865996447ffaf3ca3d2cd313438e0e41e243d2bbfeae4222059dd67c73c1051d8e9a128df49564e8a6f251ce86f039fb12c1ec21d4b1ee6654c32e699fbd0c5a029d017d935a15d46cf2e1b64cac6921f94e8fcda66614d302a841b2e8fc00fdcae8222a4411e8017fd1eefc21e4a0ad01635704b7ac3628a227981f9fa0094c77c3cb38f8ee471db3c85735bc1a90ea1b9bf045bd4fd5b5d2525874a9160eba3305433f222c7a539b7ad183c35fa58ae8fbd85e2c9999848df48cd865a80a0b46204a0cc406ba74eca22fca04d77a2cd87067f172031d29094e32f2c6ee06d9c3a51ba06b914b01556ffb5601cfd05aa1adf3e439ceaaaf21ed6352a0660c9e8cc16288249b9c7920077f5d4531d55c7cb7cc960bfb8b86367be0efe30d09c18e81a0b4316853730c29d16dd817718f6ec7e8b4734d620f2db0086906f80149c490590bef70db7e152dc557685ed977f4b08a5f710301c6aa0900b53026024ac513ffb7b8421801d455267ab433b06e3d0da1b912a04f4efe0401d27dd403db915a1c313c685f7afcfbe88040380f4180442cd367c8a1a88fa74694acfa086dc7e205597a80776caa9a51dbfc0fdfd999fd24a9886be5e45b0681fce11a0f94bc42fe8f5186157a8cede291acd10cb29b221903baed878d378791210a760e0af7fbcf97977e88276954f7d72fbb9aceb02ecba33d80fda9339e9e02945e07b71d8399311b7f24c24845f4b1ee6867a58091bef4132440ec68c18483f75b03562fbcbb3234cd45c68175d9eeb5a698881d48309d6c7d7b58959783c074cd0de565afcbe0eb530474dce9dfa03a24018be90b3f20510c16be2e856a89264b0e9bbf137585c341dfded0adcd7925dcc65be96802d67838b5cd023683adca7f08f8268c54b1cc3b535c2b71e8bfd8729a87df0cf042a025e059e9e8a5b95b350788cc46496b230e9a460236cbef153f938a7abfd3331308f9d1f6ed0acc82f30ef7d5772e7b99441a896b0e05372692d3ab32640fa78f46f0bc36dcefd53ca9098fc067352c7e873b9f0d19a4f27453c9b62700262700a3b4c0a351c8a29d8406ac7daa5e44af140c3c8b1f3d2ebb4b845097cfa9782a4715b2768de4dfe73d0d79ad67c345674538d7ec75b835f6a701ab95d304935185a3ef906f0b1c19a70206e6b03af99b833a00be005eb80de223fa640e14013d0fd7052b0cf7b43578023a598090ec0b5d08e3f9b3d81ad148a49ee319f91069a681c545742fe4970500e9ce4c9b6858d18246bf958ff7211ebca07a1b30dfcda9bba99fd66134357806500d3d820a7ab3c3ad35226efa2d2712fa0edbf87966fd625de6e9184f240d0a650a5455d49368a3f7677554ec87dff121ef1ddaf16ccfd1c703b032a3f8d006bc61e558d0364cf6f867ac76dafa66b059710c7f9147e3e07190bd63bf9c73060a2b51a4fc0e057611f57c6d1d6089433ec896edf5372fd993bff5b5d8b8960e972119e09b6d84b955181a02499f98a6eaa8b97cc044ab993f4b4e7f0fd2680866b9613c7336e45fd3076d0fd428819c2efdd76db297d7c240a52d54c0a0850c2cab511b8144321c234c11fe1decb78448c684367309ba1cf5a4d22ea1cf2b067ad7b2d81c0fc5ef96ac80d5ed35ddc29222d5f89b3ffb0aee7a310dc82a890b2b957ebfa95747a86137a8a662fc1aeb78732d57e16d6850797970945f80c40de6cded7477fdd2008998328d1bf49e0506ea87ac8e54d884b9ccf90eab320404478221f419e7ad162d0566613d9eb894d250efd4c15a9a26ba3e2a48137add02bee1f44bd8077358fe028675883966fbe3b09e2b46344440688d187f5b3967051d3db7085c5ce0c47884b1b433c9aa71de17b8ed48525de4502db1c47b3e2f05801007c868e0b823dbfeb25ebaec26214e03630c8b101f5145b989500099bd06ce5a44d7f18f7848acf7d2592b1dd53918aab2ea5c93f7c7887bafb25d77bb0a89ea20d51986e3bc847022f4bb6ca12ecc6e3f2ab1e780813b57df5bc84b53079324e81ab53187453065758f279601dd1a852db0c95c68bbe388445923d65609f9bb51fdd2f4ad2ab8a2c97ee338ca86a3d45462c8f1f9345d9a073694b0960e57a547d670956249f34770e62b945aa337f722059b4a6d59b7093495416e230d16cac7e498ee2ed399411f0f48f500aebe96934f514e1a08b73e591447cb850db6a000715dbb9541d0c32a286c391fcae23a2145c1ae48f75cc5c0c532709a01457926de113a42ec274157936e10f2d48d6c235d7245cab78588c2c4f6baec061952ab08e2a09619e106d0295094fef72d719e04f8ab73c8ee18a5ac3e71200478ddf20439885849561f098d7e81e8f00517d7657ec37ee72c950e4e8a9402072b072660e5a2cc8f138ab2c91d38c0c2846324171b97381f04682e4cbb6e6e06e492bc18d8b807772514bed262104b835e718d608b0c052f492fd9988e23d607dd9a9539bfb38661321f27bffaa6b64624d80d8f0481d6e11def4f279fdd3605e73ecf3205365fa548caf48be95491df36fc89392f3f0463146d61accf1d0d045f262c3b586ca2d8206fb54121f864bfd3a369ac30b3c5d4e54128991cd24b00a320e442c46d54a6206ed75fe136566e2e9a5f6d15eddbd5adb1cfa02d3d9902c175a03c50529aaca0379f98759e228f813d1faadead687116e9d7cce019ee0b2657fa7117b29361dbd849e8aa8c9bbaf9663179b92028059f76149dcc5dfc04dda34b17ffec9e7284fc2835e1596366cdec13dd7f711b5cd07a9a5f67bbef056afe2c73cc23e31b08028fb949fe10823cb325d8acbe078e0187a5fb0c860c00aa84c32c57739e719884d14139f2e67ad1f330be3a17d37180d208091b521f05ee083c237e317741cfabaa7ca46372951d944062158aba3482cbda4b4fa0d4094e287845e3fb0443086a7249612230e68c34de2b72526fd6bbb2ee6ca493d24ea2f5e02d985931abb3ea2325ede77d55de400cb7ac21f35a8bf4f4229a662443d68dd15bc1054fbe581518de734da565f0eeb8085cc3fb6df9acb0385d6a5d6df6b5c68c139e1d8ad931c74679c511582765d894312e3d1177cd7e00b6094fd3bf78699ee5c4bf285b8e535c4dacaf13d20bd96ce90c2c56e97e458b6f95528f2a08068d8de84b2ef50466984e78cc62fff47f54e9ba1037a8c4f295eeb3f6b98f903664e5d2fa7035c4a9398caf475f2ea3047318ad48e4cee730e8315d08d2d75f43e65153d3623f2464c2c8656cdc71a58b35cbe51536133c0b283310aff55ad9018230fdc8cc8d189dc3d6411a3663ebbf52560ad94325cb7b1b9638a90b669bc0ae3436cea9fef783f6cc58eb6604ada97febd35910482dcc0db31cf5632057a5603cc3ffc57212424529ea1bafde40ece5baf40cf3f93ceed623a528eaa348195d48181d2796f2741dcd2621a85de40acc3595c0b3a685d72c9acf8117b863490f6ef8da0b32e55a476e5b075fb668a515b5875dec4fc74c784420fe337196907cc0a05374f8ae479b5fd9c33fa3ffe564552c24584fe9d4b5fd4d1c1b3f49837d185d01805bb8e68366d1b92eef3ec9bc52c0b6754cb1f17ed3e66fc1508a0a429d23da1fff5ee9a072b7c9200e42691f0a1862667f2dc0adf227f8ab883fd26540bab532106a202963c1d6454381f1a57345e3ed92483d819ab4761b081a81d76fdbe62c63a35120f610d272aae8ef1223c4b5127d43fdf765923be61eca6d7470ec98449ff6d4238c3be8de62f50ac76749db2372390204462713b977ccda8f3918ec489eaeb322c099f9748a030911699da51d257c435b444cee789d53d22ae0b8ddedd2ef658aac38f2fb0da37b2be48a421f0f05a5591274fde71d7b998628611adf0d5d5df4e5a7bd95826715da9d02fef6805bd9b74a4cac3c04c785edb414200ef501e78a9df73db42e2f262d525988b4b640cfbd5add9ee8086482fcde0a6c533f407fe54fe63ed5e4dadcca181531421f354c48ab2675abf240e85f18ff6e5e8204d8ba65f3d97889307859ff145da746b799f4f43708f95d0370e62bc1f612180c888c7d8f95f93c9bb7953af56004606d97f7078fb12efb39612659f6068b357a11831a78cf73559bcfb27db229d179a177284f04560275137d0712489df2907b7093eb61f2ca9f4a903a9734de567f6671e6b9047d6aab50f75496a625de59c9d05b4b87eaea473e302f521f77a98c595d985fb1c7ada6cd278ff78bd517ef7fd096afdb9d19fbc0741a145418c58bff551da37d75fcb2ae3200cd8e68dce8483a825b21b985eca6f05c95e06cf54a2dac431a8ec8b3c851218b584a00136c83bb3e3d0d9faa43bf780b2b7467cbf3e5ae31d090944b0a63bea115c8ffdd9c82b9eb6a1db53c5fb74358f8cc876e66d8682788625b457aeed9af1c58c472dae24d41caf4ea3274d7be1c36dba647a1d6fefc6d87b0305f93bfc94fdaf97ca1cfad8758491a2ddae54056e89ad11f3c9bda51bc425dad52ff4938793a9e69d738a0a8f774834e776e57c87ef92f2c25dc7dbc7525e5617d9336fa3a484aa7315a971e8026c228e25a0048919086dee53c692dd394519313879b7c498821ce857e4ef98a1807c2d5bad64b2b06ba5a73d538b76968457a018fbfbf928232becea93dd67b13da7b0f9af55d4f2eb744d99a930431bbddf2110756c9d3a5cb4e0f1ad60f24b91f0a90d9eb75ada68f855d46c0616d97ecbf80e7cbbc94f7988eb43f95d87afe6dc75762085a01520159d8407f3371b3eb9f467efbe340ace9f325055bf14f9131e701c3dcec50336bd21b9d736fa60aee60ef9fe6d3822c2406b7da3574ac141b3e24ecdc27c29160805d5acd8996e953a79d8760f7c65f2def129bb03ed560a155bc3e1d3ebaedbff33657991c9c68834aafbef28771831a8ebf8e44dee70a329b697d7a3d822d85738625431b9ec786aa198389379b73552ed0c030872f3a8a1e8f67d27c686ddbad7c24608d44c9bfa1e6e5ffe3aedb597669fba50b18218535504f256531c356d677ad79acff96c5a8aae86614429dfcd27b25a142ecf871b03956c4f3cf4443a54613c598a4668aa210fc354e0f44726a8315bae4988ffff858e2a9eb65301730ff7c32536fefb0fa28b5ea29e165f009dfcca3d9e8a0f44a4e4d5a5566aa973b891b6dd98e2160b6cb0961f1c53cfe75423660efd74cfd9c26850d67a0264de4c0b1b418f22777cae593a48d9e7248910364b359d7e32ece97ad40c35b5de5566627d2d2688dc8fec0066c29c59bebc326c1573f690ffc67d7078d2f147d4e48d1a751ab9a157be67169cb38d2aa28603ea8ce797f8d6c79353989bd2837d55fbe4187edc73f4da1c47c7408068791c6336abe9f26e42893f19c953e195b7694389da03485720c4dd16ffd506796886804ce40ce977a2ab54684152fe30d3a35b2aba5b5d8ccf424329aaa5105291ca7ffb54329789c0b97b690b83f88fedf4e7daf91e51c4e7cff1b50092e17328909c911c452dd41c62990706dfaea588b943086ef76d8512a713974256f9a7b5159953e946c9c32e263c218902cbafb84150aa73448cc5b8cccba681e72cdf2d4d95ae762e24b6712e26bd4552f721a324978b7e7e2a539049db092994b439a3bc796bc722fd2191b2955fb10993c702cbe19738aa4011b6c6de09c0c10a13ce66cc0dd533a12ee54177bd5d5f69ccf2d365115a69481f59f0e2c2eef133fa246a08a6e3c7e807a441e2b1bf7b4842f1e7e8cd4bf16dba58bd378cbdb6d77acc2cd2832f3d5865562835b37634737b4aa0f973d13c4ce9cf8288d8b825d7b39c9cec5c413ef31345a4cfd9d142c9328a2487705c6081de8b40f263c4d63743e121780fdb160230d48f62986dd528e6fa58720538c9687aa0f0b1f2ff49a6fed0b96b309a1c1619c4a845e00141d0c26ff9756800e8b98de3b0d0c832d99298254d3453690d869df8bbf5e83d442c031f6c58017f7c0e5d5a303360fd32cb05bc147d69071ec969721adbd606165f7f3b4ecaee9431306c60e06ed0826acb1d491c3f2eee24e9956696b92574f7089041643dc3d081ae51379035d8688681eaa74282648f969afc98adf719a505d2e4cc461e9e505fae094b6090ad44ad9f10de2cadb596558d0975e1ae52db69add177addc906b78ff0a83e0fcf54140723cbc7c7fecfe37374cc4eeee4a08fd4db026200f123f9cdf9842c0933ad6f97bfbbb7303603666200b7126687d199e224334866b1d2b19e82266100943c180f934cfb97f65d2db529573d075eabd31dff0bca9af76b96e33c3a4e01ddf7ce8c8ca6fae6ca1c85782454cc2d23c353956b623e8193bd323ba9ec550f9c62b663a5510c818c7e0555c8605841bda70c347f0ff3c37bb34d21095c620ba6995ece411c4ddfdbf03bd08a614a5de9c13bb2bce63a6dfe5288ff409f9f00516116114e795057ec8dbdf61ac4b877b9afd99ea484e5c59dcfb03b6534360bc81c7658c0d4de5d84d7bf9d6df3c64bc5fef8cd9d429f8fa1db19f5e33218049a7fe536a1ab0932de6eeabb2b60f9b7b5432c7fe56dff9100c7c8296bee360a2143dc1ade2f92f4ab1c585f77f5cfc53323944b07ab026d8dd439265c38560d29eb98d26fe032ab233beabafba2e1525da3bb0e632bbac598efba0b38b8b504ebfe4fac3b5f51807579948978d1af50a4c5df66b35894505cdb7a679ff2e5090a6536d98549a27ced11b634a9dd379b0d7791dcce11c268eae8ebaf29e6cd01e4f7f00407bfa1dc790415756436ac46b077eb6a10364b9450a07811231a3f014cf777c0eebab8900a2478d9cb7be11f72827e7715cfe72a9b26aa9acc07e508980dedb34727aa180e323158835e962da2c5d046e546a7b35d3a71ae2573e10f05acf0f5caf8aa6dabfc9a1574a1eef15fc34a478c572b1eaff4cf901f63fb070b1d3f7d5f525b8aa8d34023f92cb309f137415b96054f9e57f0a46635ca9702303dc9dd7cbdc24b46c59841aa809bd373dbdf32488a3e3f356b41c501a5f90b94a1e1b8cf3e61009e18e94210b83b32e0e1812efe43210b5f384ba4c15de30e934d31bdbe9cff3ed4975b0c4da0d3d674f751e309c96ff38c49bf202e2cb60269651b9404a62420c374b988ca006612303b4debbd4595f6d0637e69237561017173ecba76aa843d7e876070f73941359b46c65cb4983932c01587a5d2c2f7081172b9e20652ff73a81733710e017b2b50707f2a6fbab6cefdbc9e5129a5ae04049b53ba7382a2d5c78fdce9938b246b6882cad116811d8c0c7be87a4ed12707820afc236cecbe4c553c4bf6f4a518d58b59a3a697bcd5f878ab303d200ffb00049d00586fac0882e21960c383683ac3f2c86aca0ff581197ee5c56b12fbb3060d40d9f450a5c5a5007ac922559a234d41f9298ce40667723865c4f1ff0b1b096913451d7b343174902a52751b80c923b427d41913042e0ce4eaf0fea0642200235e199eff66432bf4d53a26012ea8a8d619927f7c9f0a9c9990772c19e3cf04217f8883fc8061b694cbd30caf1afe91301961183a24bad7a53fbdf2b437850b17e6236a2beb861f840f6ef0076f07c831dbd9d895303b9a699fef7a118eee085a08bb61d83b4e641a869dc6615a9b626c2016af3c8f4107ce3771dfa1f9920b3842e0f4f130c456113060a4c3e071928ebd90ce3775d56336afb8b1eb03a1077e60786b75109abe273b959e443983deffb0f930f143f0153e85e0598bfdf80e09907fbb27ad08b7db046b19be630822bab48310030dac904d7a999c5e1a32056611b334d75164789a73a75bea1d5d6f2c7cef0756a5ea621bf02eaa950e9d06d47f6dd9af33920c33e488ec6e739aade25b860a0060f7b540672d39f46e64092507f8d758787b2bb16a7ec2bf7b65a70b05fb68bfa6c6b6decf3d777e6b5b0b9253e0ba20c199bbc3cf60fc24ca4ed71694836652ee6e7a8a5935c64e696d06ee72fa3696a46d2078c2128da007c1fad57417f1c2cb626f34a2dc393b08c70fff8668ee0cd9c3f08a205a80e20eac5437670628c9413bb68ad8a89f442bb70b92ac0c9b962d0f330e0de2b2f82fbd3730e95bbd4e50a8ff4f792862351c1c07d15b7f3e0ce0bd8fe013e3760701f8178a2ee86110e2be9afce91419efc8620551af717ce387b72a9ba83bd068bfcc21f55401c4a6dc80fde44351831423de0795090005e22520888519b57e83e600fcd360ec7fa9885b465caa820e05880708ba4217d2fc831f2d1d101c03f05b010975236bb93fda29b8129ce717a895ca016f4af15553dcacc7afbf2f64e6bb666d23f7e783b6539e48bccaeddbe83aa70c813c787581a64bb55b75a1cabe5033c7b9fa0e388c0b8de7ffecc2b23ee87601f2dc5d192da223feea7de3cba76edc3f57536a70b025e815e4c9af7fc4f5ec0d691b106d040d9a93c04e223858d95e2abadb53cd420d29eaac89962275e58b09b0462ff94e6f3ca4fdc2a0045ecdbda8218f0c04c8aad23dc82725923c98350c15d2470aa170e9ac3984ec62df285b05969aa32eecfe8b4e6fd5b38a1abf0803e2130716f0df48f381d56bb573d623858658c768207bb127975d70028cb8470e21f370b46de84ec1dfcfbf66addc9406a7ac86b5ed1096607b7562ffb3010f0ea7d0fa3de904a95f10720a28a2293ac57594b0bd91aa1a008c9e3be0b0c09c0dd0fc3d2361be613b0cb06c84f994018b963256d07d21985256e22d754e61d301970fed0781cf931aed808938f5103c84cd2e34ad4fb23d5470a1e8bd69c2d400c52b634d8061b2539473df8bb474e477abfd39f76b7e1126aee80def49427f036c50a7d37e2d6b56131248df2b8397d8f7c9847b1b5b93f9fd48c4f997ea320af4c274198cf3b466f315a3735a233461edbfc4ffca6478ba4fa32dbf4e2ef70904fbffe6d13466be5d0152d371cacb061198366ab7b43985f49871c2f41f1304d7a4517e8e300f75b71a655e405ec41d3b1994c57ef495983fc5e30c5557dc07dbd78b7e8c582c129a5d77204dea70692456c0e7bd811833a9afc9c42ab4fb0814bc9bd6087228f6cced657636114944f46e85bd3df766ab0feb726b72faf1025b418edf15550b96158b173c37158e0ba93679ee1af43a836e758b949ca843024ca0d3fb770f7104dc4c98446fb4e77ad1992a21dead6c704aca432c8f4cfb0740f9e3c744916b3024121c5928ee44906e87e944d40ac116fc894ae0786c620d6c45797e5d7371e7b1c64f90de11e3e7dc49cdb5d687f7ef99ae94b93482d707a6c8ffacc60966a3d37d6f412c461145d1725a83739db41dc0cce75c4f773703a9f5388a7eb647ed154ff718546958c22609269b155d5e9bbafa6e716c43e103b7118ca730ec5a650d36df4395c4b9c861d928a6f82da228aa1012416948150024a35084a2c836862051381acc687a49723ec84f6df2bca37473d59c7c5f0906d7edf633bb57f49d4a36cfb18cb139a46c72ece777a6c3f4d0c0c8ac652aab0440150deabd873b586c10ef5526fb5c2a6d96b0f25514afc9a004a075197d39085d6a951c68fe5955d800dba3f3db300121e61b227ce0b978b04fd0f3d2fa2c0f38146b0da08833f7fb7bf3276e37e7160d270bc5b52817a34e4a0b148facd301176df44eaf05cbb714f18fb105b5e84ee679486583caf9a34f54c0694cbd36080e410f48e9b523cb0d8aef317d4f689bc9fd0ff02f3cc5740f9aaac0c5b8cd0cbbf70ff6227fbd9a767b87ff182c1ddc94133fa4cb1bc82297dc7d3b1585d20e7f4035f9e5f26a9918dc7fa5aeb29bc0790b426f4cd2a79a39f6adef69b465066a93fd3f2552ab7a750138dc8655d49c0fb4e6684b858397efb4803260733501e1711ffe566cb10d779a0a3649f859844704ea73227b46c5eb365f8864a4b1055e87653c51dc879ad534572de7f8a502ef9fb4bb4e896b3eaefde2456dc3b1028b1f3ed93463514d9ec29491a9ab11cfe6505161fd614f32db12a65418ebca0c0e0cb34b43b1c2b4359e3340b5d2b7ef1cf2a904a5e255f7c78922cd33982e080e16931503175fdbfa43da6c83a47857c3340d2b374acaab85cfe9fe27d9eb09c436f9813f05315aa6671da45cabe411b2cddfff2365e001f6020d462ab32909abcaff0beb63a79539ea4ff10246a222229b2ddf74c299de70d3acbdf97c7f07d2537e2767a5b4455f081a40b766f7ad5d4bab427fa0bae2ddcf564b19e824006f1b0f34ee9a746b0c358cd5104859cdeb32d3332545accbf090d42f094c4c05767e2ad7642ef5d6e1d6343e94fba005b39922465cbde5baa7b65e77c36a4307c80e088c889c2a19fb3ada925ea15d62aa54f89a0438cc6f905a5246e8dcf80ab3c40dce9d3b8f50b11f1a2d60088a53ac8fa39d96a873c7dea0b4246ccee10b5b1db80ccfced77f9275837ff8a0f743bd1990d25f8b7e353027a497705d300ceea2faa499b5a7fbed813a88f0a9308d77c78148c5f9cc88f4437b9961cfd70868afef596604b63efb7033843fd7d245917c4bc18eee1a35229d032684c59207e726e9ad9fe16d1c0c1e51e6052581a1b9b80673080c0568f6969a647786a80379843ff035f44cc3cb46462f897ca8069ffd921e56872fc84a33a4fdb7093c0c890bc177227452130009dc5b87660785776ae083144387555351380db554820ecd9b4b33b3ef394c26d56116cde8688f67339d058c912c88f0e8a7de0084250ef7c686ffdf7ef5ad1829af39acd8c0cf14015398ce192d2dd93fc53b23092700d0a8edbe919297c62efcf00e48f64153c2018a65cc80c3fb6d5252807471650552904c49cbd7de01527c62a97639f0162ba2011b8366fa3433fe7bc454f4c90a92b70852e3573c230def6a75a231277ef73e9f337cfc3f2952d0c9795e8d4806c5e627f0cf24f760d4572826731a496c3b323d6ab98c870efde5c3fe9c690d04edc4027d69f69ebe1656c04e06a3c5265926256cbcb5343df7ca80ad6aa3fc0ac3fc5e2ba29a942202c077282309d3f305b25f81e13d4baa2d16d383bf57080740bc3625b96fe1393f9edd8d956f0339758d0e1746445a3cae301d95b9ecd90b97a9112ca9d52167814e782fa4d7640231ca86086ad40bb9760856fde692bb013fe9672057403055fd91b19f2cc44d7f008a4a573c72c26067e92a40b7ddf50ae5dadefa0a665b216a84160d58aafcd4e345c0ae0f92164b292d1e4754302d0fd277d31d355a6e8106d84868fb83ec62fe323030b189fe671dc9caea869e640cc400673893c99f88e0505da49dc5a0f90eddaf84a66cf4f2f3c6e11ee591d602cc030c63d59bf266c4bebe7c1b66c1bf246143164b4de54744f00f444778af05fa108ef427129339eae3340ec1d43dce8a14d99e83aefab957424e5238e77f031f644990aad3bd17f2b3fb9a816dae7a3c9f2e7fc9dc25d7665ada90adafe50c9cdbad79137a4541e89510778e85926d2d91744b8c090ccfb92efea599ed370e134d775566463bc4d30d163d906d6d19b47bd7bd44eecb89f75b91a9d86cf101caad3fd46b8470e434d96280ffcd670efe57a85cd853bcf5b5598e4ff1ba0f0dfb9450310dcd4774a0009f2b91f0a0603017ba0d32142b0826275d2939379305212767fd00bdbba49e70c1c3c58c5984dcc118e539c4d49740f03e477ef250498fd109944e1b59081b7e839888369fc7a2de206473217683eecb3f51184577bd924cf06d48d02e7e0632894097607a60a21ad87b9e89c1c2aaf38f06098fb28c8e850c1bc78239cb7686ada793b16274f5d8c05cbbfc49104cfca9da730f789ee047d041787bf07e5509301463ea42f520ed3b0bdb5e52e5892cbbe4060cdcf4757ec48c5654b08407de6a25d17b3138af5278ed61076927944aff8516444a1358ad64de92af03560ce5fcae3db166d478c07d407731f850de5ea7209ebece485989433595e9e9c22b11f892135571ad7f43bb92a8a2f68d6d65d182f7178edd25c725eb44437afc971d2ce9720e58658162b6ebc9abc5be81fde2b1e82cd031a8ff3cb624b2bdf17f1da7ceca80b9738a0022a5a325cfa85d77c431f11bf68dc296672989f2ba29a05f5798861835e79dafac3d272815372824aef5d39274b7381da6a3ecc6a09b21deac347694174a0f42a62fcd4df53e51419c42263b3d323185d46ea956321969751357cd53b383e0c3235800c10ef6dc294731eeaca48ec2b88b39728860dd5074cea83f111555dc07ae1257e76b7412f9492ce7923c1eb54cec26cc8c06f64b7e7831960c25d448d886065256d839d503388650c3a18c40c496c5468569ec0d4957c308e85c12d99add9a70979ee48cded650dc39a52e77e980cd6be46c474222b107984cd277417bddb692602e4ba925eaffaec6889c66867b82e5c3484061cd1337e2e245bf5c4a6e5ac2e01316f23d1526ea9b7ce97a720c7e67e276a18a16e11eace58c83edc490f371255568f2c8daac02301648169128c6b492519c5ecaa4f37b48232a811373a804cf23b93c07631a0041989b31021bf77f9cd14a20342d44616790eb85f8337ece742364c6af9f4c604033647ec5d7064ae072487454a1e21ccab8bb948d4fc95493cefdf46f794ad9bad3847487128d932f736dda564703224a1e7fcbeea5f569aa198f84545efc5c6017e4451d4fd65c6241ec7fca593d39ce7853d763ac5cc99be856df039841f8ba6782041153013527db153b5adea418d1b8c32b0fd5a82fc1e6e3bbf6601e520f2750b0eef33047a1a81ecffc658b6032c75d07db9af4e405d7a5841b79851aa6ac7de073c37ffdc44151f486de5982c32be8417e9a4b450df0a5822489dc1fafc506ca39cd6dd86fe83bee7683458171cf0d97be990c49af3bfe4937337f444e940e03f6e244ef3a43f1e6c0523b855df03ffa12b9b0ec34a1c7f17a2bd73870ac7780c0cfe17a0752c195707b139824ab8e5b1643395303e7614e307ff881529df9b5d7d7d99c2e5cc833c56adea2618ab528ef19eaffe08bb0cc07e621ab6c3e8c800e6270b48c1e05dad74733a7579428cf7e76b2f1f16171deab5e4c3b51954c0d18f7eb5c626fc4fa4e70fb7595cfe9261befcc7656dd1bd7acba7416aa1185e6da1ca9570b886ec85a66321608f8ac10596ab5ad948974f851ea7a897d4ea42f375fc5e967c8fd3997411f6bf392e9e9641b59db3bb356de5a34f2ba6a34888c7a9aafde60350f384583d76d55ceb8d6771f986624b0d859959b4a5a74ba85b2ba53ead6d80a44e078ab7fb0c6bdafa07943105ee7d273be9bed14d6127a7164925a78f703001f6a2a7eb11c54f7a342ff0d253e24a6235d06f97061a42a3b68db2d84328df6eee8dc94624292adf942b0183d5f67c44c88800fb1771a08482c32edc510564d6acfcbaa1d7d405a20c4e47f37288398a18b2dae47e9cbcc8c95e5fbd2c070dbb963d0558c3ea844d8c6cba6c84f9e9eeff93dbb72dafffa2a8fed6c4cdeb2ecd6524f54ca31ef4228b560ebe6cf1712597547febe9d5da2eb7017adfaf70e09624fdbf81da5ad7d6298574d311bd900ceb189452277334540a13616cc4a5f1ed14f140715d0047b6df396aa3824e6ea01ef308b1c0760f1423cc1e09eb4b63dda71a1c1f80cc1e191eecf789485b4e89940cb5dd35f4a16c8ec25cbfd1fe50068e8d9c91949cf65ba679784a5d39cc9c6805867afb90f4b76c2d956821752d00d68a16c7f11984e1f54570773936167ddda9add1c8dcd105903ec6315a4e5a28ec3d0f196bec7bf77a0c086285768dad76d3e812921cafb579f5e0c42dcba829a8e3c8f4467ce892b626d32602fd48e6da95ee3e2d923dbab4779664a472b7e42657be27b40f14c3568c56d0ebcc15a5a8ede4b3f43fc057e60f8f4d1ca802d518929734645bab8069016172120970ba2365248d697c5cc768b1107cf015f4528391820a16ba6a2429a0c89a495a45de09f8cb876ef0d6266cae9d50ad0c8ead39152ca425f3dc0712dcb6886e006f5048febcc503d1ce2880e925bcd6e90fe23de7341a12a4bdd253e251412629bbe5f927de264c5efd99a70e098ba05e11c0f0c8aa612b38d42a0944bc4fdeeea518a599aa3526d992d80f4d9574775693bc9ae4ddc5095c176e57fcb74306bdb353fc778c9581f68af828d4b1cff1d0b9f7146b7f1aeef8e55828364c51ded8c4295963baa8331b56a2580115ddce6659f4fc608bf3b44a588342108c33d6d39e0e88d6e3bd52b0d1388794d1b5263000e610122cf5cd3ffa42205053924edda90a4b623da2adebe3ffa3135866a19b7b551a904860d97b2cbcd74adbf1a8cf705bbe6b6601587bf3a4b66a164893793c76f677b79ad5ec9e400362b3d2b1916b16c7193cee13f6541b36a80e45745d2e6a99853e8c74221df7caf1beeaee59087d46c4bb120e2bc70a3f8937236ddf5797de940f9f83c1fdb1c7a6adcb7be1b49eeb2aeee75ecec53576a2984be7d1ba494736d0eb132d1c713ba75a33ce0dc77639aa1eb9c444d0c5f96072d45d391549ef96ce71191797fd1c059886c2110d2386484d43f7a311e062a9406a20c392cc4b3cb193864c9c9b28ff512b6e9300cc210acd00c05a53905f677b862956018b5b4d7507c98cb231cccaa3f1a718665cd353d1cfa36f3d10690bafd53e01275a626fa4ee7afd34d421dc8a545e343bd95a12c192ccff140097fa5a33b21a6f6058065127625574f9ea06a44c8dccf3cf7cca3fdc09766260d2c3e8c7a8d53f90e01d2c1cdd6d22a2e091e8471ecc1f294cc93de807ea7ca0f11f47b293eb24324a0537699bd3a0dfa409eab66833b33cfd4720ca24e86490161662cc5c2554e02b44c7b62c59e1d767b8b0e1438b64ca9ee8fbd9026ba3407177d143dbbc16ee08abce96dfae4f3b8680c5e0e17f17a887e7cff2364348d0505f2a3e05aae4e19ace2ac744fed130264c06c8ab29fbf2ce01870e3be68740858a2fe71c0ca57320b594bab089a5c9610d3d47d556c11e60ed39962e0d78e04092a4687c4eea07de9dcf29185fcce2ca54f755650a6521a163d922686b6e70dfc5617d9a8355692f2940c27b2e387815f17f971131c9f885ab384708739e605620deabb528409495f3241c601bae632054b0fcd96a78305ffe1caa49fecca07ce0cb5560266a06fc3c01bd730f44176a4d40d2b26ddfbfef2a9f6ab0e137805eda7fbda81af8a82723efe5aaabe17b31c963fdf6b8254a944a625ea0348910e4d9109141f6fe01d5fcb471197e55143ce55854412878930add65c69423c6e0f6174d2e5dffaa0504b43ce09ac320d1040fbc06ee5d93cb750e9e547397a8c048fed5e5bae73d4a86b843289e9821148a4aba4fb25b2ed12edd4701894a9430f1a88d25a7fd67dcff77d507f5c07f77fe40e2a941776b455681f9a05e238c904f4615976c47f2bdee35592c6db210452d7ba57091100dbe2b7c3061cbfcc970aa06c954b641b1183765b84d6ecb3e3ff0a901699bd9874869b10266e8c58e409"
+
+	tx_raw, _ := hex.DecodeString(tx_hex)
+
+	var tx Transaction
+	if err := tx.DeserializeHeader(tx_raw); err != nil {
+		t.Fatalf("Tx Deserialisation failed err %s", err)
+	}
+
+	var full map[string]interface{}
+	full_json, err := tx.Serialize_JSON(false)
+	if err != nil {
+		t.Fatalf("Tx json encoding failed err %s", err)
+	}
+	if err = json.Unmarshal(full_json, &full); err != nil {
+		t.Fatalf("Tx json is invalid err %s", err)
+	}
+
+	for _, field := range []string{"version", "unlock_time", "vin", "vout", "extra", "rct_signatures", "rctsig_prunable"} {
+		if _, ok := full[field]; !ok {
+			t.Fatalf("Tx json is missing field %s", field)
+		}
+	}
+	if len(full["vin"].([]interface{})) != len(tx.Vin) || len(full["vout"].([]interface{})) != len(tx.Vout) || len(full["extra"].([]interface{})) != len(tx.Extra) {
+		t.Fatalf("Tx json vin/vout/extra mismatch")
+	}
+
+	vin := full["vin"].([]interface{})[0].(map[string]interface{})["key"].(map[string]interface{})
+	if vin["k_image"] != tx.Vin[0].(Txin_to_key).K_image.String() {
+		t.Fatalf("Tx json key image mismatch")
+	}
+
+	rct := full["rct_signatures"].(map[string]interface{})
+	if uint64(rct["txnFee"].(float64)) != tx.RctSignature.Get_TX_Fee() || uint8(rct["type"].(float64)) != tx.RctSignature.Get_Sig_Type() {
+		t.Fatalf("Tx json rct signature mismatch")
+	}
+
+	prunable := full["rctsig_prunable"].(map[string]interface{})
+	if len(prunable["rangeSigs"].([]interface{})) != len(tx.Vout) || len(prunable["MGs"].([]interface{})) == 0 {
+		t.Fatalf("Tx json prunable part mismatch")
+	}
+
+	// pruned json must not carry prunable part
+	var pruned map[string]interface{}
+	pruned_json, err := tx.Serialize_JSON(true)
+	if err != nil {
+		t.Fatalf("Tx pruned json encoding failed err %s", err)
+	}
+	json.Unmarshal(pruned_json, &pruned)
+	if _, ok := pruned["rctsig_prunable"]; ok {
+		t.Fatalf("Tx pruned json contains prunable part")
+	}
+	if _, ok := pruned["rct_signatures"]; !ok {
+		t.Fatalf("Tx pruned json is missing base part")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package walletapi
+
+/* this file handles the communication with the daemon required while creating transactions
+ * this includes fetching ring members ( decoys ) from the output index
+ * and checking whether txs relayed by us are still known to the daemon
+ */
+import "fmt"
+import "net"
+import "time"
+import "bytes"
+import "net/http"
+import "compress/gzip"
+import "encoding/json"
+
+import "github.com/vmihailenco/msgpack"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/globals"
+
+// daemon which is used to fetch decoys, wallet cli/rpc set this up using Setup_Daemon
+// if not set, daemon on loopback at network specific default port is used
+var Daemon_Endpoint string
+
+var daemon_scheme = "http"                                     // https if daemon serves rpc over TLS
+var daemon_transport http.RoundTripper = http.DefaultTransport // adds login to every request
+var daemon_client = &http.Client{Timeout: 10 * time.Second, Transport: daemon_transport}
+
+// adds http basic auth to every request sent to the daemon
+type login_transport struct {
+	user     string
+	password string
+	next     http.RoundTripper
+}
+
+func (t *login_transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context()) // round trippers must not modify the request
+	req.SetBasicAuth(t.user, t.password)
+	return t.next.RoundTrip(req)
+}
+
+// setup connection to daemon, wallet cli and rpc use same settings for all their requests
+// login is user:password as provided to daemon --rpc-login, empty if daemon does not need login
+// if ssl is set, daemon is connected over TLS, fingerprint pins daemon certificate, see globals.RPC_Client_TLS_Config
+func Setup_Daemon(endpoint string, login string, ssl bool, fingerprint string) (err error) {
+	transport := &http.Transport{
+		Dial:                (&net.Dialer{Timeout: 5 * time.Second}).Dial,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+
+	scheme := "http"
+	if ssl {
+		if transport.TLSClientConfig, err = globals.RPC_Client_TLS_Config(fingerprint); err != nil {
+			return
+		}
+		scheme = "https"
+	}
+
+	var next http.RoundTripper = transport
+	if login != "" {
+		user, password, err := globals.Parse_RPC_Login(login)
+		if err != nil {
+			return err
+		}
+		next = &login_transport{user: user, password: password, next: transport}
+	}
+
+	Daemon_Endpoint = endpoint
+	daemon_scheme = scheme
+	daemon_transport = next
+	daemon_client = &http.Client{Timeout: 10 * time.Second, Transport: next}
+	return nil
+}
+
+// returns url of daemon path such as /json_rpc
+func Daemon_URL(path string) string {
+	return daemon_scheme + "://" + daemon_endpoint() + path
+}
+
+// returns http client which talks to the daemon using login and TLS settings of Setup_Daemon
+// timeout 0 means no timeout, used while streaming outputs
+func Daemon_Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: daemon_transport}
+}
+
+// fetch output data for a specific global index from the daemon
+// this uses the same getoutputs.bin stream which is used while scanning the chain
+func Get_Output_Data(index uint64) (output globals.TX_Output_Data, err error) {
+	// the daemon always streams start till stop ( both inclusive ), so ask for 1 more
+	response, err := daemon_client.Get(Daemon_URL(fmt.Sprintf("/getoutputs.bin?start=%d&stop=%d", index, index+1)))
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	gzipreader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		return
+	}
+	defer gzipreader.Close()
+
+	err = msgpack.NewDecoder(gzipreader).Decode(&output)
+	if err != nil {
+		return
+	}
+
+	if output.Index_Global != index {
+		err = fmt.Errorf("daemon returned output %d, requested %d", output.Index_Global, index)
+	}
+	return
+}
+
+// check whether the daemon knows the tx, either mined or in pool
+// this uses the monero compatible /gettransactions, which reports unknown txs with status "TX NOT FOUND"
+func TX_Known(txid crypto.Hash) (found bool, err error) {
+	request, err := json.Marshal(map[string][]string{"txs_hashes": {txid.String()}})
+	if err != nil {
+		return
+	}
+	response, err := daemon_client.Post(Daemon_URL("/gettransactions"), "application/json", bytes.NewReader(request))
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return
+	}
+	switch result.Status {
+	case "OK":
+		return true, nil
+	case "TX NOT FOUND":
+		return false, nil
+	}
+	return false, fmt.Errorf("daemon returned status %s", result.Status)
+}
+
+// returns the daemon endpoint, network is known only after startup so default is built here
+func daemon_endpoint() string {
+	if Daemon_Endpoint != "" {
+		return Daemon_Endpoint
+	}
+	return fmt.Sprintf("127.0.0.1:%d", globals.Config.RPC_Default_Port)
+}
@@ -0,0 +1,103 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package walletapi
+
+/* this file provides the transfer history of the wallet
+ * incoming transfers are listed per output, outgoing transfers are grouped per spending tx
+ */
+import "sort"
+import "bytes"
+
+import "github.com/arnaucode/derosuite/crypto"
+
+// single entry of wallet history
+type Transfer_Entry struct {
+	TXID          crypto.Hash
+	Height        uint64
+	Block_Time    uint64
+	Amount        uint64 // for outgoing transfers, this is total amount spent including fee, change is excluded
+	Unlock_Height uint64
+	Index_Global  uint64 // only for incoming transfers
+	Payment_ID    []byte
+	Incoming      bool
+}
+
+// get transfers between heights min_height and max_height ( both inclusive ), max_height 0 means no limit
+// change coming back to us is not listed as incoming
+func (user *Account) Get_Transfers(in bool, out bool, min_height uint64, max_height uint64) (entries []Transfer_Entry) {
+	user.Lock()
+	defer user.Unlock()
+
+	// group spendings per tx
+	spent := map[crypto.Hash]*Transfer_Entry{}
+	for _, output := range user.Outputs_Consumed {
+		entry, ok := spent[output.TXdata.TXID]
+		if !ok {
+			entry = &Transfer_Entry{TXID: output.TXdata.TXID, Height: output.TXdata.Height, Block_Time: output.TXdata.Block_Time}
+			spent[output.TXdata.TXID] = entry
+		}
+		entry.Amount += output.WAmount
+	}
+
+	for i := range user.Outputs_Array {
+		output := user.Outputs_Array[i]
+		if output.WSpent { // spendings are handled above
+			continue
+		}
+		if entry, ok := spent[output.TXdata.TXID]; ok { // this is change
+			entry.Amount -= output.WAmount
+			continue
+		}
+		if in && in_range(output.TXdata.Height, min_height, max_height) {
+			entries = append(entries, Transfer_Entry{
+				TXID:          output.TXdata.TXID,
+				Height:        output.TXdata.Height,
+				Block_Time:    output.TXdata.Block_Time,
+				Amount:        output.WAmount,
+				Unlock_Height: output.TXdata.Unlock_Height,
+				Index_Global:  output.TXdata.Index_Global,
+				Payment_ID:    output.WPaymentID,
+				Incoming:      true,
+			})
+		}
+	}
+
+	if out {
+		for _, entry := range spent {
+			if in_range(entry.Height, min_height, max_height) {
+				entries = append(entries, *entry)
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Height < entries[j].Height })
+	return
+}
+
+// get all incoming transfers which carry the payment id
+func (user *Account) Get_Payments(payment_id []byte) (entries []Transfer_Entry) {
+	for _, entry := range user.Get_Transfers(true, false, 0, 0) {
+		if len(entry.Payment_ID) > 0 && bytes.Equal(entry.Payment_ID, payment_id) {
+			entries = append(entries, entry)
+		}
+	}
+	return
+}
+
+func in_range(height uint64, min_height uint64, max_height uint64) bool {
+	return height >= min_height && (max_height == 0 || height <= max_height)
+}
@@ -0,0 +1,106 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package walletapi
+
+import "bytes"
+import "testing"
+import "encoding/hex"
+
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/transaction"
+
+// convert the outputs of a tx to output index entries, as the daemon does
+func test_tx_outputs(tx *transaction.Transaction, index uint64, height uint64) (outputs []globals.TX_Output_Data) {
+	tx.Parse_Extra()
+	for j := range tx.Vout {
+		var o globals.TX_Output_Data
+		o.TXID = tx.GetHash()
+		o.Tx_Public_Key = tx.Extra_map[transaction.TX_PUBLIC_KEY].(crypto.Key)
+		o.InKey.Destination = ringct.Key(tx.Vout[j].Target.(transaction.Txout_to_key).Key)
+		o.InKey.Mask = ringct.Key(tx.RctSignature.OutPk[j].Mask)
+		o.ECDHTuple = tx.RctSignature.ECdhInfo[j]
+		o.SigType = uint64(tx.RctSignature.Get_Sig_Type())
+		o.Index_within_tx = uint64(j)
+		o.Index_Global = index + uint64(j)
+		o.Height = height
+		if j == 0 {
+			for i := range tx.Vin {
+				o.Key_Images = append(o.Key_Images, crypto.Key(tx.Vin[i].(transaction.Txin_to_key).K_image))
+			}
+		}
+		if payment_id, ok := tx.PaymentID_map[transaction.TX_EXTRA_NONCE_ENCRYPTED_PAYMENT_ID]; ok {
+			o.PaymentID = append([]byte{byte(transaction.TX_EXTRA_NONCE_ENCRYPTED_PAYMENT_ID)}, payment_id.([]byte)...)
+		}
+		outputs = append(outputs, o)
+	}
+	return
+}
+
+func Test_Get_Transfers(t *testing.T) {
+	sender, _ := Generate_Keys_From_Random()
+	receiver, _ := Generate_Keys_From_Random()
+
+	chain, server := test_setup_chain(t, sender, 100)
+	defer server.Close()
+
+	payment_id, _ := hex.DecodeString("0123456789abcdef")
+	integrated := receiver.GetAddress()
+	integrated.PaymentID = payment_id
+
+	amount := uint64(100000000000)
+	tx, _, change, err := sender.Transfer([]address.Address{integrated}, []uint64{amount}, 0, "", 0, 5)
+	if err != nil {
+		t.Fatalf("Transfer failed err %s", err)
+	}
+	sender.Mark_Outputs_Spent(tx)
+	second, _, _, err := sender.Transfer([]address.Address{integrated}, []uint64{amount}, 0, "", 0, 5)
+	if err != nil {
+		t.Fatalf("second Transfer failed err %s", err)
+	}
+	if second.Vin[0].(transaction.Txin_to_key).K_image == tx.Vin[0].(transaction.Txin_to_key).K_image {
+		t.Fatalf("outputs marked spent must not be selected again")
+	}
+
+	outputs := test_tx_outputs(tx, uint64(len(chain))+1, 1001)
+	for i := range outputs {
+		receiver.Process_Output(&outputs[i])
+		sender.Process_Output(&outputs[i])
+	}
+
+	if received := receiver.Get_Transfers(true, true, 0, 0); len(received) != 1 || received[0].Amount != amount || !received[0].Incoming {
+		t.Fatalf("receiver transfers mismatch %+v", received)
+	}
+	payments := receiver.Get_Payments(payment_id)
+	if len(payments) != 1 || !bytes.Equal(payments[0].Payment_ID, payment_id) || payments[0].TXID != tx.GetHash() {
+		t.Fatalf("receiver payments mismatch %+v", payments)
+	}
+
+	// sender sees the spending and no change as incoming
+	sent := sender.Get_Transfers(false, true, 1001, 0)
+	if len(sent) != 1 || sent[0].Incoming || sent[0].Amount != amount+tx.RctSignature.Get_TX_Fee() {
+		t.Fatalf("sender transfers mismatch %+v change %d", sent, change)
+	}
+	if len(sender.Get_Transfers(true, false, 1001, 0)) != 0 {
+		t.Fatalf("change must not be listed as incoming")
+	}
+	if sender.Get_Height() != 1001 {
+		t.Fatalf("wallet height not updated")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package walletapi
+
+import "os"
+import "fmt"
+import "io/ioutil"
+
+import "github.com/vmihailenco/msgpack"
+
+import "github.com/arnaucode/derosuite/crypto"
+
+// this file implements saving wallet state, so as a restarted wallet does not need to resync
+// and does not reuse outputs spent by txs which are not yet mined
+// keys are never saved, the wallet is opened from its keys and then its state is loaded
+
+// this is how wallet state is saved to disk
+type account_state struct {
+	Address string // state belongs to this wallet
+
+	Index_Global   uint64
+	Height         uint64
+	Balance        uint64
+	Balance_Locked uint64
+
+	Outputs_Array    []TX_Wallet_Data
+	Outputs_Index    map[uint64]bool
+	Outputs_Ready    map[uint64]TX_Wallet_Data
+	Keyimages_Ready  map[crypto.Key]bool
+	Outputs_Consumed map[crypto.Key]TX_Wallet_Data
+}
+
+// save wallet state, file is written to a temp file and renamed so a crash never leaves a partial state
+func (user *Account) Save_State(filename string) (err error) {
+	user.Lock()
+	state := account_state{Address: user.GetAddress().String(), Index_Global: user.Index_Global, Height: user.Height,
+		Balance: user.Balance, Balance_Locked: user.Balance_Locked, Outputs_Array: user.Outputs_Array, Outputs_Index: user.Outputs_Index, Outputs_Ready: user.Outputs_Ready,
+		Keyimages_Ready: user.Keyimages_Ready, Outputs_Consumed: user.Outputs_Consumed}
+	data, err := msgpack.Marshal(&state)
+	user.Unlock()
+	if err != nil {
+		return
+	}
+
+	tmp_filename := filename + ".tmp"
+	if err = ioutil.WriteFile(tmp_filename, data, 0600); err != nil { // amounts and key images are private
+		return
+	}
+	return os.Rename(tmp_filename, filename)
+}
+
+// load wallet state saved by Save_State, a missing file is not an error, the wallet syncs from scratch
+// state of another wallet is refused
+func (user *Account) Load_State(filename string) (err error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return
+	}
+
+	state := account_state{Outputs_Index: map[uint64]bool{}, Outputs_Ready: map[uint64]TX_Wallet_Data{},
+		Keyimages_Ready: map[crypto.Key]bool{}, Outputs_Consumed: map[crypto.Key]TX_Wallet_Data{}}
+	if err = msgpack.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("wallet state %s could not be parsed err %s", filename, err)
+	}
+	if state.Address != user.GetAddress().String() {
+		return fmt.Errorf("wallet state %s belongs to wallet %s", filename, state.Address)
+	}
+
+	user.Lock()
+	defer user.Unlock()
+	user.Index_Global, user.Height = state.Index_Global, state.Height
+	user.Balance, user.Balance_Locked = state.Balance, state.Balance_Locked
+	user.Outputs_Array = state.Outputs_Array
+	user.Outputs_Index = state.Outputs_Index
+	user.Outputs_Ready = state.Outputs_Ready
+	user.Keyimages_Ready = state.Keyimages_Ready
+	user.Outputs_Consumed = state.Outputs_Consumed
+	return nil
+}
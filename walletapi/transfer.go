@@ -0,0 +1,442 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package walletapi
+
+import "fmt"
+import "math"
+import "sort"
+import "time"
+import "crypto/rand"
+import "encoding/hex"
+import "encoding/binary"
+import mrand "math/rand"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/transaction"
+import "github.com/arnaucode/derosuite/blockchain/inputmaturity"
+
+// this file implements creation of transactions, so as funds can be sent
+
+// the blockchain rejects txs with ring size lower than this
+const MINIMUM_MIXIN = 3
+
+// encrypted payment ids are xored with keccak(derivation || tail)
+const ENCRYPTED_PAYMENT_ID_TAIL = 0x8d
+
+// decoy ages are picked as exp(gamma) seconds, same as monero wallets
+// real spends are mostly recent outputs, so uniformly picked decoys would stand out
+const DECOY_GAMMA_SHAPE = 19.28
+const DECOY_GAMMA_SCALE = 1 / 1.61
+
+// decoys which cannot be fetched are replaced by other outputs, till these many fetches failed
+const DECOY_FETCH_RETRIES = 10
+
+// a ring member which is used while signing
+type ring_member struct {
+	index uint64
+	key   ringct.CtKey
+}
+
+// create a transaction which sends amount[i] to addr[i], rest of the funds come back as change
+// mixin is the ring size ( including the real input ), as checked by the blockchain
+// payment_id_hex can be empty, 64 hex chars ( unencrypted ) or 16 hex chars ( encrypted, only with single destination )
+// fees_per_kb is the dynamic fee as reported by the daemon
+// decoys are fetched from the daemon using Get_Output_Data
+// the returned serialized tx is ready for relay
+func (user *Account) Transfer(addr []address.Address, amount []uint64, unlock_time uint64, payment_id_hex string, fees_per_kb uint64, mixin uint64) (tx *transaction.Transaction, serialized []byte, change uint64, err error) {
+	return user.transfer(addr, amount, unlock_time, payment_id_hex, fees_per_kb, mixin, Get_Output_Data)
+}
+
+func (user *Account) transfer(addr []address.Address, amount []uint64, unlock_time uint64, payment_id_hex string, fees_per_kb uint64, mixin uint64, fetch func(uint64) (globals.TX_Output_Data, error)) (tx *transaction.Transaction, serialized []byte, change uint64, err error) {
+	if user.ViewOnly {
+		err = fmt.Errorf("view only wallet cannot send funds")
+		return
+	}
+	if len(addr) < 1 || len(addr) != len(amount) {
+		err = fmt.Errorf("number of addresses %d and amounts %d must be same and atleast 1", len(addr), len(amount))
+		return
+	}
+	if mixin < MINIMUM_MIXIN {
+		err = fmt.Errorf("mixin must be atleast %d", MINIMUM_MIXIN)
+		return
+	}
+
+	total := uint64(0)
+	for i := range amount {
+		if amount[i] == 0 {
+			err = fmt.Errorf("amount for destination %d is zero", i)
+			return
+		}
+		if total+amount[i] < total {
+			err = fmt.Errorf("amounts overflow")
+			return
+		}
+		total += amount[i]
+	}
+
+	// integrated address carries its own payment id
+	for i := range addr {
+		if addr[i].IsIntegratedAddress() {
+			if len(addr) != 1 || payment_id_hex != "" {
+				err = fmt.Errorf("integrated address can only be used with single destination and without payment id")
+				return
+			}
+			payment_id_hex = hex.EncodeToString(addr[i].PaymentID)
+		}
+	}
+
+	var payment_id []byte
+	if payment_id_hex != "" {
+		if payment_id, err = hex.DecodeString(payment_id_hex); err != nil {
+			err = fmt.Errorf("payment id is not valid hex err %s", err)
+			return
+		}
+		switch len(payment_id) {
+		case 32:
+		case 8:
+			if len(addr) != 1 {
+				err = fmt.Errorf("encrypted payment id can only be used with single destination")
+				return
+			}
+		default:
+			err = fmt.Errorf("payment id must be 64 or 16 hex chars")
+			return
+		}
+	}
+
+	// the fee depends on size, which depends on number of inputs, so try till it settles
+	fee := fees_per_kb
+	for tries := 0; tries < 10; tries++ {
+		var selected []TX_Wallet_Data
+		var sum uint64
+		selected, sum, err = user.select_outputs(total + fee)
+		if err != nil {
+			return
+		}
+		change = sum - total - fee
+
+		tx, err = user.build_transaction(addr, amount, unlock_time, payment_id, fee, change, mixin, selected, fetch)
+		if err != nil {
+			return
+		}
+
+		serialized = tx.Serialize()
+		needed_fee := calculate_fee(fees_per_kb, uint64(len(serialized)))
+		if needed_fee <= fee {
+			return
+		}
+		fee = needed_fee // more fee is required, try again
+	}
+
+	err = fmt.Errorf("fee could not be settled")
+	return nil, nil, 0, err
+}
+
+// once the tx has been relayed, mark its inputs as spent so they are not selected again
+// they are finally consumed when the tx is seen in the chain
+// if the tx never gets mined, Release_Stale_Spends makes them available again
+func (user *Account) Mark_Outputs_Spent(tx *transaction.Transaction) {
+	user.mark_outputs_spent(tx, time.Now())
+}
+
+func (user *Account) mark_outputs_spent(tx *transaction.Transaction, now time.Time) {
+	user.Lock()
+	defer user.Unlock()
+
+	txid := tx.GetHash()
+	for i := range tx.Vin {
+		key_image := crypto.Key(tx.Vin[i].(transaction.Txin_to_key).K_image)
+		for k, output := range user.Outputs_Ready {
+			if output.WKimage == key_image {
+				output.WSpent = true
+				output.WPending_TXID = txid
+				output.WPending_Time = now.Unix()
+				user.Outputs_Ready[k] = output
+			}
+		}
+	}
+}
+
+// daemon drops txs from pool after config.MEMPOOL_TX_LIVETIME, a pending tx which is neither mined
+// nor in pool by then never will be, so its inputs are unmarked and can be spent again
+// returns the number of outputs released, daemon is asked using TX_Known
+func (user *Account) Release_Stale_Spends() (released int) {
+	return user.release_stale_spends(time.Now(), TX_Known)
+}
+
+func (user *Account) release_stale_spends(now time.Time, known func(crypto.Hash) (bool, error)) (released int) {
+	// collect stale txs first, daemon is not queried with the lock held
+	stale := map[crypto.Hash]bool{}
+	user.Lock()
+	for _, output := range user.Outputs_Ready {
+		if output.WSpent && output.WPending_Time != 0 && now.Unix()-output.WPending_Time > int64(config.MEMPOOL_TX_LIVETIME) {
+			stale[output.WPending_TXID] = true
+		}
+	}
+	user.Unlock()
+
+	for txid := range stale {
+		if found, err := known(txid); err != nil || found { // mined txs are consumed once the wallet syncs them
+			delete(stale, txid)
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	user.Lock()
+	defer user.Unlock()
+	for k, output := range user.Outputs_Ready {
+		if output.WSpent && stale[output.WPending_TXID] {
+			output.WSpent = false
+			output.WPending_TXID = crypto.Hash{}
+			output.WPending_Time = 0
+			user.Outputs_Ready[k] = output
+			released++
+		}
+	}
+	return
+}
+
+// for every part of 1KB multiply by fee_per_kb, same as the blockchain
+func calculate_fee(fees_per_kb uint64, tx_size uint64) uint64 {
+	size_in_kb := tx_size / 1024
+	if (tx_size % 1024) != 0 { // for any part there of, use a full KB fee
+		size_in_kb += 1
+	}
+	return size_in_kb * fees_per_kb
+}
+
+// select mature unspent outputs, biggest first, till the needed amount is reached
+func (user *Account) select_outputs(needed uint64) (selected []TX_Wallet_Data, sum uint64, err error) {
+	user.Lock()
+	defer user.Unlock()
+
+	var candidates []TX_Wallet_Data
+	for k := range user.Outputs_Ready {
+		output := user.Outputs_Ready[k]
+		if output.WSpent {
+			continue
+		}
+		if inputmaturity.Is_Input_Mature(user.Height, output.TXdata.Height, output.TXdata.Unlock_Height, output.TXdata.SigType) {
+			candidates = append(candidates, output)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].WAmount > candidates[j].WAmount })
+
+	for i := range candidates {
+		if sum >= needed {
+			break
+		}
+		selected = append(selected, candidates[i])
+		sum += candidates[i].WAmount
+	}
+
+	if sum < needed {
+		err = fmt.Errorf("insufficient unlocked balance, needed %s available %s", globals.FormatMoney(needed), globals.FormatMoney(sum))
+	}
+	return
+}
+
+// sample gamma distribution using Marsaglia and Tsang method, shape must be >= 1
+func gamma_sample(r *mrand.Rand, shape, scale float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		if math.Log(r.Float64()) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v * scale
+		}
+	}
+}
+
+// pick global index of a decoy, recent outputs are picked more often
+// age is converted to outputs using average outputs per block, ages beyond the chain fall back to uniform pick
+func pick_decoy_index(r *mrand.Rand, max_index uint64, height uint64) uint64 {
+	age := math.Exp(gamma_sample(r, DECOY_GAMMA_SHAPE, DECOY_GAMMA_SCALE))                         // in seconds
+	blocks := age/float64(config.COIN_DIFFICULTY_TARGET) + float64(config.NORMAL_TX_AMOUNT_UNLOCK) // younger outputs are not spendable
+
+	outputs_per_block := float64(max_index)
+	if height > 0 {
+		outputs_per_block = float64(max_index) / float64(height)
+	}
+	offset := blocks * outputs_per_block
+	if offset >= float64(max_index) {
+		return uint64(r.Int63n(int64(max_index)))
+	}
+	return max_index - 1 - uint64(offset)
+}
+
+// pick random mature outputs from the chain as decoys
+// real input is placed in the ring and the ring is sorted by global index
+func (user *Account) build_ring(real TX_Wallet_Data, mixin uint64, fetch func(uint64) (globals.TX_Output_Data, error)) (ring []ring_member, err error) {
+	user.Lock()
+	max_index, height := user.Index_Global, user.Height
+	user.Unlock()
+
+	if max_index <= mixin {
+		err = fmt.Errorf("not enough outputs in chain for mixin %d", mixin)
+		return
+	}
+
+	var seed [8]byte
+	if _, err = rand.Read(seed[:]); err != nil {
+		return
+	}
+	r := mrand.New(mrand.NewSource(int64(binary.LittleEndian.Uint64(seed[:]))))
+
+	used := map[uint64]bool{real.TXdata.Index_Global: true}
+	ring = append(ring, ring_member{index: real.TXdata.Index_Global, key: real.TXdata.InKey})
+
+	failures := 0
+	for attempts := uint64(0); uint64(len(ring)) < mixin; attempts++ {
+		if attempts > mixin*20 {
+			err = fmt.Errorf("could not find enough mature decoys")
+			return
+		}
+
+		index := pick_decoy_index(r, max_index, height)
+		if used[index] {
+			continue
+		}
+		used[index] = true
+
+		decoy, ferr := fetch(index)
+		if ferr != nil {
+			if failures++; failures > DECOY_FETCH_RETRIES {
+				err = fmt.Errorf("decoy %d could not be fetched err %s", index, ferr)
+				return
+			}
+			continue // try another output
+		}
+
+		// immature decoys will be rejected by the daemon
+		if !inputmaturity.Is_Input_Mature(height, decoy.Height, decoy.Unlock_Height, decoy.SigType) {
+			continue
+		}
+		ring = append(ring, ring_member{index: index, key: decoy.InKey})
+	}
+
+	sort.Slice(ring, func(i, j int) bool { return ring[i].index < ring[j].index })
+	return
+}
+
+// build the complete transaction and sign it
+func (user *Account) build_transaction(addr []address.Address, amount []uint64, unlock_time uint64, payment_id []byte, fee uint64, change uint64, mixin uint64, selected []TX_Wallet_Data, fetch func(uint64) (globals.TX_Output_Data, error)) (tx *transaction.Transaction, err error) {
+	tx = &transaction.Transaction{}
+	tx.Version = 2
+	tx.Unlock_Time = unlock_time
+	tx.Extra_map = map[transaction.EXTRA_TAG]interface{}{}
+	tx.PaymentID_map = map[transaction.EXTRA_TAG]interface{}{}
+
+	// inputs and their rings
+	var inputs []ringct.Input_Info
+	for i := range selected {
+		var ring []ring_member
+		if ring, err = user.build_ring(selected[i], mixin, fetch); err != nil {
+			return nil, err
+		}
+
+		var input ringct.Input_Info
+		var vin transaction.Txin_to_key
+		input.Sk = selected[i].WKey
+		input.Amount = selected[i].WAmount
+		if selected[i].TXdata.SigType == 0 { // miner tx commitment is zero commitment, so mask is 1
+			input.Sk.Mask = ringct.Key{}
+			input.Sk.Mask[0] = 1
+		}
+
+		last := uint64(0)
+		for j := range ring {
+			if ring[j].index == selected[i].TXdata.Index_Global {
+				input.Index = j
+			}
+			input.Ring = append(input.Ring, ring[j].key)
+			vin.Key_offsets = append(vin.Key_offsets, ring[j].index-last) // offsets are relative
+			last = ring[j].index
+		}
+		vin.K_image = crypto.Hash(selected[i].WKimage)
+
+		inputs = append(inputs, input)
+		tx.Vin = append(tx.Vin, vin)
+	}
+
+	// outputs, change comes back to us as last output
+	destinations := append([]address.Address{}, addr...)
+	amounts := append([]uint64{}, amount...)
+	if change > 0 {
+		destinations = append(destinations, user.GetAddress())
+		amounts = append(amounts, change)
+	}
+
+	tx_secret_key, tx_public_key := crypto.NewKeyPair() // create new tx key pair
+	tx.Extra_map[transaction.TX_PUBLIC_KEY] = *tx_public_key
+
+	var outputs []ringct.Output_Info
+	for i := range destinations {
+		derivation := crypto.KeyDerivation(&destinations[i].ViewKey, tx_secret_key)
+		ephermal_public_key := derivation.KeyDerivation_To_PublicKey(uint64(i), destinations[i].SpendKey)
+		tx.Vout = append(tx.Vout, transaction.Tx_out{Amount: 0, Target: transaction.Txout_to_key{Key: ephermal_public_key}})
+
+		outputs = append(outputs, ringct.Output_Info{
+			Destination: ringct.Key(ephermal_public_key),
+			Amount:      amounts[i],
+			Amount_Key:  ringct.Key(*(derivation.KeyDerivationToScalar(uint64(i)))),
+		})
+
+		// encrypted payment id is for the first destination
+		if i == 0 && len(payment_id) == 8 {
+			key := crypto.Keccak256(derivation[:], []byte{ENCRYPTED_PAYMENT_ID_TAIL})
+			encrypted := make([]byte, 8, 8)
+			for j := range encrypted {
+				encrypted[j] = payment_id[j] ^ key[j]
+			}
+			tx.PaymentID_map[transaction.TX_EXTRA_NONCE_ENCRYPTED_PAYMENT_ID] = encrypted
+		}
+	}
+	if len(payment_id) == 32 {
+		tx.PaymentID_map[transaction.TX_EXTRA_NONCE_PAYMENT_ID] = payment_id
+	}
+	tx.Extra = tx.Serialize_Extra()
+
+	// single input uses full signature, otherwise simple
+	sigtype := uint8(ringct.RCTTypeSimple)
+	if len(inputs) == 1 {
+		sigtype = ringct.RCTTypeFull
+	}
+
+	tx.RctSignature, err = ringct.Generate_RctSig(sigtype, ringct.Key(tx.GetPrefixHash()), inputs, outputs, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(tx.Serialize())) > config.CRYPTONOTE_MAX_TX_SIZE {
+		return nil, fmt.Errorf("transaction is too big, try sending smaller amount")
+	}
+
+	return tx, nil
+}
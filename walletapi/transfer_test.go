@@ -0,0 +1,389 @@
+// Copyright 2017-2018 DERO Project. All rights reserved.
+// Use of this source code in any form is governed by RESEARCH license.
+// license can be found in the LICENSE file.
+// GPG: 0F39 E425 8C65 3947 702A  8234 08B2 0360 A03A 9DE8
+//
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY
+// EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL
+// THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT,
+// STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
+// THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package walletapi
+
+import "os"
+import "fmt"
+import "time"
+import "strconv"
+import mrand "math/rand"
+import "testing"
+import "net/http"
+import "compress/gzip"
+import "net/http/httptest"
+import "crypto/sha256"
+import "encoding/hex"
+import "path/filepath"
+
+import "github.com/vmihailenco/msgpack"
+import "github.com/sirupsen/logrus"
+
+import "github.com/arnaucode/derosuite/config"
+import "github.com/arnaucode/derosuite/crypto"
+import "github.com/arnaucode/derosuite/crypto/ringct"
+import "github.com/arnaucode/derosuite/globals"
+import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/transaction"
+
+// create a miner output for the account at specific global index
+func test_miner_output(user *Account, index uint64, amount uint64) (o globals.TX_Output_Data) {
+	tx_secret_key, tx_public_key := crypto.NewKeyPair()
+	addr := user.GetAddress()
+	derivation := crypto.KeyDerivation(&addr.ViewKey, tx_secret_key)
+
+	o.Tx_Public_Key = *tx_public_key
+	o.InKey.Destination = ringct.Key(derivation.KeyDerivation_To_PublicKey(0, addr.SpendKey))
+	o.InKey.Mask = ringct.ZeroCommitment_From_Amount(amount)
+	o.Amount = amount
+	o.Index_Global = index
+	o.Height = 1
+	return
+}
+
+// create a fake chain, with some outputs belonging to user and serve it as daemon does
+func test_setup_chain(t *testing.T, user *Account, count uint64) (chain []globals.TX_Output_Data, server *httptest.Server) {
+	for i := uint64(0); i < count; i++ {
+		var o globals.TX_Output_Data
+		if i%10 == 5 {
+			o = test_miner_output(user, i, 1000000000000)
+			if !user.Add_Transaction_Record_Funds(&o) {
+				t.Fatalf("miner output could not be added to wallet")
+			}
+		} else {
+			o.InKey.Destination = *(ringct.RandomPubKey())
+			o.InKey.Mask = *(ringct.RandomPubKey())
+			o.Index_Global = i
+			o.Height = 1
+			o.SigType = 2
+		}
+		chain = append(chain, o)
+	}
+	user.Index_Global = count
+	user.Height = 1000
+
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start, _ := strconv.ParseUint(req.URL.Query().Get("start"), 10, 64)
+		stop, _ := strconv.ParseUint(req.URL.Query().Get("stop"), 10, 64)
+		gzipwriter := gzip.NewWriter(rw)
+		defer gzipwriter.Close()
+		for i := start; i <= stop && i < uint64(len(chain)); i++ {
+			data, _ := msgpack.Marshal(&chain[i])
+			gzipwriter.Write(data)
+		}
+	}))
+	Daemon_Endpoint = server.Listener.Addr().String()
+	return
+}
+
+func Test_Transfer(t *testing.T) {
+	sender, _ := Generate_Keys_From_Random()
+	receiver, _ := Generate_Keys_From_Random()
+
+	_, server := test_setup_chain(t, sender, 100)
+	defer server.Close()
+
+	fees_per_kb := uint64(10000000000)
+
+	for _, amount := range []uint64{1500000000000, 100000000000} { // multiple inputs and single input
+		tx, serialized, change, err := sender.Transfer([]address.Address{receiver.GetAddress()}, []uint64{amount}, 0, "", fees_per_kb, 5)
+		if err != nil {
+			t.Fatalf("Transfer failed err %s", err)
+		}
+
+		if !tx.RctSignature.Verify() {
+			t.Fatalf("Transfer created tx with invalid signature")
+		}
+
+		fee := tx.RctSignature.Get_TX_Fee()
+		if fee < calculate_fee(fees_per_kb, uint64(len(serialized))) {
+			t.Fatalf("Transfer fee %d is less than required", fee)
+		}
+
+		// key images within vin must be the ones used in signature
+		kimages := tx.RctSignature.Get_Key_Images()
+		for i := range tx.Vin {
+			if crypto.Key(tx.Vin[i].(transaction.Txin_to_key).K_image) != crypto.Key(kimages[i]) {
+				t.Fatalf("key image mismatch for input %d", i)
+			}
+			if len(tx.Vin[i].(transaction.Txin_to_key).Key_offsets) != 5 {
+				t.Fatalf("ring size mismatch for input %d", i)
+			}
+		}
+
+		// the serialized tx must parse back to same tx
+		var parsed transaction.Transaction
+		if err = parsed.DeserializeHeader(serialized); err != nil {
+			t.Fatalf("serialized tx could not be parsed err %s", err)
+		}
+		if parsed.GetHash() != tx.GetHash() {
+			t.Fatalf("parsed tx hash mismatch")
+		}
+
+		// receiver must be able to find and decode the amount
+		tx.Parse_Extra()
+		tx_public := tx.Extra_map[transaction.TX_PUBLIC_KEY].(crypto.Key)
+		if !receiver.Is_Output_Ours(tx_public, 0, tx.Vout[0].Target.(transaction.Txout_to_key).Key) {
+			t.Fatalf("receiver cannot find output")
+		}
+		decoded, _, ok := receiver.Decode_RingCT_Output(tx_public, 0, crypto.Key(tx.RctSignature.OutPk[0].Mask), tx.RctSignature.ECdhInfo[0], uint64(tx.RctSignature.Get_Sig_Type()))
+		if !ok || decoded != amount {
+			t.Fatalf("receiver decoded amount %d expected %d", decoded, amount)
+		}
+
+		// change must come back to sender
+		if change > 0 {
+			last := uint64(len(tx.Vout) - 1)
+			if !sender.Is_Output_Ours(tx_public, last, tx.Vout[last].Target.(transaction.Txout_to_key).Key) {
+				t.Fatalf("change is not ours")
+			}
+		}
+	}
+}
+
+func Test_Transfer_Errors(t *testing.T) {
+	sender, _ := Generate_Keys_From_Random()
+	receiver, _ := Generate_Keys_From_Random()
+
+	_, server := test_setup_chain(t, sender, 30)
+	defer server.Close()
+
+	addr := []address.Address{receiver.GetAddress()}
+	tests := []struct {
+		name       string
+		amount     uint64
+		payment_id string
+		mixin      uint64
+	}{
+		{"insufficient funds", 100000000000000, "", 5},
+		{"low mixin", 1000, "", 2},
+		{"bad payment id", 1000, "1234", 5},
+		{"zero amount", 0, "", 5},
+	}
+
+	for _, test := range tests {
+		if _, _, _, err := sender.Transfer(addr, []uint64{test.amount}, 0, test.payment_id, 0, test.mixin); err == nil {
+			t.Fatalf("%s should have failed", test.name)
+		}
+	}
+
+	// payment ids
+	for _, payment_id := range []string{"0123456789abcdef", fmt.Sprintf("%064x", 1)} {
+		tx, _, _, err := sender.Transfer(addr, []uint64{1000}, 0, payment_id, 0, 4)
+		if err != nil {
+			t.Fatalf("payment id %s transfer failed err %s", payment_id, err)
+		}
+		tx.Parse_Extra()
+		if len(tx.PaymentID_map) != 1 {
+			t.Fatalf("payment id %s missing in tx", payment_id)
+		}
+	}
+}
+
+// outputs of a relayed tx which was never mined must become spendable again after the pool ttl
+func Test_Release_Stale_Spends(t *testing.T) {
+	sender, _ := Generate_Keys_From_Random()
+	receiver, _ := Generate_Keys_From_Random()
+
+	_, server := test_setup_chain(t, sender, 100)
+	defer server.Close()
+
+	tx, _, _, err := sender.Transfer([]address.Address{receiver.GetAddress()}, []uint64{100000000000}, 0, "", 0, 5)
+	if err != nil {
+		t.Fatalf("Transfer failed err %s", err)
+	}
+	now := time.Now()
+	sender.mark_outputs_spent(tx, now)
+
+	known := func(found bool, err error) func(crypto.Hash) (bool, error) {
+		return func(txid crypto.Hash) (bool, error) {
+			if txid != tx.GetHash() {
+				t.Fatalf("unexpected tx %s queried", txid)
+			}
+			return found, err
+		}
+	}
+	expired := now.Add(time.Duration(config.MEMPOOL_TX_LIVETIME+1) * time.Second)
+
+	if released := sender.release_stale_spends(now, known(false, nil)); released != 0 {
+		t.Fatalf("outputs released before ttl")
+	}
+	if released := sender.release_stale_spends(expired, known(true, nil)); released != 0 {
+		t.Fatalf("outputs of known tx released")
+	}
+	if released := sender.release_stale_spends(expired, known(false, fmt.Errorf("daemon down"))); released != 0 {
+		t.Fatalf("outputs released while daemon could not be queried")
+	}
+	if released := sender.release_stale_spends(expired, known(false, nil)); released != len(tx.Vin) {
+		t.Fatalf("outputs not released, released %d inputs %d", released, len(tx.Vin))
+	}
+
+	for _, output := range sender.Outputs_Ready {
+		if output.WSpent || output.WPending_Time != 0 {
+			t.Fatalf("output still marked spent %+v", output)
+		}
+	}
+	if selected, _, err := sender.select_outputs(uint64(len(sender.Outputs_Ready)) * 1000000000000); err != nil || len(selected) != len(sender.Outputs_Ready) {
+		t.Fatalf("released outputs cannot be selected again err %v", err)
+	}
+}
+
+// decoys which cannot be fetched are replaced, till too many fetches failed
+func Test_Build_Ring_Fetch_Failures(t *testing.T) {
+	sender, _ := Generate_Keys_From_Random()
+	chain, server := test_setup_chain(t, sender, 100)
+	server.Close()
+
+	real := sender.Outputs_Ready[0]
+	failed := 0
+	flaky := func(index uint64) (globals.TX_Output_Data, error) {
+		if failed < DECOY_FETCH_RETRIES/2 {
+			failed++
+			return globals.TX_Output_Data{}, fmt.Errorf("daemon busy")
+		}
+		return chain[index], nil
+	}
+	if ring, err := sender.build_ring(real, 5, flaky); err != nil || len(ring) != 5 {
+		t.Fatalf("ring not built with few fetch failures err %v", err)
+	}
+
+	down := func(index uint64) (globals.TX_Output_Data, error) {
+		return globals.TX_Output_Data{}, fmt.Errorf("daemon down")
+	}
+	if _, err := sender.build_ring(real, 5, down); err == nil {
+		t.Fatalf("ring built while daemon is down")
+	}
+}
+
+// decoys must be biased towards recent outputs, as real spends are
+func Test_Pick_Decoy_Index(t *testing.T) {
+	r := mrand.New(mrand.NewSource(1))
+	max_index, height := uint64(3000000), uint64(300000) // 10 outputs per block, 1 year of blocks
+
+	recent := 0
+	for i := 0; i < 1000; i++ {
+		index := pick_decoy_index(r, max_index, height)
+		if index >= max_index {
+			t.Fatalf("decoy %d out of range", index)
+		}
+		if index >= max_index-max_index/10 { // most recent 10% of the chain
+			recent++
+		}
+	}
+	if recent < 500 {
+		t.Fatalf("decoys not biased towards recent outputs, recent %d of 1000", recent)
+	}
+
+	if index := pick_decoy_index(r, 10, 1000); index >= 10 { // chain younger than picked age
+		t.Fatalf("decoy %d out of range", index)
+	}
+}
+
+// spend marks must survive a restart, otherwise a restarted wallet spends same outputs again
+func Test_Wallet_State(t *testing.T) {
+	sender, _ := Generate_Keys_From_Random()
+	receiver, _ := Generate_Keys_From_Random()
+
+	_, server := test_setup_chain(t, sender, 100)
+	defer server.Close()
+
+	tx, _, _, err := sender.Transfer([]address.Address{receiver.GetAddress()}, []uint64{100000000000}, 0, "", 0, 5)
+	if err != nil {
+		t.Fatalf("Transfer failed err %s", err)
+	}
+	sender.Mark_Outputs_Spent(tx)
+
+	filename := filepath.Join(os.TempDir(), "derod_test_wallet.state")
+	defer os.Remove(filename)
+	if err = sender.Save_State(filename); err != nil {
+		t.Fatalf("wallet state could not be saved err %s", err)
+	}
+
+	restarted, _ := Generate_Account_From_Seed(sender.Keys.Spendkey_Secret)
+	if err = restarted.Load_State(filename); err != nil {
+		t.Fatalf("wallet state could not be loaded err %s", err)
+	}
+	if restarted.Index_Global != sender.Index_Global || len(restarted.Outputs_Ready) != len(sender.Outputs_Ready) {
+		t.Fatalf("wallet state not restored")
+	}
+	if _, _, err = restarted.select_outputs(uint64(len(sender.Outputs_Ready)) * 1000000000000); err == nil {
+		t.Fatalf("outputs spent before restart can be selected again")
+	}
+
+	if err = receiver.Load_State(filename); err == nil {
+		t.Fatalf("state of another wallet loaded")
+	}
+	if err = receiver.Load_State(filename + ".missing"); err != nil {
+		t.Fatalf("missing state should not fail err %s", err)
+	}
+}
+
+func Test_TX_Known(t *testing.T) {
+	status := "OK"
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/gettransactions" {
+			t.Fatalf("unexpected path %s", req.URL.Path)
+		}
+		fmt.Fprintf(rw, `{"status":%q}`, status)
+	}))
+	defer server.Close()
+	Daemon_Endpoint = server.Listener.Addr().String()
+
+	for _, test := range []struct {
+		status string
+		found  bool
+		err    bool
+	}{{"OK", true, false}, {"TX NOT FOUND", false, false}, {"Failed to parse hash", false, true}} {
+		status = test.status
+		if found, err := TX_Known(crypto.Hash{1}); found != test.found || (err != nil) != test.err {
+			t.Fatalf("status %s found %t err %v", test.status, found, err)
+		}
+	}
+}
+
+// daemons running with --rpc-login and --rpc-ssl must be reachable
+func Test_Setup_Daemon(t *testing.T) {
+	globals.Logger = logrus.New()
+	server_config, err := globals.RPC_TLS_Config("", "")
+	if err != nil {
+		t.Fatalf("TLS config failed err %s", err)
+	}
+	server := httptest.NewUnstartedServer(globals.RPC_Auth_Handler("derod", "user", "pass", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(rw, `{"status":"OK"}`)
+	})))
+	server.TLS = server_config
+	server.StartTLS()
+	defer server.Close()
+	defer Setup_Daemon("", "", false, "")
+
+	fingerprint := sha256.Sum256(server_config.Certificates[0].Certificate[0])
+	if err = Setup_Daemon(server.Listener.Addr().String(), "user:pass", true, hex.EncodeToString(fingerprint[:])); err != nil {
+		t.Fatalf("daemon setup failed err %s", err)
+	}
+	if found, err := TX_Known(crypto.Hash{1}); err != nil || !found {
+		t.Fatalf("daemon with login and TLS not reachable err %v", err)
+	}
+
+	if err = Setup_Daemon(server.Listener.Addr().String(), "user:wrong", true, hex.EncodeToString(fingerprint[:])); err != nil {
+		t.Fatalf("daemon setup failed err %s", err)
+	}
+	if _, err := TX_Known(crypto.Hash{1}); err == nil {
+		t.Fatalf("daemon accepted wrong login")
+	}
+	if err = Setup_Daemon(server.Listener.Addr().String(), "user", false, ""); err == nil {
+		t.Fatalf("login without password accepted")
+	}
+}
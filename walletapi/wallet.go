@@ -25,6 +25,7 @@ import "github.com/arnaucode/derosuite/crypto/ringct"
 import "github.com/arnaucode/derosuite/globals"
 import "github.com/arnaucode/derosuite/walletapi/mnemonics"
 import "github.com/arnaucode/derosuite/address"
+import "github.com/arnaucode/derosuite/transaction"
 import "github.com/arnaucode/derosuite/blockchain/inputmaturity"
 
 type _Keys struct {
@@ -65,6 +66,11 @@ type TX_Wallet_Data struct {
 	WKey    ringct.CtKey // key which is used to later send this specific output
 	WKimage crypto.Key   // key image which gets consumed when this output is spent
 	WSpent  bool         // whether this output has been spent
+
+	WPending_TXID crypto.Hash // tx which spends this output, set when tx is relayed by us and till it is mined
+	WPending_Time int64       // when the spending tx was relayed, in epoch, spent mark is released if tx disappears
+
+	WPaymentID []byte // payment id of the tx, decrypted in case it was encrypted
 }
 
 // generate keys from using random numbers
@@ -259,6 +265,7 @@ func (user *Account) Add_Transaction_Record_Funds(txdata *globals.TX_Output_Data
 	}
 
 	tx_wallet.TXdata = *txdata
+	tx_wallet.WPaymentID = user.decode_payment_id(txdata)
 
 	// check whether we are deduplicating, is the transaction already in our records, skip it
 	if _, ok := user.Outputs_Index[txdata.Index_Global]; ok { // transaction is already in our wallet, skip it for being duplicate
@@ -283,6 +290,73 @@ func (user *Account) Add_Transaction_Record_Funds(txdata *globals.TX_Output_Data
 	return true
 }
 
+// payment ids are stored with tag, encrypted payment id can only be decrypted by the receiver
+func (user *Account) decode_payment_id(txdata *globals.TX_Output_Data) (payment_id []byte) {
+	switch {
+	case len(txdata.PaymentID) == 33 && txdata.PaymentID[0] == byte(transaction.TX_EXTRA_NONCE_PAYMENT_ID):
+		payment_id = append(payment_id, txdata.PaymentID[1:]...)
+
+	case len(txdata.PaymentID) == 9 && txdata.PaymentID[0] == byte(transaction.TX_EXTRA_NONCE_ENCRYPTED_PAYMENT_ID):
+		derivation := crypto.KeyDerivation(&txdata.Tx_Public_Key, &user.Keys.Viewkey_Secret)
+		key := crypto.Keccak256(derivation[:], []byte{ENCRYPTED_PAYMENT_ID_TAIL})
+		payment_id = make([]byte, 8, 8)
+		for i := range payment_id {
+			payment_id[i] = txdata.PaymentID[1+i] ^ key[i]
+		}
+	}
+	return
+}
+
+// process an output as streamed by the daemon, outputs must be supplied in order of global index
+// it adds the output to wallet if it is ours and tracks whether any of our funds are spent by the tx
+// returns the amount received and spent, wallet cli and rpc both use this
+func (user *Account) Process_Output(output *globals.TX_Output_Data) (received uint64, spent uint64) {
+	user.Lock()
+	if user.Index_Global >= output.Index_Global { // already processed earlier
+		user.Unlock()
+		return
+	}
+	user.Height = output.Height
+	user.Unlock()
+
+	if user.Is_Output_Ours(output.Tx_Public_Key, output.Index_within_tx, crypto.Key(output.InKey.Destination)) {
+		if user.Add_Transaction_Record_Funds(output) {
+			user.Lock()
+			received = user.Outputs_Ready[output.Index_Global].WAmount
+			user.Unlock()
+		}
+	}
+
+	// check this keyimage represents our funds
+	// if yes we have consumed that specific funds, mark them as such
+	for i := range output.Key_Images {
+		amount, result := user.Is_Our_Fund_Consumed(output.Key_Images[i])
+		if result {
+			spent += amount
+			user.Consume_Transaction_Record_Funds(output, output.Key_Images[i]) // decrease fund from our wallet
+		}
+	}
+
+	user.Lock()
+	user.Index_Global = output.Index_Global
+	user.Unlock()
+	return
+}
+
+// height till which wallet has processed the chain
+func (user *Account) Get_Height() uint64 {
+	user.Lock()
+	defer user.Unlock()
+	return user.Height
+}
+
+// global index of last output processed, sync continues from here
+func (user *Account) Get_Index_Global() uint64 {
+	user.Lock()
+	defer user.Unlock()
+	return user.Index_Global
+}
+
 // check whether our fund is consumed
 // this is done by finding the keyimages floating in blockchain, to what keyimages belong to this account
 //  if  match is found, we have consumed our funds